@@ -143,6 +143,8 @@ type Dal interface {
 	UpdateColumn(entityOrTable interface{}, columnName string, value interface{}, clauses ...Clause) errors.Error
 	// UpdateColumns allows you to update multiple columns of multiple records
 	UpdateColumns(entityOrTable interface{}, set []DalSet, clauses ...Clause) errors.Error
+	// UpdateColumnsCount is the same as UpdateColumns but returns the number of records affected
+	UpdateColumnsCount(entityOrTable interface{}, set []DalSet, clauses ...Clause) (int64, errors.Error)
 	// UpdateAllColumn updated all Columns of entity
 	UpdateAllColumn(entity interface{}, clauses ...Clause) errors.Error
 	// CreateOrUpdate tries to create the record, or fallback to update all if failed
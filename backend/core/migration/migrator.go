@@ -87,6 +87,11 @@ func (m *migratorImpl) Info(stage string) {
 
 // Execute all registered migration script in order and mark them as executed in migration_history table
 func (m *migratorImpl) Execute() errors.Error {
+	return m.ExecuteOnly(nil)
+}
+
+// ExecuteOnly executes the pending scripts accepted by the filter in order, the others are left pending
+func (m *migratorImpl) ExecuteOnly(filter func(script plugin.MigrationScript) bool) errors.Error {
 	// sort the scripts by version
 	sort.Slice(m.pending, func(i, j int) bool {
 		return m.pending[i].script.Version() < m.pending[j].script.Version()
@@ -94,24 +99,62 @@ func (m *migratorImpl) Execute() errors.Error {
 	m.Info("Execute")
 	// execute them one by one
 	db := m.basicRes.GetDal()
-	for _, swc := range m.pending {
+	skipped := make([]*scriptWithComment, 0)
+	for i, swc := range m.pending {
+		if filter != nil && !filter(swc.script) {
+			skipped = append(skipped, swc)
+			continue
+		}
 		scriptId := getScriptId(swc.script.Name(), swc.script.Version())
 		m.logger.Info("applying migration script %s", scriptId)
 		err := swc.script.Up(m.basicRes)
-		if err != nil {
-			return err
+		if err == nil {
+			err = db.Create(&MigrationHistory{
+				ScriptVersion: swc.script.Version(),
+				ScriptName:    swc.script.Name(),
+				Comment:       swc.comment,
+			})
+			if err != nil {
+				err = errors.Default.Wrap(err, fmt.Sprintf("failed to execute migration script %s", scriptId))
+			}
 		}
-		err = db.Create(&MigrationHistory{
-			ScriptVersion: swc.script.Version(),
-			ScriptName:    swc.script.Name(),
-			Comment:       swc.comment,
-		})
 		if err != nil {
-			return errors.Default.Wrap(err, fmt.Sprintf("failed to execute migration script %s", scriptId))
+			m.pending = append(skipped, m.pending[i:]...)
+			return err
 		}
 		m.executed[scriptId] = true
-		m.pending = m.pending[1:]
 	}
+	m.pending = skipped
+	return nil
+}
+
+// Scripts returns all registered scripts
+func (m *migratorImpl) Scripts() []plugin.MigrationScript {
+	m.Lock()
+	defer m.Unlock()
+	scripts := make([]plugin.MigrationScript, 0, len(m.scripts))
+	for _, swc := range m.scripts {
+		scripts = append(scripts, swc.script)
+	}
+	return scripts
+}
+
+// Refresh reloads the migration history and recomputes the pending list, scripts might be executed by
+// another devlake instance sharing the same database, or the history might be wiped out by a restoration
+func (m *migratorImpl) Refresh() errors.Error {
+	m.Lock()
+	defer m.Unlock()
+	err := m.loadExecuted()
+	if err != nil {
+		return err
+	}
+	pending := make([]*scriptWithComment, 0, len(m.scripts))
+	for _, swc := range m.scripts {
+		if !m.executed[getScriptId(swc.script.Name(), swc.script.Version())] {
+			pending = append(pending, swc)
+		}
+	}
+	m.pending = pending
 	return nil
 }
 
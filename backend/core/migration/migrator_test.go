@@ -85,3 +85,48 @@ func TestHasPendingScripts(t *testing.T) {
 	// make sure all method got called
 	mockDal.AssertExpectations(t)
 }
+
+func TestExecuteOnly(t *testing.T) {
+	mockDal := new(mockdal.Dal)
+	mockDal.On("AutoMigrate", mock.Anything, mock.Anything).Return(nil)
+	history := []MigrationHistory{}
+	mockDal.On("All", mock.Anything, mock.Anything).Return(func(i interface{}, _ ...dal.Clause) errors.Error {
+		*i.(*[]MigrationHistory) = history
+		return nil
+	})
+	mockDal.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	basicRes := context.NewDefaultBasicRes(viper.New(), unithelper.DummyLogger(), mockDal)
+	migrator, err := NewMigrator(basicRes)
+	assert.Nil(t, err)
+
+	newScript := func(name string, version uint64) *mockplugin.MigrationScript {
+		script := new(mockplugin.MigrationScript)
+		script.On("Version").Return(version)
+		script.On("Name").Return(name)
+		return script
+	}
+	scriptA := newScript("A", 1)
+	scriptB := newScript("B", 2)
+	scriptC := newScript("C", 3)
+	scriptA.On("Up", mock.Anything).Return(nil).Once()
+	scriptC.On("Up", mock.Anything).Return(nil).Once()
+	migrator.Register([]plugin.MigrationScript{scriptC, scriptB, scriptA}, "UnitTest")
+	assert.Len(t, migrator.Scripts(), 3)
+
+	// only A and C are applied, B is left pending
+	assert.Nil(t, migrator.ExecuteOnly(func(script plugin.MigrationScript) bool {
+		return script.Name() != "B"
+	}))
+	scriptB.AssertNotCalled(t, "Up", mock.Anything)
+
+	// the history got wiped out, all of them are pending again
+	assert.Nil(t, migrator.Refresh())
+	scriptA.On("Up", mock.Anything).Return(nil).Once()
+	scriptB.On("Up", mock.Anything).Return(nil).Once()
+	scriptC.On("Up", mock.Anything).Return(nil).Once()
+	assert.Nil(t, migrator.Execute())
+	scriptA.AssertNumberOfCalls(t, "Up", 2)
+	scriptB.AssertNumberOfCalls(t, "Up", 1)
+	scriptC.AssertNumberOfCalls(t, "Up", 2)
+}
@@ -22,6 +22,11 @@ import (
 	"time"
 )
 
+const (
+	API_KEY_PERMISSION_READ  = "read"
+	API_KEY_PERMISSION_WRITE = "write"
+)
+
 // ApiKey is the basic of api key management.
 type ApiKey struct {
 	common.Model
@@ -33,6 +38,17 @@ type ApiKey struct {
 	AllowedPath string     `json:"allowedPath"`
 	Type        string     `json:"type"`
 	Extra       string     `json:"extra"`
+	// read keys are limited to GET, HEAD and OPTIONS requests
+	Permission string `json:"permission"`
+	// limits the key to the resources of the project when set
+	ProjectName        string `json:"projectName"`
+	RateLimitPerMinute int    `json:"rateLimitPerMinute"`
+	// the hashed key replaced by the last rotation, it keeps working until PreviousExpiredAt
+	PreviousApiKey    string     `json:"previousApiKey,omitempty"`
+	PreviousExpiredAt *time.Time `json:"previousExpiredAt"`
+	LastUsedAt        *time.Time `json:"lastUsedAt"`
+	LastUsedIp        string     `json:"lastUsedIp"`
+	RequestCount      uint64     `json:"requestCount"`
 }
 
 func (apiKey *ApiKey) TableName() string {
@@ -41,13 +57,27 @@ func (apiKey *ApiKey) TableName() string {
 
 func (apiKey *ApiKey) RemoveHashedApiKey() {
 	apiKey.ApiKey = ""
+	apiKey.PreviousApiKey = ""
+}
+
+// IsReadOnly tells whether the key is limited to requests changing nothing
+func (apiKey *ApiKey) IsReadOnly() bool {
+	return apiKey.Permission == API_KEY_PERMISSION_READ
 }
 
 type ApiInputApiKey struct {
-	Name        string     `json:"name" validate:"required,max=255"`
-	Type        string     `json:"type" validate:"required"`
-	AllowedPath string     `json:"allowedPath" validate:"required"`
-	ExpiredAt   *time.Time `json:"expiredAt" `
+	Name               string     `json:"name" validate:"required,max=255"`
+	Type               string     `json:"type" validate:"required"`
+	AllowedPath        string     `json:"allowedPath" validate:"required"`
+	ExpiredAt          *time.Time `json:"expiredAt" `
+	Permission         string     `json:"permission" validate:"omitempty,oneof=read write"`
+	ProjectName        string     `json:"projectName" validate:"max=255"`
+	RateLimitPerMinute int        `json:"rateLimitPerMinute" validate:"gte=0"`
+}
+
+// ApiInputRotateApiKey tells how long the replaced key keeps working after the rotation
+type ApiInputRotateApiKey struct {
+	GracePeriodSeconds int64 `json:"gracePeriodSeconds" validate:"gte=0"`
 }
 
 type ApiOutputApiKey = ApiKey
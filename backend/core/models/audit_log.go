@@ -0,0 +1,80 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+)
+
+const (
+	AUDIT_ACTION_CREATE  = "create"
+	AUDIT_ACTION_UPDATE  = "update"
+	AUDIT_ACTION_DELETE  = "delete"
+	AUDIT_ACTION_TRIGGER = "trigger"
+	AUDIT_ACTION_CANCEL  = "cancel"
+	AUDIT_ACTION_RERUN   = "rerun"
+	AUDIT_ACTION_APPLY   = "apply"
+	AUDIT_ACTION_RESTORE = "restore"
+	AUDIT_ACTION_PURGE   = "purge"
+	AUDIT_ACTION_ROTATE  = "rotate"
+)
+
+const (
+	AUDIT_RESOURCE_CONNECTION   = "connection"
+	AUDIT_RESOURCE_SCOPE        = "scope"
+	AUDIT_RESOURCE_SCOPE_CONFIG = "scope-config"
+	AUDIT_RESOURCE_BLUEPRINT    = "blueprint"
+	AUDIT_RESOURCE_PROJECT      = "project"
+	AUDIT_RESOURCE_API_KEY      = "api-key"
+	AUDIT_RESOURCE_PIPELINE     = "pipeline"
+	AUDIT_RESOURCE_TASK         = "task"
+	AUDIT_RESOURCE_ROLE         = "role-assignment"
+	AUDIT_RESOURCE_GRANT        = "project-grant"
+	AUDIT_RESOURCE_CONFIG       = "config"
+	AUDIT_RESOURCE_BACKUP       = "backup"
+)
+
+// AuditChange is a single field changed by an audited action, sensitive values are redacted
+type AuditChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditLog is an append-only record of a configuration change or a manual action
+type AuditLog struct {
+	ID           uint64                 `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time              `json:"createdAt" gorm:"index"`
+	Actor        string                 `json:"actor" gorm:"type:varchar(255);index"`
+	ActorEmail   string                 `json:"actorEmail" gorm:"type:varchar(255)"`
+	ApiKeyId     uint64                 `json:"apiKeyId"`
+	ApiKeyName   string                 `json:"apiKeyName" gorm:"type:varchar(255)"`
+	Action       string                 `json:"action" gorm:"type:varchar(50);index"`
+	ResourceType string                 `json:"resourceType" gorm:"type:varchar(50);index"`
+	ResourceId   string                 `json:"resourceId" gorm:"type:varchar(255);index"`
+	PluginName   string                 `json:"pluginName" gorm:"type:varchar(255)"`
+	Method       string                 `json:"method" gorm:"type:varchar(10)"`
+	Path         string                 `json:"path"`
+	Before       map[string]interface{} `json:"before" gorm:"type:text;serializer:json"`
+	After        map[string]interface{} `json:"after" gorm:"type:text;serializer:json"`
+	Changes      []AuditChange          `json:"changes" gorm:"type:text;serializer:json"`
+}
+
+func (AuditLog) TableName() string {
+	return "_devlake_audit_logs"
+}
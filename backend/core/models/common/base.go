@@ -24,7 +24,9 @@ import (
 )
 
 const (
-	USER = "user"
+	USER      = "user"
+	PRINCIPAL = "principal"
+	API_KEY   = "apiKey"
 )
 
 type User struct {
@@ -0,0 +1,86 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package code
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// CodeOwnersFile is a version of the CODEOWNERS file of a repo, one per commit changing it.
+// FilePath is empty and RuleCount is 0 when the commit removed the file.
+type CodeOwnersFile struct {
+	common.NoPKModel
+	RepoId        string `gorm:"primaryKey;type:varchar(255)"`
+	CommitSha     string `gorm:"primaryKey;type:varchar(40)"`
+	FilePath      string `gorm:"type:varchar(255)"`
+	CommittedDate time.Time
+	RuleCount     int
+}
+
+func (CodeOwnersFile) TableName() string {
+	return "code_owners_files"
+}
+
+// CodeOwnerRule is a rule of a version of the CODEOWNERS file, Owners are separated by spaces.
+// Section is the name of the GitLab section the rule belongs to.
+type CodeOwnerRule struct {
+	common.NoPKModel
+	RepoId     string `gorm:"primaryKey;type:varchar(255)"`
+	CommitSha  string `gorm:"primaryKey;type:varchar(40)"`
+	LineNumber int    `gorm:"primaryKey"`
+	Section    string `gorm:"type:varchar(255)"`
+	Pattern    string `gorm:"type:varchar(500)"`
+	Owners     string
+}
+
+func (CodeOwnerRule) TableName() string {
+	return "code_owner_rules"
+}
+
+// PullRequestOwnerReview tells whether an owner of the files changed by a pull request reviewed it
+type PullRequestOwnerReview struct {
+	common.NoPKModel
+	PullRequestId  string `gorm:"primaryKey;type:varchar(255)"`
+	Owner          string `gorm:"primaryKey;type:varchar(255)"`
+	RepoId         string `gorm:"index;type:varchar(255)"`
+	TeamId         string `gorm:"index;type:varchar(255)"`
+	OwnedFileCount int
+	Reviewed       bool
+	ReviewerId     string `gorm:"type:varchar(255)"`
+}
+
+func (PullRequestOwnerReview) TableName() string {
+	return "pull_request_owner_reviews"
+}
+
+// OwnerReviewCoverage is the share of the pull requests changing owned files which were reviewed by the owners,
+// the row with empty TeamId is for the whole repo
+type OwnerReviewCoverage struct {
+	common.NoPKModel
+	RepoId          string `gorm:"primaryKey;type:varchar(255)"`
+	TeamId          string `gorm:"primaryKey;type:varchar(255)"`
+	PrCount         int
+	ReviewedPrCount int
+	Coverage        float64
+}
+
+func (OwnerReviewCoverage) TableName() string {
+	return "owner_review_coverages"
+}
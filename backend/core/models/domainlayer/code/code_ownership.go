@@ -0,0 +1,79 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package code
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	OWNERSHIP_PATH_FILE      = "FILE"
+	OWNERSHIP_PATH_DIRECTORY = "DIRECTORY"
+)
+
+// CodeOwnership is the share of the surviving lines of a file or directory written by an author, based on repo_snapshot
+type CodeOwnership struct {
+	common.NoPKModel
+	RepoId     string `gorm:"primaryKey;type:varchar(255)"`
+	Path       string `gorm:"primaryKey;type:varchar(255)"`
+	AuthorId   string `gorm:"primaryKey;type:varchar(255)"`
+	PathType   string `gorm:"type:varchar(20)"`
+	AuthorName string `gorm:"type:varchar(255)"`
+	Lines      int
+	TotalLines int
+	Share      float64
+}
+
+func (CodeOwnership) TableName() string {
+	return "code_ownerships"
+}
+
+// ComponentBusFactor is the minimum number of authors owning the majority of the surviving lines of a component,
+// the row with empty ComponentName is for the whole repo
+type ComponentBusFactor struct {
+	common.NoPKModel
+	RepoId         string `gorm:"primaryKey;type:varchar(255)"`
+	ComponentName  string `gorm:"primaryKey;type:varchar(255)"`
+	BusFactor      int
+	AuthorCount    int
+	TotalLines     int
+	TopAuthorId    string `gorm:"type:varchar(255)"`
+	TopAuthorShare float64
+}
+
+func (ComponentBusFactor) TableName() string {
+	return "component_bus_factors"
+}
+
+// FileHotspot combines the recent churn of a file with its complexity, files changed often and being complex come first
+type FileHotspot struct {
+	common.NoPKModel
+	RepoId              string `gorm:"primaryKey;type:varchar(255)"`
+	FilePath            string `gorm:"primaryKey;type:varchar(255)"`
+	CommitCount         int
+	Churn               int
+	AuthorCount         int
+	Lines               int
+	Complexity          *int
+	CognitiveComplexity *int
+	HotspotScore        float64
+}
+
+func (FileHotspot) TableName() string {
+	return "file_hotspots"
+}
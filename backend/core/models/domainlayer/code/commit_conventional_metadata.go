@@ -0,0 +1,83 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package code
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	SEMVER_BUMP_NONE    = "NONE"
+	SEMVER_BUMP_PATCH   = "PATCH"
+	SEMVER_BUMP_MINOR   = "MINOR"
+	SEMVER_BUMP_MAJOR   = "MAJOR"
+	SEMVER_BUMP_INVALID = "INVALID"
+)
+
+// CommitConventionalMetadata is the Conventional Commit header and footers parsed from the message of a commit,
+// IssueRefs are the issues referred by the footers separated by commas
+type CommitConventionalMetadata struct {
+	common.NoPKModel
+	CommitSha      string `gorm:"primaryKey;type:varchar(40)"`
+	IsConventional bool
+	Type           string `gorm:"type:varchar(100)"`
+	Scope          string `gorm:"type:varchar(255)"`
+	Breaking       bool
+	Description    string
+	IssueRefs      string
+}
+
+func (CommitConventionalMetadata) TableName() string {
+	return "commit_conventional_metadata"
+}
+
+// RefSemverCheck compares the version bump between two consecutive tags with the bump implied by the commits between them
+type RefSemverCheck struct {
+	common.NoPKModel
+	NewRefId                string `gorm:"primaryKey;type:varchar(255)"`
+	OldRefId                string `gorm:"primaryKey;type:varchar(255)"`
+	RepoId                  string `gorm:"index;type:varchar(255)"`
+	NewVersion              string `gorm:"type:varchar(255)"`
+	OldVersion              string `gorm:"type:varchar(255)"`
+	ActualBump              string `gorm:"type:varchar(20)"`
+	ExpectedBump            string `gorm:"type:varchar(20)"`
+	Compliant               bool
+	CommitCount             int
+	ConventionalCommitCount int
+}
+
+func (RefSemverCheck) TableName() string {
+	return "ref_semver_checks"
+}
+
+// RepoConventionalCompliance is the share of the commits following Conventional Commits and
+// the share of the tag pairs bumping the version as implied by their commits
+type RepoConventionalCompliance struct {
+	common.NoPKModel
+	RepoId                  string `gorm:"primaryKey;type:varchar(255)"`
+	CommitCount             int
+	ConventionalCommitCount int
+	ConventionalCommitRate  float64
+	TagPairCount            int
+	CompliantTagPairCount   int
+	SemverComplianceRate    float64
+}
+
+func (RepoConventionalCompliance) TableName() string {
+	return "repo_conventional_compliances"
+}
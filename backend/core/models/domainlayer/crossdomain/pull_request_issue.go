@@ -26,6 +26,8 @@ type PullRequestIssue struct {
 	IssueId        string `gorm:"primaryKey;type:varchar(255)"`
 	PullRequestKey int
 	IssueKey       string `gorm:"type:varchar(255)"`
+	Source         string `gorm:"type:varchar(100)"`
+	Confidence     float64
 	common.NoPKModel
 }
 
@@ -0,0 +1,41 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crossdomain
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// TeamAttribution attributes a pull request, commit, deployment or issue of a project to a team
+// through the account of its author or assignee and the team membership at the time
+type TeamAttribution struct {
+	common.NoPKModel
+	ProjectName    string `gorm:"primaryKey;type:varchar(100)"`
+	TeamId         string `gorm:"primaryKey;type:varchar(255)"`
+	EntityTable    string `gorm:"primaryKey;type:varchar(100)"`
+	EntityId       string `gorm:"primaryKey;type:varchar(255)"`
+	UserId         string `gorm:"type:varchar(255)"`
+	AccountId      string `gorm:"type:varchar(255)"`
+	AttributedDate *time.Time
+}
+
+func (TeamAttribution) TableName() string {
+	return "team_attributions"
+}
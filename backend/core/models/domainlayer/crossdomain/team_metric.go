@@ -0,0 +1,49 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crossdomain
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// TeamMetric is the monthly rollup of the data attributed to a team in a project, including the four dora metrics:
+// deployment frequency (DeploymentDays), change lead time, change failure rate and recovery time. Times are in minutes
+type TeamMetric struct {
+	common.NoPKModel
+	ProjectName           string    `gorm:"primaryKey;type:varchar(100)"`
+	TeamId                string    `gorm:"primaryKey;type:varchar(255)"`
+	Month                 time.Time `gorm:"primaryKey;type:date"`
+	PrCount               int
+	MergedPrCount         int
+	MedianPrCycleTime     *int64
+	CommitCount           int
+	DeploymentCount       int
+	DeploymentDays        int
+	MedianChangeLeadTime  *int64
+	FailedDeploymentCount int
+	ChangeFailureRate     *float64
+	MedianRecoveryTime    *int64
+	IssueCount            int
+	ResolvedIssueCount    int
+}
+
+func (TeamMetric) TableName() string {
+	return "team_metrics"
+}
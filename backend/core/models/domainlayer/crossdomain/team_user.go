@@ -18,12 +18,17 @@ limitations under the License.
 package crossdomain
 
 import (
+	"time"
+
 	"github.com/apache/incubator-devlake/core/models/common"
 )
 
+// TeamUser is the membership of a user in a team, the membership is unbounded when StartDate or EndDate is nil
 type TeamUser struct {
-	TeamId string `gorm:"primaryKey;type:varchar(255)"`
-	UserId string `gorm:"primaryKey;type:varchar(255)"`
+	TeamId    string `gorm:"primaryKey;type:varchar(255)"`
+	UserId    string `gorm:"primaryKey;type:varchar(255)"`
+	StartDate *time.Time
+	EndDate   *time.Time
 	common.NoPKModel
 }
 
@@ -30,24 +30,34 @@ import (
 func GetDomainTablesInfo() []dal.Tabler {
 	return []dal.Tabler{
 		// code
+		&code.CodeOwnerRule{},
+		&code.CodeOwnersFile{},
+		&code.CodeOwnership{},
 		&code.Commit{},
 		&code.CommitFile{},
 		&code.CommitFileComponent{},
+		&code.CommitConventionalMetadata{},
 		&code.CommitParent{},
 		&code.Component{},
+		&code.ComponentBusFactor{},
+		&code.FileHotspot{},
+		&code.OwnerReviewCoverage{},
 		&code.CommitLineChange{},
 		&code.PullRequest{},
 		&code.PullRequestComment{},
 		&code.PullRequestCommit{},
 		&code.PullRequestLabel{},
+		&code.PullRequestOwnerReview{},
 		&code.PullRequestReviewer{},
 		&code.PullRequestAssignee{},
 		&code.Ref{},
 		&code.CommitsDiff{},
 		&code.RefCommit{},
+		&code.RefSemverCheck{},
 		&code.RefsPrCherrypick{},
 		&code.Repo{},
 		&code.RepoCommit{},
+		&code.RepoConventionalCompliance{},
 		&code.RepoLanguage{},
 		&code.RepoSnapshot{},
 		// codequality
@@ -67,6 +77,8 @@ func GetDomainTablesInfo() []dal.Tabler {
 		&crossdomain.PullRequestIssue{},
 		&crossdomain.RefsIssuesDiffs{},
 		&crossdomain.Team{},
+		&crossdomain.TeamAttribution{},
+		&crossdomain.TeamMetric{},
 		&crossdomain.TeamUser{},
 		&crossdomain.User{},
 		&crossdomain.UserAccount{},
@@ -0,0 +1,94 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addTeamAttributions)(nil)
+
+type addTeamAttributions struct{}
+
+type teamUser20251107 struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+func (teamUser20251107) TableName() string {
+	return "team_users"
+}
+
+type teamAttribution20251107 struct {
+	archived.NoPKModel
+	ProjectName    string `gorm:"primaryKey;type:varchar(100)"`
+	TeamId         string `gorm:"primaryKey;type:varchar(255)"`
+	EntityTable    string `gorm:"primaryKey;type:varchar(100)"`
+	EntityId       string `gorm:"primaryKey;type:varchar(255)"`
+	UserId         string `gorm:"type:varchar(255)"`
+	AccountId      string `gorm:"type:varchar(255)"`
+	AttributedDate *time.Time
+}
+
+func (teamAttribution20251107) TableName() string {
+	return "team_attributions"
+}
+
+type teamMetric20251107 struct {
+	archived.NoPKModel
+	ProjectName           string    `gorm:"primaryKey;type:varchar(100)"`
+	TeamId                string    `gorm:"primaryKey;type:varchar(255)"`
+	Month                 time.Time `gorm:"primaryKey;type:date"`
+	PrCount               int
+	MergedPrCount         int
+	MedianPrCycleTime     *int64
+	CommitCount           int
+	DeploymentCount       int
+	FailedDeploymentCount int
+	ChangeFailureRate     *float64
+	MedianRecoveryTime    *int64
+	IssueCount            int
+	ResolvedIssueCount    int
+}
+
+func (teamMetric20251107) TableName() string {
+	return "team_metrics"
+}
+
+func (*addTeamAttributions) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		new(teamUser20251107),
+		new(teamAttribution20251107),
+		new(teamMetric20251107),
+	)
+}
+
+func (*addTeamAttributions) Version() uint64 {
+	return 20251107100000
+}
+
+func (*addTeamAttributions) Name() string {
+	return "add time bounds to team_users, add team_attributions and team_metrics"
+}
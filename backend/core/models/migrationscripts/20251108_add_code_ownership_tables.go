@@ -0,0 +1,95 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addCodeOwnershipTables)(nil)
+
+type addCodeOwnershipTables struct{}
+
+type codeOwnership20251108 struct {
+	archived.NoPKModel
+	RepoId     string `gorm:"primaryKey;type:varchar(255)"`
+	Path       string `gorm:"primaryKey;type:varchar(255)"`
+	AuthorId   string `gorm:"primaryKey;type:varchar(255)"`
+	PathType   string `gorm:"type:varchar(20)"`
+	AuthorName string `gorm:"type:varchar(255)"`
+	Lines      int
+	TotalLines int
+	Share      float64
+}
+
+func (codeOwnership20251108) TableName() string {
+	return "code_ownerships"
+}
+
+type componentBusFactor20251108 struct {
+	archived.NoPKModel
+	RepoId         string `gorm:"primaryKey;type:varchar(255)"`
+	ComponentName  string `gorm:"primaryKey;type:varchar(255)"`
+	BusFactor      int
+	AuthorCount    int
+	TotalLines     int
+	TopAuthorId    string `gorm:"type:varchar(255)"`
+	TopAuthorShare float64
+}
+
+func (componentBusFactor20251108) TableName() string {
+	return "component_bus_factors"
+}
+
+type fileHotspot20251108 struct {
+	archived.NoPKModel
+	RepoId              string `gorm:"primaryKey;type:varchar(255)"`
+	FilePath            string `gorm:"primaryKey;type:varchar(255)"`
+	CommitCount         int
+	Churn               int
+	AuthorCount         int
+	Lines               int
+	Complexity          *int
+	CognitiveComplexity *int
+	HotspotScore        float64
+}
+
+func (fileHotspot20251108) TableName() string {
+	return "file_hotspots"
+}
+
+func (*addCodeOwnershipTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		new(codeOwnership20251108),
+		new(componentBusFactor20251108),
+		new(fileHotspot20251108),
+	)
+}
+
+func (*addCodeOwnershipTables) Version() uint64 {
+	return 20251108100000
+}
+
+func (*addCodeOwnershipTables) Name() string {
+	return "add code_ownerships, component_bus_factors and file_hotspots"
+}
@@ -0,0 +1,105 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addCodeOwnersTables)(nil)
+
+type addCodeOwnersTables struct{}
+
+type codeOwnersFile20251109 struct {
+	archived.NoPKModel
+	RepoId        string `gorm:"primaryKey;type:varchar(255)"`
+	CommitSha     string `gorm:"primaryKey;type:varchar(40)"`
+	FilePath      string `gorm:"type:varchar(255)"`
+	CommittedDate time.Time
+	RuleCount     int
+}
+
+func (codeOwnersFile20251109) TableName() string {
+	return "code_owners_files"
+}
+
+type codeOwnerRule20251109 struct {
+	archived.NoPKModel
+	RepoId     string `gorm:"primaryKey;type:varchar(255)"`
+	CommitSha  string `gorm:"primaryKey;type:varchar(40)"`
+	LineNumber int    `gorm:"primaryKey"`
+	Section    string `gorm:"type:varchar(255)"`
+	Pattern    string `gorm:"type:varchar(500)"`
+	Owners     string
+}
+
+func (codeOwnerRule20251109) TableName() string {
+	return "code_owner_rules"
+}
+
+type pullRequestOwnerReview20251109 struct {
+	archived.NoPKModel
+	PullRequestId  string `gorm:"primaryKey;type:varchar(255)"`
+	Owner          string `gorm:"primaryKey;type:varchar(255)"`
+	RepoId         string `gorm:"index;type:varchar(255)"`
+	TeamId         string `gorm:"index;type:varchar(255)"`
+	OwnedFileCount int
+	Reviewed       bool
+	ReviewerId     string `gorm:"type:varchar(255)"`
+}
+
+func (pullRequestOwnerReview20251109) TableName() string {
+	return "pull_request_owner_reviews"
+}
+
+type ownerReviewCoverage20251109 struct {
+	archived.NoPKModel
+	RepoId          string `gorm:"primaryKey;type:varchar(255)"`
+	TeamId          string `gorm:"primaryKey;type:varchar(255)"`
+	PrCount         int
+	ReviewedPrCount int
+	Coverage        float64
+}
+
+func (ownerReviewCoverage20251109) TableName() string {
+	return "owner_review_coverages"
+}
+
+func (*addCodeOwnersTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		new(codeOwnersFile20251109),
+		new(codeOwnerRule20251109),
+		new(pullRequestOwnerReview20251109),
+		new(ownerReviewCoverage20251109),
+	)
+}
+
+func (*addCodeOwnersTables) Version() uint64 {
+	return 20251109100000
+}
+
+func (*addCodeOwnersTables) Name() string {
+	return "add code_owners_files, code_owner_rules, pull_request_owner_reviews and owner_review_coverages"
+}
@@ -0,0 +1,95 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addConventionalCommitTables)(nil)
+
+type addConventionalCommitTables struct{}
+
+type commitConventionalMetadata20251110 struct {
+	archived.NoPKModel
+	CommitSha      string `gorm:"primaryKey;type:varchar(40)"`
+	IsConventional bool
+	Type           string `gorm:"type:varchar(100)"`
+	Scope          string `gorm:"type:varchar(255)"`
+	Breaking       bool
+	Description    string
+	IssueRefs      string
+}
+
+func (commitConventionalMetadata20251110) TableName() string {
+	return "commit_conventional_metadata"
+}
+
+type refSemverCheck20251110 struct {
+	archived.NoPKModel
+	NewRefId                string `gorm:"primaryKey;type:varchar(255)"`
+	OldRefId                string `gorm:"primaryKey;type:varchar(255)"`
+	RepoId                  string `gorm:"index;type:varchar(255)"`
+	NewVersion              string `gorm:"type:varchar(255)"`
+	OldVersion              string `gorm:"type:varchar(255)"`
+	ActualBump              string `gorm:"type:varchar(20)"`
+	ExpectedBump            string `gorm:"type:varchar(20)"`
+	Compliant               bool
+	CommitCount             int
+	ConventionalCommitCount int
+}
+
+func (refSemverCheck20251110) TableName() string {
+	return "ref_semver_checks"
+}
+
+type repoConventionalCompliance20251110 struct {
+	archived.NoPKModel
+	RepoId                  string `gorm:"primaryKey;type:varchar(255)"`
+	CommitCount             int
+	ConventionalCommitCount int
+	ConventionalCommitRate  float64
+	TagPairCount            int
+	CompliantTagPairCount   int
+	SemverComplianceRate    float64
+}
+
+func (repoConventionalCompliance20251110) TableName() string {
+	return "repo_conventional_compliances"
+}
+
+func (*addConventionalCommitTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		new(commitConventionalMetadata20251110),
+		new(refSemverCheck20251110),
+		new(repoConventionalCompliance20251110),
+	)
+}
+
+func (*addConventionalCommitTables) Version() uint64 {
+	return 20251110100000
+}
+
+func (*addConventionalCommitTables) Name() string {
+	return "add commit_conventional_metadata, ref_semver_checks and repo_conventional_compliances"
+}
@@ -0,0 +1,68 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addRbacTables)(nil)
+
+type addRbacTables struct{}
+
+type roleAssignment20251116 struct {
+	archived.Model
+	archived.Creator
+	Subject string `gorm:"type:varchar(255);uniqueIndex"`
+	Role    string `gorm:"type:varchar(20)"`
+}
+
+func (roleAssignment20251116) TableName() string {
+	return "_devlake_role_assignments"
+}
+
+type projectGrant20251116 struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	archived.Creator
+	ProjectName string `gorm:"primaryKey;type:varchar(255)"`
+	Subject     string `gorm:"primaryKey;type:varchar(255)"`
+	Role        string `gorm:"type:varchar(20)"`
+}
+
+func (projectGrant20251116) TableName() string {
+	return "_devlake_project_grants"
+}
+
+func (*addRbacTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, new(roleAssignment20251116), new(projectGrant20251116))
+}
+
+func (*addRbacTables) Version() uint64 {
+	return 20251116100000
+}
+
+func (*addRbacTables) Name() string {
+	return "add _devlake_role_assignments and _devlake_project_grants"
+}
@@ -0,0 +1,69 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addApiKeyScopesAndUsage)(nil)
+
+type addApiKeyScopesAndUsage struct{}
+
+type apiKey20251118 struct {
+	ID                 uint64 `gorm:"primaryKey"`
+	Permission         string `gorm:"type:varchar(20)"`
+	ProjectName        string `gorm:"type:varchar(255)"`
+	RateLimitPerMinute int
+	PreviousApiKey     string `gorm:"type:varchar(255);index"`
+	PreviousExpiredAt  *time.Time
+	LastUsedAt         *time.Time
+	LastUsedIp         string `gorm:"type:varchar(255)"`
+	RequestCount       uint64
+}
+
+func (apiKey20251118) TableName() string {
+	return "_devlake_api_keys"
+}
+
+func (*addApiKeyScopesAndUsage) Up(basicRes context.BasicRes) errors.Error {
+	err := migrationhelper.AutoMigrateTables(basicRes, new(apiKey20251118))
+	if err != nil {
+		return err
+	}
+	// existing keys were allowed to send any request
+	return basicRes.GetDal().UpdateColumn(
+		new(apiKey20251118),
+		"permission", "write",
+		dal.Where("permission IS NULL OR permission = ''"),
+	)
+}
+
+func (*addApiKeyScopesAndUsage) Version() uint64 {
+	return 20251118100000
+}
+
+func (*addApiKeyScopesAndUsage) Name() string {
+	return "add permission, project, rate limit, rotation and usage to _devlake_api_keys"
+}
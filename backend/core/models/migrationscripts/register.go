@@ -141,5 +141,15 @@ func All() []plugin.MigrationScript {
 		new(addIssueFixVerion),
 		new(addPipelinePriority),
 		new(fixNullPriority),
+		new(addSourceToPullRequestIssues),
+		new(addTeamAttributions),
+		new(addCodeOwnershipTables),
+		new(addCodeOwnersTables),
+		new(addConventionalCommitTables),
+		new(addWorkerToPipelinesAndTasks),
+		new(addRbacTables),
+		new(addAuditLogs),
+		new(addApiKeyScopesAndUsage),
+		new(addDoraMetricsToTeamMetrics),
 	}
 }
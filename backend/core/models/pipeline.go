@@ -67,7 +67,10 @@ type Pipeline struct {
 	Stage         int          `json:"stage"`
 	Labels        []string     `json:"labels" gorm:"-"`
 	Priority      int          `json:"priority"` // greater is higher
-	SyncPolicy    `gorm:"embedded"`
+	// WorkerId and LeaseExpiresAt record which devlake instance claimed the pipeline in HA mode
+	WorkerId       string     `json:"workerId" gorm:"type:varchar(255);index"`
+	LeaseExpiresAt *time.Time `json:"leaseExpiresAt"`
+	SyncPolicy     `gorm:"embedded"`
 }
 
 // We use a 2D array because the request body must be an array of a set of tasks
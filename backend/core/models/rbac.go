@@ -0,0 +1,110 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	ROLE_ADMIN      = "admin"
+	ROLE_MAINTAINER = "maintainer"
+	ROLE_VIEWER     = "viewer"
+)
+
+// RoleAssignment grants a global role to a subject, which is either the user name or the email
+// forwarded by the authentication proxy. Admins can do anything while viewers can read everything
+type RoleAssignment struct {
+	common.Model
+	common.Creator
+	Subject string `json:"subject" gorm:"type:varchar(255);uniqueIndex" validate:"required"`
+	Role    string `json:"role" gorm:"type:varchar(20)" validate:"required,oneof=admin viewer"`
+}
+
+func (RoleAssignment) TableName() string {
+	return "_devlake_role_assignments"
+}
+
+// ProjectGrant grants a project scoped role to a subject. Maintainers can manage the project,
+// its blueprint, pipelines and the connections used by it, viewers can only read them
+type ProjectGrant struct {
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	common.Creator
+	ProjectName string `json:"projectName" gorm:"primaryKey;type:varchar(255)"`
+	Subject     string `json:"subject" gorm:"primaryKey;type:varchar(255)" validate:"required"`
+	Role        string `json:"role" gorm:"type:varchar(20)" validate:"required,oneof=maintainer viewer"`
+}
+
+func (ProjectGrant) TableName() string {
+	return "_devlake_project_grants"
+}
+
+// Principal is the effective permission set of the current user
+type Principal struct {
+	User         *common.User      `json:"user"`
+	Role         string            `json:"role"`
+	ProjectRoles map[string]string `json:"projectRoles"`
+}
+
+// IsAdmin returns true if the principal has full access
+func (p *Principal) IsAdmin() bool {
+	return p.Role == ROLE_ADMIN
+}
+
+// IsMaintainer returns true if the principal is an admin or maintains any project
+func (p *Principal) IsMaintainer() bool {
+	if p.IsAdmin() {
+		return true
+	}
+	for _, role := range p.ProjectRoles {
+		if role == ROLE_MAINTAINER {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReadAll returns true if the principal can read resources of all projects
+func (p *Principal) CanReadAll() bool {
+	return p.Role == ROLE_ADMIN || p.Role == ROLE_VIEWER
+}
+
+// CanRead returns true if the principal can read resources of the project
+func (p *Principal) CanRead(projectName string) bool {
+	return p.CanReadAll() || p.ProjectRoles[projectName] != ""
+}
+
+// CanWrite returns true if the principal can manage resources of the project
+func (p *Principal) CanWrite(projectName string) bool {
+	return p.IsAdmin() || p.ProjectRoles[projectName] == ROLE_MAINTAINER
+}
+
+// ReadableProjects returns names of projects the principal can read, nil means all projects
+func (p *Principal) ReadableProjects() []string {
+	if p.CanReadAll() {
+		return nil
+	}
+	projectNames := make([]string, 0, len(p.ProjectRoles))
+	for projectName := range p.ProjectRoles {
+		projectNames = append(projectNames, projectName)
+	}
+	return projectNames
+}
@@ -0,0 +1,56 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipal(t *testing.T) {
+	admin := &Principal{Role: ROLE_ADMIN}
+	assert.True(t, admin.IsAdmin())
+	assert.True(t, admin.IsMaintainer())
+	assert.True(t, admin.CanWrite("any"))
+	assert.Nil(t, admin.ReadableProjects())
+
+	viewer := &Principal{Role: ROLE_VIEWER, ProjectRoles: map[string]string{"team-a": ROLE_MAINTAINER}}
+	assert.False(t, viewer.IsAdmin())
+	assert.True(t, viewer.IsMaintainer())
+	assert.True(t, viewer.CanRead("team-b"))
+	assert.False(t, viewer.CanWrite("team-b"))
+	assert.True(t, viewer.CanWrite("team-a"))
+	assert.Nil(t, viewer.ReadableProjects())
+
+	member := &Principal{ProjectRoles: map[string]string{"team-a": ROLE_VIEWER, "team-b": ROLE_MAINTAINER}}
+	assert.False(t, member.CanReadAll())
+	assert.True(t, member.IsMaintainer())
+	assert.True(t, member.CanRead("team-a"))
+	assert.False(t, member.CanWrite("team-a"))
+	assert.True(t, member.CanWrite("team-b"))
+	assert.False(t, member.CanRead("team-c"))
+	projects := member.ReadableProjects()
+	sort.Strings(projects)
+	assert.Equal(t, []string{"team-a", "team-b"}, projects)
+
+	nobody := &Principal{ProjectRoles: map[string]string{}}
+	assert.False(t, nobody.IsMaintainer())
+	assert.Equal(t, []string{}, nobody.ReadableProjects())
+}
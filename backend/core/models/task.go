@@ -77,6 +77,7 @@ type Task struct {
 	BeganAt       *time.Time `json:"beganAt"`
 	FinishedAt    *time.Time `json:"finishedAt" gorm:"index"`
 	SpentSeconds  int        `json:"spentSeconds"`
+	WorkerId      string     `json:"workerId" gorm:"type:varchar(255)"`
 }
 
 func (Task) TableName() string {
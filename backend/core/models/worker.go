@@ -0,0 +1,47 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import "time"
+
+// Worker is a devlake instance participating in HA mode, multiple workers may share the same database.
+// Each worker refreshes its HeartbeatAt periodically, a worker whose heartbeat is older than the lease
+// duration is considered dead and the pipelines it claimed would be taken over by the others.
+type Worker struct {
+	Id          string    `gorm:"primaryKey;type:varchar(255)" json:"id"`
+	HostName    string    `gorm:"type:varchar(255)" json:"hostName"`
+	Version     string    `gorm:"type:varchar(255)" json:"version"`
+	StartedAt   time.Time `json:"startedAt"`
+	HeartbeatAt time.Time `json:"heartbeatAt"`
+}
+
+func (Worker) TableName() string {
+	return "_devlake_workers"
+}
+
+// LeaderLease is a named lease held by one worker at a time, it is used for electing the worker
+// responsible for singleton duties like triggering blueprints and running migrations
+type LeaderLease struct {
+	Name      string    `gorm:"primaryKey;type:varchar(100)" json:"name"`
+	Holder    string    `gorm:"type:varchar(255)" json:"holder"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (LeaderLease) TableName() string {
+	return "_devlake_leader_leases"
+}
@@ -27,10 +27,11 @@ import (
 
 // ApiResourceInput Contains api request information
 type ApiResourceInput struct {
-	Params  map[string]string      // path variables
-	Query   url.Values             // query string
-	Body    map[string]interface{} // json body
-	Request *http.Request
+	Params   map[string]string      // path variables
+	Query    url.Values             // query string
+	Body     map[string]interface{} // json body
+	BodyList []interface{}          // json body, when it is an array
+	Request  *http.Request
 
 	User *common.User
 }
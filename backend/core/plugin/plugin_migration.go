@@ -33,7 +33,13 @@ type MigrationScript interface {
 type Migrator interface {
 	Register(scripts []MigrationScript, comment string)
 	Execute() errors.Error
+	// ExecuteOnly executes the pending scripts accepted by the filter, the others are left pending
+	ExecuteOnly(filter func(script MigrationScript) bool) errors.Error
+	// Scripts returns all registered scripts
+	Scripts() []MigrationScript
 	HasPendingScripts() bool
+	// Refresh reloads executed scripts from the database, they might be applied by another instance
+	Refresh() errors.Error
 }
 
 // PluginMigration is implemented by the plugin to declare all migration script that have to be applied to the database
@@ -35,8 +35,11 @@ import (
 	tlsMysql "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
+	gormMigrator "gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
 )
 
 // NewGormDb creates a new *gorm.DB and set it up properly
@@ -154,11 +157,65 @@ func MakeDbConnection(dbUrl string, conf *gorm.Config) (*gorm.DB, error) {
 		return gorm.Open(mysql.Open(dbUrl), conf)
 	case "postgresql", "postgres", "pg":
 		return gorm.Open(postgres.Open(dbUrl), conf)
+	case "sqlite", "sqlite3":
+		dsn, err := getSqliteDsn(u)
+		if err != nil {
+			return nil, err
+		}
+		return gorm.Open(sqliteDialector{&sqlite.Dialector{DSN: dsn}}, conf)
 	default:
 		return nil, fmt.Errorf("invalid DB_URL:%s", dbUrl)
 	}
 }
 
+// sqliteDialector keeps composite primary keys intact for sqlite
+type sqliteDialector struct {
+	*sqlite.Dialector
+}
+
+// DataTypeOf gorm marks the only primary key of embedded structs (e.g. common.Scope) as auto increment,
+// which would be declared as `integer PRIMARY KEY AUTOINCREMENT` and drop the rest of the composite primary key
+func (d sqliteDialector) DataTypeOf(field *schema.Field) string {
+	if field.AutoIncrement && len(field.Schema.PrimaryFields) > 1 {
+		return "integer"
+	}
+	return d.Dialector.DataTypeOf(field)
+}
+
+func (d sqliteDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqlite.Migrator{Migrator: gormMigrator.Migrator{Config: gormMigrator.Config{
+		DB:                          db,
+		Dialector:                   d,
+		CreateIndexAfterCreateTable: true,
+	}}}
+}
+
+// getSqliteDsn converts `sqlite:///absolute/path.db` or `sqlite:relative/path.db` into the dsn of the driver,
+// transactions take the write lock of the database up front since sqlite has no table level locks
+func getSqliteDsn(u *url.URL) (string, error) {
+	path := u.Opaque
+	if path == "" {
+		path = u.Host + u.Path
+	}
+	if path == "" {
+		return "", fmt.Errorf("invalid DB_URL:%s, the path of the database file is missing", u.String())
+	}
+	query := u.Query()
+	if query.Get("_busy_timeout") == "" {
+		query.Set("_busy_timeout", "10000")
+	}
+	if query.Get("_journal_mode") == "" {
+		query.Set("_journal_mode", "WAL")
+	}
+	if query.Get("_txlock") == "" {
+		query.Set("_txlock", "immediate")
+	}
+	if query.Get("_loc") == "" {
+		query.Set("_loc", "auto")
+	}
+	return fmt.Sprintf("%s?%s", path, query.Encode()), nil
+}
+
 func CheckDbConnection(dbUrl string, d time.Duration) errors.Error {
 	ctx := context.Background()
 
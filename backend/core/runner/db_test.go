@@ -66,3 +66,48 @@ func Test_addLocal(t *testing.T) {
 		})
 	}
 }
+
+func Test_getSqliteDsn(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbUrl   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "absolute path",
+			dbUrl: "sqlite:///var/lib/devlake/lake.db",
+			want:  "/var/lib/devlake/lake.db?_busy_timeout=10000&_journal_mode=WAL&_loc=auto&_txlock=immediate",
+		},
+		{
+			name:  "relative path",
+			dbUrl: "sqlite:lake.db",
+			want:  "lake.db?_busy_timeout=10000&_journal_mode=WAL&_loc=auto&_txlock=immediate",
+		},
+		{
+			name:  "keep custom options",
+			dbUrl: "sqlite3:///tmp/lake.db?_journal_mode=DELETE",
+			want:  "/tmp/lake.db?_busy_timeout=10000&_journal_mode=DELETE&_loc=auto&_txlock=immediate",
+		},
+		{
+			name:    "missing path",
+			dbUrl:   "sqlite://",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.dbUrl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := getSqliteDsn(u)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getSqliteDsn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("getSqliteDsn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models"
+)
+
+// Types of the events published to PipelineEvents
+const (
+	PIPELINE_EVENT_PIPELINE_STATUS  = "pipeline-status"
+	PIPELINE_EVENT_TASK_STATUS      = "task-status"
+	PIPELINE_EVENT_SUBTASK_STARTED  = "subtask-started"
+	PIPELINE_EVENT_SUBTASK_FINISHED = "subtask-finished"
+	PIPELINE_EVENT_PROGRESS         = "progress"
+	PIPELINE_EVENT_LOG              = "log"
+)
+
+// PipelineEvent is a single change happened during the execution of a pipeline
+type PipelineEvent struct {
+	Type          string                     `json:"type"`
+	PipelineId    uint64                     `json:"pipelineId"`
+	TaskId        uint64                     `json:"taskId,omitempty"`
+	Status        string                     `json:"status,omitempty"`
+	SubTaskName   string                     `json:"subTaskName,omitempty"`
+	SubTaskNumber int                        `json:"subTaskNumber,omitempty"`
+	Progress      *models.TaskProgressDetail `json:"progress,omitempty"`
+	Message       string                     `json:"message,omitempty"`
+	Time          time.Time                  `json:"time"`
+}
+
+// PipelineEventSubscription receives the events of a single pipeline, it must be closed once done
+type PipelineEventSubscription struct {
+	bus        *PipelineEventBus
+	pipelineId uint64
+	events     chan *PipelineEvent
+	once       sync.Once
+}
+
+// Events returns the channel of the events, it is closed when the subscription is closed
+func (s *PipelineEventSubscription) Events() <-chan *PipelineEvent {
+	return s.events
+}
+
+// Close unsubscribes from the bus
+func (s *PipelineEventSubscription) Close() {
+	s.once.Do(func() {
+		s.bus.unsubscribe(s)
+	})
+}
+
+// PipelineEventBus is an in-process publish/subscribe hub of the pipeline events
+type PipelineEventBus struct {
+	mu          sync.RWMutex
+	subscribers map[uint64]map[*PipelineEventSubscription]struct{}
+}
+
+// NewPipelineEventBus creates an empty PipelineEventBus
+func NewPipelineEventBus() *PipelineEventBus {
+	return &PipelineEventBus{
+		subscribers: make(map[uint64]map[*PipelineEventSubscription]struct{}),
+	}
+}
+
+// Subscribe starts receiving events of the specified pipeline, events would be dropped
+// for the subscriber if more than bufferSize of them were waiting to be consumed
+func (bus *PipelineEventBus) Subscribe(pipelineId uint64, bufferSize int) *PipelineEventSubscription {
+	subscription := &PipelineEventSubscription{
+		bus:        bus,
+		pipelineId: pipelineId,
+		events:     make(chan *PipelineEvent, bufferSize),
+	}
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	if bus.subscribers[pipelineId] == nil {
+		bus.subscribers[pipelineId] = make(map[*PipelineEventSubscription]struct{})
+	}
+	bus.subscribers[pipelineId][subscription] = struct{}{}
+	return subscription
+}
+
+// HasSubscribers tells whether anyone is listening to the specified pipeline
+func (bus *PipelineEventBus) HasSubscribers(pipelineId uint64) bool {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+	return len(bus.subscribers[pipelineId]) > 0
+}
+
+// Publish delivers the event to all subscribers of its pipeline without blocking the publisher
+func (bus *PipelineEventBus) Publish(event *PipelineEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+	for subscription := range bus.subscribers[event.PipelineId] {
+		select {
+		case subscription.events <- event:
+		default:
+			// the subscriber is too slow, drop the event rather than holding up the pipeline
+		}
+	}
+}
+
+func (bus *PipelineEventBus) unsubscribe(subscription *PipelineEventSubscription) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	subscriptions := bus.subscribers[subscription.pipelineId]
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(bus.subscribers, subscription.pipelineId)
+	}
+	close(subscription.events)
+}
+
+// PipelineEvents is the bus that the runner publishes the pipeline events to
+var PipelineEvents = NewPipelineEventBus()
+
+// pipelineEventLogWriter publishes every log entry written to the task logger as a log event
+type pipelineEventLogWriter struct {
+	pipelineId uint64
+	taskId     uint64
+}
+
+func (w *pipelineEventLogWriter) Write(p []byte) (int, error) {
+	if !PipelineEvents.HasSubscribers(w.pipelineId) {
+		return len(p), nil
+	}
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		PipelineEvents.Publish(&PipelineEvent{
+			Type:       PIPELINE_EVENT_LOG,
+			PipelineId: w.pipelineId,
+			TaskId:     w.taskId,
+			Message:    string(line),
+		})
+	}
+	return len(p), nil
+}
@@ -0,0 +1,82 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineEventBus(t *testing.T) {
+	bus := NewPipelineEventBus()
+	sub1 := bus.Subscribe(1, 10)
+	sub2 := bus.Subscribe(2, 10)
+
+	bus.Publish(&PipelineEvent{Type: PIPELINE_EVENT_TASK_STATUS, PipelineId: 1, TaskId: 3})
+	bus.Publish(&PipelineEvent{Type: PIPELINE_EVENT_PROGRESS, PipelineId: 3})
+
+	event := <-sub1.Events()
+	assert.Equal(t, PIPELINE_EVENT_TASK_STATUS, event.Type)
+	assert.Equal(t, uint64(3), event.TaskId)
+	assert.False(t, event.Time.IsZero())
+	assert.Len(t, sub2.Events(), 0)
+
+	sub1.Close()
+	sub1.Close()
+	_, ok := <-sub1.Events()
+	assert.False(t, ok)
+	assert.False(t, bus.HasSubscribers(1))
+	assert.True(t, bus.HasSubscribers(2))
+	// publishing to a closed subscription must not panic
+	bus.Publish(&PipelineEvent{Type: PIPELINE_EVENT_PROGRESS, PipelineId: 1})
+	sub2.Close()
+}
+
+func TestPipelineEventBusDropsForSlowSubscribers(t *testing.T) {
+	bus := NewPipelineEventBus()
+	sub := bus.Subscribe(1, 2)
+	defer sub.Close()
+	for i := 0; i < 5; i++ {
+		bus.Publish(&PipelineEvent{Type: PIPELINE_EVENT_PROGRESS, PipelineId: 1, SubTaskNumber: i})
+	}
+	assert.Len(t, sub.Events(), 2)
+	assert.Equal(t, 0, (<-sub.Events()).SubTaskNumber)
+	assert.Equal(t, 1, (<-sub.Events()).SubTaskNumber)
+}
+
+func TestPipelineEventLogWriter(t *testing.T) {
+	old := PipelineEvents
+	PipelineEvents = NewPipelineEventBus()
+	defer func() { PipelineEvents = old }()
+
+	writer := &pipelineEventLogWriter{pipelineId: 1, taskId: 2}
+	n, err := writer.Write([]byte("no subscribers\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 15, n)
+
+	sub := PipelineEvents.Subscribe(1, 10)
+	defer sub.Close()
+	_, _ = writer.Write([]byte("first line\nsecond line\n"))
+	assert.Len(t, sub.Events(), 2)
+	first := <-sub.Events()
+	assert.Equal(t, PIPELINE_EVENT_LOG, first.Type)
+	assert.Equal(t, uint64(2), first.TaskId)
+	assert.Equal(t, "first line", first.Message)
+	assert.Equal(t, "second line", (<-sub.Events()).Message)
+}
@@ -77,6 +77,15 @@ func runPipelineTasks(
 	// This double for loop executes each set of tasks sequentially while
 	// executing the set of tasks concurrently.
 	for i, row := range taskIds {
+		// the pipeline might have been cancelled by another devlake instance sharing the database
+		var statuses []string
+		err = db.Pluck("status", &statuses, dal.From(dbPipeline), dal.Where("id = ?", pipelineId))
+		if err != nil {
+			return err
+		}
+		if len(statuses) > 0 && statuses[0] == models.TASK_CANCELLED {
+			return errors.Convert(gocontext.Canceled)
+		}
 		// update stage
 		err = db.UpdateColumns(dbPipeline, []dal.DalSet{
 			{ColumnName: "status", Value: models.TASK_RUNNING},
@@ -20,6 +20,7 @@ package runner
 import (
 	gocontext "context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -98,6 +99,13 @@ func RunTask(
 			if dbe != nil {
 				logger.Error(dbe, "failed to finalize task status into db (task failed)")
 			}
+			PipelineEvents.Publish(&PipelineEvent{
+				Type:       PIPELINE_EVENT_TASK_STATUS,
+				PipelineId: task.PipelineId,
+				TaskId:     task.ID,
+				Status:     models.TASK_FAILED,
+				Message:    lakeErr.Error(),
+			})
 		} else {
 			dbe := db.UpdateColumns(task, []dal.DalSet{
 				{ColumnName: "status", Value: models.TASK_COMPLETED},
@@ -108,6 +116,12 @@ func RunTask(
 			if dbe != nil {
 				logger.Error(dbe, "failed to finalize task status into db (task succeeded)")
 			}
+			PipelineEvents.Publish(&PipelineEvent{
+				Type:       PIPELINE_EVENT_TASK_STATUS,
+				PipelineId: task.PipelineId,
+				TaskId:     task.ID,
+				Status:     models.TASK_COMPLETED,
+			})
 		}
 		// update finishedTasks
 		errors.Must(db.UpdateColumn(
@@ -135,6 +149,12 @@ func RunTask(
 	if dbe != nil {
 		return dbe
 	}
+	PipelineEvents.Publish(&PipelineEvent{
+		Type:       PIPELINE_EVENT_TASK_STATUS,
+		PipelineId: task.PipelineId,
+		TaskId:     task.ID,
+		Status:     models.TASK_RUNNING,
+	})
 
 	err = RunPluginTask(
 		ctx,
@@ -327,7 +347,7 @@ func RunPluginSubTasks(
 		} else {
 			logger.Info("executing subtask %s", subtaskMeta.Name)
 			start := time.Now()
-			err = runSubtask(basicRes, subtaskCtx, task.ID, subtaskNumber, subtaskMeta.EntryPoint)
+			err = runSubtask(basicRes, subtaskCtx, task, subtaskNumber, subtaskMeta.EntryPoint)
 			logger.Info("subtask %s finished in %d ms", subtaskMeta.Name, time.Since(start).Milliseconds())
 			if err != nil {
 				err = errors.SubtaskErr.Wrap(err, fmt.Sprintf("subtask %s ended unexpectedly", subtaskMeta.Name), errors.WithData(&subtaskMeta))
@@ -402,18 +422,25 @@ func UpdateProgressDetail(basicRes context.BasicRes, taskId uint64, progressDeta
 func runSubtask(
 	basicRes context.BasicRes,
 	ctx plugin.SubTaskContext,
-	parentID uint64,
+	parent *models.Task,
 	subtaskNumber int,
 	entryPoint plugin.SubTaskEntryPoint,
-) errors.Error {
+) (err errors.Error) {
 	beginAt := time.Now()
 	subtask := &models.Subtask{
 		Name:    ctx.GetName(),
-		TaskID:  parentID,
+		TaskID:  parent.ID,
 		Number:  subtaskNumber,
 		BeganAt: &beginAt,
 	}
 	recordSubtask(basicRes, subtask)
+	PipelineEvents.Publish(&PipelineEvent{
+		Type:          PIPELINE_EVENT_SUBTASK_STARTED,
+		PipelineId:    parent.PipelineId,
+		TaskId:        parent.ID,
+		SubTaskName:   subtask.Name,
+		SubTaskNumber: subtaskNumber,
+	})
 	// defer to record subtask status
 	defer func() {
 		finishedAt := time.Now()
@@ -421,6 +448,17 @@ func runSubtask(
 		subtask.SpentSeconds = finishedAt.Unix() - beginAt.Unix()
 
 		recordSubtask(basicRes, subtask)
+		event := &PipelineEvent{
+			Type:          PIPELINE_EVENT_SUBTASK_FINISHED,
+			PipelineId:    parent.PipelineId,
+			TaskId:        parent.ID,
+			SubTaskName:   subtask.Name,
+			SubTaskNumber: subtaskNumber,
+		}
+		if err != nil {
+			event.Message = err.Error()
+		}
+		PipelineEvents.Publish(event)
 	}()
 	return entryPoint(ctx)
 }
@@ -445,9 +483,14 @@ func getTaskLogger(parentLogger log.Logger, task *models.Task) (log.Logger, erro
 	if err != nil {
 		return nil, err
 	}
+	// tee the log entries to the subscribers of the pipeline events
+	eventWriter := &pipelineEventLogWriter{
+		pipelineId: task.PipelineId,
+		taskId:     task.ID,
+	}
 	logger.SetStream(&log.LoggerStreamConfig{
 		Path:   loggingPath,
-		Writer: stream,
+		Writer: io.MultiWriter(stream, eventWriter),
 	})
 	return logger, nil
 }
@@ -62,27 +62,46 @@ func NewApiKeyHelper(basicRes context.BasicRes, logger log.Logger) *ApiKeyHelper
 }
 
 func (c *ApiKeyHelper) Create(tx dal.Transaction, user *common.User, name string, expiredAt *time.Time, allowedPath string, apiKeyType string, extra string) (*models.ApiKey, errors.Error) {
-	if _, err := regexp.Compile(allowedPath); err != nil {
+	return c.CreateFromInput(tx, user, &models.ApiInputApiKey{
+		Name:        name,
+		Type:        apiKeyType,
+		AllowedPath: allowedPath,
+		ExpiredAt:   expiredAt,
+	}, extra)
+}
+
+// CreateFromInput creates an api key with the permission, project and rate limit of the input, keys without
+// permission are allowed to send any request
+func (c *ApiKeyHelper) CreateFromInput(tx dal.Transaction, user *common.User, input *models.ApiInputApiKey, extra string) (*models.ApiKey, errors.Error) {
+	name := input.Name
+	if _, err := regexp.Compile(input.AllowedPath); err != nil {
 		c.logger.Error(err, "Compile allowed path")
-		return nil, errors.Default.Wrap(err, fmt.Sprintf("compile allowed path: %s", allowedPath))
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("compile allowed path: %s", input.AllowedPath))
 	}
 	apiKey, hashedApiKey, err := c.generateApiKey()
 	if err != nil {
 		c.logger.Error(err, "generateApiKey")
 		return nil, err
 	}
+	permission := input.Permission
+	if permission == "" {
+		permission = models.API_KEY_PERMISSION_WRITE
+	}
 	now := time.Now()
 	apiKeyRecord := &models.ApiKey{
 		Model: common.Model{
 			CreatedAt: now,
 			UpdatedAt: now,
 		},
-		Name:        name,
-		ApiKey:      hashedApiKey,
-		ExpiredAt:   expiredAt,
-		AllowedPath: allowedPath,
-		Type:        apiKeyType,
-		Extra:       extra,
+		Name:               name,
+		ApiKey:             hashedApiKey,
+		ExpiredAt:          input.ExpiredAt,
+		AllowedPath:        input.AllowedPath,
+		Type:               input.Type,
+		Extra:              extra,
+		Permission:         permission,
+		ProjectName:        input.ProjectName,
+		RateLimitPerMinute: input.RateLimitPerMinute,
 	}
 	if user != nil {
 		apiKeyRecord.Creator = common.Creator{
@@ -124,6 +143,9 @@ func (c *ApiKeyHelper) Put(user *common.User, id uint64) (*models.ApiKey, errors
 		return nil, err
 	}
 	apiKey.ApiKey = hashApiKey
+	// the regenerated key replaces the old one immediately
+	apiKey.PreviousApiKey = ""
+	apiKey.PreviousExpiredAt = nil
 	apiKey.UpdatedAt = time.Now()
 	if user != nil {
 		apiKey.Updater = common.Updater{
@@ -139,6 +161,45 @@ func (c *ApiKeyHelper) Put(user *common.User, id uint64) (*models.ApiKey, errors
 	return apiKey, nil
 }
 
+// Rotate replaces the key with a new one, the replaced key keeps working for the grace period so that clients can
+// be switched over without downtime. Key replaced by a former rotation stops working immediately
+func (c *ApiKeyHelper) Rotate(user *common.User, id uint64, gracePeriod time.Duration) (*models.ApiKey, errors.Error) {
+	db := c.basicRes.GetDal()
+	apiKey, err := c.getApiKeyById(db, id)
+	if err != nil {
+		c.logger.Error(err, "get api key by id: %d", id)
+		return nil, err
+	}
+	apiKeyStr, hashApiKey, err := c.generateApiKey()
+	if err != nil {
+		c.logger.Error(err, "generateApiKey")
+		return nil, err
+	}
+	now := time.Now()
+	apiKey.PreviousApiKey = ""
+	apiKey.PreviousExpiredAt = nil
+	if gracePeriod > 0 {
+		previousExpiredAt := now.Add(gracePeriod)
+		apiKey.PreviousApiKey = apiKey.ApiKey
+		apiKey.PreviousExpiredAt = &previousExpiredAt
+	}
+	apiKey.ApiKey = hashApiKey
+	apiKey.UpdatedAt = now
+	if user != nil {
+		apiKey.Updater = common.Updater{
+			Updater:      user.Name,
+			UpdaterEmail: user.Email,
+		}
+	}
+	if err = db.Update(apiKey); err != nil {
+		c.logger.Error(err, "rotate api key, id: %d", id)
+		return nil, errors.Default.Wrap(err, "error rotating api key")
+	}
+	apiKey.ApiKey = apiKeyStr
+	apiKey.PreviousApiKey = ""
+	return apiKey, nil
+}
+
 func (c *ApiKeyHelper) Delete(id uint64) errors.Error {
 	// verify exists
 	db := c.basicRes.GetDal()
@@ -205,6 +266,30 @@ func (c *ApiKeyHelper) GetApiKey(tx dal.Dal, additionalClauses ...dal.Clause) (*
 	return apiKey, err
 }
 
+// GetApiKeyByToken returns the api key matching the plain token, either the current key or the one replaced by
+// a rotation within its grace period
+func (c *ApiKeyHelper) GetApiKeyByToken(tx dal.Dal, token string) (*models.ApiKey, errors.Error) {
+	hashedApiKey, err := c.DigestToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetApiKey(tx, dal.Where(
+		"api_key = ? OR (previous_api_key = ? AND previous_expired_at > ?)",
+		hashedApiKey, hashedApiKey, time.Now(),
+	))
+}
+
+// RecordUsage adds count requests authenticated by the api key and remembers when and where the last one was sent from
+func (c *ApiKeyHelper) RecordUsage(tx dal.Dal, id uint64, count uint64, ip string, usedAt time.Time) errors.Error {
+	if tx == nil {
+		tx = c.basicRes.GetDal()
+	}
+	return tx.Exec(
+		"UPDATE _devlake_api_keys SET request_count = request_count + ?, last_used_at = ?, last_used_ip = ? WHERE id = ?",
+		count, usedAt, ip, id,
+	)
+}
+
 func (c *ApiKeyHelper) GenApiKeyNameForPlugin(pluginName string, connectionId uint64) string {
 	return fmt.Sprintf("%s-%d", pluginName, connectionId)
 }
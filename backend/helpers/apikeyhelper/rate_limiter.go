@@ -0,0 +1,71 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeyhelper
+
+import (
+	"sync"
+	"time"
+)
+
+const rateLimitWindow = time.Minute
+
+// RateLimiter counts requests of api keys in fixed one-minute windows, the counters live in memory so each
+// instance of DevLake enforces the limits separately
+type RateLimiter struct {
+	mu      sync.Mutex
+	windows map[uint64]*rateLimitWindowState
+}
+
+type rateLimitWindowState struct {
+	start time.Time
+	count int
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{windows: make(map[uint64]*rateLimitWindowState)}
+}
+
+// Allow counts a request of the api key sent at now, it returns false along with the time to wait before the
+// next window when the key exceeds limitPerMinute. Non-positive limits mean unlimited
+func (l *RateLimiter) Allow(apiKeyId uint64, limitPerMinute int, now time.Time) (bool, time.Duration) {
+	if limitPerMinute <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	window, ok := l.windows[apiKeyId]
+	if !ok || now.Sub(window.start) >= rateLimitWindow {
+		window = &rateLimitWindowState{start: now}
+		l.windows[apiKeyId] = window
+		l.evict(now)
+	}
+	if window.count >= limitPerMinute {
+		return false, window.start.Add(rateLimitWindow).Sub(now)
+	}
+	window.count++
+	return true, 0
+}
+
+// evict drops the windows which are over, keeps the map from growing with keys that are no longer used
+func (l *RateLimiter) evict(now time.Time) {
+	for id, window := range l.windows {
+		if now.Sub(window.start) >= rateLimitWindow {
+			delete(l.windows, id)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeyhelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter()
+	now := time.Date(2025, 11, 18, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.Allow(1, 3, now.Add(time.Duration(i)*time.Second))
+		assert.True(t, allowed)
+	}
+	allowed, retryAfter := limiter.Allow(1, 3, now.Add(20*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	// other keys are counted separately
+	allowed, _ = limiter.Allow(2, 1, now.Add(20*time.Second))
+	assert.True(t, allowed)
+
+	// a new window starts after a minute
+	allowed, _ = limiter.Allow(1, 3, now.Add(time.Minute))
+	assert.True(t, allowed)
+
+	// unlimited
+	for i := 0; i < 100; i++ {
+		allowed, _ = limiter.Allow(3, 0, now)
+		assert.True(t, allowed)
+	}
+}
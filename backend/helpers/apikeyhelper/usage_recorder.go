@@ -0,0 +1,100 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeyhelper
+
+import (
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+)
+
+// UsageFlushInterval is how often the buffered usage of api keys is written to the database
+const UsageFlushInterval = 10 * time.Second
+
+// UsageRecorder buffers the usage of api keys in memory and writes it to the database periodically, keeps the
+// database out of the request path. Usage buffered since the last flush is lost when DevLake stops
+type UsageRecorder struct {
+	mu      sync.Mutex
+	logger  log.Logger
+	pending map[uint64]*apiKeyUsage
+	write   func(id uint64, count uint64, ip string, usedAt time.Time) errors.Error
+}
+
+type apiKeyUsage struct {
+	count  uint64
+	ip     string
+	usedAt time.Time
+}
+
+func NewUsageRecorder(apiKeyHelper *ApiKeyHelper, logger log.Logger) *UsageRecorder {
+	return &UsageRecorder{
+		logger:  logger,
+		pending: make(map[uint64]*apiKeyUsage),
+		write: func(id uint64, count uint64, ip string, usedAt time.Time) errors.Error {
+			return apiKeyHelper.RecordUsage(nil, id, count, ip, usedAt)
+		},
+	}
+}
+
+// Record counts a request authenticated by the api key, it only touches memory
+func (r *UsageRecorder) Record(apiKeyId uint64, ip string, usedAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.add(apiKeyId, &apiKeyUsage{count: 1, ip: ip, usedAt: usedAt})
+}
+
+// Run flushes the buffered usage every interval, it never returns
+func (r *UsageRecorder) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.Flush()
+	}
+}
+
+// Flush writes the buffered usage to the database, the usage of keys failed to be written is kept for the next flush
+func (r *UsageRecorder) Flush() {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[uint64]*apiKeyUsage)
+	r.mu.Unlock()
+	for id, usage := range pending {
+		if err := r.write(id, usage.count, usage.ip, usage.usedAt); err != nil {
+			r.logger.Error(err, "record usage of api key %d", id)
+			r.mu.Lock()
+			r.add(id, usage)
+			r.mu.Unlock()
+		}
+	}
+}
+
+// add merges the usage into the buffer, the caller must hold the lock
+func (r *UsageRecorder) add(apiKeyId uint64, usage *apiKeyUsage) {
+	buffered, ok := r.pending[apiKeyId]
+	if !ok {
+		r.pending[apiKeyId] = usage
+		return
+	}
+	buffered.count += usage.count
+	if usage.usedAt.After(buffered.usedAt) {
+		buffered.ip = usage.ip
+		buffered.usedAt = usage.usedAt
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apikeyhelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageRecorder(t *testing.T) {
+	written := make(map[uint64]apiKeyUsage)
+	fail := true
+	recorder := &UsageRecorder{
+		logger:  logruslog.Global,
+		pending: make(map[uint64]*apiKeyUsage),
+		write: func(id uint64, count uint64, ip string, usedAt time.Time) errors.Error {
+			if id == 2 && fail {
+				return errors.Default.New("database is down")
+			}
+			written[id] = apiKeyUsage{count: count, ip: ip, usedAt: usedAt}
+			return nil
+		},
+	}
+	now := time.Date(2025, 11, 18, 10, 0, 0, 0, time.UTC)
+
+	recorder.Record(1, "10.0.0.1", now.Add(2*time.Second))
+	recorder.Record(1, "10.0.0.2", now)
+	recorder.Record(2, "10.0.0.3", now)
+	recorder.Flush()
+	assert.Equal(t, map[uint64]apiKeyUsage{1: {count: 2, ip: "10.0.0.1", usedAt: now.Add(2 * time.Second)}}, written)
+
+	// usage failed to be written is kept for the next flush
+	fail = false
+	recorder.Record(2, "10.0.0.4", now.Add(time.Second))
+	recorder.Flush()
+	assert.Equal(t, apiKeyUsage{count: 2, ip: "10.0.0.4", usedAt: now.Add(time.Second)}, written[2])
+
+	delete(written, 1)
+	recorder.Flush()
+	assert.NotContains(t, written, uint64(1))
+}
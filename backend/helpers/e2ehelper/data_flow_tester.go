@@ -20,6 +20,7 @@ package e2ehelper
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
@@ -142,6 +143,10 @@ func (t *DataFlowTester) ImportCsvIntoRawTable(csvRelPath string, rawTableName s
 	for csvIter.HasNext() {
 		toInsertValues := csvIter.Fetch()
 		toInsertValues[`data`] = []byte(toInsertValues[`data`].(string))
+		// sqlite returns TEXT values as string which can't be scanned into json.RawMessage
+		if input, ok := toInsertValues[`input`].(string); ok && t.Dal.Dialect() == "sqlite" {
+			toInsertValues[`input`] = []byte(input)
+		}
 		result := t.Db.Table(rawTableName).Create(toInsertValues)
 		if result.Error != nil {
 			panic(result.Error)
@@ -157,6 +162,11 @@ func (t *DataFlowTester) importCsv(csvRelPath string, dst schema.Tabler, nullabl
 	}
 	defer csvIter.Close()
 	t.FlushTabler(dst)
+	// sqlite keeps datetime values as text, they have to be in a format the driver could read back
+	var timeColumns []string
+	if t.Dal.Dialect() == "sqlite" {
+		timeColumns = t.extractTimeColumns(dst)
+	}
 	// load rows and insert into target table
 	for csvIter.HasNext() {
 		toInsertValues := csvIter.Fetch()
@@ -171,6 +181,11 @@ func (t *DataFlowTester) importCsv(csvRelPath string, dst schema.Tabler, nullabl
 				}
 			}
 		}
+		for _, column := range timeColumns {
+			if value, ok := toInsertValues[column].(string); ok {
+				toInsertValues[column] = parseCsvTime(value)
+			}
+		}
 		result := t.Db.Model(dst).Create(toInsertValues)
 		if result.Error != nil {
 			panic(result.Error)
@@ -448,6 +463,11 @@ func formatDbValue(value interface{}, nullable bool) string {
 	switch value := value.(type) {
 	case time.Time:
 		return value.In(location).Format("2006-01-02T15:04:05.000-07:00")
+	case *interface{}:
+		// sqlite scans columns of unknown types (e.g. json) into `*interface{}`
+		return formatDbValue(*value, nullable)
+	case []byte:
+		return string(value)
 	case bool:
 		if value {
 			return `1`
@@ -462,6 +482,10 @@ func formatDbValue(value interface{}, nullable bool) string {
 	return ``
 }
 
+func isJsonValue(value string) bool {
+	return (strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[")) && json.Valid([]byte(value))
+}
+
 // ColumnWithRawData create a Column string with _raw_data_* appending
 func ColumnWithRawData(column ...string) []string {
 	return append(
@@ -500,6 +524,37 @@ func (t *DataFlowTester) extractColumns(ifc interface{}) []string {
 	return columns
 }
 
+func (t *DataFlowTester) extractTimeColumns(ifc interface{}) []string {
+	sch, err := schema.Parse(ifc, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Sprintf("error getting object schema: %v", err))
+	}
+	var columns []string
+	for _, f := range sch.Fields {
+		if f.DataType == schema.Time {
+			columns = append(columns, f.DBName)
+		}
+	}
+	return columns
+}
+
+// parseCsvTime parses the datetime values in csv files which might not be zero padded (e.g. `2022-1-11 4:51:47`),
+// the value is kept as is if none of the layouts matches
+func parseCsvTime(value string) interface{} {
+	for _, layout := range []string{
+		"2006-1-2T15:4:5Z07:00",
+		"2006-1-2 15:4:5Z07:00",
+		"2006-1-2T15:4:5",
+		"2006-1-2 15:4:5",
+		"2006-1-2",
+	} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t
+		}
+	}
+	return value
+}
+
 func (t *DataFlowTester) resolveTargetFields(dst schema.Tabler, opts TableOptions) []string {
 	for _, ignore := range opts.IgnoreTypes {
 		opts.IgnoreFields = append(opts.IgnoreFields, t.extractColumns(ignore)...)
@@ -578,6 +633,11 @@ func (t *DataFlowTester) VerifyTableWithOptions(dst schema.Tabler, opts TableOpt
 		for _, field := range targetFields {
 			expectation := formatDbValue(expected[field], opts.Nullable)
 			reality := formatDbValue(actual[field], opts.Nullable)
+			// the csv files keep the json values normalized by mysql, other databases store them as is
+			if t.Dal.Dialect() != "mysql" && isJsonValue(expectation) && isJsonValue(reality) {
+				assert.JSONEq(t.T, expectation, reality, fmt.Sprintf(`%s.%s not match (with params from csv %s)`, dst.TableName(), field, pkValues))
+				continue
+			}
 			if !assert.Equal(t.T, expectation, reality, fmt.Sprintf(`%s.%s not match (with params from csv %s)`, dst.TableName(), field, pkValues)) {
 				_ = t.T // useful for debugging
 			}
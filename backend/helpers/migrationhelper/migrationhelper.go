@@ -21,7 +21,6 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
-	"net/url"
 	"reflect"
 	"strings"
 
@@ -127,16 +126,12 @@ func ChangePrimaryKeyColumnsType[D any](
 	update func(tmpColumnParams []interface{}) errors.Error,
 ) (err errors.Error) {
 	db := basicRes.GetDal()
-	dbUrl := basicRes.GetConfig("DB_URL")
-	if dbUrl == "" {
-		return errors.BadInput.New("DB_URL is required")
-	}
-	u, errParse := url.Parse(dbUrl)
-	if errParse != nil {
-		return errors.Convert(errParse)
+	// sqlite can neither drop nor add the primary key of an existing table
+	if db.Dialect() == "sqlite" {
+		return rebuildTableWithPrimaryKeyColumnsType[D](basicRes, script, tableName, TargetPriColumns, update)
 	}
 	// Delete the primary key
-	if u.Scheme == "mysql" {
+	if db.Dialect() == "mysql" {
 		sql := fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY", tableName)
 		if err := db.Exec(sql); err != nil {
 			return err
@@ -162,6 +157,89 @@ func ChangePrimaryKeyColumnsType[D any](
 	return nil
 }
 
+// rebuildTableWithPrimaryKeyColumnsType copies the records into a new table created by D with the changed
+// primary key, the original values of the primary key columns are provided to `update` as temporary columns
+func rebuildTableWithPrimaryKeyColumnsType[D any](
+	basicRes context.BasicRes,
+	script plugin.MigrationScript,
+	tableName string,
+	TargetPriColumns []string,
+	update func(tmpColumnParams []interface{}) errors.Error,
+) errors.Error {
+	db := basicRes.GetDal()
+	oldTableName := fmt.Sprintf("%s_%s", tableName, hashScript(script))
+	oldColumns, err := db.GetColumns(&dal.DefaultTabler{Name: tableName}, nil)
+	if err != nil {
+		return err
+	}
+	err = db.RenameTable(tableName, oldTableName)
+	if err != nil {
+		return err
+	}
+	err = db.AutoMigrate(new(D), dal.From(tableName))
+	if err != nil {
+		return errors.Default.Wrap(err, "AutoMigrate for the new table Error")
+	}
+	// keep the columns added outside D and copy the original values of the primary key into the temporary columns
+	columns := make([]string, 0, len(oldColumns))
+	for _, column := range oldColumns {
+		if !db.HasColumn(tableName, column.Name()) {
+			columnType, ok := column.ColumnType()
+			if !ok {
+				columnType = column.DatabaseTypeName()
+			}
+			err = db.Exec(fmt.Sprintf("ALTER TABLE ? ADD ? %s", columnType), dal.ClauseTable{Name: tableName}, dal.ClauseColumn{Name: column.Name()})
+			if err != nil {
+				return err
+			}
+		}
+		columns = append(columns, fmt.Sprintf("`%s`", column.Name()))
+	}
+	insertColumns := append([]string{}, columns...)
+	selectColumns := append([]string{}, columns...)
+	tmpColumnsNames := make([]string, len(TargetPriColumns))
+	for i, v := range TargetPriColumns {
+		tmpColumnsNames[i] = fmt.Sprintf("%s_%s", v, hashScript(script))
+		err = db.Exec("ALTER TABLE ? ADD ? text", dal.ClauseTable{Name: tableName}, dal.ClauseColumn{Name: tmpColumnsNames[i]})
+		if err != nil {
+			return err
+		}
+		insertColumns = append(insertColumns, fmt.Sprintf("`%s`", tmpColumnsNames[i]))
+		selectColumns = append(selectColumns, fmt.Sprintf("`%s`", v))
+	}
+	err = db.Exec(fmt.Sprintf(
+		"INSERT INTO `%s` (%s) SELECT %s FROM `%s`",
+		tableName, strings.Join(insertColumns, ","), strings.Join(selectColumns, ","), oldTableName,
+	))
+	if err != nil {
+		return err
+	}
+	if update == nil {
+		dalSet := make([]dal.DalSet, 0, len(TargetPriColumns))
+		for i, v := range TargetPriColumns {
+			dalSet = append(dalSet, dal.DalSet{
+				ColumnName: v,
+				Value:      dal.DalClause{Expr: " ? ", Params: []interface{}{dal.ClauseColumn{Name: tmpColumnsNames[i]}}},
+			})
+		}
+		err = db.UpdateColumns(tableName, dalSet, dal.Where("1=1"))
+	} else {
+		params := make([]interface{}, 0, len(tmpColumnsNames))
+		for _, v := range tmpColumnsNames {
+			params = append(params, dal.ClauseColumn{Name: v})
+		}
+		err = update(params)
+	}
+	if err != nil {
+		return err
+	}
+	err = db.DropColumns(tableName, tmpColumnsNames...)
+	if err != nil {
+		return err
+	}
+	return db.DropTables(oldTableName)
+}
+
 // TransformColumns change the type of specified columns for the table and transform data one by one
 func TransformColumns[S any, D any](
 	basicRes context.BasicRes,
@@ -0,0 +1,150 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/utils"
+)
+
+// VerifyHmacSha256Signature checks a `sha256=<hex>` signature of the payload, as sent by GitHub in `X-Hub-Signature-256`
+func VerifyHmacSha256Signature(secret string, payload []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// VerifyWebhookToken compares a shared secret token, as sent by GitLab in `X-Gitlab-Token`, in constant time
+func VerifyWebhookToken(secret string, token string) bool {
+	if secret == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
+
+type webhookTrigger struct {
+	timer     *time.Timer
+	firstSeen time.Time
+	entities  []string
+}
+
+// WebhookDebouncer coalesces bursts of webhook events for the same scope into one run: the run fires once no event
+// arrived for `delay`, or `maxWait` after the first event when the burst never settles
+type WebhookDebouncer struct {
+	mu       sync.Mutex
+	delay    time.Duration
+	maxWait  time.Duration
+	triggers map[string]*webhookTrigger
+}
+
+func NewWebhookDebouncer(delay time.Duration) *WebhookDebouncer {
+	return &WebhookDebouncer{
+		delay:    delay,
+		maxWait:  5 * delay,
+		triggers: make(map[string]*webhookTrigger),
+	}
+}
+
+// NewWebhookDebouncerFromConfig reads the delay from `WEBHOOK_DEBOUNCE_SECONDS`, 60 seconds by default
+func NewWebhookDebouncerFromConfig(basicRes context.BasicRes) *WebhookDebouncer {
+	seconds, err := utils.StrToIntOr(basicRes.GetConfig("WEBHOOK_DEBOUNCE_SECONDS"), 60)
+	if err != nil || seconds < 0 {
+		seconds = 60
+	}
+	return NewWebhookDebouncer(time.Duration(seconds) * time.Second)
+}
+
+// Trigger schedules fire for the key with the union of the entities of all events received while it was pending
+func (d *WebhookDebouncer) Trigger(key string, entities []string, fire func(entities []string)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	trigger, ok := d.triggers[key]
+	if !ok {
+		trigger = &webhookTrigger{firstSeen: time.Now()}
+		d.triggers[key] = trigger
+	}
+	for _, entity := range entities {
+		if !utils.StringsContains(trigger.entities, entity) {
+			trigger.entities = append(trigger.entities, entity)
+		}
+	}
+	wait := d.delay
+	if remaining := d.maxWait - time.Since(trigger.firstSeen); remaining < wait {
+		wait = remaining
+	}
+	if trigger.timer != nil {
+		trigger.timer.Stop()
+	}
+	trigger.timer = time.AfterFunc(wait, func() {
+		d.mu.Lock()
+		if d.triggers[key] != trigger {
+			d.mu.Unlock()
+			return
+		}
+		delete(d.triggers, key)
+		d.mu.Unlock()
+		fire(trigger.entities)
+	})
+}
+
+// Pending returns the number of scopes waiting for their run
+func (d *WebhookDebouncer) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.triggers)
+}
+
+const (
+	WebhookReceiptScheduled = "scheduled"
+	WebhookReceiptIgnored   = "ignored"
+)
+
+// WebhookReceipt is the response of the webhook receivers of data source plugins
+type WebhookReceipt struct {
+	Event    string   `json:"event"`
+	ScopeId  string   `json:"scopeId,omitempty"`
+	Entities []string `json:"entities,omitempty"`
+	Status   string   `json:"status"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+// IntersectEntities keeps the entities affected by an event that the scope config collects
+func IntersectEntities(affected []string, enabled []string) []string {
+	entities := make([]string, 0, len(affected))
+	for _, entity := range affected {
+		if utils.StringsContains(enabled, entity) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
@@ -0,0 +1,73 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyHmacSha256Signature(t *testing.T) {
+	payload := []byte(`{"action":"closed"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(payload)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, VerifyHmacSha256Signature("s3cret", payload, signature))
+	assert.False(t, VerifyHmacSha256Signature("other", payload, signature))
+	assert.False(t, VerifyHmacSha256Signature("s3cret", []byte(`{}`), signature))
+	assert.False(t, VerifyHmacSha256Signature("s3cret", payload, "sha256=zz"))
+	assert.False(t, VerifyHmacSha256Signature("", payload, signature))
+}
+
+func TestVerifyWebhookToken(t *testing.T) {
+	assert.True(t, VerifyWebhookToken("s3cret", "s3cret"))
+	assert.False(t, VerifyWebhookToken("s3cret", "s3cre"))
+	assert.False(t, VerifyWebhookToken("", ""))
+}
+
+func TestWebhookDebouncer(t *testing.T) {
+	debouncer := NewWebhookDebouncer(50 * time.Millisecond)
+	fired := make(chan []string, 2)
+	fire := func(entities []string) {
+		fired <- entities
+	}
+	debouncer.Trigger("github:1:1", []string{"CODE"}, fire)
+	debouncer.Trigger("github:1:1", []string{"CODEREVIEW", "CODE"}, fire)
+	debouncer.Trigger("github:1:2", []string{"CICD"}, fire)
+	assert.Equal(t, 2, debouncer.Pending())
+
+	var runs [][]string
+	for i := 0; i < 2; i++ {
+		select {
+		case entities := <-fired:
+			sort.Strings(entities)
+			runs = append(runs, entities)
+		case <-time.After(time.Second):
+			t.Fatal("debounced run didn't fire")
+		}
+	}
+	assert.ElementsMatch(t, [][]string{{"CODE", "CODEREVIEW"}, {"CICD"}}, runs)
+	assert.Equal(t, 0, debouncer.Pending())
+}
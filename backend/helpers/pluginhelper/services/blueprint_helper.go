@@ -39,6 +39,8 @@ type GetBlueprintQuery struct {
 	PageSize    int
 	Mode        string
 	Type        string
+	// ProjectNames restricts the result to blueprints of the given projects, nil means no restriction
+	ProjectNames []string
 }
 
 type BlueprintProjectPairs struct {
@@ -155,6 +157,9 @@ func (b *BlueprintManager) GetDbBlueprints(query *GetBlueprintQuery) ([]*models.
 	if query.Mode != "" {
 		clauses = append(clauses, dal.Where("mode = ?", query.Mode))
 	}
+	if query.ProjectNames != nil {
+		clauses = append(clauses, dal.Where("project_name IN ?", query.ProjectNames))
+	}
 
 	// count total records
 	// var count int64
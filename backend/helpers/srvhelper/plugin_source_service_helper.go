@@ -0,0 +1,237 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srvhelper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/go-playground/validator/v10"
+)
+
+// PluginSourceSrvHelper manages connections, scope configs and scopes of any plugin implementing plugin.PluginSource
+// by reflection, it serves the framework features which can't refer to the concrete models, i.e. configuration-as-code
+type PluginSourceSrvHelper struct {
+	db          dal.Dal
+	validator   *validator.Validate
+	pluginName  string
+	connection  dal.Tabler
+	scope       plugin.ToolLayerScope
+	scopeConfig dal.Tabler
+}
+
+// NewPluginSourceSrvHelper creates a PluginSourceSrvHelper for the given plugin
+func NewPluginSourceSrvHelper(basicRes context.BasicRes, pluginName string) (*PluginSourceSrvHelper, errors.Error) {
+	pluginMeta, err := plugin.GetPlugin(pluginName)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, fmt.Sprintf("plugin %s not found", pluginName))
+	}
+	src, ok := pluginMeta.(plugin.PluginSource)
+	if !ok || isNilModel(src.Connection()) {
+		return nil, errors.BadInput.New(fmt.Sprintf("plugin %s doesn't manage connections", pluginName))
+	}
+	if _, ok := src.Connection().(models.DynamicTabler); ok {
+		return nil, errors.BadInput.New(fmt.Sprintf("plugin %s is a remote plugin which is not supported", pluginName))
+	}
+	helper := &PluginSourceSrvHelper{
+		db:         basicRes.GetDal(),
+		validator:  validator.New(),
+		pluginName: pluginName,
+		connection: src.Connection(),
+	}
+	if !isNilModel(src.Scope()) {
+		helper.scope = src.Scope()
+	}
+	if !isNilModel(src.ScopeConfig()) {
+		helper.scopeConfig = src.ScopeConfig()
+	}
+	return helper, nil
+}
+
+// NewTx returns a copy of the helper working inside the given transaction
+func (srv *PluginSourceSrvHelper) NewTx(tx dal.Transaction) *PluginSourceSrvHelper {
+	helper := new(PluginSourceSrvHelper)
+	*helper = *srv
+	helper.db = tx
+	return helper
+}
+
+func (srv *PluginSourceSrvHelper) GetPluginName() string {
+	return srv.pluginName
+}
+
+// ConnectionTable returns the table name of connections, plugins sharing the same table manage the same connections
+func (srv *PluginSourceSrvHelper) ConnectionTable() string {
+	return srv.connection.TableName()
+}
+
+func (srv *PluginSourceSrvHelper) HasScope() bool {
+	return srv.scope != nil
+}
+
+func (srv *PluginSourceSrvHelper) HasScopeConfig() bool {
+	return srv.scopeConfig != nil
+}
+
+// NewConnection returns a pointer to an empty connection
+func (srv *PluginSourceSrvHelper) NewConnection() plugin.ToolLayerConnection {
+	return newModel(srv.connection).(plugin.ToolLayerConnection)
+}
+
+// NewScopeConfig returns a pointer to an empty scope config
+func (srv *PluginSourceSrvHelper) NewScopeConfig() plugin.ToolLayerScopeConfig {
+	return newModel(srv.scopeConfig).(plugin.ToolLayerScopeConfig)
+}
+
+// NewScope returns a pointer to an empty scope
+func (srv *PluginSourceSrvHelper) NewScope() plugin.ToolLayerScope {
+	return newModel(srv.scope).(plugin.ToolLayerScope)
+}
+
+// GetAllConnections returns all connections ordered by id
+func (srv *PluginSourceSrvHelper) GetAllConnections() ([]plugin.ToolLayerConnection, errors.Error) {
+	connections := make([]plugin.ToolLayerConnection, 0)
+	return connections, srv.findAll(srv.connection, func(model interface{}) {
+		connections = append(connections, model.(plugin.ToolLayerConnection))
+	}, dal.Orderby("id"))
+}
+
+// GetScopeConfigsByConnectionId returns all scope configs of the connection ordered by id
+func (srv *PluginSourceSrvHelper) GetScopeConfigsByConnectionId(connectionId uint64) ([]plugin.ToolLayerScopeConfig, errors.Error) {
+	scopeConfigs := make([]plugin.ToolLayerScopeConfig, 0)
+	if srv.scopeConfig == nil {
+		return scopeConfigs, nil
+	}
+	return scopeConfigs, srv.findAll(srv.scopeConfig, func(model interface{}) {
+		scopeConfigs = append(scopeConfigs, model.(plugin.ToolLayerScopeConfig))
+	}, dal.Where("connection_id = ?", connectionId), dal.Orderby("id"))
+}
+
+// GetScopesByConnectionId returns all scopes of the connection
+func (srv *PluginSourceSrvHelper) GetScopesByConnectionId(connectionId uint64) ([]plugin.ToolLayerScope, errors.Error) {
+	scopes := make([]plugin.ToolLayerScope, 0)
+	if srv.scope == nil {
+		return scopes, nil
+	}
+	return scopes, srv.findAll(srv.scope, func(model interface{}) {
+		scopes = append(scopes, model.(plugin.ToolLayerScope))
+	}, dal.Where("connection_id = ?", connectionId))
+}
+
+// GetScope returns the scope of the connection by its id, or nil if there is no such scope
+func (srv *PluginSourceSrvHelper) GetScope(connectionId uint64, scopeId string) (plugin.ToolLayerScope, errors.Error) {
+	scopes, err := srv.GetScopesByConnectionId(connectionId)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		if scope.ScopeId() == scopeId {
+			return scope, nil
+		}
+	}
+	return nil, nil
+}
+
+// ValidateModel validates the connection, scope config or scope the same way as ModelSrvHelper does
+func (srv *PluginSourceSrvHelper) ValidateModel(model interface{}) errors.Error {
+	if customValidator, ok := model.(CustomValidator); ok {
+		return customValidator.CustomValidate(model, srv.validator)
+	}
+	if e := srv.validator.Struct(model); e != nil {
+		return errors.BadInput.Wrap(e, "validation faild")
+	}
+	return nil
+}
+
+// Save validates the model and inserts or updates it by its primary key
+func (srv *PluginSourceSrvHelper) Save(model dal.Tabler) errors.Error {
+	if scopeConfig, ok := model.(plugin.ToolLayerScopeConfig); ok && srv.scopeConfig != nil &&
+		reflect.TypeOf(scopeConfig) == reflect.TypeOf(srv.scopeConfig) {
+		setDefaultEntities(model)
+	}
+	err := srv.ValidateModel(model)
+	if err != nil {
+		return err
+	}
+	err = srv.db.CreateOrUpdate(model)
+	if err != nil {
+		if srv.db.IsDuplicationError(err) {
+			return errors.BadInput.Wrap(err, fmt.Sprintf("duplicated %s of plugin %s", model.TableName(), srv.pluginName))
+		}
+		return err
+	}
+	return nil
+}
+
+func (srv *PluginSourceSrvHelper) findAll(proto dal.Tabler, collect func(model interface{}), clauses ...dal.Clause) errors.Error {
+	slice := reflect.New(reflect.SliceOf(reflect.TypeOf(proto)))
+	err := srv.db.All(slice.Interface(), append([]dal.Clause{dal.From(proto)}, clauses...)...)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < slice.Elem().Len(); i++ {
+		collect(slice.Elem().Index(i).Interface())
+	}
+	return nil
+}
+
+// GetSecretFields returns json names of the fields encrypted in database, which are the secrets of a connection
+func GetSecretFields(model interface{}) []string {
+	var fields []string
+	collectSecretFields(reflectType(model), &fields)
+	return fields
+}
+
+func collectSecretFields(typ reflect.Type, fields *[]string) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectSecretFields(field.Type, fields)
+			continue
+		}
+		if !field.IsExported() || !strings.Contains(field.Tag.Get("gorm"), "serializer:encdec") {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		*fields = append(*fields, name)
+	}
+}
+
+func newModel(proto dal.Tabler) interface{} {
+	return reflect.New(reflectType(proto)).Interface()
+}
+
+func isNilModel(model interface{}) bool {
+	if model == nil {
+		return true
+	}
+	value := reflect.ValueOf(model)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
@@ -0,0 +1,45 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package srvhelper
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password" gorm:"serializer:encdec"`
+}
+
+type testConnection struct {
+	Name string `json:"name"`
+	common.Model
+	testAuth
+	Endpoint      string `json:"endpoint"`
+	WebhookSecret string `json:"webhookSecret" gorm:"serializer:encdec"`
+	DbUrl         string `gorm:"serializer:encdec"`
+	Hidden        string `json:"-" gorm:"serializer:encdec"`
+}
+
+func TestGetSecretFields(t *testing.T) {
+	assert.Equal(t, []string{"password", "webhookSecret", "DbUrl"}, GetSecretFields(&testConnection{}))
+	assert.Empty(t, GetSecretFields(&common.Model{}))
+}
@@ -197,7 +197,11 @@ func (d *Dalgorm) CursorTx(clauses ...dal.Clause) *gorm.DB {
 // Fetch loads row data from `cursor` into `dst`
 func (d *Dalgorm) Fetch(cursor dal.Rows, dst interface{}) errors.Error {
 	if rows, ok := cursor.(*sql.Rows); ok {
-		return d.convertGormError(d.db.ScanRows(rows, dst))
+		err := d.db.ScanRows(rows, dst)
+		if err == nil {
+			derefMapValues(dst)
+		}
+		return d.convertGormError(err)
 	} else {
 		return errors.Default.New(fmt.Sprintf("can not support type %s to be a dal.Rows interface", reflect.TypeOf(cursor).String()))
 	}
@@ -206,7 +210,33 @@ func (d *Dalgorm) Fetch(cursor dal.Rows, dst interface{}) errors.Error {
 // All loads matched rows from database to `dst`, USE IT WITH COUTIOUS!!
 func (d *Dalgorm) All(dst interface{}, clauses ...dal.Clause) errors.Error {
 	d.unwrapDynamic(&dst, &clauses)
-	return d.convertGormError(buildTx(d.db, clauses).Find(dst).Error)
+	err := buildTx(d.db, clauses).Find(dst).Error
+	if err == nil {
+		derefMapValues(dst)
+	}
+	return d.convertGormError(err)
+}
+
+// derefMapValues unwraps the `*interface{}` values of scanned maps, the sqlite driver reports
+// columns of unknown types (e.g. json) with a `*interface{}` scan type which gorm keeps as is
+func derefMapValues(dst interface{}) {
+	deref := func(row map[string]interface{}) {
+		for column, value := range row {
+			if ptr, ok := value.(*interface{}); ok {
+				row[column] = *ptr
+			}
+		}
+	}
+	switch rows := dst.(type) {
+	case *map[string]interface{}:
+		deref(*rows)
+	case map[string]interface{}:
+		deref(rows)
+	case *[]map[string]interface{}:
+		for _, row := range *rows {
+			deref(row)
+		}
+	}
 }
 
 // First loads first matched row from database to `dst`, error will be returned if no records were found
@@ -288,6 +318,12 @@ func (d *Dalgorm) UpdateColumn(entityOrTable interface{}, columnName string, val
 
 // UpdateColumns allows you to update multiple columns of mulitple records
 func (d *Dalgorm) UpdateColumns(entityOrTable interface{}, set []dal.DalSet, clauses ...dal.Clause) errors.Error {
+	_, err := d.UpdateColumnsCount(entityOrTable, set, clauses...)
+	return err
+}
+
+// UpdateColumnsCount updates multiple columns of mulitple records and returns the number of records affected
+func (d *Dalgorm) UpdateColumnsCount(entityOrTable interface{}, set []dal.DalSet, clauses ...dal.Clause) (int64, errors.Error) {
 	d.unwrapDynamic(&entityOrTable, &clauses)
 	updatesSet := make(map[string]interface{})
 
@@ -299,7 +335,8 @@ func (d *Dalgorm) UpdateColumns(entityOrTable interface{}, set []dal.DalSet, cla
 	}
 
 	clauses = append(clauses, dal.From(entityOrTable))
-	return d.convertGormError(buildTx(d.db, clauses).Updates(updatesSet).Error)
+	r := buildTx(d.db, clauses).Updates(updatesSet)
+	return r.RowsAffected, d.convertGormError(r.Error)
 }
 
 // UpdateAllColumn updated all Columns of entity
@@ -341,6 +378,19 @@ func (d *Dalgorm) DropColumns(table string, columnNames ...string) errors.Error
 		_ = d.Exec("SELECT * FROM ? LIMIT 1", clause.Table{Name: table})
 	}()
 	for _, columnName := range columnNames {
+		if d.db.Dialector.Name() == "sqlite" {
+			// sqlite refuses to drop indexed columns, drop the indexes along with the column like other databases do
+			indexes, err := d.sqliteColumnIndexes(table, columnName)
+			if err != nil {
+				return err
+			}
+			for _, index := range indexes {
+				err = d.DropIndexes(table, index.Name)
+				if err != nil {
+					return err
+				}
+			}
+		}
 		err := d.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: table}, clause.Column{Name: columnName})
 		// err := d.db.Migrator().DropColumn(table, columnName)
 		if err != nil {
@@ -379,6 +429,9 @@ func (d *Dalgorm) ModifyColumnType(table, columnName, columnType string) errors.
 	defer func() {
 		_ = d.Exec("SELECT * FROM ? LIMIT 1", clause.Table{Name: table})
 	}()
+	if d.db.Dialector.Name() == "sqlite" {
+		return d.sqliteModifyColumnType(table, columnName, columnType)
+	}
 	query := "ALTER TABLE ? MODIFY COLUMN ? %s"
 	if d.db.Dialector.Name() == "postgres" {
 		query = "ALTER TABLE ? ALTER COLUMN ? TYPE %s"
@@ -390,11 +443,95 @@ func (d *Dalgorm) ModifyColumnType(table, columnName, columnType string) errors.
 	)
 }
 
+// sqliteIndex is an index created by `CREATE INDEX` in sqlite
+type sqliteIndex struct {
+	Name string
+	Sql  string
+}
+
+// sqliteColumnIndexes returns the explicitly created indexes covering the column,
+// indexes backing PRIMARY KEY and UNIQUE constraints have no sql and are excluded
+func (d *Dalgorm) sqliteColumnIndexes(table, columnName string) ([]sqliteIndex, errors.Error) {
+	var indexes []sqliteIndex
+	err := d.db.Raw(
+		`SELECT DISTINCT m.name AS name, m.sql AS sql FROM sqlite_master m, pragma_index_info(m.name) i
+		WHERE m.type = 'index' AND m.tbl_name = ? AND m.sql IS NOT NULL AND i.name = ?`,
+		table, columnName,
+	).Scan(&indexes).Error
+	if err != nil {
+		return nil, d.convertGormError(err)
+	}
+	return indexes, nil
+}
+
+// sqliteAffinity returns the type affinity of the declared column type
+// according to https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+func sqliteAffinity(columnType string) string {
+	t := strings.ToUpper(columnType)
+	switch {
+	case strings.Contains(t, "INT"):
+		return "INTEGER"
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return "TEXT"
+	case strings.Contains(t, "BLOB"), strings.TrimSpace(t) == "":
+		return "BLOB"
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return "REAL"
+	default:
+		return "NUMERIC"
+	}
+}
+
+// sqliteModifyColumnType emulates `ALTER COLUMN TYPE` since sqlite doesn't support it.
+// Column types in sqlite are mere hints (e.g. the length of varchar is not enforced), so only
+// a change of the type affinity requires copying the values into a new column
+func (d *Dalgorm) sqliteModifyColumnType(table, columnName, columnType string) errors.Error {
+	columnTypes, err := d.db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return d.convertGormError(err)
+	}
+	for _, ct := range columnTypes {
+		if ct.Name() == columnName && sqliteAffinity(ct.DatabaseTypeName()) == sqliteAffinity(columnType) {
+			return nil
+		}
+	}
+	indexes, errs := d.sqliteColumnIndexes(table, columnName)
+	if errs != nil {
+		return errs
+	}
+	tmpColumnName := columnName + "_tmp"
+	errs = d.Exec("ALTER TABLE ? ADD ? ?", clause.Table{Name: table}, clause.Column{Name: tmpColumnName}, clause.Expr{SQL: columnType})
+	if errs != nil {
+		return errs
+	}
+	errs = d.Exec("UPDATE ? SET ? = ?", clause.Table{Name: table}, clause.Column{Name: tmpColumnName}, clause.Column{Name: columnName})
+	if errs != nil {
+		return errs
+	}
+	errs = d.DropColumns(table, columnName)
+	if errs != nil {
+		return errs
+	}
+	errs = d.RenameColumn(table, tmpColumnName, columnName)
+	if errs != nil {
+		return errs
+	}
+	for _, index := range indexes {
+		errs = d.Exec(index.Sql)
+		if errs != nil {
+			return errs
+		}
+	}
+	return nil
+}
+
 // AllTables returns all tables in the database
 func (d *Dalgorm) AllTables() ([]string, errors.Error) {
 	var tableSql string
 	if d.db.Dialector.Name() == "mysql" {
 		tableSql = "show tables"
+	} else if d.db.Dialector.Name() == "sqlite" {
+		tableSql = "select name from sqlite_master where type = 'table' and name not like 'sqlite_%'"
 	} else {
 		tableSql = "select table_name from information_schema.tables where table_schema = 'public' and table_name not like '_devlake%'"
 	}
@@ -447,7 +584,40 @@ func (d *Dalgorm) HasColumn(table interface{}, columnName string) bool {
 // RenameTable renames table name
 func (d *Dalgorm) RenameTable(oldName, newName string) errors.Error {
 	err := d.db.Migrator().RenameTable(oldName, newName)
-	return d.convertGormError(err)
+	if err != nil {
+		return d.convertGormError(err)
+	}
+	if d.db.Dialector.Name() == "sqlite" {
+		return d.sqliteRenameIndexes(oldName, newName)
+	}
+	return nil
+}
+
+// sqliteRenameIndexes renames the indexes along with the table, index names are unique across the whole
+// database in sqlite, a table created with the old name would fail to create its indexes otherwise
+func (d *Dalgorm) sqliteRenameIndexes(oldName, newName string) errors.Error {
+	var indexes []sqliteIndex
+	err := d.db.Raw(
+		"SELECT name, sql FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL",
+		newName,
+	).Scan(&indexes).Error
+	if err != nil {
+		return d.convertGormError(err)
+	}
+	for _, index := range indexes {
+		if !strings.Contains(index.Name, oldName) {
+			continue
+		}
+		errs := d.DropIndexes(newName, index.Name)
+		if errs != nil {
+			return errs
+		}
+		errs = d.Exec(strings.Replace(index.Sql, index.Name, strings.Replace(index.Name, oldName, newName, 1), 1))
+		if errs != nil {
+			return errs
+		}
+	}
+	return nil
 }
 
 // DropIndexes drops indexes for specified table
@@ -493,7 +663,8 @@ func (d *Dalgorm) IsErrorNotFound(err error) bool {
 
 // IsDuplicationError checking if the sql error is not found.
 func (d *Dalgorm) IsDuplicationError(err error) bool {
-	return strings.Contains(strings.ToLower(err.Error()), "duplicate")
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "duplicate") || strings.Contains(msg, "unique constraint failed")
 }
 
 // IsCachedPlanError checks if the error is related to postgres cached query plan
@@ -510,7 +681,7 @@ func (d *Dalgorm) IsJsonOrderError(err error) bool {
 
 // IsTableExist checks if table exists
 func (d *Dalgorm) IsTableExist(err error) bool {
-	return strings.Contains(err.Error(), "Unknown table")
+	return strings.Contains(err.Error(), "Unknown table") || strings.Contains(err.Error(), "no such table")
 }
 
 // RawCursor (Deprecated) executes raw sql query and returns a database cursor
@@ -18,9 +18,12 @@ limitations under the License.
 package dalgorm
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func Test_validateQuery(t *testing.T) {
@@ -48,3 +51,58 @@ func Test_validateQuery(t *testing.T) {
 		assert.Nil(t, validateQuery(target), "failed text: `%s`", target)
 	}
 }
+
+type testRecord struct {
+	Id    string `gorm:"primaryKey;type:varchar(100)"`
+	Name  string `gorm:"type:varchar(100);index"`
+	Count string `gorm:"type:varchar(20)"`
+}
+
+func (testRecord) TableName() string {
+	return "test_records"
+}
+
+func TestDalgorm_Sqlite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	assert.Nil(t, err)
+	d := NewDalgorm(db)
+	assert.Nil(t, d.AutoMigrate(&testRecord{}))
+	assert.Nil(t, d.Create(&testRecord{Id: "1", Name: "a", Count: "10"}))
+
+	tables, errs := d.AllTables()
+	assert.Nil(t, errs)
+	assert.Equal(t, []string{"test_records"}, tables)
+
+	assert.True(t, d.IsDuplicationError(d.Create(&testRecord{Id: "1"})))
+
+	// a change of the affinity copies the values and keeps the indexes
+	assert.Nil(t, d.ModifyColumnType("test_records", "count", "bigint"))
+	var count int64
+	assert.Nil(t, db.Raw("SELECT count FROM test_records").Scan(&count).Error)
+	assert.Equal(t, int64(10), count)
+	assert.Nil(t, d.ModifyColumnType("test_records", "name", "text"))
+	indexes, errs := d.sqliteColumnIndexes("test_records", "name")
+	assert.Nil(t, errs)
+	assert.Len(t, indexes, 1)
+
+	assert.Nil(t, d.RenameColumn("test_records", "count", "total"))
+	assert.True(t, d.HasColumn("test_records", "total"))
+
+	// indexes are renamed along with the table so the table could be created again
+	assert.Nil(t, d.RenameTable("test_records", "test_records_old"))
+	indexes, errs = d.sqliteColumnIndexes("test_records_old", "name")
+	assert.Nil(t, errs)
+	assert.Equal(t, "idx_test_records_old_name", indexes[0].Name)
+	assert.Nil(t, d.AutoMigrate(&testRecord{}))
+	assert.Nil(t, d.DropTables("test_records"))
+	assert.Nil(t, d.RenameTable("test_records_old", "test_records"))
+
+	// indexed columns can be dropped
+	assert.Nil(t, d.DropColumns("test_records", "name"))
+	assert.False(t, d.HasColumn("test_records", "name"))
+
+	tx := d.Begin()
+	assert.Nil(t, tx.LockTables(nil))
+	assert.Nil(t, tx.UnlockTables())
+	assert.Nil(t, tx.Commit())
+}
@@ -82,6 +82,10 @@ func (t *DalgormTransaction) LockTables(lockTables dal.LockTables) errors.Error
 			}
 		}
 		return nil
+	case "sqlite":
+		// sqlite has no table level locks, the transaction is opened with `BEGIN IMMEDIATE`
+		// (see `_txlock` in runner.getSqliteDsn) and holds the write lock of the whole database
+		return nil
 	default:
 		panic(fmt.Errorf("unknown dialect %s", t.Dialect()))
 	}
@@ -96,6 +100,9 @@ func (t *DalgormTransaction) UnlockTables() errors.Error {
 	case "postgres":
 		// pg has no unlock tables
 		return nil
+	case "sqlite":
+		// locks are released along with the transaction
+		return nil
 	default:
 		panic(fmt.Errorf("unknown dialect %s", t.Dialect()))
 	}
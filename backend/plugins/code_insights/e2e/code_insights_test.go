@@ -0,0 +1,75 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/codequality"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/helpers/e2ehelper"
+	"github.com/apache/incubator-devlake/plugins/code_insights/impl"
+	"github.com/apache/incubator-devlake/plugins/code_insights/tasks"
+)
+
+func TestCodeInsightsDataFlow(t *testing.T) {
+	var plugin impl.CodeInsights
+	dataflowTester := e2ehelper.NewDataFlowTester(t, "code_insights", plugin)
+
+	taskData := &tasks.CodeInsightsTaskData{
+		Options: &tasks.CodeInsightsOptions{
+			ProjectName: "project1",
+			// the commits of the fixtures are older than the default window
+			ChurnDays:          36500,
+			BusFactorThreshold: 0.5,
+			MaxDirectoryDepth:  2,
+		},
+	}
+
+	dataflowTester.ImportCsvIntoTabler("./code_insights/project_mapping.csv", &crossdomain.ProjectMapping{})
+	dataflowTester.ImportCsvIntoTabler("./code_insights/commits.csv", &code.Commit{})
+	dataflowTester.ImportCsvIntoTabler("./code_insights/repo_commits.csv", &code.RepoCommit{})
+	dataflowTester.ImportCsvIntoTabler("./code_insights/commit_files.csv", &code.CommitFile{})
+	dataflowTester.ImportCsvIntoTabler("./code_insights/repo_snapshot.csv", &code.RepoSnapshot{})
+	dataflowTester.ImportCsvIntoTabler("./code_insights/components.csv", &code.Component{})
+	dataflowTester.FlushTabler(&codequality.CqFileMetrics{})
+	dataflowTester.FlushTabler(&codequality.CqProject{})
+
+	// verify ownership calculation
+	dataflowTester.FlushTabler(&code.CodeOwnership{})
+	dataflowTester.FlushTabler(&code.ComponentBusFactor{})
+	dataflowTester.Subtask(tasks.CalculateCodeOwnershipMeta, taskData)
+	dataflowTester.VerifyTableWithOptions(&code.CodeOwnership{}, e2ehelper.TableOptions{
+		CSVRelPath:  "./code_insights/code_ownerships.csv",
+		IgnoreTypes: []interface{}{common.NoPKModel{}},
+	})
+	dataflowTester.VerifyTableWithOptions(&code.ComponentBusFactor{}, e2ehelper.TableOptions{
+		CSVRelPath:  "./code_insights/component_bus_factors.csv",
+		IgnoreTypes: []interface{}{common.NoPKModel{}},
+	})
+
+	// verify hotspot calculation
+	dataflowTester.FlushTabler(&code.FileHotspot{})
+	dataflowTester.Subtask(tasks.CalculateHotspotsMeta, taskData)
+	dataflowTester.VerifyTableWithOptions(&code.FileHotspot{}, e2ehelper.TableOptions{
+		CSVRelPath:  "./code_insights/file_hotspots.csv",
+		IgnoreTypes: []interface{}{common.NoPKModel{}},
+	})
+}
@@ -0,0 +1,120 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package impl
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	coreModels "github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/code_insights/tasks"
+)
+
+// make sure interface is implemented
+var _ interface {
+	plugin.PluginMeta
+	plugin.PluginTask
+	plugin.PluginModel
+	plugin.PluginMetric
+	plugin.MetricPluginBlueprintV200
+} = (*CodeInsights)(nil)
+
+type CodeInsights struct{}
+
+func (p CodeInsights) Description() string {
+	return "calculate code ownership, bus factor, hotspots and owner review coverage from git history"
+}
+
+// RequiredDataEntities hasn't been used so far
+func (p CodeInsights) RequiredDataEntities() (data []map[string]interface{}, err errors.Error) {
+	return []map[string]interface{}{}, nil
+}
+
+func (p CodeInsights) GetTablesInfo() []dal.Tabler {
+	return []dal.Tabler{}
+}
+
+func (p CodeInsights) Name() string {
+	return "code_insights"
+}
+
+func (p CodeInsights) IsProjectMetric() bool {
+	return true
+}
+
+func (p CodeInsights) RunAfter() ([]string, errors.Error) {
+	return []string{}, nil
+}
+
+func (p CodeInsights) Settings() interface{} {
+	return nil
+}
+
+func (p CodeInsights) SubTaskMetas() []plugin.SubTaskMeta {
+	return []plugin.SubTaskMeta{
+		tasks.CalculateCodeOwnershipMeta,
+		tasks.CalculateHotspotsMeta,
+		tasks.CalculateOwnerReviewCoverageMeta,
+	}
+}
+
+func (p CodeInsights) PrepareTaskData(taskCtx plugin.TaskContext, options map[string]interface{}) (interface{}, errors.Error) {
+	op, err := tasks.DecodeAndValidateTaskOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return &tasks.CodeInsightsTaskData{
+		Options: op,
+	}, nil
+}
+
+// RootPkgPath information lost when compiled as plugin(.so)
+func (p CodeInsights) RootPkgPath() string {
+	return "github.com/apache/incubator-devlake/plugins/code_insights"
+}
+
+func (p CodeInsights) MakeMetricPluginPipelinePlanV200(projectName string, options json.RawMessage) (coreModels.PipelinePlan, errors.Error) {
+	op := &tasks.CodeInsightsOptions{}
+	if len(options) > 0 {
+		err := json.Unmarshal(options, op)
+		if err != nil {
+			return nil, errors.Default.WrapRaw(err)
+		}
+	}
+	plan := coreModels.PipelinePlan{
+		{
+			{
+				Plugin: "code_insights",
+				Options: map[string]interface{}{
+					"projectName":        projectName,
+					"churnDays":          op.ChurnDays,
+					"busFactorThreshold": op.BusFactorThreshold,
+					"maxDirectoryDepth":  op.MaxDirectoryDepth,
+				},
+				Subtasks: []string{
+					tasks.CalculateCodeOwnershipMeta.Name,
+					tasks.CalculateHotspotsMeta.Name,
+					tasks.CalculateOwnerReviewCoverageMeta.Name,
+				},
+			},
+		},
+	}
+	return plan, nil
+}
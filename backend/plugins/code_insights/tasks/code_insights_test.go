@@ -0,0 +1,101 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnershipCalculator(t *testing.T) {
+	calculator := newOwnershipCalculator(1, 0.5)
+	calculator.add(&fileAuthorLines{FilePath: "api/a.go", AuthorId: "alice", AuthorName: "Alice", Lines: 60})
+	calculator.add(&fileAuthorLines{FilePath: "api/a.go", AuthorId: "bob", AuthorName: "Bob", Lines: 40})
+	calculator.add(&fileAuthorLines{FilePath: "api/v1/b.go", AuthorId: "bob", AuthorName: "Bob", Lines: 50})
+	calculator.add(&fileAuthorLines{FilePath: "main.go", AuthorId: "carol", AuthorName: "Carol", Lines: 50})
+
+	shares := map[string]float64{}
+	for _, o := range calculator.ownerships("repo") {
+		shares[o.PathType+":"+o.Path+":"+o.AuthorId] = o.Share
+	}
+	assert.Equal(t, map[string]float64{
+		code.OWNERSHIP_PATH_FILE + ":api/a.go:alice":  0.6,
+		code.OWNERSHIP_PATH_FILE + ":api/a.go:bob":    0.4,
+		code.OWNERSHIP_PATH_FILE + ":api/v1/b.go:bob": 1,
+		code.OWNERSHIP_PATH_FILE + ":main.go:carol":   1,
+		code.OWNERSHIP_PATH_DIRECTORY + ":api:alice":  0.4,
+		code.OWNERSHIP_PATH_DIRECTORY + ":api:bob":    0.6,
+	}, shares)
+
+	repo := calculator.busFactor("repo", "", nil)
+	assert.Equal(t, 200, repo.TotalLines)
+	assert.Equal(t, 3, repo.AuthorCount)
+	assert.Equal(t, "bob", repo.TopAuthorId)
+	assert.Equal(t, 0.45, repo.TopAuthorShare)
+	assert.Equal(t, 2, repo.BusFactor)
+
+	api := calculator.busFactor("repo", "api", regexp.MustCompile("^api/"))
+	assert.Equal(t, 150, api.TotalLines)
+	assert.Equal(t, "bob", api.TopAuthorId)
+	assert.Equal(t, 1, api.BusFactor)
+
+	empty := calculator.busFactor("repo", "web", regexp.MustCompile("^web/"))
+	assert.Equal(t, 0, empty.BusFactor)
+	assert.Equal(t, 0, empty.TotalLines)
+}
+
+func TestScoreHotspots(t *testing.T) {
+	churns := []fileChurn{
+		{FilePath: "a.go", CommitCount: 10, Churn: 300, AuthorCount: 3},
+		{FilePath: "b.go", CommitCount: 5, Churn: 50, AuthorCount: 1},
+		{FilePath: "deleted.go", CommitCount: 20, Churn: 100, AuthorCount: 2},
+	}
+	lines := map[string]int{"a.go": 100, "b.go": 400}
+
+	hotspots := scoreHotspots("repo", churns, lines, nil)
+	assert.Len(t, hotspots, 2)
+	assert.Equal(t, "a.go", hotspots[0].FilePath)
+	assert.Equal(t, 0.25, hotspots[0].HotspotScore)
+	assert.Equal(t, 0.5, hotspots[1].HotspotScore)
+	assert.Nil(t, hotspots[0].Complexity)
+
+	complexities := map[string]fileComplexity{"a.go": {FilePath: "a.go", Complexity: 40, CognitiveComplexity: 30}}
+	hotspots = scoreHotspots("repo", churns, lines, complexities)
+	assert.Equal(t, 1.0, hotspots[0].HotspotScore)
+	assert.Equal(t, 40, *hotspots[0].Complexity)
+	assert.Equal(t, 0.0, hotspots[1].HotspotScore)
+}
+
+func TestGroupComplexities(t *testing.T) {
+	complexities := []fileComplexity{
+		{ProjectKey: "cq1", RepoId: "repo1", FilePath: "main.go", Complexity: 10},
+		{ProjectKey: "cq2", RepoId: "repo2", FilePath: "main.go", Complexity: 20},
+		{ProjectKey: "cq3", FilePath: "main.go", Complexity: 30},
+		{ProjectKey: "cq3", FilePath: "a.go", Complexity: 40},
+		{ProjectKey: "cq4", FilePath: "main.go", Complexity: 50},
+	}
+	complexityByRepo := groupComplexities([]string{"repo1", "repo2", "repo3"}, complexities)
+	assert.Equal(t, 10, complexityByRepo["repo1"]["main.go"].Complexity)
+	assert.Len(t, complexityByRepo["repo1"], 1)
+	assert.Equal(t, 20, complexityByRepo["repo2"]["main.go"].Complexity)
+	// main.go is reported by two cq projects without a known repo
+	assert.Equal(t, map[string]fileComplexity{"a.go": complexities[3]}, complexityByRepo["repo3"])
+}
@@ -0,0 +1,267 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+// CalculateCodeOwnershipMeta contains metadata for the CalculateCodeOwnership subtask.
+var CalculateCodeOwnershipMeta = plugin.SubTaskMeta{
+	Name:             "calculateCodeOwnership",
+	EntryPoint:       CalculateCodeOwnership,
+	EnabledByDefault: true,
+	Description:      "Calculate the ownership of files and directories and the bus factor of components from the surviving lines",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE},
+	DependencyTables: []string{"repo_snapshot", "commits", "components", "project_mapping"},
+	ProductTables: []string{
+		code.CodeOwnership{}.TableName(),
+		code.ComponentBusFactor{}.TableName(),
+	},
+}
+
+type fileAuthorLines struct {
+	FilePath   string
+	AuthorId   string
+	AuthorName string
+	Lines      int `gorm:"column:line_count"`
+}
+
+// CalculateCodeOwnership calculates the ownership and bus factor of every repo of the project.
+func CalculateCodeOwnership(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+	data := taskCtx.GetData().(*CodeInsightsTaskData)
+	repoIds, err := getProjectRepoIds(db, data.Options.ProjectName)
+	if err != nil {
+		return err
+	}
+	for _, repoId := range repoIds {
+		logger.Info("calculate code ownership of repo %s", repoId)
+		err = calculateRepoOwnership(taskCtx, repoId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func calculateRepoOwnership(taskCtx plugin.SubTaskContext, repoId string) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*CodeInsightsTaskData)
+	err := db.Delete(&code.CodeOwnership{}, dal.Where("repo_id = ?", repoId))
+	if err != nil {
+		return err
+	}
+	err = db.Delete(&code.ComponentBusFactor{}, dal.Where("repo_id = ?", repoId))
+	if err != nil {
+		return err
+	}
+
+	cursor, err := db.Cursor(
+		dal.Select("rs.file_path, c.author_id, MAX(c.author_name) AS author_name, COUNT(*) AS line_count"),
+		dal.From("repo_snapshot rs"),
+		dal.Join("JOIN commits c ON c.sha = rs.commit_sha"),
+		dal.Where("rs.repo_id = ?", repoId),
+		dal.Groupby("rs.file_path, c.author_id"),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	calculator := newOwnershipCalculator(data.Options.MaxDirectoryDepth, data.Options.BusFactorThreshold)
+	for cursor.Next() {
+		row := &fileAuthorLines{}
+		err = db.Fetch(cursor, row)
+		if err != nil {
+			return err
+		}
+		calculator.add(row)
+	}
+
+	var components []code.Component
+	err = db.All(&components, dal.Where("repo_id = ?", repoId))
+	if err != nil {
+		return err
+	}
+
+	divider := api.NewBatchSaveDivider(taskCtx, 500, "", "")
+	ownershipSaver, err := divider.ForType(reflect.TypeOf(&code.CodeOwnership{}))
+	if err != nil {
+		return err
+	}
+	for _, ownership := range calculator.ownerships(repoId) {
+		err = ownershipSaver.Add(ownership)
+		if err != nil {
+			return err
+		}
+	}
+	busFactorSaver, err := divider.ForType(reflect.TypeOf(&code.ComponentBusFactor{}))
+	if err != nil {
+		return err
+	}
+	err = busFactorSaver.Add(calculator.busFactor(repoId, "", nil))
+	if err != nil {
+		return err
+	}
+	for _, component := range components {
+		re, e := regexp.Compile(component.PathRegex)
+		if e != nil {
+			taskCtx.GetLogger().Warn(e, "invalid path regex of component %s", component.Name)
+			continue
+		}
+		err = busFactorSaver.Add(calculator.busFactor(repoId, component.Name, re))
+		if err != nil {
+			return err
+		}
+	}
+	return divider.Close()
+}
+
+type authorLines struct {
+	name  string
+	lines int
+}
+
+type pathOwnership struct {
+	authors map[string]*authorLines
+	total   int
+}
+
+func (p *pathOwnership) add(authorId, authorName string, lines int) {
+	a := p.authors[authorId]
+	if a == nil {
+		a = &authorLines{name: authorName}
+		p.authors[authorId] = a
+	}
+	a.lines += lines
+	p.total += lines
+}
+
+type ownershipCalculator struct {
+	maxDepth  int
+	threshold float64
+	files     map[string]*pathOwnership
+	dirs      map[string]*pathOwnership
+}
+
+func newOwnershipCalculator(maxDepth int, threshold float64) *ownershipCalculator {
+	return &ownershipCalculator{
+		maxDepth:  maxDepth,
+		threshold: threshold,
+		files:     make(map[string]*pathOwnership),
+		dirs:      make(map[string]*pathOwnership),
+	}
+}
+
+func getPathOwnership(m map[string]*pathOwnership, path string) *pathOwnership {
+	p := m[path]
+	if p == nil {
+		p = &pathOwnership{authors: make(map[string]*authorLines)}
+		m[path] = p
+	}
+	return p
+}
+
+// add counts the lines of the author in the file and all its parent directories within the max depth
+func (c *ownershipCalculator) add(row *fileAuthorLines) {
+	if len(row.FilePath) > maxPathLength {
+		return
+	}
+	getPathOwnership(c.files, row.FilePath).add(row.AuthorId, row.AuthorName, row.Lines)
+	parts := strings.Split(row.FilePath, "/")
+	for depth := 1; depth < len(parts) && depth <= c.maxDepth; depth++ {
+		getPathOwnership(c.dirs, strings.Join(parts[:depth], "/")).add(row.AuthorId, row.AuthorName, row.Lines)
+	}
+}
+
+func (c *ownershipCalculator) ownerships(repoId string) []*code.CodeOwnership {
+	var result []*code.CodeOwnership
+	collect := func(m map[string]*pathOwnership, pathType string) {
+		for path, p := range m {
+			for authorId, a := range p.authors {
+				result = append(result, &code.CodeOwnership{
+					RepoId:     repoId,
+					Path:       path,
+					AuthorId:   authorId,
+					PathType:   pathType,
+					AuthorName: a.name,
+					Lines:      a.lines,
+					TotalLines: p.total,
+					Share:      float64(a.lines) / float64(p.total),
+				})
+			}
+		}
+	}
+	collect(c.files, code.OWNERSHIP_PATH_FILE)
+	collect(c.dirs, code.OWNERSHIP_PATH_DIRECTORY)
+	return result
+}
+
+// busFactor calculates the bus factor of the files matching the regex, all files when the regex is nil
+func (c *ownershipCalculator) busFactor(repoId, componentName string, re *regexp.Regexp) *code.ComponentBusFactor {
+	component := &pathOwnership{authors: make(map[string]*authorLines)}
+	for path, p := range c.files {
+		if re != nil && !re.MatchString(path) {
+			continue
+		}
+		for authorId, a := range p.authors {
+			component.add(authorId, a.name, a.lines)
+		}
+	}
+	result := &code.ComponentBusFactor{
+		RepoId:        repoId,
+		ComponentName: componentName,
+		AuthorCount:   len(component.authors),
+		TotalLines:    component.total,
+	}
+	if component.total == 0 {
+		return result
+	}
+	authorIds := make([]string, 0, len(component.authors))
+	for authorId := range component.authors {
+		authorIds = append(authorIds, authorId)
+	}
+	sort.Slice(authorIds, func(i, j int) bool {
+		li, lj := component.authors[authorIds[i]].lines, component.authors[authorIds[j]].lines
+		if li != lj {
+			return li > lj
+		}
+		return authorIds[i] < authorIds[j]
+	})
+	result.TopAuthorId = authorIds[0]
+	result.TopAuthorShare = float64(component.authors[authorIds[0]].lines) / float64(component.total)
+	owned := 0
+	for _, authorId := range authorIds {
+		owned += component.authors[authorId].lines
+		result.BusFactor++
+		if float64(owned) > c.threshold*float64(component.total) {
+			break
+		}
+	}
+	return result
+}
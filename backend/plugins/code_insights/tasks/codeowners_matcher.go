@@ -0,0 +1,94 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"regexp"
+	"strings"
+)
+
+// compileCodeOwnersPattern converts a CODEOWNERS pattern into a regexp matching the file paths it owns.
+// It follows the gitignore rules supported by GitHub and GitLab:
+// patterns with a leading or inner slash are relative to the root, others match at any depth,
+// patterns ending with a slash match directories only and patterns ending with `/*` don't match nested files.
+func compileCodeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(trimmed, "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+	var sb strings.Builder
+	if anchored {
+		sb.WriteString("^")
+	} else {
+		sb.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(trimmed); i++ {
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case trimmed[i] == '*':
+			sb.WriteString("[^/]*")
+		case trimmed[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(trimmed[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		sb.WriteString("/.*$")
+	case strings.HasSuffix(trimmed, "/*") && !strings.HasSuffix(trimmed, "/**"):
+		sb.WriteString("$")
+	default:
+		sb.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(sb.String())
+}
+
+type codeOwnersRule struct {
+	section string
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// ownersOf returns the owners of the file, one set per section, a single owner of each set has to review the change.
+// The last matching rule of a section wins.
+func ownersOf(rules []*codeOwnersRule, filePath string) [][]string {
+	lastMatches := make(map[string]*codeOwnersRule)
+	var sections []string
+	for _, rule := range rules {
+		if !rule.pattern.MatchString(filePath) {
+			continue
+		}
+		if _, ok := lastMatches[rule.section]; !ok {
+			sections = append(sections, rule.section)
+		}
+		lastMatches[rule.section] = rule
+	}
+	var owners [][]string
+	for _, section := range sections {
+		if rule := lastMatches[section]; len(rule.owners) > 0 {
+			owners = append(owners, rule.owners)
+		}
+	}
+	return owners
+}
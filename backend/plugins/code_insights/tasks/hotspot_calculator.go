@@ -0,0 +1,227 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+// CalculateHotspotsMeta contains metadata for the CalculateHotspots subtask.
+var CalculateHotspotsMeta = plugin.SubTaskMeta{
+	Name:             "calculateHotspots",
+	EntryPoint:       CalculateHotspots,
+	EnabledByDefault: true,
+	Description:      "Calculate the hotspots of the repos by combining the recent churn of the files with their complexity",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE, plugin.DOMAIN_TYPE_CODE_QUALITY},
+	DependencyTables: []string{"commit_files", "commits", "repo_commits", "repo_snapshot", "cq_file_metrics", "cq_projects", "project_mapping"},
+	ProductTables:    []string{code.FileHotspot{}.TableName()},
+}
+
+type fileChurn struct {
+	FilePath    string
+	CommitCount int
+	Churn       int
+	AuthorCount int
+}
+
+type fileLines struct {
+	FilePath string
+	Lines    int `gorm:"column:line_count"`
+}
+
+type fileComplexity struct {
+	ProjectKey          string
+	RepoId              string
+	FilePath            string
+	Complexity          int
+	CognitiveComplexity int
+}
+
+// CalculateHotspots calculates the file hotspots of every repo of the project.
+func CalculateHotspots(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+	data := taskCtx.GetData().(*CodeInsightsTaskData)
+	repoIds, err := getProjectRepoIds(db, data.Options.ProjectName)
+	if err != nil {
+		return err
+	}
+	// the repo of a cq project is the one containing the commit it was analysed at
+	var complexities []fileComplexity
+	err = db.All(&complexities,
+		dal.Select("m.project_key, rc.repo_id, m.file_path, m.complexity, m.cognitive_complexity"),
+		dal.From("cq_file_metrics m"),
+		dal.Join("JOIN project_mapping pm ON pm.row_id = m.project_key AND ? = 'cq_projects'", dal.ClauseColumn{Table: "pm", Name: "table"}),
+		dal.Join("LEFT JOIN cq_projects p ON p.id = m.project_key"),
+		dal.Join("LEFT JOIN repo_commits rc ON rc.commit_sha = p.commit_sha AND rc.repo_id IN ?", repoIds),
+		dal.Where("pm.project_name = ?", data.Options.ProjectName),
+	)
+	if err != nil {
+		return err
+	}
+	complexityByRepo := groupComplexities(repoIds, complexities)
+	since := time.Now().AddDate(0, 0, -data.Options.ChurnDays)
+	for _, repoId := range repoIds {
+		logger.Info("calculate hotspots of repo %s", repoId)
+		err = calculateRepoHotspots(taskCtx, repoId, since, complexityByRepo[repoId])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func calculateRepoHotspots(taskCtx plugin.SubTaskContext, repoId string, since time.Time, complexityByPath map[string]fileComplexity) errors.Error {
+	db := taskCtx.GetDal()
+	err := db.Delete(&code.FileHotspot{}, dal.Where("repo_id = ?", repoId))
+	if err != nil {
+		return err
+	}
+	var churns []fileChurn
+	err = db.All(&churns,
+		dal.Select("cf.file_path, COUNT(DISTINCT cf.commit_sha) AS commit_count, SUM(cf.additions + cf.deletions) AS churn, COUNT(DISTINCT c.author_id) AS author_count"),
+		dal.From("commit_files cf"),
+		dal.Join("JOIN commits c ON c.sha = cf.commit_sha"),
+		dal.Join("JOIN repo_commits rc ON rc.commit_sha = cf.commit_sha"),
+		dal.Where("rc.repo_id = ? AND c.authored_date >= ?", repoId, since),
+		dal.Groupby("cf.file_path"),
+	)
+	if err != nil {
+		return err
+	}
+	var lines []fileLines
+	err = db.All(&lines,
+		dal.Select("file_path, COUNT(*) AS line_count"),
+		dal.From("repo_snapshot"),
+		dal.Where("repo_id = ?", repoId),
+		dal.Groupby("file_path"),
+	)
+	if err != nil {
+		return err
+	}
+	linesByPath := make(map[string]int, len(lines))
+	for _, l := range lines {
+		linesByPath[l.FilePath] = l.Lines
+	}
+
+	divider := api.NewBatchSaveDivider(taskCtx, 500, "", "")
+	saver, err := divider.ForType(reflect.TypeOf(&code.FileHotspot{}))
+	if err != nil {
+		return err
+	}
+	for _, hotspot := range scoreHotspots(repoId, churns, linesByPath, complexityByPath) {
+		err = saver.Add(hotspot)
+		if err != nil {
+			return err
+		}
+	}
+	return divider.Close()
+}
+
+// groupComplexities maps the file complexities by repo and path. A repo gets the complexities of the cq projects
+// analysed at one of its commits, when there is none it falls back to the cq projects whose repo is unknown, keeping
+// only the paths reported by a single one of them since the others can't be told apart
+func groupComplexities(repoIds []string, complexities []fileComplexity) map[string]map[string]fileComplexity {
+	complexityByRepo := make(map[string]map[string]fileComplexity)
+	unresolved := make(map[string]fileComplexity)
+	ambiguous := make(map[string]bool)
+	for _, c := range complexities {
+		if c.RepoId != "" {
+			if complexityByRepo[c.RepoId] == nil {
+				complexityByRepo[c.RepoId] = make(map[string]fileComplexity)
+			}
+			complexityByRepo[c.RepoId][c.FilePath] = c
+			continue
+		}
+		if other, ok := unresolved[c.FilePath]; ok && other.ProjectKey != c.ProjectKey {
+			ambiguous[c.FilePath] = true
+		}
+		unresolved[c.FilePath] = c
+	}
+	for path := range ambiguous {
+		delete(unresolved, path)
+	}
+	for _, repoId := range repoIds {
+		if complexityByRepo[repoId] == nil {
+			complexityByRepo[repoId] = unresolved
+		}
+	}
+	return complexityByRepo
+}
+
+// scoreHotspots scores the files by their normalized commit count multiplied by their normalized complexity,
+// the lines are used instead of the complexity when no complexity is known for the repo.
+// Files deleted from the repo are skipped when the snapshot is available.
+func scoreHotspots(repoId string, churns []fileChurn, linesByPath map[string]int, complexityByPath map[string]fileComplexity) []*code.FileHotspot {
+	var hotspots []*code.FileHotspot
+	maxCommitCount, maxLines, maxComplexity := 0, 0, 0
+	for _, churn := range churns {
+		if len(churn.FilePath) > maxPathLength {
+			continue
+		}
+		lines, ok := linesByPath[churn.FilePath]
+		if !ok && len(linesByPath) > 0 {
+			continue
+		}
+		hotspot := &code.FileHotspot{
+			RepoId:      repoId,
+			FilePath:    churn.FilePath,
+			CommitCount: churn.CommitCount,
+			Churn:       churn.Churn,
+			AuthorCount: churn.AuthorCount,
+			Lines:       lines,
+		}
+		if c, ok := complexityByPath[churn.FilePath]; ok {
+			complexity, cognitiveComplexity := c.Complexity, c.CognitiveComplexity
+			hotspot.Complexity = &complexity
+			hotspot.CognitiveComplexity = &cognitiveComplexity
+			if complexity > maxComplexity {
+				maxComplexity = complexity
+			}
+		}
+		if churn.CommitCount > maxCommitCount {
+			maxCommitCount = churn.CommitCount
+		}
+		if lines > maxLines {
+			maxLines = lines
+		}
+		hotspots = append(hotspots, hotspot)
+	}
+	if maxCommitCount == 0 {
+		return hotspots
+	}
+	for _, hotspot := range hotspots {
+		size := 0.0
+		if maxComplexity > 0 {
+			if hotspot.Complexity != nil {
+				size = float64(*hotspot.Complexity) / float64(maxComplexity)
+			}
+		} else if maxLines > 0 {
+			size = float64(hotspot.Lines) / float64(maxLines)
+		}
+		hotspot.HotspotScore = float64(hotspot.CommitCount) / float64(maxCommitCount) * size
+	}
+	return hotspots
+}
@@ -0,0 +1,421 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+// CalculateOwnerReviewCoverageMeta contains metadata for the CalculateOwnerReviewCoverage subtask.
+var CalculateOwnerReviewCoverageMeta = plugin.SubTaskMeta{
+	Name:             "calculateOwnerReviewCoverage",
+	EntryPoint:       CalculateOwnerReviewCoverage,
+	EnabledByDefault: true,
+	Description:      "Check whether the owners in CODEOWNERS reviewed the pull requests changing their files",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE, plugin.DOMAIN_TYPE_CODE_REVIEW, plugin.DOMAIN_TYPE_CROSS},
+	DependencyTables: []string{
+		code.CodeOwnersFile{}.TableName(),
+		code.CodeOwnerRule{}.TableName(),
+		"pull_requests",
+		"pull_request_commits",
+		"pull_request_reviewers",
+		"commit_files",
+		"accounts",
+		"teams",
+		"team_users",
+		"user_accounts",
+	},
+	ProductTables: []string{
+		code.PullRequestOwnerReview{}.TableName(),
+		code.OwnerReviewCoverage{}.TableName(),
+	},
+}
+
+type prFile struct {
+	PullRequestId string
+	FilePath      string
+}
+
+type prReviewer struct {
+	PullRequestId string
+	ReviewerId    string
+	UserName      string
+	Email         string
+}
+
+type accountTeam struct {
+	AccountId string
+	UserName  string
+	Email     string
+	TeamId    string
+}
+
+// CalculateOwnerReviewCoverage calculates the owner review coverage of every repo of the project.
+func CalculateOwnerReviewCoverage(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+	data := taskCtx.GetData().(*CodeInsightsTaskData)
+	repoIds, err := getProjectRepoIds(db, data.Options.ProjectName)
+	if err != nil {
+		return err
+	}
+	resolver, err := loadOwnerResolver(db)
+	if err != nil {
+		return err
+	}
+	for _, repoId := range repoIds {
+		logger.Info("calculate owner review coverage of repo %s", repoId)
+		err = calculateRepoOwnerReviewCoverage(taskCtx, repoId, resolver)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadOwnerResolver(db dal.Dal) (*ownerResolver, errors.Error) {
+	var teams []crossdomain.Team
+	err := db.All(&teams)
+	if err != nil {
+		return nil, err
+	}
+	var accountTeams []accountTeam
+	err = db.All(&accountTeams,
+		dal.Select("a.id AS account_id, a.user_name, a.email, tu.team_id"),
+		dal.From("accounts a"),
+		dal.Join("JOIN user_accounts ua ON ua.account_id = a.id"),
+		dal.Join("JOIN team_users tu ON tu.user_id = ua.user_id"),
+		dal.Orderby("tu.team_id"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newOwnerResolver(teams, accountTeams), nil
+}
+
+func calculateRepoOwnerReviewCoverage(taskCtx plugin.SubTaskContext, repoId string, resolver *ownerResolver) errors.Error {
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+	err := db.Delete(&code.PullRequestOwnerReview{}, dal.Where("repo_id = ?", repoId))
+	if err != nil {
+		return err
+	}
+	err = db.Delete(&code.OwnerReviewCoverage{}, dal.Where("repo_id = ?", repoId))
+	if err != nil {
+		return err
+	}
+
+	var codeOwnersFiles []code.CodeOwnersFile
+	err = db.All(&codeOwnersFiles, dal.Where("repo_id = ?", repoId), dal.Orderby("committed_date"))
+	if err != nil {
+		return err
+	}
+	if len(codeOwnersFiles) == 0 {
+		return nil
+	}
+	var codeOwnerRules []code.CodeOwnerRule
+	err = db.All(&codeOwnerRules, dal.Where("repo_id = ?", repoId), dal.Orderby("commit_sha, line_number"))
+	if err != nil {
+		return err
+	}
+	rulesBySha := make(map[string][]*codeOwnersRule)
+	for _, r := range codeOwnerRules {
+		pattern, e := compileCodeOwnersPattern(r.Pattern)
+		if e != nil {
+			logger.Warn(e, "invalid CODEOWNERS pattern %s at %s", r.Pattern, r.CommitSha)
+			continue
+		}
+		rulesBySha[r.CommitSha] = append(rulesBySha[r.CommitSha], &codeOwnersRule{
+			section: r.Section,
+			pattern: pattern,
+			owners:  strings.Fields(r.Owners),
+		})
+	}
+
+	var pullRequests []code.PullRequest
+	err = db.All(&pullRequests, dal.Select("id, created_date"), dal.Where("base_repo_id = ?", repoId))
+	if err != nil {
+		return err
+	}
+	var files []prFile
+	err = db.All(&files,
+		dal.Select("DISTINCT prc.pull_request_id, cf.file_path"),
+		dal.From("pull_request_commits prc"),
+		dal.Join("JOIN pull_requests pr ON pr.id = prc.pull_request_id"),
+		dal.Join("JOIN commit_files cf ON cf.commit_sha = prc.commit_sha"),
+		dal.Where("pr.base_repo_id = ?", repoId),
+	)
+	if err != nil {
+		return err
+	}
+	filesByPr := make(map[string][]string)
+	for _, f := range files {
+		filesByPr[f.PullRequestId] = append(filesByPr[f.PullRequestId], f.FilePath)
+	}
+	var reviewers []prReviewer
+	err = db.All(&reviewers,
+		dal.Select("prr.pull_request_id, prr.reviewer_id, prr.user_name, a.email"),
+		dal.From("pull_request_reviewers prr"),
+		dal.Join("JOIN pull_requests pr ON pr.id = prr.pull_request_id"),
+		dal.Join("LEFT JOIN accounts a ON a.id = prr.reviewer_id"),
+		dal.Where("pr.base_repo_id = ?", repoId),
+	)
+	if err != nil {
+		return err
+	}
+	reviewersByPr := make(map[string][]prReviewer)
+	for _, r := range reviewers {
+		reviewersByPr[r.PullRequestId] = append(reviewersByPr[r.PullRequestId], r)
+	}
+
+	divider := api.NewBatchSaveDivider(taskCtx, 500, "", "")
+	reviewSaver, err := divider.ForType(reflect.TypeOf(&code.PullRequestOwnerReview{}))
+	if err != nil {
+		return err
+	}
+	coverages := newOwnerReviewCoverages(repoId)
+	for _, pr := range pullRequests {
+		codeOwnersFile := codeOwnersFileAt(codeOwnersFiles, pr.CreatedDate)
+		if codeOwnersFile == nil {
+			continue
+		}
+		ownerReviews, reviewed := evaluateOwnerReviews(
+			repoId, pr.Id, rulesBySha[codeOwnersFile.CommitSha], filesByPr[pr.Id], reviewersByPr[pr.Id], resolver,
+		)
+		if len(ownerReviews) == 0 {
+			continue
+		}
+		coverages.add(ownerReviews, reviewed)
+		for _, ownerReview := range ownerReviews {
+			err = reviewSaver.Add(ownerReview)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	coverageSaver, err := divider.ForType(reflect.TypeOf(&code.OwnerReviewCoverage{}))
+	if err != nil {
+		return err
+	}
+	for _, coverage := range coverages.list() {
+		err = coverageSaver.Add(coverage)
+		if err != nil {
+			return err
+		}
+	}
+	return divider.Close()
+}
+
+// codeOwnersFileAt returns the version of the CODEOWNERS file in effect at the time, files must be sorted by committed date
+func codeOwnersFileAt(files []code.CodeOwnersFile, t time.Time) *code.CodeOwnersFile {
+	var result *code.CodeOwnersFile
+	for i := range files {
+		if files[i].CommittedDate.After(t) {
+			break
+		}
+		result = &files[i]
+	}
+	return result
+}
+
+// ownerResolver maps the owners of CODEOWNERS to the reviewers and teams of DevLake.
+// `@org/team` owners match the teams by their name or alias, `@login` and email owners match the accounts.
+type ownerResolver struct {
+	teamsBySlug     map[string]string
+	teamsByAccount  map[string]map[string]bool
+	teamsByUserName map[string]string
+	teamsByEmail    map[string]string
+}
+
+func teamSlug(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+}
+
+func newOwnerResolver(teams []crossdomain.Team, accountTeams []accountTeam) *ownerResolver {
+	r := &ownerResolver{
+		teamsBySlug:     make(map[string]string),
+		teamsByAccount:  make(map[string]map[string]bool),
+		teamsByUserName: make(map[string]string),
+		teamsByEmail:    make(map[string]string),
+	}
+	for _, team := range teams {
+		for _, name := range []string{team.Name, team.Alias} {
+			if slug := teamSlug(name); slug != "" {
+				if _, ok := r.teamsBySlug[slug]; !ok {
+					r.teamsBySlug[slug] = team.Id
+				}
+			}
+		}
+	}
+	for _, at := range accountTeams {
+		if r.teamsByAccount[at.AccountId] == nil {
+			r.teamsByAccount[at.AccountId] = make(map[string]bool)
+		}
+		r.teamsByAccount[at.AccountId][at.TeamId] = true
+		if userName := strings.ToLower(at.UserName); userName != "" {
+			if _, ok := r.teamsByUserName[userName]; !ok {
+				r.teamsByUserName[userName] = at.TeamId
+			}
+		}
+		if email := strings.ToLower(at.Email); email != "" {
+			if _, ok := r.teamsByEmail[email]; !ok {
+				r.teamsByEmail[email] = at.TeamId
+			}
+		}
+	}
+	return r
+}
+
+// teamOf returns the DevLake team of the owner, the first one when a user belongs to several teams
+func (r *ownerResolver) teamOf(owner string) string {
+	login := strings.ToLower(strings.TrimPrefix(owner, "@"))
+	if i := strings.Index(login, "/"); i >= 0 {
+		return r.teamsBySlug[login[i+1:]]
+	}
+	if strings.Contains(login, "@") {
+		return r.teamsByEmail[login]
+	}
+	return r.teamsByUserName[login]
+}
+
+// reviewerOf returns the id of the reviewer acting as the owner, empty when the owner didn't review
+func (r *ownerResolver) reviewerOf(owner string, reviewers []prReviewer) string {
+	login := strings.ToLower(strings.TrimPrefix(owner, "@"))
+	for _, reviewer := range reviewers {
+		switch {
+		case strings.Contains(login, "/"):
+			if teamId := r.teamOf(owner); teamId != "" && r.teamsByAccount[reviewer.ReviewerId][teamId] {
+				return reviewer.ReviewerId
+			}
+		case strings.Contains(login, "@"):
+			if strings.EqualFold(reviewer.Email, login) {
+				return reviewer.ReviewerId
+			}
+		default:
+			if strings.EqualFold(reviewer.UserName, login) {
+				return reviewer.ReviewerId
+			}
+		}
+	}
+	return ""
+}
+
+// evaluateOwnerReviews returns the owners of the changed files and whether they reviewed the pull request,
+// the pull request is reviewed when an owner of every owner set of every changed file reviewed it
+func evaluateOwnerReviews(
+	repoId, pullRequestId string,
+	rules []*codeOwnersRule,
+	files []string,
+	reviewers []prReviewer,
+	resolver *ownerResolver,
+) ([]*code.PullRequestOwnerReview, bool) {
+	ownerReviews := make(map[string]*code.PullRequestOwnerReview)
+	var owners []string
+	reviewed := true
+	for _, file := range files {
+		for _, ownerSet := range ownersOf(rules, file) {
+			setReviewed := false
+			for _, owner := range ownerSet {
+				ownerReview := ownerReviews[owner]
+				if ownerReview == nil {
+					ownerReview = &code.PullRequestOwnerReview{
+						PullRequestId: pullRequestId,
+						Owner:         owner,
+						RepoId:        repoId,
+						TeamId:        resolver.teamOf(owner),
+						ReviewerId:    resolver.reviewerOf(owner, reviewers),
+					}
+					ownerReview.Reviewed = ownerReview.ReviewerId != ""
+					ownerReviews[owner] = ownerReview
+					owners = append(owners, owner)
+				}
+				ownerReview.OwnedFileCount++
+				setReviewed = setReviewed || ownerReview.Reviewed
+			}
+			reviewed = reviewed && setReviewed
+		}
+	}
+	result := make([]*code.PullRequestOwnerReview, 0, len(owners))
+	for _, owner := range owners {
+		result = append(result, ownerReviews[owner])
+	}
+	return result, reviewed
+}
+
+// ownerReviewCoverages counts the pull requests changing owned files of the repo and of every team,
+// a team reviewed a pull request when any of its owners did
+type ownerReviewCoverages struct {
+	repoId    string
+	coverages map[string]*code.OwnerReviewCoverage
+}
+
+func newOwnerReviewCoverages(repoId string) *ownerReviewCoverages {
+	return &ownerReviewCoverages{
+		repoId:    repoId,
+		coverages: make(map[string]*code.OwnerReviewCoverage),
+	}
+}
+
+func (c *ownerReviewCoverages) count(teamId string, reviewed bool) {
+	coverage := c.coverages[teamId]
+	if coverage == nil {
+		coverage = &code.OwnerReviewCoverage{RepoId: c.repoId, TeamId: teamId}
+		c.coverages[teamId] = coverage
+	}
+	coverage.PrCount++
+	if reviewed {
+		coverage.ReviewedPrCount++
+	}
+	coverage.Coverage = float64(coverage.ReviewedPrCount) / float64(coverage.PrCount)
+}
+
+func (c *ownerReviewCoverages) add(ownerReviews []*code.PullRequestOwnerReview, reviewed bool) {
+	c.count("", reviewed)
+	teamReviewed := make(map[string]bool)
+	for _, ownerReview := range ownerReviews {
+		if ownerReview.TeamId != "" {
+			teamReviewed[ownerReview.TeamId] = teamReviewed[ownerReview.TeamId] || ownerReview.Reviewed
+		}
+	}
+	for teamId, reviewed := range teamReviewed {
+		c.count(teamId, reviewed)
+	}
+}
+
+func (c *ownerReviewCoverages) list() []*code.OwnerReviewCoverage {
+	teamIds := make([]string, 0, len(c.coverages))
+	for teamId := range c.coverages {
+		teamIds = append(teamIds, teamId)
+	}
+	sort.Strings(teamIds)
+	result := make([]*code.OwnerReviewCoverage, 0, len(teamIds))
+	for _, teamId := range teamIds {
+		result = append(result, c.coverages[teamId])
+	}
+	return result
+}
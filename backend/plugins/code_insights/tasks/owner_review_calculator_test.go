@@ -0,0 +1,115 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileCodeOwnersPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*", "a/b/c.go", true},
+		{"*.js", "web/app.js", true},
+		{"*.js", "web/app.jsx", false},
+		{"docs/", "docs/a.md", true},
+		{"docs/", "web/docs/a.md", true},
+		{"docs/", "docs", false},
+		{"/docs/", "web/docs/a.md", false},
+		{"docs/*", "docs/a.md", true},
+		{"docs/*", "docs/sub/a.md", false},
+		{"apps/", "apps/x/y.go", true},
+		{"/build/logs/", "build/logs/a.log", true},
+		{"**/logs", "a/b/logs/x.log", true},
+		{"**/logs", "logs/x.log", true},
+		{"src/**/test", "src/a/b/test/x.go", true},
+		{"Makefile", "sub/Makefile", true},
+		{"/Makefile", "sub/Makefile", false},
+		{"file?.go", "file1.go", true},
+	}
+	for _, c := range cases {
+		re, err := compileCodeOwnersPattern(c.pattern)
+		assert.Nil(t, err)
+		assert.Equal(t, c.match, re.MatchString(c.path), "%s %s", c.pattern, c.path)
+	}
+}
+
+func mustRule(t *testing.T, section, pattern string, owners ...string) *codeOwnersRule {
+	re, err := compileCodeOwnersPattern(pattern)
+	assert.Nil(t, err)
+	return &codeOwnersRule{section: section, pattern: re, owners: owners}
+}
+
+func TestEvaluateOwnerReviews(t *testing.T) {
+	rules := []*codeOwnersRule{
+		mustRule(t, "", "*", "@org/core"),
+		mustRule(t, "", "/docs/", "@alice", "docs@example.com"),
+		mustRule(t, "", "/vendor/"),
+		mustRule(t, "Security", "auth/", "@bob"),
+	}
+	resolver := newOwnerResolver(
+		[]crossdomain.Team{{DomainEntity: domainlayer.DomainEntity{Id: "t1"}, Name: "Core"}},
+		[]accountTeam{
+			{AccountId: "github:1", UserName: "carol", TeamId: "t1"},
+			{AccountId: "github:2", UserName: "alice", Email: "alice@example.com", TeamId: "t2"},
+		},
+	)
+	assert.Equal(t, [][]string{{"@org/core"}, {"@bob"}}, ownersOf(rules, "auth/login.go"))
+	assert.Empty(t, ownersOf(rules, "vendor/lib.go"))
+
+	reviews, reviewed := evaluateOwnerReviews("repo", "pr1", rules,
+		[]string{"main.go", "docs/a.md", "vendor/lib.go"},
+		[]prReviewer{{ReviewerId: "github:1", UserName: "carol"}},
+		resolver,
+	)
+	assert.False(t, reviewed)
+	assert.Len(t, reviews, 3)
+	assert.Equal(t, "@org/core", reviews[0].Owner)
+	assert.Equal(t, "t1", reviews[0].TeamId)
+	assert.True(t, reviews[0].Reviewed)
+	assert.Equal(t, "github:1", reviews[0].ReviewerId)
+	assert.Equal(t, "@alice", reviews[1].Owner)
+	assert.Equal(t, "t2", reviews[1].TeamId)
+	assert.False(t, reviews[1].Reviewed)
+	assert.Equal(t, 1, reviews[1].OwnedFileCount)
+
+	_, reviewed = evaluateOwnerReviews("repo", "pr2", rules,
+		[]string{"main.go", "docs/a.md"},
+		[]prReviewer{{ReviewerId: "github:1", UserName: "carol"}, {ReviewerId: "github:3", Email: "DOCS@example.com"}},
+		resolver,
+	)
+	assert.True(t, reviewed)
+
+	coverages := newOwnerReviewCoverages("repo")
+	coverages.add(reviews, false)
+	list := coverages.list()
+	assert.Len(t, list, 3)
+	assert.Equal(t, "", list[0].TeamId)
+	assert.Equal(t, 0.0, list[0].Coverage)
+	assert.Equal(t, "t1", list[1].TeamId)
+	assert.Equal(t, 1.0, list[1].Coverage)
+	assert.Equal(t, "t2", list[2].TeamId)
+	assert.Equal(t, 0, list[2].ReviewedPrCount)
+}
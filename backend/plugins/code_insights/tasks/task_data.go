@@ -0,0 +1,77 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+const (
+	defaultChurnDays          = 90
+	defaultBusFactorThreshold = 0.5
+	defaultMaxDirectoryDepth  = 2
+	// maxPathLength is the length of the path columns, longer paths can't be used as keys
+	maxPathLength = 255
+)
+
+type CodeInsightsOptions struct {
+	ProjectName string `json:"projectName"`
+	// ChurnDays is the window of the churn used by hotspots, 90 days by default
+	ChurnDays int `json:"churnDays"`
+	// BusFactorThreshold is the share of the lines the bus factor authors own together, 0.5 by default
+	BusFactorThreshold float64 `json:"busFactorThreshold"`
+	// MaxDirectoryDepth limits the depth of the directories the ownership is calculated for, 2 by default
+	MaxDirectoryDepth int `json:"maxDirectoryDepth"`
+}
+
+type CodeInsightsTaskData struct {
+	Options *CodeInsightsOptions
+}
+
+func DecodeAndValidateTaskOptions(options map[string]interface{}) (*CodeInsightsOptions, errors.Error) {
+	var op CodeInsightsOptions
+	err := helper.Decode(options, &op, nil)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error decoding code insights task options")
+	}
+	if op.ProjectName == "" {
+		return nil, errors.BadInput.New("projectName is required")
+	}
+	if op.ChurnDays <= 0 {
+		op.ChurnDays = defaultChurnDays
+	}
+	if op.BusFactorThreshold <= 0 || op.BusFactorThreshold >= 1 {
+		op.BusFactorThreshold = defaultBusFactorThreshold
+	}
+	if op.MaxDirectoryDepth <= 0 {
+		op.MaxDirectoryDepth = defaultMaxDirectoryDepth
+	}
+	return &op, nil
+}
+
+// getProjectRepoIds returns the ids of the repos mapped to the project
+func getProjectRepoIds(db dal.Dal, projectName string) ([]string, errors.Error) {
+	var repoIds []string
+	err := db.Pluck("pm.row_id", &repoIds,
+		dal.From("project_mapping pm"),
+		dal.Where("pm.project_name = ? AND ? = 'repos'", projectName, dal.ClauseColumn{Table: "pm", Name: "table"}),
+	)
+	return repoIds, err
+}
@@ -0,0 +1,137 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/customize/service"
+)
+
+// ImportDeployments accepts a CSV file, parses and saves it to the database
+// @Summary      Upload deployments.csv file
+// @Description  Upload deployments.csv file, one row per deployed commit. 3 tables(cicd_scopes, cicd_deployments, cicd_deployment_commits) would be affected.
+// @Description  Nothing is saved if any row is invalid, the invalid rows are listed in the response.
+// @Tags 		 plugins/customize
+// @Accept       multipart/form-data
+// @Param        cicdScopeId formData string true "the ID of the cicd scope"
+// @Param        cicdScopeName formData string false "the name of the cicd scope"
+// @Param        projectName formData string false "the project the cicd scope would be mapped to"
+// @Param        incremental formData bool false "whether to import incrementally"
+// @Param        file formData file true "select file to upload"
+// @Produce      json
+// @Success      200  {object} service.CsvImportResult
+// @Failure 400  {object} service.CsvImportResult "Invalid rows"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/customize/csvfiles/deployments.csv [post]
+func (h *Handlers) ImportDeployments(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	file, err := h.extractFile(input)
+	if err != nil {
+		return nil, err
+	}
+	// nolint
+	defer file.Close()
+	cicdScopeId := strings.TrimSpace(input.Request.FormValue("cicdScopeId"))
+	if cicdScopeId == "" {
+		return nil, errors.BadInput.New("empty cicdScopeId")
+	}
+	cicdScopeName := strings.TrimSpace(input.Request.FormValue("cicdScopeName"))
+	projectName := strings.TrimSpace(input.Request.FormValue("projectName"))
+	incremental := input.Request.FormValue("incremental") == "true"
+	return csvImportOutput(h.svc.ImportDeployments(cicdScopeId, cicdScopeName, projectName, file, incremental))
+}
+
+// ImportIncidents accepts a CSV file, parses and saves it to the database
+// @Summary      Upload incidents.csv file
+// @Description  Upload incidents.csv file. 3 tables(boards, incidents, accounts) would be affected.
+// @Description  Nothing is saved if any row is invalid, the invalid rows are listed in the response.
+// @Tags 		 plugins/customize
+// @Accept       multipart/form-data
+// @Param        boardId formData string true "the ID of the board"
+// @Param        boardName formData string false "the name of the board"
+// @Param        projectName formData string false "the project the board would be mapped to"
+// @Param        incremental formData bool false "whether to import incrementally"
+// @Param        file formData file true "select file to upload"
+// @Produce      json
+// @Success      200  {object} service.CsvImportResult
+// @Failure 400  {object} service.CsvImportResult "Invalid rows"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/customize/csvfiles/incidents.csv [post]
+func (h *Handlers) ImportIncidents(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	file, err := h.extractFile(input)
+	if err != nil {
+		return nil, err
+	}
+	// nolint
+	defer file.Close()
+	boardId := strings.TrimSpace(input.Request.FormValue("boardId"))
+	if boardId == "" {
+		return nil, errors.BadInput.New("empty boardId")
+	}
+	boardName := strings.TrimSpace(input.Request.FormValue("boardName"))
+	projectName := strings.TrimSpace(input.Request.FormValue("projectName"))
+	incremental := input.Request.FormValue("incremental") == "true"
+	return csvImportOutput(h.svc.ImportIncidents(boardId, boardName, projectName, file, incremental))
+}
+
+// ImportCicdPipelines accepts a CSV file, parses and saves it to the database
+// @Summary      Upload cicd_pipelines.csv file
+// @Description  Upload cicd_pipelines.csv file, rows sharing the same id describe one pipeline building several commits.
+// @Description  3 tables(cicd_scopes, cicd_pipelines, cicd_pipeline_commits) would be affected.
+// @Description  Nothing is saved if any row is invalid, the invalid rows are listed in the response.
+// @Tags 		 plugins/customize
+// @Accept       multipart/form-data
+// @Param        cicdScopeId formData string true "the ID of the cicd scope"
+// @Param        cicdScopeName formData string false "the name of the cicd scope"
+// @Param        projectName formData string false "the project the cicd scope would be mapped to"
+// @Param        incremental formData bool false "whether to import incrementally"
+// @Param        file formData file true "select file to upload"
+// @Produce      json
+// @Success      200  {object} service.CsvImportResult
+// @Failure 400  {object} service.CsvImportResult "Invalid rows"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/customize/csvfiles/cicd_pipelines.csv [post]
+func (h *Handlers) ImportCicdPipelines(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	file, err := h.extractFile(input)
+	if err != nil {
+		return nil, err
+	}
+	// nolint
+	defer file.Close()
+	cicdScopeId := strings.TrimSpace(input.Request.FormValue("cicdScopeId"))
+	if cicdScopeId == "" {
+		return nil, errors.BadInput.New("empty cicdScopeId")
+	}
+	cicdScopeName := strings.TrimSpace(input.Request.FormValue("cicdScopeName"))
+	projectName := strings.TrimSpace(input.Request.FormValue("projectName"))
+	incremental := input.Request.FormValue("incremental") == "true"
+	return csvImportOutput(h.svc.ImportCicdPipelines(cicdScopeId, cicdScopeName, projectName, file, incremental))
+}
+
+func csvImportOutput(result *service.CsvImportResult, err errors.Error) (*plugin.ApiResourceOutput, errors.Error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return &plugin.ApiResourceOutput{Body: result, Status: http.StatusBadRequest}, nil
+	}
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
@@ -40,6 +40,14 @@ type Field struct {
 	DisplayName string `json:"displayName" example:"department"`
 	DataType    string `json:"dataType" example:"varchar(255)"`
 	Description string `json:"description" example:"more details about the column"`
+	Expression  string `json:"expression" example:"labels contains 'customer' && type == 'BUG'"`
+}
+
+type previewRequest struct {
+	Expression string                   `json:"expression" example:"days_between(created_date, resolution_date)"`
+	DataType   string                   `json:"dataType" example:"float"`
+	Rows       []map[string]interface{} `json:"rows"`
+	Limit      int                      `json:"limit" example:"10"`
 }
 
 func (f *Field) toDBModel(table string) (*models.CustomizedField, errors.Error) {
@@ -59,6 +67,7 @@ func (f *Field) toDBModel(table string) (*models.CustomizedField, errors.Error)
 		DisplayName: f.DisplayName,
 		DataType:    t,
 		Description: f.Description,
+		Expression:  strings.TrimSpace(f.Expression),
 	}, nil
 }
 
@@ -69,6 +78,7 @@ func fromCustomizedField(cf models.CustomizedField) fieldResponse {
 			DisplayName: cf.DisplayName,
 			DataType:    cf.DataType.String(),
 			Description: cf.Description,
+			Expression:  cf.Expression,
 		},
 		IsCustomizedField: strings.HasPrefix(cf.ColumnName, "x_"),
 	}
@@ -131,6 +141,40 @@ func (h *Handlers) CreateFields(input *plugin.ApiResourceInput) (*plugin.ApiReso
 	return &plugin.ApiResourceOutput{Body: fieldResponse{*fld, true}, Status: http.StatusOK}, nil
 }
 
+// PreviewField evaluates the expression of a derived field against sample rows
+// @Summary evaluate the expression of a derived field against sample rows
+// @Description evaluate the expression against the rows in the request body, or the first `limit` rows of the table if no row is given
+// @Tags plugins/customize
+// @Param table path string true "the table name"
+// @Param request body previewRequest true "request body"
+// @Success 200  {object} []service.PreviewResult "Success"
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router /plugins/customize/{table}/fields/preview [POST]
+func (h *Handlers) PreviewField(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	table := input.Params["table"]
+	req := &previewRequest{}
+	err := helper.Decode(input.Body, req, nil)
+	if err != nil {
+		return &plugin.ApiResourceOutput{Status: http.StatusBadRequest}, err
+	}
+	if strings.TrimSpace(req.Expression) == "" {
+		return nil, errors.BadInput.New("the expression is empty")
+	}
+	dataType := dal.Varchar
+	if req.DataType != "" {
+		var ok bool
+		if dataType, ok = dal.ToColumnType(req.DataType); !ok {
+			return nil, errors.BadInput.New(fmt.Sprintf("the columnType:%s is unsupported", req.DataType))
+		}
+	}
+	results, err := h.svc.PreviewExpression(table, req.Expression, dataType, req.Rows, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: results, Status: http.StatusOK}, nil
+}
+
 // DeleteField delete a customized fields
 // @Summary return all customized fields
 // @Description return all customized fields
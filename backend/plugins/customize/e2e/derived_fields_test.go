@@ -0,0 +1,105 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/helpers/e2ehelper"
+	"github.com/apache/incubator-devlake/plugins/customize/impl"
+	"github.com/apache/incubator-devlake/plugins/customize/models"
+	"github.com/apache/incubator-devlake/plugins/customize/service"
+	"github.com/apache/incubator-devlake/plugins/customize/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeDerivedFieldsDataFlow(t *testing.T) {
+	var plugin impl.Customize
+	dataflowTester := e2ehelper.NewDataFlowTester(t, "customize", plugin)
+
+	dataflowTester.ImportCsvIntoTabler("./raw_tables/issues.csv", &ticket.Issue{})
+	dataflowTester.ImportCsvIntoTabler("./raw_tables/board_issues.csv", &ticket.BoardIssue{})
+	dataflowTester.FlushTabler(&crossdomain.ProjectMapping{})
+	dataflowTester.FlushTabler(&models.CustomizedField{})
+	err := dataflowTester.Dal.Create(&crossdomain.ProjectMapping{ProjectName: "project1", Table: "boards", RowId: "csv-board2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := service.NewService(dataflowTester.Dal)
+	err = svc.CreateField(&models.CustomizedField{
+		TbName:      "issues",
+		ColumnName:  "x_kind",
+		DisplayName: "kind",
+		DataType:    "varchar(255)",
+		Expression:  "type",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	getDerivedFields := func(column string) map[string]interface{} {
+		var rows []map[string]interface{}
+		err := dataflowTester.Dal.All(&rows, dal.Select("id, "+column), dal.From(&ticket.Issue{}), dal.Where(column+" IS NOT NULL"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := make(map[string]interface{})
+		for _, row := range rows {
+			values[row["id"].(string)] = row[column]
+		}
+		return values
+	}
+
+	// only the issues of the boards in the project are computed
+	dataflowTester.Subtask(tasks.ComputeDerivedFieldsMeta, &tasks.TaskData{Options: &tasks.Options{ProjectName: "project1"}})
+	assert.Equal(t, map[string]interface{}{
+		"jira:JiraIssue:1:10063": "STORY",
+		"jira:JiraIssue:1:10064": "STORY",
+		"jira:JiraIssue:1:10065": "STORY",
+		"jira:JiraIssue:1:10066": "STORY",
+	}, getDerivedFields("x_kind"))
+
+	// the issues whose title is not a number are skipped
+	err = svc.CreateField(&models.CustomizedField{
+		TbName:      "issues",
+		ColumnName:  "x_number",
+		DisplayName: "number",
+		DataType:    "bigint",
+		Expression:  "title",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dataflowTester.Dal.UpdateColumn(&ticket.Issue{}, "title", "3", dal.Where("id IN ?", []string{"jira:JiraIssue:1:10063", "jira:JiraIssue:1:10067"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataflowTester.Subtask(tasks.ComputeDerivedFieldsMeta, &tasks.TaskData{Options: &tasks.Options{BoardId: "csv-board2", DerivedFieldTables: []string{"issues"}}})
+	assert.Len(t, getDerivedFields("x_number"), 1)
+	assert.EqualValues(t, 3, getDerivedFields("x_number")["jira:JiraIssue:1:10063"])
+
+	// the sampled rows of the preview carry their primary keys
+	results, err := svc.PreviewExpression("issues", "title", dal.Int, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, results, 1)
+	assert.NotEmpty(t, results[0].Row["id"])
+}
@@ -0,0 +1,69 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/helpers/e2ehelper"
+	"github.com/apache/incubator-devlake/plugins/customize/impl"
+	"github.com/apache/incubator-devlake/plugins/customize/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportIncidentDataFlow(t *testing.T) {
+	var plugin impl.Customize
+	dataflowTester := e2ehelper.NewDataFlowTester(t, "customize", plugin)
+
+	dataflowTester.FlushTabler(&ticket.Incident{})
+	dataflowTester.FlushTabler(&ticket.Board{})
+	dataflowTester.FlushTabler(&crossdomain.Account{})
+	svc := service.NewService(dataflowTester.Dal)
+	importIncidents := func(boardId, csv string, incremental bool) {
+		result, err := svc.ImportIncidents(boardId, boardId, "", io.NopCloser(strings.NewReader(csv)), incremental)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, result.Errors)
+	}
+	countIncidents := func(boardId string) int64 {
+		count, err := dataflowTester.Dal.Count(dal.From(&ticket.Incident{}), dal.Where("scope_id = ?", boardId))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	header := "id,title,created_date,assignee_name\n"
+	importIncidents("board1", header+"1,outage,2024-01-01T00:00:00Z,alice\n2,slow,2024-01-02T00:00:00Z,bob\n", false)
+	importIncidents("board2", header+"b2-1,outage,2024-01-01T00:00:00Z,alice\n", false)
+	assert.Equal(t, int64(2), countIncidents("board1"))
+
+	// the incidents of the board are replaced, those of other boards are kept
+	importIncidents("board1", header+"3,down,2024-01-03T00:00:00Z,alice\n", false)
+	assert.Equal(t, int64(1), countIncidents("board1"))
+	assert.Equal(t, int64(1), countIncidents("board2"))
+
+	importIncidents("board1", header+"4,error,2024-01-04T00:00:00Z,bob\n", true)
+	assert.Equal(t, int64(2), countIncidents("board1"))
+}
@@ -0,0 +1,95 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// virtualColumns could be referenced by the expressions of a table although they are stored in other tables
+var virtualColumns = map[string]map[string]func(d dal.Dal, row map[string]interface{}) (interface{}, errors.Error){
+	"issues": {
+		"labels": func(d dal.Dal, row map[string]interface{}) (interface{}, errors.Error) {
+			var labels []string
+			err := d.Pluck("label_name", &labels, dal.From("issue_labels"), dal.Where("issue_id = ?", row["id"]))
+			return Normalize(labels), err
+		},
+	},
+}
+
+// IsVirtualColumn tells whether the column of the table is a virtual one
+func IsVirtualColumn(table, column string) bool {
+	_, ok := virtualColumns[table][column]
+	return ok
+}
+
+// FillVirtualColumns loads the virtual columns referenced by identifiers into the row
+func FillVirtualColumns(d dal.Dal, table string, identifiers []string, row map[string]interface{}) errors.Error {
+	for _, name := range identifiers {
+		loader, ok := virtualColumns[table][name]
+		if !ok {
+			continue
+		}
+		value, err := loader(d, row)
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("failed to load %s of %s", name, table))
+		}
+		row[name] = value
+	}
+	return nil
+}
+
+// Convert converts the result of an expression to the type of the column it would be saved to
+func Convert(value interface{}, dataType dal.ColumnType) (interface{}, errors.Error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch dataType {
+	case dal.Int:
+		number, ok := toNumber(value)
+		if !ok {
+			return nil, errors.BadInput.New(fmt.Sprintf("%v is not a number", value))
+		}
+		return int64(math.Round(number)), nil
+	case dal.Float:
+		number, ok := toNumber(value)
+		if !ok {
+			return nil, errors.BadInput.New(fmt.Sprintf("%v is not a number", value))
+		}
+		return number, nil
+	case dal.Time:
+		t, ok := toTime(value)
+		if !ok {
+			return nil, errors.BadInput.New(fmt.Sprintf("%v is not a date", value))
+		}
+		return t, nil
+	}
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			items = append(items, toString(item))
+		}
+		return strings.Join(items, ","), nil
+	}
+	return toString(value), nil
+}
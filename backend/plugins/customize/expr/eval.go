@@ -0,0 +1,575 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+type node interface {
+	eval(row map[string]interface{}) (interface{}, errors.Error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, errors.Error) {
+	return n.value, nil
+}
+
+type identifierNode struct {
+	name string
+}
+
+func (n *identifierNode) eval(row map[string]interface{}) (interface{}, errors.Error) {
+	value, ok := row[n.name]
+	if !ok {
+		return nil, errors.BadInput.New(fmt.Sprintf("unknown column `%s`", n.name))
+	}
+	return Normalize(value), nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(row map[string]interface{}) (interface{}, errors.Error) {
+	list := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(row map[string]interface{}) (interface{}, errors.Error) {
+	value, err := n.operand.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return !truthy(value), nil
+	}
+	if value == nil {
+		return nil, nil
+	}
+	number, ok := toNumber(value)
+	if !ok {
+		return nil, errors.BadInput.New(fmt.Sprintf("cannot negate %v", value))
+	}
+	return -number, nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(row map[string]interface{}) (interface{}, errors.Error) {
+	left, err := n.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	// short circuit
+	if n.op == "&&" && !truthy(left) {
+		return false, nil
+	}
+	if n.op == "||" && truthy(left) {
+		return true, nil
+	}
+	right, err := n.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(right), nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(row map[string]interface{}) (interface{}, errors.Error) {
+	left, err := n.left.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "contains":
+		return contains(left, right), nil
+	case "in":
+		return contains(right, left), nil
+	case "startsWith", "endsWith":
+		if left == nil || right == nil {
+			return false, nil
+		}
+		if n.op == "startsWith" {
+			return strings.HasPrefix(toString(left), toString(right)), nil
+		}
+		return strings.HasSuffix(toString(left), toString(right)), nil
+	}
+	return arithmetic(n.op, left, right)
+}
+
+type matchesNode struct {
+	left, right node
+	re          *regexp.Regexp
+}
+
+func newMatchesNode(left, right node) (node, errors.Error) {
+	n := &matchesNode{left: left, right: right}
+	if literal, ok := right.(*literalNode); ok {
+		pattern, isString := literal.value.(string)
+		if !isString {
+			return nil, errors.BadInput.New("the right side of `matches` must be a string")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.BadInput.New(fmt.Sprintf("invalid regular expression `%s`: %s", pattern, err.Error()))
+		}
+		n.re = re
+	}
+	return n, nil
+}
+
+func (n *matchesNode) eval(row map[string]interface{}) (interface{}, errors.Error) {
+	left, err := n.left.eval(row)
+	if err != nil || left == nil {
+		return false, err
+	}
+	re, err := evalRegexp(n.re, n.right, row)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString(toString(left)), nil
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+	re   *regexp.Regexp
+}
+
+func (n *callNode) eval(row map[string]interface{}) (interface{}, errors.Error) {
+	if n.name == "regex_extract" {
+		return n.regexExtract(row)
+	}
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := arg.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	return n.fn.call(args)
+}
+
+// regexExtract returns the first capture group, or the given one, of the first match
+func (n *callNode) regexExtract(row map[string]interface{}) (interface{}, errors.Error) {
+	value, err := n.args[0].eval(row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	re, err := evalRegexp(n.re, n.args[1], row)
+	if err != nil {
+		return nil, err
+	}
+	group := 0
+	if re.NumSubexp() > 0 {
+		group = 1
+	}
+	if len(n.args) > 2 {
+		groupValue, err := n.args[2].eval(row)
+		if err != nil {
+			return nil, err
+		}
+		number, ok := toNumber(groupValue)
+		if !ok || number < 0 || int(number) > re.NumSubexp() {
+			return nil, errors.BadInput.New(fmt.Sprintf("invalid group %v for `%s`", groupValue, re.String()))
+		}
+		group = int(number)
+	}
+	match := re.FindStringSubmatch(toString(value))
+	if match == nil {
+		return nil, nil
+	}
+	return match[group], nil
+}
+
+func evalRegexp(compiled *regexp.Regexp, pattern node, row map[string]interface{}) (*regexp.Regexp, errors.Error) {
+	if compiled != nil {
+		return compiled, nil
+	}
+	value, err := pattern.eval(row)
+	if err != nil {
+		return nil, err
+	}
+	re, e := regexp.Compile(toString(value))
+	if e != nil {
+		return nil, errors.BadInput.New(fmt.Sprintf("invalid regular expression `%v`: %s", value, e.Error()))
+	}
+	return re, nil
+}
+
+type function struct {
+	minArgs, maxArgs int
+	call             func(args []interface{}) (interface{}, errors.Error)
+}
+
+var functions = map[string]function{
+	"lower": {1, 1, func(args []interface{}) (interface{}, errors.Error) {
+		return mapString(args[0], strings.ToLower), nil
+	}},
+	"upper": {1, 1, func(args []interface{}) (interface{}, errors.Error) {
+		return mapString(args[0], strings.ToUpper), nil
+	}},
+	"trim": {1, 1, func(args []interface{}) (interface{}, errors.Error) {
+		return mapString(args[0], strings.TrimSpace), nil
+	}},
+	"len": {1, 1, func(args []interface{}) (interface{}, errors.Error) {
+		switch v := args[0].(type) {
+		case nil:
+			return float64(0), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		}
+		return float64(len([]rune(toString(args[0])))), nil
+	}},
+	"string": {1, 1, func(args []interface{}) (interface{}, errors.Error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		return toString(args[0]), nil
+	}},
+	"number": {1, 1, func(args []interface{}) (interface{}, errors.Error) {
+		if number, ok := toNumber(args[0]); ok {
+			return number, nil
+		}
+		return nil, nil
+	}},
+	"round": {1, 2, func(args []interface{}) (interface{}, errors.Error) {
+		number, ok := toNumber(args[0])
+		if !ok {
+			return nil, nil
+		}
+		digits := float64(0)
+		if len(args) > 1 {
+			digits, _ = toNumber(args[1])
+		}
+		scale := math.Pow(10, digits)
+		return math.Round(number*scale) / scale, nil
+	}},
+	"coalesce": {1, -1, func(args []interface{}) (interface{}, errors.Error) {
+		for _, arg := range args {
+			if arg != nil {
+				return arg, nil
+			}
+		}
+		return nil, nil
+	}},
+	"if": {3, 3, func(args []interface{}) (interface{}, errors.Error) {
+		if truthy(args[0]) {
+			return args[1], nil
+		}
+		return args[2], nil
+	}},
+	"now": {0, 0, func([]interface{}) (interface{}, errors.Error) {
+		return time.Now(), nil
+	}},
+	"date": {1, 1, func(args []interface{}) (interface{}, errors.Error) {
+		if t, ok := toTime(args[0]); ok {
+			return t, nil
+		}
+		return nil, nil
+	}},
+	"days_between":    timeDiff(24 * time.Hour),
+	"hours_between":   timeDiff(time.Hour),
+	"minutes_between": timeDiff(time.Minute),
+	"regex_extract": {2, 3, func([]interface{}) (interface{}, errors.Error) {
+		// evaluated by callNode.regexExtract to reuse the pattern compiled beforehand
+		return nil, nil
+	}},
+}
+
+// timeDiff returns a function computing `to - from` in the unit, nil if any of them isn't a date
+func timeDiff(unit time.Duration) function {
+	return function{2, 2, func(args []interface{}) (interface{}, errors.Error) {
+		from, ok := toTime(args[0])
+		if !ok {
+			return nil, nil
+		}
+		to, ok := toTime(args[1])
+		if !ok {
+			return nil, nil
+		}
+		return float64(to.Sub(from)) / float64(unit), nil
+	}}
+}
+
+// Normalize converts the values fetched from the database to the types handled by expressions
+func Normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, float64, string, time.Time, []interface{}:
+		return v
+	case []byte:
+		return string(v)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return Normalize(rv.Elem().Interface())
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := common.ConvertStringToTime(v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+func mapString(value interface{}, fn func(string) string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return fn(toString(value))
+}
+
+func equal(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	if l, ok := left.(float64); ok {
+		r, ok := toNumber(right)
+		return ok && l == r
+	}
+	if r, ok := right.(float64); ok {
+		l, ok := toNumber(left)
+		return ok && l == r
+	}
+	if l, ok := left.(time.Time); ok {
+		r, ok := toTime(right)
+		return ok && l.Equal(r)
+	}
+	if r, ok := right.(time.Time); ok {
+		l, ok := toTime(left)
+		return ok && l.Equal(r)
+	}
+	if l, ok := left.(bool); ok {
+		return l == truthy(right)
+	}
+	if r, ok := right.(bool); ok {
+		return r == truthy(left)
+	}
+	return toString(left) == toString(right)
+}
+
+func compare(op string, left, right interface{}) (interface{}, errors.Error) {
+	if left == nil || right == nil {
+		return false, nil
+	}
+	var sign int
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	_, lIsString := left.(string)
+	_, rIsString := right.(string)
+	lt, ltok := toTime(left)
+	rt, rtok := toTime(right)
+	switch {
+	case lok && rok && !(lIsString && rIsString):
+		sign = compareFloat(l, r)
+	case ltok && rtok:
+		sign = compareFloat(float64(lt.UnixNano()), float64(rt.UnixNano()))
+	case lIsString && rIsString:
+		sign = strings.Compare(left.(string), right.(string))
+	default:
+		return nil, errors.BadInput.New(fmt.Sprintf("cannot compare %v with %v", left, right))
+	}
+	switch op {
+	case "<":
+		return sign < 0, nil
+	case "<=":
+		return sign <= 0, nil
+	case ">":
+		return sign > 0, nil
+	}
+	return sign >= 0, nil
+}
+
+func compareFloat(l, r float64) int {
+	if l < r {
+		return -1
+	}
+	if l > r {
+		return 1
+	}
+	return 0
+}
+
+// contains checks if the list holds the item, or the text holds the substring
+func contains(collection, item interface{}) bool {
+	switch c := collection.(type) {
+	case nil:
+		return false
+	case []interface{}:
+		for _, element := range c {
+			if equal(element, item) {
+				return true
+			}
+		}
+		return false
+	}
+	if item == nil {
+		return false
+	}
+	return strings.Contains(toString(collection), toString(item))
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, errors.Error) {
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	_, lIsString := left.(string)
+	_, rIsString := right.(string)
+	if op == "+" && (lIsString || rIsString) {
+		return toString(left) + toString(right), nil
+	}
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	if !lok || !rok {
+		return nil, errors.BadInput.New(fmt.Sprintf("cannot apply `%s` to %v and %v", op, left, right))
+	}
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, nil
+		}
+		return l / r, nil
+	}
+	if r == 0 {
+		return nil, nil
+	}
+	return math.Mod(l, r), nil
+}
@@ -0,0 +1,405 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// Expression is a compiled derived field rule, e.g. `labels contains 'customer' && type == 'BUG'`
+//
+// Supported syntax:
+//   - literals: 'text', "text", 1.5, true, false, null and lists like ['a', 'b']
+//   - columns of the row referenced by name
+//   - operators: || && ! == != < <= > >= + - * / % and contains, startsWith, endsWith, matches, in
+//   - functions: see `functions`
+type Expression struct {
+	source      string
+	root        node
+	identifiers []string
+}
+
+// Compile parses the source of an expression, unknown functions, invalid regular expressions
+// and syntax errors are reported here rather than on evaluation
+func Compile(source string) (*Expression, errors.Error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, identifiers: make(map[string]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.errorf("unexpected %s", p.peek())
+	}
+	identifiers := make([]string, 0, len(p.identifiers))
+	for name := range p.identifiers {
+		identifiers = append(identifiers, name)
+	}
+	sort.Strings(identifiers)
+	return &Expression{source: source, root: root, identifiers: identifiers}, nil
+}
+
+// Identifiers returns the columns referenced by the expression in alphabetical order
+func (e *Expression) Identifiers() []string {
+	return e.identifiers
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression against a row, the result is nil, bool, float64, string, time.Time or []interface{}
+func (e *Expression) Eval(row map[string]interface{}) (interface{}, errors.Error) {
+	return e.root.eval(row)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("`%s` at %d", t.text, t.pos)
+}
+
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ","}
+
+func tokenize(source string) ([]token, errors.Error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				sb.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, errors.BadInput.New(fmt.Sprintf("unterminated string at %d", i))
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[i : j+1]), value: sb.String(), pos: i})
+			i = j + 1
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			text := string(runes[i:j])
+			number, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, errors.BadInput.New(fmt.Sprintf("invalid number `%s` at %d", text, i))
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: number, pos: i})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[i:j]), pos: i})
+			i = j
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, errors.BadInput.New(fmt.Sprintf("unexpected character `%c` at %d", r, i))
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// keyword operators share the precedence of the comparisons
+var keywordOperators = map[string]bool{
+	"contains":   true,
+	"startsWith": true,
+	"endsWith":   true,
+	"matches":    true,
+	"in":         true,
+}
+
+type parser struct {
+	tokens      []token
+	pos         int
+	identifiers map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) errors.Error {
+	if !p.isOperator(op) {
+		return p.errorf("expected `%s` but got %s", op, p.peek())
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) errors.Error {
+	return errors.BadInput.New(fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseOr() (node, errors.Error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, errors.Error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&&") {
+		p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, errors.Error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	isKeyword := t.kind == tokenIdent && keywordOperators[t.text]
+	if !isKeyword && !p.isOperator("==", "!=", "<", "<=", ">", ">=") {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if t.text == "matches" {
+		return newMatchesNode(left, right)
+	}
+	return &binaryNode{op: t.text, left: left, right: right}, nil
+}
+
+func (p *parser) parseAdditive() (node, errors.Error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+", "-") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (node, errors.Error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*", "/", "%") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, errors.Error) {
+	if p.isOperator("!", "-") {
+		op := p.next().text
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, errors.Error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber, tokenString:
+		return &literalNode{value: t.value}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if p.isOperator("(") {
+			return p.parseCall(t)
+		}
+		if keywordOperators[t.text] {
+			return nil, p.errorf("unexpected %s", t)
+		}
+		p.identifiers[t.text] = true
+		return &identifierNode{name: t.text}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		}
+	}
+	return nil, p.errorf("unexpected %s", t)
+}
+
+func (p *parser) parseCall(name token) (node, errors.Error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, p.errorf("unknown function `%s` at %d", name.text, name.pos)
+	}
+	p.next()
+	args, err := p.parseList(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, p.errorf("wrong number of arguments for `%s` at %d", name.text, name.pos)
+	}
+	call := &callNode{name: name.text, fn: fn, args: args}
+	if name.text == "regex_extract" {
+		if literal, ok := args[1].(*literalNode); ok {
+			pattern, isString := literal.value.(string)
+			if !isString {
+				return nil, p.errorf("the pattern of `regex_extract` at %d must be a string", name.pos)
+			}
+			re, e := regexp.Compile(pattern)
+			if e != nil {
+				return nil, p.errorf("invalid regular expression `%s`: %s", pattern, e.Error())
+			}
+			call.re = re
+		}
+	}
+	return call, nil
+}
+
+func (p *parser) parseList(closing string) ([]node, errors.Error) {
+	var items []node
+	if p.isOperator(closing) {
+		p.next()
+		return items, nil
+	}
+	for {
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.isOperator(",") {
+			p.next()
+			continue
+		}
+		return items, p.expect(closing)
+	}
+}
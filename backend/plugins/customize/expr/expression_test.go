@@ -0,0 +1,129 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEval(t *testing.T) {
+	row := map[string]interface{}{
+		"type":            "BUG",
+		"title":           []byte("[PROJ-12] login fails"),
+		"labels":          []interface{}{"customer", "p1"},
+		"story_point":     int64(3),
+		"created_date":    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"resolution_date": "2024-01-03T12:00:00Z",
+		"assignee_name":   nil,
+	}
+	testCases := []struct {
+		expression string
+		expected   interface{}
+	}{
+		{"labels contains 'customer' && type == 'BUG'", true},
+		{"labels contains 'internal' || type != 'BUG'", false},
+		{"!(type in ['BUG', 'INCIDENT'])", false},
+		{"title contains 'login'", true},
+		{"title startsWith '[PROJ' && title endsWith 'fails'", true},
+		{"title matches '^\\\\[[A-Z]+-\\\\d+\\\\]'", true},
+		{"regex_extract(title, '\\\\[([A-Z]+)-(\\\\d+)\\\\]')", "PROJ"},
+		{"regex_extract(title, '\\\\[([A-Z]+)-(\\\\d+)\\\\]', 2)", "12"},
+		{"regex_extract(title, 'missing')", nil},
+		{"days_between(created_date, resolution_date)", 2.5},
+		{"hours_between(created_date, resolution_date) > 48", true},
+		{"days_between(created_date, assignee_name)", nil},
+		{"story_point * 2 + 1", float64(7)},
+		{"story_point >= 3 && story_point < 5", true},
+		{"-story_point % 2", float64(-1)},
+		{"story_point / 0", nil},
+		{"coalesce(assignee_name, 'unassigned')", "unassigned"},
+		{"if(story_point > 5, 'large', 'small')", "small"},
+		{"lower(type) + '-' + len(labels)", "bug-2"},
+		{"assignee_name == null", true},
+		{"assignee_name contains 'a'", false},
+		{"round(10 / 3, 2)", 3.33},
+		{"created_date < resolution_date", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			e, err := Compile(tc.expression)
+			if !assert.Nil(t, err) {
+				return
+			}
+			value, err := e.Eval(row)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expression := range []string{
+		"type ==",
+		"type == 'BUG",
+		"(type == 'BUG'",
+		"unknown_fn(type)",
+		"lower(type, title)",
+		"title matches '['",
+		"type # 'BUG'",
+		"contains 'a'",
+	} {
+		_, err := Compile(expression)
+		assert.NotNil(t, err, expression)
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	e, err := Compile("labels contains 'customer' && type == 'BUG' && days_between(created_date, now()) > 1 && type != 'X'")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"created_date", "labels", "type"}, e.Identifiers())
+}
+
+func TestEvalUnknownColumn(t *testing.T) {
+	e, err := Compile("priority == 'HIGH'")
+	assert.Nil(t, err)
+	_, err = e.Eval(map[string]interface{}{})
+	assert.NotNil(t, err)
+}
+
+func TestConvert(t *testing.T) {
+	value, err := Convert(true, dal.Varchar)
+	assert.Nil(t, err)
+	assert.Equal(t, "true", value)
+	value, err = Convert(true, dal.Int)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), value)
+	value, err = Convert(2.5, dal.Float)
+	assert.Nil(t, err)
+	assert.Equal(t, 2.5, value)
+	value, err = Convert("2024-01-03T12:00:00Z", dal.Time)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), value)
+	value, err = Convert([]interface{}{"a", "b"}, dal.Text)
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b", value)
+	_, err = Convert("abc", dal.Int)
+	assert.NotNil(t, err)
+	value, err = Convert(nil, dal.Int)
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+}
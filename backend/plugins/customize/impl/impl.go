@@ -56,6 +56,7 @@ func (p Customize) GetTablesInfo() []dal.Tabler {
 func (p Customize) SubTaskMetas() []plugin.SubTaskMeta {
 	return []plugin.SubTaskMeta{
 		tasks.ExtractCustomizedFieldsMeta,
+		tasks.ComputeDerivedFieldsMeta,
 	}
 }
 
@@ -96,6 +97,9 @@ func (p Customize) ApiResources() map[string]map[string]plugin.ApiResourceHandle
 			"GET":  handlers.ListFields,
 			"POST": handlers.CreateFields,
 		},
+		":table/fields/preview": {
+			"POST": handlers.PreviewField,
+		},
 		":table/fields/:field": {
 			"DELETE": handlers.DeleteField,
 		},
@@ -126,5 +130,14 @@ func (p Customize) ApiResources() map[string]map[string]plugin.ApiResourceHandle
 		"csvfiles/qa_test_case_executions.csv": {
 			"POST": handlers.ImportQaTestCaseExecutions,
 		},
+		"csvfiles/deployments.csv": {
+			"POST": handlers.ImportDeployments,
+		},
+		"csvfiles/incidents.csv": {
+			"POST": handlers.ImportIncidents,
+		},
+		"csvfiles/cicd_pipelines.csv": {
+			"POST": handlers.ImportCicdPipelines,
+		},
 	}
 }
@@ -31,6 +31,7 @@ type CustomizedField struct {
 	DisplayName string         `gorm:"type:varchar(255)"`
 	DataType    dal.ColumnType `gorm:"type:varchar(255)"`
 	Description string
+	Expression  string // the field is derived from other columns by the expression if not empty
 }
 
 func (t *CustomizedField) TableName() string {
@@ -0,0 +1,45 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+type customizedField20251113 struct {
+	Expression string
+}
+
+func (customizedField20251113) TableName() string {
+	return "_tool_customized_fields"
+}
+
+type addExpressionToCustomizedFields struct{}
+
+func (*addExpressionToCustomizedFields) Up(basicRes context.BasicRes) errors.Error {
+	return basicRes.GetDal().AutoMigrate(&customizedField20251113{})
+}
+
+func (*addExpressionToCustomizedFields) Version() uint64 {
+	return 20251113100000
+}
+
+func (*addExpressionToCustomizedFields) Name() string {
+	return "add expression to _tool_customized_fields"
+}
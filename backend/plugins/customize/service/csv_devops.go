@@ -0,0 +1,527 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/utils"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper"
+)
+
+var (
+	csvEnvironments = []string{devops.PRODUCTION, devops.STAGING, devops.TESTING, "DEVELOPMENT"}
+	csvResults      = []string{devops.RESULT_SUCCESS, devops.RESULT_FAILURE}
+	csvStatuses     = []string{devops.STATUS_IN_PROGRESS, devops.STATUS_DONE, devops.STATUS_OTHER}
+	csvIssueStatus  = []string{ticket.TODO, ticket.IN_PROGRESS, ticket.DONE, ticket.OTHER}
+	csvPipelineType = []string{"CI", "CD"}
+)
+
+// CsvRowError tells why a line of the csv file was rejected, the first record is on line 1
+type CsvRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// CsvImportResult is returned by the imports validating every row before saving anything
+type CsvImportResult struct {
+	Imported int           `json:"imported"`
+	Errors   []CsvRowError `json:"errors,omitempty"`
+}
+
+// validateCSV parses every record of the csv file with rowParser, all the invalid rows are reported at once
+func validateCSV[T any](file io.ReadCloser, rowParser func(map[string]interface{}) (T, errors.Error)) ([]T, []CsvRowError, errors.Error) {
+	iterator, err := pluginhelper.NewCsvFileIteratorFromFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rows []T
+	var rowErrors []CsvRowError
+	for line := 1; ; line++ {
+		hasNext, err := iterator.HasNextWithError()
+		if err != nil {
+			return nil, nil, errors.BadInput.Wrap(err, fmt.Sprintf("error on processing the line:%d", line))
+		}
+		if !hasNext {
+			return rows, rowErrors, nil
+		}
+		record := iterator.Fetch()
+		for k, v := range record {
+			if v.(string) == "NULL" {
+				record[k] = nil
+			}
+		}
+		row, err := rowParser(record)
+		if err != nil {
+			rowErrors = append(rowErrors, CsvRowError{Line: line, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, row)
+	}
+}
+
+// getTimeField parses a date field, see getStringField for the meaning of required
+func getTimeField(record map[string]interface{}, fieldName string, required bool) (*time.Time, errors.Error) {
+	value, err := getStringField(record, fieldName, required)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	t, e := common.ConvertStringToTime(value)
+	if e != nil {
+		return nil, errors.Default.New(fmt.Sprintf("%s is not a valid date: %s", fieldName, value))
+	}
+	return &t, nil
+}
+
+// getEnumField returns the value of the field if it is one of the allowed values, or the default value when empty
+func getEnumField(record map[string]interface{}, fieldName string, allowed []string, defaultValue string) (string, errors.Error) {
+	value, err := getStringField(record, fieldName, false)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return defaultValue, nil
+	}
+	if !utils.StringsContains(allowed, value) {
+		return "", errors.Default.New(fmt.Sprintf("%s must be one of %v, got %s", fieldName, allowed, value))
+	}
+	return value, nil
+}
+
+func durationSec(startedDate, finishedDate *time.Time) *float64 {
+	if startedDate == nil || finishedDate == nil {
+		return nil
+	}
+	duration := finishedDate.Sub(*startedDate).Seconds()
+	return &duration
+}
+
+// parseDeploymentRow turns a row of deployments.csv into a deployment commit, a deployment spans all the rows with the same id
+func parseDeploymentRow(cicdScopeId string, record map[string]interface{}) (*devops.CicdDeploymentCommit, errors.Error) {
+	deploymentId, err := getStringField(record, "id", true)
+	if err != nil {
+		return nil, err
+	}
+	commitSha, err := getStringField(record, "commit_sha", true)
+	if err != nil {
+		return nil, err
+	}
+	repoUrl, err := getStringField(record, "repo_url", true)
+	if err != nil {
+		return nil, err
+	}
+	finishedDate, err := getTimeField(record, "finished_date", true)
+	if err != nil {
+		return nil, err
+	}
+	startedDate, err := getTimeField(record, "started_date", false)
+	if err != nil {
+		return nil, err
+	}
+	if startedDate == nil {
+		startedDate = finishedDate
+	}
+	createdDate, err := getTimeField(record, "created_date", false)
+	if err != nil {
+		return nil, err
+	}
+	if createdDate == nil {
+		createdDate = startedDate
+	}
+	result, err := getEnumField(record, "result", csvResults, devops.RESULT_SUCCESS)
+	if err != nil {
+		return nil, err
+	}
+	status, err := getEnumField(record, "status", csvStatuses, devops.STATUS_DONE)
+	if err != nil {
+		return nil, err
+	}
+	environment, err := getEnumField(record, "environment", csvEnvironments, devops.PRODUCTION)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string)
+	for _, name := range []string{"name", "display_title", "url", "original_environment", "ref_name", "repo_id", "commit_msg"} {
+		if fields[name], err = getStringField(record, name, false); err != nil {
+			return nil, err
+		}
+	}
+	name := fields["name"]
+	if name == "" {
+		name = deploymentId
+	}
+	return &devops.CicdDeploymentCommit{
+		DomainEntity: domainlayer.DomainEntity{
+			Id: csvDeploymentCommitId(deploymentId, repoUrl, commitSha),
+		},
+		CicdScopeId:         cicdScopeId,
+		CicdDeploymentId:    deploymentId,
+		Name:                name,
+		DisplayTitle:        fields["display_title"],
+		Url:                 fields["url"],
+		Result:              result,
+		Status:              status,
+		OriginalResult:      result,
+		OriginalStatus:      status,
+		Environment:         environment,
+		OriginalEnvironment: fields["original_environment"],
+		TaskDatesInfo: devops.TaskDatesInfo{
+			CreatedDate:  *createdDate,
+			StartedDate:  startedDate,
+			FinishedDate: finishedDate,
+		},
+		DurationSec: durationSec(startedDate, finishedDate),
+		CommitSha:   commitSha,
+		CommitMsg:   fields["commit_msg"],
+		RefName:     fields["ref_name"],
+		RepoId:      fields["repo_id"],
+		RepoUrl:     repoUrl,
+	}, nil
+}
+
+// csvDeploymentCommitId keeps the commits of different repos deployed together apart
+func csvDeploymentCommitId(deploymentId, repoUrl, commitSha string) string {
+	urlHash16 := fmt.Sprintf("%x", md5.Sum([]byte(repoUrl)))[:16]
+	return fmt.Sprintf("%s:%s:%s", deploymentId, urlHash16, commitSha)
+}
+
+// parseIncidentRow turns a row of incidents.csv into an incident of the board
+func parseIncidentRow(boardId string, record map[string]interface{}) (*csvIncident, errors.Error) {
+	id, err := getStringField(record, "id", true)
+	if err != nil {
+		return nil, err
+	}
+	title, err := getStringField(record, "title", true)
+	if err != nil {
+		return nil, err
+	}
+	createdDate, err := getTimeField(record, "created_date", true)
+	if err != nil {
+		return nil, err
+	}
+	resolutionDate, err := getTimeField(record, "resolution_date", false)
+	if err != nil {
+		return nil, err
+	}
+	updatedDate, err := getTimeField(record, "updated_date", false)
+	if err != nil {
+		return nil, err
+	}
+	defaultStatus := ticket.TODO
+	if resolutionDate != nil {
+		defaultStatus = ticket.DONE
+	}
+	status, err := getEnumField(record, "status", csvIssueStatus, defaultStatus)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string)
+	for _, name := range []string{"url", "incident_key", "description", "original_status", "priority", "severity", "urgency", "component", "creator_name", "assignee_name"} {
+		if fields[name], err = getStringField(record, name, false); err != nil {
+			return nil, err
+		}
+	}
+	incidentKey := fields["incident_key"]
+	if incidentKey == "" {
+		incidentKey = id
+	}
+	incident := &ticket.Incident{
+		DomainEntity: domainlayer.DomainEntity{
+			Id: id,
+		},
+		Url:            fields["url"],
+		IncidentKey:    incidentKey,
+		Title:          title,
+		Description:    fields["description"],
+		Status:         status,
+		OriginalStatus: fields["original_status"],
+		ResolutionDate: resolutionDate,
+		CreatedDate:    createdDate,
+		UpdatedDate:    updatedDate,
+		Priority:       fields["priority"],
+		Severity:       fields["severity"],
+		Urgency:        fields["urgency"],
+		Component:      fields["component"],
+		CreatorName:    fields["creator_name"],
+		AssigneeName:   fields["assignee_name"],
+		Table:          "boards",
+		ScopeId:        boardId,
+	}
+	if resolutionDate != nil {
+		leadTimeMinutes := uint(resolutionDate.Sub(*createdDate).Minutes())
+		incident.LeadTimeMinutes = &leadTimeMinutes
+	}
+	return &csvIncident{Incident: incident}, nil
+}
+
+type csvIncident struct {
+	*ticket.Incident
+}
+
+// parsePipelineRow turns a row of cicd_pipelines.csv into a pipeline, a pipeline spans all the rows with the same id,
+// each of them may carry one of the commits it built
+func parsePipelineRow(cicdScopeId string, record map[string]interface{}) (*csvPipeline, errors.Error) {
+	id, err := getStringField(record, "id", true)
+	if err != nil {
+		return nil, err
+	}
+	dates := make(map[string]*time.Time)
+	for _, name := range []string{"created_date", "queued_date", "started_date", "finished_date"} {
+		if dates[name], err = getTimeField(record, name, false); err != nil {
+			return nil, err
+		}
+	}
+	createdDate := dates["created_date"]
+	for _, fallback := range []string{"queued_date", "started_date", "finished_date"} {
+		if createdDate == nil {
+			createdDate = dates[fallback]
+		}
+	}
+	if createdDate == nil {
+		return nil, errors.Default.New("record without any of created_date, queued_date, started_date and finished_date")
+	}
+	result, err := getEnumField(record, "result", csvResults, devops.RESULT_DEFAULT)
+	if err != nil {
+		return nil, err
+	}
+	status, err := getEnumField(record, "status", csvStatuses, devops.STATUS_DONE)
+	if err != nil {
+		return nil, err
+	}
+	pipelineType, err := getEnumField(record, "type", csvPipelineType, "")
+	if err != nil {
+		return nil, err
+	}
+	environment, err := getEnumField(record, "environment", csvEnvironments, "")
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string)
+	for _, name := range []string{"name", "display_title", "url", "original_result", "original_status", "commit_sha", "commit_msg", "branch", "repo_id", "repo_url"} {
+		if fields[name], err = getStringField(record, name, false); err != nil {
+			return nil, err
+		}
+	}
+	name := fields["name"]
+	if name == "" {
+		name = id
+	}
+	pipeline := &devops.CICDPipeline{
+		DomainEntity: domainlayer.DomainEntity{
+			Id: id,
+		},
+		Name:           name,
+		DisplayTitle:   fields["display_title"],
+		Url:            fields["url"],
+		Result:         result,
+		Status:         status,
+		OriginalResult: fields["original_result"],
+		OriginalStatus: fields["original_status"],
+		Type:           pipelineType,
+		Environment:    environment,
+		TaskDatesInfo: devops.TaskDatesInfo{
+			CreatedDate:  *createdDate,
+			QueuedDate:   dates["queued_date"],
+			StartedDate:  dates["started_date"],
+			FinishedDate: dates["finished_date"],
+		},
+		CicdScopeId: cicdScopeId,
+	}
+	if duration := durationSec(dates["started_date"], dates["finished_date"]); duration != nil {
+		pipeline.DurationSec = *duration
+	}
+	pipeline.QueuedDurationSec = pipeline.TaskDatesInfo.CalculateQueueDuration()
+	row := &csvPipeline{CICDPipeline: pipeline}
+	if fields["commit_sha"] != "" {
+		if fields["repo_url"] == "" && fields["repo_id"] == "" {
+			return nil, errors.Default.New("record with commit_sha requires repo_url or repo_id")
+		}
+		row.commit = &devops.CiCDPipelineCommit{
+			PipelineId: id,
+			CommitSha:  fields["commit_sha"],
+			CommitMsg:  fields["commit_msg"],
+			Branch:     fields["branch"],
+			RepoId:     fields["repo_id"],
+			RepoUrl:    fields["repo_url"],
+		}
+	}
+	return row, nil
+}
+
+type csvPipeline struct {
+	*devops.CICDPipeline
+	commit *devops.CiCDPipelineCommit
+}
+
+// ImportDeployments imports deployments.csv into `cicd_deployments` and `cicd_deployment_commits` of the cicd scope,
+// nothing is saved unless every row is valid
+func (s *Service) ImportDeployments(cicdScopeId, cicdScopeName, projectName string, file io.ReadCloser, incremental bool) (*CsvImportResult, errors.Error) {
+	commits, rowErrors, err := validateCSV(file, func(record map[string]interface{}) (*devops.CicdDeploymentCommit, errors.Error) {
+		return parseDeploymentRow(cicdScopeId, record)
+	})
+	if err != nil || len(rowErrors) > 0 {
+		return &CsvImportResult{Errors: rowErrors}, err
+	}
+	if err = s.saveCicdScope(cicdScopeId, cicdScopeName, projectName); err != nil {
+		return nil, err
+	}
+	if !incremental {
+		if err = s.dal.Delete(&devops.CicdDeploymentCommit{}, dal.Where("cicd_scope_id = ?", cicdScopeId)); err != nil {
+			return nil, err
+		}
+		if err = s.dal.Delete(&devops.CICDDeployment{}, dal.Where("cicd_scope_id = ?", cicdScopeId)); err != nil {
+			return nil, err
+		}
+	}
+	deployments := make(map[string]bool)
+	for _, commit := range commits {
+		commit.RawDataParams = cicdScopeId
+		if err = s.dal.CreateOrUpdate(commit); err != nil {
+			return nil, err
+		}
+		// the first row of a deployment describes it
+		if !deployments[commit.CicdDeploymentId] {
+			deployments[commit.CicdDeploymentId] = true
+			if err = s.dal.CreateOrUpdate(commit.ToDeployment()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &CsvImportResult{Imported: len(commits)}, nil
+}
+
+// ImportIncidents imports incidents.csv into `incidents` of the board, nothing is saved unless every row is valid
+func (s *Service) ImportIncidents(boardId, boardName, projectName string, file io.ReadCloser, incremental bool) (*CsvImportResult, errors.Error) {
+	incidents, rowErrors, err := validateCSV(file, func(record map[string]interface{}) (*csvIncident, errors.Error) {
+		return parseIncidentRow(boardId, record)
+	})
+	if err != nil || len(rowErrors) > 0 {
+		return &CsvImportResult{Errors: rowErrors}, err
+	}
+	if err = s.SaveBoard(boardId, boardName); err != nil {
+		return nil, err
+	}
+	if err = s.saveProjectMapping(projectName, ticket.Board{}.TableName(), boardId); err != nil {
+		return nil, err
+	}
+	if !incremental {
+		if err = s.dal.Delete(&ticket.Incident{}, dal.Where("? = ? AND scope_id = ?", dal.ClauseColumn{Name: "table"}, "boards", boardId)); err != nil {
+			return nil, err
+		}
+	}
+	for _, incident := range incidents {
+		incident.RawDataParams = boardId
+		if incident.CreatorId, err = s.createOrUpdateAccount(incident.CreatorName, boardId); err != nil {
+			return nil, err
+		}
+		if incident.AssigneeId, err = s.createOrUpdateAccount(incident.AssigneeName, boardId); err != nil {
+			return nil, err
+		}
+		if err = s.dal.CreateOrUpdate(incident.Incident); err != nil {
+			return nil, err
+		}
+	}
+	return &CsvImportResult{Imported: len(incidents)}, nil
+}
+
+// ImportCicdPipelines imports cicd_pipelines.csv into `cicd_pipelines` and `cicd_pipeline_commits` of the cicd scope,
+// nothing is saved unless every row is valid
+func (s *Service) ImportCicdPipelines(cicdScopeId, cicdScopeName, projectName string, file io.ReadCloser, incremental bool) (*CsvImportResult, errors.Error) {
+	pipelines, rowErrors, err := validateCSV(file, func(record map[string]interface{}) (*csvPipeline, errors.Error) {
+		return parsePipelineRow(cicdScopeId, record)
+	})
+	if err != nil || len(rowErrors) > 0 {
+		return &CsvImportResult{Errors: rowErrors}, err
+	}
+	if err = s.saveCicdScope(cicdScopeId, cicdScopeName, projectName); err != nil {
+		return nil, err
+	}
+	if !incremental {
+		err = s.dal.Delete(
+			&devops.CiCDPipelineCommit{},
+			dal.Where("pipeline_id IN (SELECT id FROM cicd_pipelines WHERE cicd_scope_id = ?)", cicdScopeId),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.dal.Delete(&devops.CICDPipeline{}, dal.Where("cicd_scope_id = ?", cicdScopeId)); err != nil {
+			return nil, err
+		}
+	}
+	saved := make(map[string]bool)
+	for _, pipeline := range pipelines {
+		// the first row of a pipeline describes it
+		if !saved[pipeline.Id] {
+			saved[pipeline.Id] = true
+			pipeline.RawDataParams = cicdScopeId
+			if err = s.dal.CreateOrUpdate(pipeline.CICDPipeline); err != nil {
+				return nil, err
+			}
+		}
+		if pipeline.commit != nil {
+			pipeline.commit.RawDataParams = cicdScopeId
+			if err = s.dal.CreateOrUpdate(pipeline.commit); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &CsvImportResult{Imported: len(pipelines)}, nil
+}
+
+// saveCicdScope makes sure the cicd scope exists and belongs to the project
+func (s *Service) saveCicdScope(cicdScopeId, cicdScopeName, projectName string) errors.Error {
+	err := s.dal.CreateOrUpdate(&devops.CicdScope{
+		DomainEntity: domainlayer.DomainEntity{
+			Id: cicdScopeId,
+		},
+		Name: cicdScopeName,
+	})
+	if err != nil {
+		return err
+	}
+	return s.saveProjectMapping(projectName, devops.CicdScope{}.TableName(), cicdScopeId)
+}
+
+// saveProjectMapping maps the scope to the project so the metric plugins, e.g. dora, pick the imported data up
+func (s *Service) saveProjectMapping(projectName, table, rowId string) errors.Error {
+	if projectName == "" {
+		return nil
+	}
+	count, err := s.dal.Count(dal.From("projects"), dal.Where("name = ?", projectName))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.NotFound.New(fmt.Sprintf("project %s not found", projectName))
+	}
+	return s.dal.CreateOrUpdate(&crossdomain.ProjectMapping{
+		ProjectName: projectName,
+		Table:       table,
+		RowId:       rowId,
+	})
+}
@@ -0,0 +1,133 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCSV(t *testing.T) {
+	csv := "id,commit_sha,repo_url,finished_date,result,environment\n" +
+		"d1,sha1,https://example.com/repo.git,2024-01-02T03:04:05Z,SUCCESS,PRODUCTION\n" +
+		"d1,sha2,https://example.com/other.git,2024-01-02T03:04:05Z,,\n" +
+		"d2,,https://example.com/repo.git,2024-01-02T03:04:05Z,SUCCESS,PRODUCTION\n" +
+		"d3,sha3,https://example.com/repo.git,yesterday,SUCCESS,PRODUCTION\n" +
+		"d4,sha4,https://example.com/repo.git,2024-01-02T03:04:05Z,PASSED,PRODUCTION\n"
+	rows, rowErrors, err := validateCSV(io.NopCloser(strings.NewReader(csv)), func(record map[string]interface{}) (*devops.CicdDeploymentCommit, errors.Error) {
+		return parseDeploymentRow("scope1", record)
+	})
+	assert.Nil(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, []int{3, 4, 5}, []int{rowErrors[0].Line, rowErrors[1].Line, rowErrors[2].Line})
+	assert.Contains(t, rowErrors[0].Error, "commit_sha")
+	assert.Contains(t, rowErrors[1].Error, "finished_date is not a valid date")
+	assert.Contains(t, rowErrors[2].Error, "result must be one of")
+}
+
+func TestParseDeploymentRow(t *testing.T) {
+	commit, err := parseDeploymentRow("scope1", map[string]interface{}{
+		"id":            "d1",
+		"commit_sha":    "sha1",
+		"repo_url":      "https://example.com/repo.git",
+		"started_date":  "2024-01-02T03:00:00Z",
+		"finished_date": "2024-01-02T03:01:30Z",
+		"name":          nil,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, csvDeploymentCommitId("d1", "https://example.com/repo.git", "sha1"), commit.Id)
+	assert.Equal(t, "scope1", commit.CicdScopeId)
+	assert.Equal(t, "d1", commit.CicdDeploymentId)
+	assert.Equal(t, "d1", commit.Name)
+	assert.Equal(t, devops.RESULT_SUCCESS, commit.Result)
+	assert.Equal(t, devops.STATUS_DONE, commit.Status)
+	assert.Equal(t, devops.PRODUCTION, commit.Environment)
+	assert.Equal(t, float64(90), *commit.DurationSec)
+	assert.Equal(t, *commit.StartedDate, commit.CreatedDate)
+	assert.NotEqual(t, commit.Id, csvDeploymentCommitId("d1", "https://example.com/other.git", "sha1"))
+}
+
+func TestParseIncidentRow(t *testing.T) {
+	incident, err := parseIncidentRow("board1", map[string]interface{}{
+		"id":              "i1",
+		"title":           "outage",
+		"created_date":    "2024-01-02T03:00:00Z",
+		"resolution_date": "2024-01-02T05:00:00Z",
+		"creator_name":    "alice",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "i1", incident.IncidentKey)
+	assert.Equal(t, ticket.DONE, incident.Status)
+	assert.Equal(t, uint(120), *incident.LeadTimeMinutes)
+	assert.Equal(t, "boards", incident.Table)
+	assert.Equal(t, "board1", incident.ScopeId)
+	assert.Equal(t, "alice", incident.CreatorName)
+
+	incident, err = parseIncidentRow("board1", map[string]interface{}{
+		"id":           "i2",
+		"title":        "outage",
+		"created_date": "2024-01-02T03:00:00Z",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, ticket.TODO, incident.Status)
+	assert.Nil(t, incident.LeadTimeMinutes)
+
+	_, err = parseIncidentRow("board1", map[string]interface{}{
+		"id":           "i3",
+		"title":        "outage",
+		"created_date": "2024-01-02T03:00:00Z",
+		"status":       "CLOSED",
+	})
+	assert.NotNil(t, err)
+}
+
+func TestParsePipelineRow(t *testing.T) {
+	pipeline, err := parsePipelineRow("scope1", map[string]interface{}{
+		"id":            "p1",
+		"result":        "FAILURE",
+		"type":          "CI",
+		"started_date":  "2024-01-02T03:00:00Z",
+		"finished_date": "2024-01-02T03:10:00Z",
+		"commit_sha":    "sha1",
+		"repo_url":      "https://example.com/repo.git",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "p1", pipeline.Name)
+	assert.Equal(t, "scope1", pipeline.CicdScopeId)
+	assert.Equal(t, devops.RESULT_FAILURE, pipeline.Result)
+	assert.Equal(t, *pipeline.StartedDate, pipeline.CreatedDate)
+	assert.Equal(t, float64(600), pipeline.DurationSec)
+	assert.Equal(t, "p1", pipeline.commit.PipelineId)
+	assert.Equal(t, "sha1", pipeline.commit.CommitSha)
+
+	_, err = parsePipelineRow("scope1", map[string]interface{}{"id": "p2"})
+	assert.NotNil(t, err)
+
+	_, err = parsePipelineRow("scope1", map[string]interface{}{
+		"id":           "p3",
+		"created_date": "2024-01-02T03:00:00Z",
+		"commit_sha":   "sha1",
+	})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,135 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/plugins/customize/expr"
+	customizeModels "github.com/apache/incubator-devlake/plugins/customize/models"
+)
+
+const (
+	defaultPreviewRows = 10
+	maxPreviewRows     = 100
+)
+
+// PreviewResult is the value of an expression evaluated against a sample row
+type PreviewResult struct {
+	Row   map[string]interface{} `json:"row"`
+	Value interface{}            `json:"value"`
+	Error string                 `json:"error,omitempty"`
+}
+
+// CompileExpression compiles the expression of the derived field `column` and makes sure all the columns it references exist in the table
+func (s *Service) CompileExpression(table, column, expression string) (*expr.Expression, errors.Error) {
+	e, err := expr.Compile(expression)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid expression")
+	}
+	columns, err := dal.GetColumnNames(s.dal, &customizeModels.Table{Name: table}, nil)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "GetColumns error")
+	}
+	if len(columns) == 0 {
+		return nil, errors.BadInput.New(fmt.Sprintf("table %s not found", table))
+	}
+	columnSet := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		columnSet[c] = true
+	}
+	for _, name := range e.Identifiers() {
+		if name == column {
+			return nil, errors.BadInput.New(fmt.Sprintf("the expression of %s references itself", column))
+		}
+		if !columnSet[name] && !expr.IsVirtualColumn(table, name) {
+			return nil, errors.BadInput.New(fmt.Sprintf("the column %s referenced by the expression doesn't exist in %s", name, table))
+		}
+	}
+	return e, nil
+}
+
+// PreviewExpression evaluates the expression against the given rows, or up to `limit` rows sampled from the table,
+// the value is converted to the dataType as it would be saved by the customize task
+func (s *Service) PreviewExpression(table, expression string, dataType dal.ColumnType, rows []map[string]interface{}, limit int) ([]PreviewResult, errors.Error) {
+	e, err := s.CompileExpression(table, "", expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		if rows, err = s.sampleRows(table, e.Identifiers(), limit); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]PreviewResult, 0, len(rows))
+	for _, row := range rows {
+		result := PreviewResult{Row: row}
+		value, err := e.Eval(row)
+		if err == nil {
+			value, err = expr.Convert(value, dataType)
+		}
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Value = value
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// sampleRows returns the primary keys and the columns referenced by an expression of the first `limit` rows of the table
+func (s *Service) sampleRows(table string, identifiers []string, limit int) ([]map[string]interface{}, errors.Error) {
+	if limit <= 0 {
+		limit = defaultPreviewRows
+	}
+	if limit > maxPreviewRows {
+		limit = maxPreviewRows
+	}
+	pkFields, err := dal.GetPrimarykeyColumns(s.dal, &customizeModels.Table{Name: table})
+	if err != nil {
+		return nil, err
+	}
+	var pkColumns []string
+	for _, field := range pkFields {
+		pkColumns = append(pkColumns, field.Name())
+	}
+	cursor, err := s.dal.Cursor(dal.From(table), dal.Limit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+	var rows []map[string]interface{}
+	for cursor.Next() {
+		record := make(map[string]interface{})
+		if err = s.dal.Fetch(cursor, &record); err != nil {
+			return nil, err
+		}
+		if err = expr.FillVirtualColumns(s.dal, table, identifiers, record); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(pkColumns)+len(identifiers))
+		for _, name := range append(pkColumns, identifiers...) {
+			row[name] = expr.Normalize(record[name])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
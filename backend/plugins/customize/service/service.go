@@ -119,6 +119,11 @@ func (s *Service) CreateField(cf *customizeModels.CustomizedField) errors.Error
 	if exists {
 		return errors.BadInput.New(fmt.Sprintf("the column %s already exists", cf.ColumnName))
 	}
+	if cf.Expression != "" {
+		if _, err = s.CompileExpression(cf.TbName, cf.ColumnName, cf.Expression); err != nil {
+			return err
+		}
+	}
 	err = s.dal.Create(cf)
 	if err != nil {
 		return errors.Default.Wrap(err, "create customizedField")
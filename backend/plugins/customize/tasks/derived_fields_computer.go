@@ -0,0 +1,267 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
+	"github.com/apache/incubator-devlake/plugins/customize/expr"
+	"github.com/apache/incubator-devlake/plugins/customize/models"
+)
+
+var _ plugin.SubTaskEntryPoint = ComputeDerivedFields
+
+// derivedFieldsBatchSize is the number of rows loaded and updated in a transaction at a time
+const derivedFieldsBatchSize = 500
+
+var ComputeDerivedFieldsMeta = plugin.SubTaskMeta{Name: "computeDerivedFields",
+	EntryPoint:       ComputeDerivedFields,
+	EnabledByDefault: true,
+	Description:      "compute the customized fields derived from other columns by expressions",
+	Dependencies:     []*plugin.SubTaskMeta{&ExtractCustomizedFieldsMeta},
+}
+
+type derivedField struct {
+	column     string
+	dataType   dal.ColumnType
+	expression *expr.Expression
+}
+
+// ComputeDerivedFields evaluates the expressions of the derived fields for the rows of their tables in the board or
+// the project of the options
+func ComputeDerivedFields(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*TaskData)
+	if data == nil || data.Options == nil {
+		return nil
+	}
+	d := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+	var fields []models.CustomizedField
+	err := d.All(&fields, dal.Where("expression IS NOT NULL AND expression != ''"), dal.Orderby("created_at"))
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading derived fields")
+	}
+	var tables []string
+	derivedFields := make(map[string][]derivedField)
+	for _, field := range fields {
+		if len(data.Options.DerivedFieldTables) > 0 && !utils.StringsContains(data.Options.DerivedFieldTables, field.TbName) {
+			continue
+		}
+		e, err := expr.Compile(field.Expression)
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("invalid expression of %s.%s", field.TbName, field.ColumnName))
+		}
+		if _, ok := derivedFields[field.TbName]; !ok {
+			tables = append(tables, field.TbName)
+		}
+		derivedFields[field.TbName] = append(derivedFields[field.TbName], derivedField{
+			column:     field.ColumnName,
+			dataType:   field.DataType,
+			expression: e,
+		})
+	}
+	for _, table := range tables {
+		scope, ok, err := getScopeClauses(d, table, data.Options)
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("error resolving the scope of %s", table))
+		}
+		if !ok {
+			logger.Warn(nil, "skip the derived fields of %s which doesn't belong to the board or project", table)
+			continue
+		}
+		logger.Info("computing %d derived fields of %s", len(derivedFields[table]), table)
+		err = computeDerivedFields(taskCtx.GetContext(), d, logger, table, scope, derivedFields[table])
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("error computing derived fields of %s", table))
+		}
+	}
+	return nil
+}
+
+// computeDerivedFields pages through the rows of the table by primary keys and updates each page in a transaction,
+// the rows failing to evaluate are logged and skipped
+func computeDerivedFields(ctx context.Context, d dal.Dal, logger log.Logger, table string, scope []dal.Clause, fields []derivedField) errors.Error {
+	pkFields, err := dal.GetPrimarykeyColumns(d, &models.Table{Name: table})
+	if err != nil {
+		return err
+	}
+	if len(pkFields) == 0 {
+		return errors.Default.New(fmt.Sprintf("table %s has no primary key", table))
+	}
+	var identifiers []string
+	for _, field := range fields {
+		identifiers = append(identifiers, field.expression.Identifiers()...)
+	}
+	pkColumns := make([]string, len(pkFields))
+	orderBy := make([]string, len(pkFields))
+	for i, field := range pkFields {
+		pkColumns[i] = field.Name()
+		orderBy[i] = fmt.Sprintf("%s.%s", table, field.Name())
+	}
+	clauses := append([]dal.Clause{dal.From(table)}, scope...)
+	clauses = append(clauses, dal.Orderby(strings.Join(orderBy, ", ")))
+
+	skipped := 0
+	for offset := 0; ; offset += derivedFieldsBatchSize {
+		select {
+		case <-ctx.Done():
+			return errors.Convert(ctx.Err())
+		default:
+		}
+		var rows []map[string]interface{}
+		err = d.All(&rows, append(clauses, dal.Limit(derivedFieldsBatchSize), dal.Offset(offset))...)
+		if err != nil {
+			return err
+		}
+		var statements [][]interface{}
+		for _, row := range rows {
+			if err = expr.FillVirtualColumns(d, table, identifiers, row); err != nil {
+				return err
+			}
+			updates, err := evaluateDerivedFields(row, fields)
+			if err != nil {
+				logger.Warn(err, "skip the derived fields of %v in %s", primaryKey(row, pkColumns), table)
+				skipped++
+				continue
+			}
+			if len(updates) > 0 {
+				query, params := mkUpdate(table, updates, primaryKey(row, pkColumns))
+				statements = append(statements, append([]interface{}{query}, params...))
+			}
+		}
+		if err = execInTransaction(d, statements); err != nil {
+			return err
+		}
+		if len(rows) < derivedFieldsBatchSize {
+			break
+		}
+	}
+	if skipped > 0 {
+		logger.Warn(nil, "skipped %d rows of %s failing to evaluate the derived fields", skipped, table)
+	}
+	return nil
+}
+
+// evaluateDerivedFields returns the derived fields whose values of the row changed
+func evaluateDerivedFields(row map[string]interface{}, fields []derivedField) (map[string]interface{}, errors.Error) {
+	updates := make(map[string]interface{})
+	// a derived field could reference the ones defined before it
+	for _, field := range fields {
+		value, err := field.expression.Eval(row)
+		if err == nil {
+			value, err = expr.Convert(value, field.dataType)
+		}
+		if err != nil {
+			return nil, errors.BadInput.Wrap(err, fmt.Sprintf("error evaluating %s", field.column))
+		}
+		if !reflect.DeepEqual(expr.Normalize(row[field.column]), expr.Normalize(value)) {
+			updates[field.column] = value
+		}
+		row[field.column] = value
+	}
+	return updates, nil
+}
+
+// execInTransaction executes the statements, each of which is the query followed by its params, in a transaction
+func execInTransaction(d dal.Dal, statements [][]interface{}) (err errors.Error) {
+	if len(statements) == 0 {
+		return nil
+	}
+	tx := d.Begin()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	for _, statement := range statements {
+		if err = tx.Exec(statement[0].(string), statement[1:]...); err != nil {
+			return errors.Default.Wrap(err, "Exec SQL error")
+		}
+	}
+	return tx.Commit()
+}
+
+// getScopeClauses returns the clauses limiting the rows of the table to the board or the project of the options,
+// ok is false if they are set but the table doesn't belong to boards or projects
+func getScopeClauses(d dal.Dal, table string, options *Options) ([]dal.Clause, bool, errors.Error) {
+	if options.BoardId == "" && options.ProjectName == "" {
+		return nil, true, nil
+	}
+	condition, scopeTable, params, err := getScopeRelation(d, table)
+	if err != nil || condition == "" {
+		return nil, false, err
+	}
+	if options.BoardId != "" {
+		if scopeTable != "boards" {
+			return nil, false, nil
+		}
+		params = append(params, options.BoardId)
+		return []dal.Clause{dal.Where(fmt.Sprintf(condition, "(?)"), params...)}, true, nil
+	}
+	params = append(params, dal.ClauseColumn{Table: "pm", Name: "table"}, scopeTable, options.ProjectName)
+	scopeIds := "(SELECT pm.row_id FROM project_mapping pm WHERE ? = ? AND pm.project_name = ?)"
+	return []dal.Clause{dal.Where(fmt.Sprintf(condition, scopeIds), params...)}, true, nil
+}
+
+// getScopeRelation returns the condition relating the rows of the table to the scopes in `%s`, the table of the scopes
+// and the params preceding the ids of the scopes, the condition is empty if the table doesn't belong to any scope
+func getScopeRelation(d dal.Dal, table string) (string, string, []interface{}, errors.Error) {
+	switch table {
+	case "boards", "repos", "cicd_scopes":
+		return table + ".id IN %s", table, nil, nil
+	case "issues":
+		return "issues.id IN (SELECT bi.issue_id FROM board_issues bi WHERE bi.board_id IN %s)", "boards", nil, nil
+	case "sprints":
+		return "sprints.id IN (SELECT bs.sprint_id FROM board_sprints bs WHERE bs.board_id IN %s)", "boards", nil, nil
+	case "incidents":
+		return "? = 'boards' AND incidents.scope_id IN %s", "boards", []interface{}{dal.ClauseColumn{Table: "incidents", Name: "table"}}, nil
+	}
+	columns, err := d.GetColumns(&models.Table{Name: table}, nil)
+	if err != nil {
+		return "", "", nil, err
+	}
+	for _, column := range columns {
+		switch column.Name() {
+		case "board_id":
+			return table + ".board_id IN %s", "boards", nil, nil
+		case "issue_id":
+			return table + ".issue_id IN (SELECT bi.issue_id FROM board_issues bi WHERE bi.board_id IN %s)", "boards", nil, nil
+		case "repo_id":
+			return table + ".repo_id IN %s", "repos", nil, nil
+		case "cicd_scope_id":
+			return table + ".cicd_scope_id IN %s", "cicd_scopes", nil, nil
+		}
+	}
+	return "", "", nil, nil
+}
+
+func primaryKey(row map[string]interface{}, pkColumns []string) map[string]interface{} {
+	pk := make(map[string]interface{}, len(pkColumns))
+	for _, name := range pkColumns {
+		pk[name] = row[name]
+	}
+	return pk
+}
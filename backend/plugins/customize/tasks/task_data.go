@@ -26,6 +26,12 @@ type MappingRules struct {
 
 type Options struct {
 	TransformationRules []MappingRules `json:"transformationRules"`
+	// DerivedFieldTables limits the tables whose derived fields would be computed, all of them if empty
+	DerivedFieldTables []string `json:"derivedFieldTables" mapstructure:"derivedFieldTables"`
+	// BoardId limits the rows whose derived fields would be computed to the board, and ProjectName to the scopes of
+	// the project, BoardId takes precedence and all the rows are computed if both are empty
+	BoardId     string `json:"boardId" mapstructure:"boardId"`
+	ProjectName string `json:"projectName" mapstructure:"projectName"`
 }
 
 type TaskData struct {
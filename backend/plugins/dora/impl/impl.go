@@ -96,6 +96,8 @@ func (p Dora) SubTaskMetas() []plugin.SubTaskMeta {
 		tasks.CalculateChangeLeadTimeMeta,
 		tasks.IssuesToIncidentsMeta,
 		tasks.ConnectIncidentToDeploymentMeta,
+		tasks.AttributeToTeamsMeta,
+		tasks.CalculateTeamMetricsMeta,
 	}
 }
 
@@ -127,6 +129,14 @@ func (p Dora) MakeMetricPluginPipelinePlanV200(projectName string, options json.
 		}
 	}
 
+	// the dora metrics of the teams are rolled up from the project ones, so only the team subtasks take the teams
+	teamOptions := map[string]interface{}{
+		"projectName": projectName,
+	}
+	if len(op.TeamIds) > 0 {
+		teamOptions["teamIds"] = op.TeamIds
+	}
+
 	plan := coreModels.PipelinePlan{
 		{
 			{
@@ -165,6 +175,16 @@ func (p Dora) MakeMetricPluginPipelinePlanV200(projectName string, options json.
 				},
 			},
 		},
+		{
+			{
+				Plugin:  "dora",
+				Options: teamOptions,
+				Subtasks: []string{
+					tasks.AttributeToTeamsMeta.Name,
+					tasks.CalculateTeamMetricsMeta.Name,
+				},
+			},
+		},
 	}
 	return plan, nil
 }
@@ -67,6 +67,25 @@ func TestMakeMetricPluginPipelinePlanV200(t *testing.T) {
 				Options: map[string]interface{}{"projectName": projectName},
 			},
 		},
+		coreModels.PipelineStage{
+			{
+				Plugin: "dora",
+				Subtasks: []string{
+					tasks.AttributeToTeamsMeta.Name,
+					tasks.CalculateTeamMetricsMeta.Name,
+				},
+				Options: map[string]interface{}{"projectName": projectName},
+			},
+		},
 	}
 	assert.Equal(t, doraOutputPlan, plan)
+
+	option["teamIds"] = []string{"team1"}
+	optionJson, err = json.Marshal(option)
+	assert.Nil(t, err)
+	plan, err = dora.MakeMetricPluginPipelinePlanV200(projectName, optionJson)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"projectName": projectName, "teamIds": []string{"team1"}}, plan[3][0].Options)
+	// project level metrics are not limited to teams
+	assert.Equal(t, doraOutputPlan[:3], plan[:3])
 }
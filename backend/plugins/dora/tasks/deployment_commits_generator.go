@@ -113,7 +113,7 @@ func GenerateDeploymentCommits(taskCtx plugin.SubTaskContext) errors.Error {
 		}
 	} else {
 		clauses = append(clauses,
-			dal.Join("LEFT JOIN project_mapping pm ON (pm.table = 'cicd_scopes' AND pm.row_id = p.cicd_scope_id)"),
+			dal.Join("LEFT JOIN project_mapping pm ON (? = 'cicd_scopes' AND pm.row_id = p.cicd_scope_id)", dal.ClauseColumn{Table: "pm", Name: "table"}),
 			dal.Where(`pm.project_name = ?`, data.Options.ProjectName),
 		)
 		// Clear previous results from the project
@@ -123,11 +123,11 @@ func GenerateDeploymentCommits(taskCtx plugin.SubTaskContext) errors.Error {
 			FROM (
 				SELECT cdc.cicd_scope_id
 				FROM cicd_deployment_commits cdc
-				LEFT JOIN project_mapping pm ON (pm.table = 'cicd_scopes' AND pm.row_id = cdc.cicd_scope_id)
+				LEFT JOIN project_mapping pm ON (? = 'cicd_scopes' AND pm.row_id = cdc.cicd_scope_id)
 				WHERE pm.project_name = ?
 			) AS subquery
 			) AND subtask_name = ?;`
-		err := db.Exec(deleteSql, data.Options.ProjectName, DORAGenerateDeploymentCommits)
+		err := db.Exec(deleteSql, dal.ClauseColumn{Table: "pm", Name: "table"}, data.Options.ProjectName, DORAGenerateDeploymentCommits)
 		if err != nil {
 			return errors.Default.Wrap(err, "error deleting previous cicd_deployment_commits")
 		}
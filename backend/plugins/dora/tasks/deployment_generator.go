@@ -88,7 +88,7 @@ func GenerateDeployment(taskCtx plugin.SubTaskContext) errors.Error {
 		}
 	} else {
 		clauses = append(clauses,
-			dal.Join("LEFT JOIN project_mapping pm ON (pm.table = 'cicd_scopes' AND pm.row_id = p.cicd_scope_id)"),
+			dal.Join("LEFT JOIN project_mapping pm ON (? = 'cicd_scopes' AND pm.row_id = p.cicd_scope_id)", dal.ClauseColumn{Table: "pm", Name: "table"}),
 			dal.Where("pm.project_name = ?", data.Options.ProjectName),
 		)
 		// Clear previous results from the project
@@ -98,11 +98,11 @@ func GenerateDeployment(taskCtx plugin.SubTaskContext) errors.Error {
 				FROM (
 					SELECT cd.cicd_scope_id
 					FROM cicd_deployments cd
-					LEFT JOIN project_mapping pm ON (pm.table = 'cicd_scopes' AND pm.row_id = cd.cicd_scope_id)
+					LEFT JOIN project_mapping pm ON (? = 'cicd_scopes' AND pm.row_id = cd.cicd_scope_id)
 					WHERE pm.project_name = ?
 				) AS subquery
 				) AND subtask_name = ?;`
-		err := db.Exec(deleteSql, data.Options.ProjectName, DORAGenerateDeployment)
+		err := db.Exec(deleteSql, dal.ClauseColumn{Table: "pm", Name: "table"}, data.Options.ProjectName, DORAGenerateDeployment)
 		if err != nil {
 			return errors.Default.Wrap(err, "error deleting previous deployments")
 		}
@@ -58,7 +58,7 @@ func ConnectIncidentToDeployment(taskCtx plugin.SubTaskContext) errors.Error {
 	// select all issues belongs to the board
 	clauses := []dal.Clause{
 		dal.From(`incidents i`),
-		dal.Join(`left join project_mapping pm on pm.row_id = i.scope_id and pm.table = i.table`),
+		dal.Join(`left join project_mapping pm on pm.row_id = i.scope_id and ? = ?`, dal.ClauseColumn{Table: "pm", Name: "table"}, dal.ClauseColumn{Table: "i", Name: "table"}),
 		dal.Where("pm.project_name = ?", data.Options.ProjectName),
 	}
 
@@ -108,9 +108,9 @@ func ConnectIncidentToDeployment(taskCtx plugin.SubTaskContext) errors.Error {
 					`cicd_deployment_commits.finished_date < ?
 					    and cicd_deployment_commits.result = ?
 						and cicd_deployment_commits.environment = ?
-						and pm.table = ?
+						and ? = ?
 						and pm.project_name = ?`,
-					incident.CreatedDate, devops.RESULT_SUCCESS, devops.PRODUCTION, "cicd_scopes", data.Options.ProjectName,
+					incident.CreatedDate, devops.RESULT_SUCCESS, devops.PRODUCTION, dal.ClauseColumn{Table: "pm", Name: "table"}, "cicd_scopes", data.Options.ProjectName,
 				),
 				dal.Orderby("finished_date DESC"),
 				dal.Limit(1),
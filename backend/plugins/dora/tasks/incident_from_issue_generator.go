@@ -59,9 +59,9 @@ func ConvertIssuesToIncidents(taskCtx plugin.SubTaskContext) errors.Error {
 			 LEFT JOIN project_mapping pm ON pm.row_id = bi.board_id
 			 WHERE i.type = ?
 			   AND pm.project_name = ?
-		       AND pm.table = ?)
+		       AND ? = ?)
 	`
-	if err := db.Exec(deleteIncidentsSql, "INCIDENT", data.Options.ProjectName, "boards"); err != nil {
+	if err := db.Exec(deleteIncidentsSql, "INCIDENT", data.Options.ProjectName, dal.ClauseColumn{Table: "pm", Name: "table"}, "boards"); err != nil {
 		return errors.Default.Wrap(err, "error deleting previous incidents")
 
 	}
@@ -75,9 +75,9 @@ func ConvertIssuesToIncidents(taskCtx plugin.SubTaskContext) errors.Error {
 			 LEFT JOIN project_mapping pm ON pm.row_id = bi.board_id
 			 WHERE i.type = ?
 			   AND pm.project_name = ?
-		       AND pm.table = ?)
+		       AND ? = ?)
 	`
-	if err := db.Exec(deleteIncidentAssigneesSql, "INCIDENT", data.Options.ProjectName, "boards"); err != nil {
+	if err := db.Exec(deleteIncidentAssigneesSql, "INCIDENT", data.Options.ProjectName, dal.ClauseColumn{Table: "pm", Name: "table"}, "boards"); err != nil {
 		return errors.Default.Wrap(err, "error deleting previous incident_assignees")
 	}
 
@@ -88,8 +88,8 @@ func ConvertIssuesToIncidents(taskCtx plugin.SubTaskContext) errors.Error {
 		dal.Join(`left join board_issues bi on bi.issue_id = i.id`),
 		dal.Join(`left join project_mapping pm on pm.row_id = bi.board_id`),
 		dal.Where(
-			"i.type = ? and pm.project_name = ? and pm.table = ?",
-			"INCIDENT", data.Options.ProjectName, "boards",
+			"i.type = ? and pm.project_name = ? and ? = ?",
+			"INCIDENT", data.Options.ProjectName, dal.ClauseColumn{Table: "pm", Name: "table"}, "boards",
 		),
 	}
 	cursor, err := db.Cursor(clauses...)
@@ -68,7 +68,7 @@ func EnrichPrevSuccessDeploymentCommit(taskCtx plugin.SubTaskContext) errors.Err
 		)
 	} else {
 		clauses = append(clauses,
-			dal.Join("LEFT JOIN project_mapping pm ON (pm.table = 'cicd_scopes' AND pm.row_id = dc.cicd_scope_id)"),
+			dal.Join("LEFT JOIN project_mapping pm ON (? = 'cicd_scopes' AND pm.row_id = dc.cicd_scope_id)", dal.ClauseColumn{Table: "pm", Name: "table"}),
 			dal.Where("pm.project_name = ?", data.Options.ProjectName),
 			dal.Orderby("dc.cicd_scope_id, dc.repo_url, dc.environment, dc.finished_date"),
 		)
@@ -31,6 +31,9 @@ type DoraOptions struct {
 	Since       string
 	ProjectName string  `json:"projectName"`
 	ScopeId     *string `json:"scopeId,omitempty"`
+	// TeamIds limits the teams whose dora metrics, i.e. deployment frequency, change lead time, change failure rate
+	// and recovery time, are calculated into team_metrics, all teams when empty
+	TeamIds []string `json:"teamIds,omitempty"`
 }
 
 type DoraTaskData struct {
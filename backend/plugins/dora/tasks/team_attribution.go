@@ -0,0 +1,177 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+// AttributeToTeamsMeta contains metadata for the AttributeToTeams subtask.
+var AttributeToTeamsMeta = plugin.SubTaskMeta{
+	Name:             "attributeToTeams",
+	EntryPoint:       AttributeToTeams,
+	EnabledByDefault: true,
+	Description:      "Attribute pull requests, commits, deployments and issues to teams by the accounts of their authors or assignees",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS, plugin.DOMAIN_TYPE_CODE, plugin.DOMAIN_TYPE_CICD, plugin.DOMAIN_TYPE_TICKET},
+	DependencyTables: []string{"pull_requests", "commits", "repo_commits", "cicd_deployment_commits", "issues", "board_issues", "user_accounts", "team_users", "project_mapping"},
+	ProductTables:    []string{crossdomain.TeamAttribution{}.TableName()},
+}
+
+type teamAttributionSource struct {
+	entityTable string
+	// dateColumn is the date used to check the team membership of the user
+	dateColumn string
+	// selectColumns selects the entity_id and attributed_date
+	selectColumns string
+	clauses       []dal.Clause
+}
+
+type teamAttributionRow struct {
+	EntityId       string
+	AttributedDate *time.Time
+	TeamId         string
+	UserId         string
+	AccountId      string
+}
+
+// AttributeToTeams attributes the data of a project to the teams whose members authored or were assigned to it,
+// a member only counts for a team when the date of the data falls into the membership.
+func AttributeToTeams(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+	data := taskCtx.GetData().(*DoraTaskData)
+	projectName := data.Options.ProjectName
+	// Clear previous results from the project
+	err := db.Delete(&crossdomain.TeamAttribution{}, dal.Where("project_name = ?", projectName))
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting previous team_attributions")
+	}
+
+	sources := []teamAttributionSource{
+		{
+			entityTable:   "pull_requests",
+			dateColumn:    "pr.created_date",
+			selectColumns: "pr.id AS entity_id, pr.created_date AS attributed_date",
+			clauses: []dal.Clause{
+				dal.From("pull_requests pr"),
+				dal.Join("JOIN project_mapping pm ON pm.row_id = pr.base_repo_id AND ? = 'repos'", dal.ClauseColumn{Table: "pm", Name: "table"}),
+				dal.Join("JOIN user_accounts ua ON ua.account_id = pr.author_id"),
+			},
+		},
+		{
+			entityTable:   "commits",
+			dateColumn:    "c.authored_date",
+			selectColumns: "DISTINCT c.sha AS entity_id, c.authored_date AS attributed_date",
+			clauses: []dal.Clause{
+				dal.From("commits c"),
+				dal.Join("JOIN repo_commits rc ON rc.commit_sha = c.sha"),
+				dal.Join("JOIN project_mapping pm ON pm.row_id = rc.repo_id AND ? = 'repos'", dal.ClauseColumn{Table: "pm", Name: "table"}),
+				dal.Join("JOIN user_accounts ua ON ua.account_id = c.author_id"),
+			},
+		},
+		{
+			// deployments are attributed to the authors of the deployed commits
+			entityTable:   "cicd_deployments",
+			dateColumn:    "cdc.finished_date",
+			selectColumns: "DISTINCT cdc.cicd_deployment_id AS entity_id, cdc.finished_date AS attributed_date",
+			clauses: []dal.Clause{
+				dal.From("cicd_deployment_commits cdc"),
+				dal.Join("JOIN project_mapping pm ON pm.row_id = cdc.cicd_scope_id AND ? = 'cicd_scopes'", dal.ClauseColumn{Table: "pm", Name: "table"}),
+				dal.Join("JOIN commits c ON c.sha = cdc.commit_sha"),
+				dal.Join("JOIN user_accounts ua ON ua.account_id = c.author_id"),
+				dal.Where("cdc.result = 'SUCCESS' AND cdc.environment = 'PRODUCTION'"),
+			},
+		},
+		{
+			entityTable:   "issues",
+			dateColumn:    "COALESCE(i.resolution_date, i.created_date)",
+			selectColumns: "DISTINCT i.id AS entity_id, COALESCE(i.resolution_date, i.created_date) AS attributed_date",
+			clauses: []dal.Clause{
+				dal.From("issues i"),
+				dal.Join("JOIN board_issues bi ON bi.issue_id = i.id"),
+				dal.Join("JOIN project_mapping pm ON pm.row_id = bi.board_id AND ? = 'boards'", dal.ClauseColumn{Table: "pm", Name: "table"}),
+				dal.Join("JOIN user_accounts ua ON ua.account_id = i.assignee_id"),
+			},
+		},
+	}
+
+	divider := api.NewBatchSaveDivider(taskCtx, 500, "", "")
+	batch, err := divider.ForType(reflect.TypeOf(&crossdomain.TeamAttribution{}))
+	if err != nil {
+		return err
+	}
+	for _, source := range sources {
+		clauses := []dal.Clause{dal.Select(source.selectColumns + ", tu.team_id, ua.user_id, ua.account_id")}
+		clauses = append(clauses, source.clauses...)
+		clauses = append(clauses,
+			dal.Join("JOIN team_users tu ON tu.user_id = ua.user_id"),
+			dal.Where("pm.project_name = ?", projectName),
+			dal.Where(fmt.Sprintf(
+				"(tu.start_date IS NULL OR tu.start_date <= %[1]s) AND (tu.end_date IS NULL OR tu.end_date >= %[1]s)",
+				source.dateColumn,
+			)),
+		)
+		if len(data.Options.TeamIds) > 0 {
+			clauses = append(clauses, dal.Where("tu.team_id IN ?", data.Options.TeamIds))
+		}
+		count, err := saveTeamAttributions(db, batch, clauses, projectName, source.entityTable)
+		if err != nil {
+			return err
+		}
+		logger.Info("attributed %d %s to teams", count, source.entityTable)
+	}
+	return divider.Close()
+}
+
+func saveTeamAttributions(db dal.Dal, batch *api.BatchSave, clauses []dal.Clause, projectName, entityTable string) (int, errors.Error) {
+	cursor, err := db.Cursor(clauses...)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+	count := 0
+	for cursor.Next() {
+		row := &teamAttributionRow{}
+		err = db.Fetch(cursor, row)
+		if err != nil {
+			return 0, err
+		}
+		err = batch.Add(&crossdomain.TeamAttribution{
+			ProjectName:    projectName,
+			TeamId:         row.TeamId,
+			EntityTable:    entityTable,
+			EntityId:       row.EntityId,
+			UserId:         row.UserId,
+			AccountId:      row.AccountId,
+			AttributedDate: row.AttributedDate,
+		})
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, nil
+}
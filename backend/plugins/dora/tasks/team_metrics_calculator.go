@@ -0,0 +1,292 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+// CalculateTeamMetricsMeta contains metadata for the CalculateTeamMetrics subtask.
+var CalculateTeamMetricsMeta = plugin.SubTaskMeta{
+	Name:             "calculateTeamMetrics",
+	EntryPoint:       CalculateTeamMetrics,
+	EnabledByDefault: true,
+	Description:      "Calculate monthly team metrics from the data attributed to teams",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS},
+	DependencyTables: []string{crossdomain.TeamAttribution{}.TableName(), "pull_requests", "project_pr_metrics", "project_incident_deployment_relationships", "incidents", "issues"},
+	ProductTables:    []string{crossdomain.TeamMetric{}.TableName()},
+}
+
+type teamPrRow struct {
+	TeamId         string
+	CreatedDate    *time.Time
+	MergedDate     *time.Time
+	PrCycleTime    *int64
+	PrDeployedDate *time.Time
+}
+
+type teamDateRow struct {
+	TeamId         string
+	AttributedDate *time.Time
+}
+
+type teamDeploymentRow struct {
+	TeamId         string
+	EntityId       string
+	AttributedDate *time.Time
+	ResolutionDate *time.Time
+}
+
+type teamIssueRow struct {
+	TeamId         string
+	CreatedDate    *time.Time
+	ResolutionDate *time.Time
+}
+
+// CalculateTeamMetrics rolls up the data attributed to teams into monthly team metrics of the project.
+func CalculateTeamMetrics(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*DoraTaskData)
+	projectName := data.Options.ProjectName
+	// Clear previous results from the project
+	err := db.Delete(&crossdomain.TeamMetric{}, dal.Where("project_name = ?", projectName))
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting previous team_metrics")
+	}
+	attributed := func(entityTable string) dal.Clause {
+		return dal.Where("ta.project_name = ? AND ta.entity_table = ?", projectName, entityTable)
+	}
+	rollup := newTeamMetricsRollup()
+
+	var prs []teamPrRow
+	err = db.All(&prs,
+		dal.Select("ta.team_id, pr.created_date, pr.merged_date, ppm.pr_cycle_time, ppm.pr_deployed_date"),
+		dal.From("team_attributions ta"),
+		dal.Join("JOIN pull_requests pr ON pr.id = ta.entity_id"),
+		dal.Join("LEFT JOIN project_pr_metrics ppm ON ppm.id = pr.id AND ppm.project_name = ta.project_name"),
+		attributed("pull_requests"),
+	)
+	if err != nil {
+		return err
+	}
+	for _, pr := range prs {
+		rollup.addPullRequest(pr)
+	}
+
+	var commits []teamDateRow
+	err = db.All(&commits,
+		dal.Select("ta.team_id, ta.attributed_date"),
+		dal.From("team_attributions ta"),
+		attributed("commits"),
+	)
+	if err != nil {
+		return err
+	}
+	for _, commit := range commits {
+		rollup.addCommit(commit)
+	}
+
+	var deployments []teamDeploymentRow
+	err = db.All(&deployments,
+		dal.Select("ta.team_id, ta.entity_id, ta.attributed_date, i.resolution_date"),
+		dal.From("team_attributions ta"),
+		dal.Join("LEFT JOIN project_incident_deployment_relationships pidr ON pidr.deployment_id = ta.entity_id AND pidr.project_name = ta.project_name"),
+		dal.Join("LEFT JOIN incidents i ON i.id = pidr.id"),
+		attributed("cicd_deployments"),
+	)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		rollup.addDeployment(deployment)
+	}
+
+	var issues []teamIssueRow
+	err = db.All(&issues,
+		dal.Select("ta.team_id, i.created_date, i.resolution_date"),
+		dal.From("team_attributions ta"),
+		dal.Join("JOIN issues i ON i.id = ta.entity_id"),
+		attributed("issues"),
+	)
+	if err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		rollup.addIssue(issue)
+	}
+
+	divider := api.NewBatchSaveDivider(taskCtx, 500, "", "")
+	batch, err := divider.ForType(reflect.TypeOf(&crossdomain.TeamMetric{}))
+	if err != nil {
+		return err
+	}
+	for _, metric := range rollup.metrics(projectName) {
+		err = batch.Add(metric)
+		if err != nil {
+			return err
+		}
+	}
+	return divider.Close()
+}
+
+type teamMonthKey struct {
+	teamId string
+	month  time.Time
+}
+
+type teamMonth struct {
+	metric            crossdomain.TeamMetric
+	prCycleTimes      []int64
+	changeLeadTimes   []int64
+	deployments       map[string]bool
+	deploymentDays    map[time.Time]bool
+	failedDeployments map[string]bool
+	recoveryTimes     []int64
+}
+
+type teamMetricsRollup struct {
+	months map[teamMonthKey]*teamMonth
+}
+
+func newTeamMetricsRollup() *teamMetricsRollup {
+	return &teamMetricsRollup{months: make(map[teamMonthKey]*teamMonth)}
+}
+
+func (r *teamMetricsRollup) get(teamId string, date *time.Time) *teamMonth {
+	if date == nil {
+		return nil
+	}
+	key := teamMonthKey{teamId: teamId, month: monthOf(*date)}
+	m := r.months[key]
+	if m == nil {
+		m = &teamMonth{
+			metric:            crossdomain.TeamMetric{TeamId: teamId, Month: key.month},
+			deployments:       make(map[string]bool),
+			deploymentDays:    make(map[time.Time]bool),
+			failedDeployments: make(map[string]bool),
+		}
+		r.months[key] = m
+	}
+	return m
+}
+
+// addPullRequest counts the pr in the month it was created, and its cycle time in the month it was merged.
+// The cycle time of a deployed pr is its change lead time, which is counted in the month it was deployed as the dora
+// dashboards do
+func (r *teamMetricsRollup) addPullRequest(pr teamPrRow) {
+	if m := r.get(pr.TeamId, pr.CreatedDate); m != nil {
+		m.metric.PrCount++
+	}
+	if m := r.get(pr.TeamId, pr.MergedDate); m != nil {
+		m.metric.MergedPrCount++
+		if pr.PrCycleTime != nil {
+			m.prCycleTimes = append(m.prCycleTimes, *pr.PrCycleTime)
+		}
+	}
+	if m := r.get(pr.TeamId, pr.PrDeployedDate); m != nil && pr.PrCycleTime != nil {
+		m.changeLeadTimes = append(m.changeLeadTimes, *pr.PrCycleTime)
+	}
+}
+
+func (r *teamMetricsRollup) addCommit(commit teamDateRow) {
+	if m := r.get(commit.TeamId, commit.AttributedDate); m != nil {
+		m.metric.CommitCount++
+	}
+}
+
+// addDeployment counts the deployment in the month it finished, a deployment may come with multiple incidents
+func (r *teamMetricsRollup) addDeployment(deployment teamDeploymentRow) {
+	m := r.get(deployment.TeamId, deployment.AttributedDate)
+	if m == nil {
+		return
+	}
+	m.deployments[deployment.EntityId] = true
+	m.deploymentDays[dayOf(*deployment.AttributedDate)] = true
+	if deployment.ResolutionDate == nil {
+		return
+	}
+	m.failedDeployments[deployment.EntityId] = true
+	if recoveryTime := computeTimeSpan(deployment.AttributedDate, deployment.ResolutionDate); recoveryTime != nil {
+		m.recoveryTimes = append(m.recoveryTimes, *recoveryTime)
+	}
+}
+
+// addIssue counts the issue in the month it was created, and as resolved in the month it was resolved
+func (r *teamMetricsRollup) addIssue(issue teamIssueRow) {
+	if m := r.get(issue.TeamId, issue.CreatedDate); m != nil {
+		m.metric.IssueCount++
+	}
+	if m := r.get(issue.TeamId, issue.ResolutionDate); m != nil {
+		m.metric.ResolvedIssueCount++
+	}
+}
+
+func (r *teamMetricsRollup) metrics(projectName string) []*crossdomain.TeamMetric {
+	result := make([]*crossdomain.TeamMetric, 0, len(r.months))
+	for _, m := range r.months {
+		metric := m.metric
+		metric.ProjectName = projectName
+		metric.MedianPrCycleTime = median(m.prCycleTimes)
+		metric.MedianChangeLeadTime = median(m.changeLeadTimes)
+		metric.DeploymentCount = len(m.deployments)
+		metric.DeploymentDays = len(m.deploymentDays)
+		metric.FailedDeploymentCount = len(m.failedDeployments)
+		if metric.DeploymentCount > 0 {
+			rate := float64(metric.FailedDeploymentCount) / float64(metric.DeploymentCount)
+			metric.ChangeFailureRate = &rate
+		}
+		metric.MedianRecoveryTime = median(m.recoveryTimes)
+		result = append(result, &metric)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TeamId != result[j].TeamId {
+			return result[i].TeamId < result[j].TeamId
+		}
+		return result[i].Month.Before(result[j].Month)
+	})
+	return result
+}
+
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func dayOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// median returns the lower median, the same as the one in dora dashboards
+func median(values []int64) *int64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	m := sorted[(len(sorted)-1)/2]
+	return &m
+}
@@ -0,0 +1,83 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamMetricsRollup(t *testing.T) {
+	date := func(s string) *time.Time {
+		d, err := time.Parse(time.RFC3339, s)
+		assert.Nil(t, err)
+		return &d
+	}
+	cycleTime := func(v int64) *int64 {
+		return &v
+	}
+	rollup := newTeamMetricsRollup()
+	rollup.addPullRequest(teamPrRow{TeamId: "t1", CreatedDate: date("2024-01-20T00:00:00Z"), MergedDate: date("2024-02-02T00:00:00Z"), PrCycleTime: cycleTime(300)})
+	rollup.addPullRequest(teamPrRow{TeamId: "t1", CreatedDate: date("2024-02-01T00:00:00Z"), MergedDate: date("2024-02-03T00:00:00Z"), PrCycleTime: cycleTime(100)})
+	rollup.addPullRequest(teamPrRow{TeamId: "t1", CreatedDate: date("2024-02-05T00:00:00Z")})
+	// deployed prs count their cycle time as change lead time in the month they were deployed
+	rollup.addPullRequest(teamPrRow{TeamId: "t1", CreatedDate: date("2024-02-06T00:00:00Z"), MergedDate: date("2024-02-07T00:00:00Z"), PrCycleTime: cycleTime(200), PrDeployedDate: date("2024-02-10T00:00:00Z")})
+	rollup.addPullRequest(teamPrRow{TeamId: "t1", CreatedDate: date("2024-02-06T00:00:00Z"), MergedDate: date("2024-02-07T00:00:00Z"), PrCycleTime: cycleTime(400), PrDeployedDate: date("2024-02-11T00:00:00Z")})
+	rollup.addCommit(teamDateRow{TeamId: "t1", AttributedDate: date("2024-02-01T00:00:00Z")})
+	rollup.addCommit(teamDateRow{TeamId: "t2", AttributedDate: date("2024-02-01T00:00:00Z")})
+	// d1 caused two incidents, the rows are joined with incidents
+	rollup.addDeployment(teamDeploymentRow{TeamId: "t1", EntityId: "d1", AttributedDate: date("2024-02-10T00:00:00Z"), ResolutionDate: date("2024-02-10T02:00:00Z")})
+	rollup.addDeployment(teamDeploymentRow{TeamId: "t1", EntityId: "d1", AttributedDate: date("2024-02-10T00:00:00Z"), ResolutionDate: date("2024-02-10T01:00:00Z")})
+	rollup.addDeployment(teamDeploymentRow{TeamId: "t1", EntityId: "d2", AttributedDate: date("2024-02-11T00:00:00Z")})
+	rollup.addDeployment(teamDeploymentRow{TeamId: "t1", EntityId: "d3", AttributedDate: date("2024-02-11T08:00:00Z")})
+	rollup.addIssue(teamIssueRow{TeamId: "t1", CreatedDate: date("2024-01-05T00:00:00Z"), ResolutionDate: date("2024-02-05T00:00:00Z")})
+
+	metrics := rollup.metrics("project")
+	assert.Len(t, metrics, 3)
+
+	jan := metrics[0]
+	assert.Equal(t, "t1", jan.TeamId)
+	assert.Equal(t, "project", jan.ProjectName)
+	assert.Equal(t, *date("2024-01-01T00:00:00Z"), jan.Month)
+	assert.Equal(t, 1, jan.PrCount)
+	assert.Equal(t, 0, jan.MergedPrCount)
+	assert.Nil(t, jan.MedianPrCycleTime)
+	assert.Equal(t, 1, jan.IssueCount)
+	assert.Nil(t, jan.ChangeFailureRate)
+	assert.Nil(t, jan.MedianChangeLeadTime)
+
+	feb := metrics[1]
+	assert.Equal(t, *date("2024-02-01T00:00:00Z"), feb.Month)
+	assert.Equal(t, 4, feb.PrCount)
+	assert.Equal(t, 4, feb.MergedPrCount)
+	assert.Equal(t, int64(200), *feb.MedianPrCycleTime)
+	assert.Equal(t, int64(200), *feb.MedianChangeLeadTime)
+	assert.Equal(t, 1, feb.CommitCount)
+	assert.Equal(t, 3, feb.DeploymentCount)
+	assert.Equal(t, 2, feb.DeploymentDays)
+	assert.Equal(t, 1, feb.FailedDeploymentCount)
+	assert.Equal(t, float64(1)/3, *feb.ChangeFailureRate)
+	assert.Equal(t, int64(60), *feb.MedianRecoveryTime)
+	assert.Equal(t, 0, feb.IssueCount)
+	assert.Equal(t, 1, feb.ResolvedIssueCount)
+
+	assert.Equal(t, "t2", metrics[2].TeamId)
+	assert.Equal(t, 1, metrics[2].CommitCount)
+}
@@ -0,0 +1,49 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/gitextractor/cache"
+)
+
+// GetCacheStatus returns the status of the repo cache
+// @Summary get the status of the repo cache
+// @Description get the cached repos along with their sizes and last used time, the least recently used first
+// @Tags plugins/gitextractor
+// @Produce json
+// @Success 200  {object} cache.Status
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router /plugins/gitextractor/cache [get]
+func GetCacheStatus(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	repoCache, err := cache.NewRepoCacheFromConfig(basicRes.GetConfigReader())
+	if err != nil {
+		return nil, err
+	}
+	if repoCache == nil {
+		return &plugin.ApiResourceOutput{Body: cache.Status{Enabled: false}, Status: http.StatusOK}, nil
+	}
+	status, err := repoCache.Status()
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: status, Status: http.StatusOK}, nil
+}
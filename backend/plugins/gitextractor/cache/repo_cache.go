@@ -0,0 +1,341 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/config"
+	"github.com/apache/incubator-devlake/core/errors"
+	giturls "github.com/chainguard-dev/git-urls"
+)
+
+const (
+	// CacheDirKey enables the repo cache when set
+	CacheDirKey = "GIT_EXTRACTOR_CACHE_DIR"
+	// CacheMaxSizeKey is the max size of the repo cache in MB, unlimited when 0
+	CacheMaxSizeKey = "GIT_EXTRACTOR_CACHE_MAX_SIZE_MB"
+
+	lockPollInterval = time.Second
+)
+
+// RepoCache keeps bare mirrors of the remote repos on disk so they can be updated by `git fetch` across pipelines.
+// Every mirror comes with a lock file, the mirror is locked exclusively while being updated or evicted
+// and shared while being used by a task.
+type RepoCache struct {
+	dir     string
+	maxSize int64
+}
+
+// Entry describes a cached repo
+type Entry struct {
+	Key       string    `json:"key"`
+	Url       string    `json:"url"`
+	Size      int64     `json:"size"`
+	LastUsed  time.Time `json:"lastUsed"`
+	InUse     bool      `json:"inUse"`
+	MirrorDir string    `json:"-"`
+}
+
+// Status describes the whole repo cache
+type Status struct {
+	Enabled bool     `json:"enabled"`
+	Dir     string   `json:"dir"`
+	MaxSize int64    `json:"maxSize"`
+	Size    int64    `json:"size"`
+	Entries []*Entry `json:"entries"`
+}
+
+type metadata struct {
+	Url      string    `json:"url"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// Lock is a lock held on a cached repo
+type Lock struct {
+	file *os.File
+}
+
+// NewRepoCache creates the repo cache in dir, eviction is disabled when maxSize <= 0
+func NewRepoCache(dir string, maxSize int64) (*RepoCache, errors.Error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Default.Wrap(err, "failed to create the repo cache dir")
+	}
+	return &RepoCache{dir: dir, maxSize: maxSize}, nil
+}
+
+// NewRepoCacheFromConfig creates the repo cache by the configuration, nil is returned when the cache is disabled
+func NewRepoCacheFromConfig(cfg config.ConfigReader) (*RepoCache, errors.Error) {
+	dir := cfg.GetString(CacheDirKey)
+	if dir == "" {
+		return nil, nil
+	}
+	return NewRepoCache(dir, cfg.GetInt64(CacheMaxSizeKey)*1024*1024)
+}
+
+// SanitizeUrl removes the credentials from the repo url, so the cache survives token rotations
+func SanitizeUrl(repoUrl string) string {
+	u, err := giturls.Parse(repoUrl)
+	if err != nil {
+		return repoUrl
+	}
+	if u.User != nil && u.Scheme == "ssh" {
+		// the user of ssh urls is part of the address rather than a credential
+		u.User = url.User(u.User.Username())
+	} else {
+		u.User = nil
+	}
+	return strings.TrimSuffix(u.String(), "/")
+}
+
+// Key returns the key of the repo in the cache
+func (c *RepoCache) Key(repoUrl string) string {
+	sum := sha256.Sum256([]byte(SanitizeUrl(repoUrl)))
+	return hex.EncodeToString(sum[:])[:32]
+}
+
+// MirrorDir returns the dir of the bare mirror of the repo
+func (c *RepoCache) MirrorDir(repoUrl string) string {
+	return c.mirrorDir(c.Key(repoUrl))
+}
+
+func (c *RepoCache) mirrorDir(key string) string {
+	return filepath.Join(c.dir, key+".git")
+}
+
+func (c *RepoCache) lockPath(key string) string {
+	return filepath.Join(c.dir, key+".lock")
+}
+
+func (c *RepoCache) metadataPath(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// LockExclusive waits until the repo is not used by anyone else, it is for updating the mirror
+func (c *RepoCache) LockExclusive(ctx context.Context, repoUrl string) (*Lock, errors.Error) {
+	return c.lock(ctx, c.Key(repoUrl), syscall.LOCK_EX)
+}
+
+// LockShared waits until the repo is not being updated, it is for reading the mirror
+func (c *RepoCache) LockShared(ctx context.Context, repoUrl string) (*Lock, errors.Error) {
+	return c.lock(ctx, c.Key(repoUrl), syscall.LOCK_SH)
+}
+
+func (c *RepoCache) lock(ctx context.Context, key string, how int) (*Lock, errors.Error) {
+	file, err := os.OpenFile(c.lockPath(key), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to open the repo cache lock")
+	}
+	lock := &Lock{file: file}
+	if err := lock.acquire(ctx, how); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return lock, nil
+}
+
+func (l *Lock) acquire(ctx context.Context, how int) errors.Error {
+	for {
+		err := syscall.Flock(int(l.file.Fd()), how|syscall.LOCK_NB)
+		if err == nil {
+			return nil
+		}
+		if err != syscall.EWOULDBLOCK {
+			return errors.Default.Wrap(err, "failed to lock the repo cache")
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Convert(ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// Downgrade turns an exclusive lock into a shared one, so other tasks can read the mirror once it is updated
+func (l *Lock) Downgrade(ctx context.Context) errors.Error {
+	return l.acquire(ctx, syscall.LOCK_SH)
+}
+
+// Release releases the lock, it is safe to be called more than once
+func (l *Lock) Release() {
+	if l == nil || l.file == nil {
+		return
+	}
+	_ = syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	_ = l.file.Close()
+	l.file = nil
+}
+
+// Touch records the usage and size of the repo, the caller should hold a lock on it
+func (c *RepoCache) Touch(repoUrl string) errors.Error {
+	key := c.Key(repoUrl)
+	size, err := dirSize(c.mirrorDir(key))
+	if err != nil {
+		return err
+	}
+	blob, e := json.Marshal(metadata{Url: SanitizeUrl(repoUrl), Size: size, LastUsed: time.Now()})
+	if e != nil {
+		return errors.Convert(e)
+	}
+	return errors.Convert(os.WriteFile(c.metadataPath(key), blob, 0644))
+}
+
+// Remove deletes the mirror of the repo, the caller should hold the exclusive lock on it
+func (c *RepoCache) Remove(repoUrl string) errors.Error {
+	return c.remove(c.Key(repoUrl))
+}
+
+func (c *RepoCache) remove(key string) errors.Error {
+	if err := os.RemoveAll(c.mirrorDir(key)); err != nil {
+		return errors.Convert(err)
+	}
+	if err := os.Remove(c.metadataPath(key)); err != nil && !os.IsNotExist(err) {
+		return errors.Convert(err)
+	}
+	return nil
+}
+
+// Status lists the cached repos, the least recently used first
+func (c *RepoCache) Status() (*Status, errors.Error) {
+	status := &Status{Enabled: true, Dir: c.dir, MaxSize: c.maxSize}
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	for _, file := range files {
+		blob, e := os.ReadFile(file)
+		if e != nil {
+			return nil, errors.Convert(e)
+		}
+		var meta metadata
+		if e := json.Unmarshal(blob, &meta); e != nil {
+			continue
+		}
+		key := strings.TrimSuffix(filepath.Base(file), ".json")
+		entry := &Entry{
+			Key:       key,
+			Url:       meta.Url,
+			Size:      meta.Size,
+			LastUsed:  meta.LastUsed,
+			MirrorDir: c.mirrorDir(key),
+		}
+		entry.InUse = c.inUse(key)
+		status.Entries = append(status.Entries, entry)
+		status.Size += entry.Size
+	}
+	sort.Slice(status.Entries, func(i, j int) bool {
+		return status.Entries[i].LastUsed.Before(status.Entries[j].LastUsed)
+	})
+	return status, nil
+}
+
+func (c *RepoCache) inUse(key string) bool {
+	file, err := os.OpenFile(c.lockPath(key), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return true
+	}
+	_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	return false
+}
+
+// Evict removes the least recently used repos until the cache fits into the max size,
+// the repos in use are skipped. It returns the keys of the removed repos.
+func (c *RepoCache) Evict() ([]string, errors.Error) {
+	if c.maxSize <= 0 {
+		return nil, nil
+	}
+	status, err := c.Status()
+	if err != nil {
+		return nil, err
+	}
+	var evicted []string
+	size := status.Size
+	for _, entry := range status.Entries {
+		if size <= c.maxSize {
+			break
+		}
+		lock, err := c.tryLockExclusive(entry.Key)
+		if err != nil {
+			return evicted, err
+		}
+		if lock == nil {
+			continue
+		}
+		err = c.remove(entry.Key)
+		lock.Release()
+		if err != nil {
+			return evicted, err
+		}
+		size -= entry.Size
+		evicted = append(evicted, entry.Key)
+	}
+	return evicted, nil
+}
+
+func (c *RepoCache) tryLockExclusive(key string) (*Lock, errors.Error) {
+	file, err := os.OpenFile(c.lockPath(key), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to open the repo cache lock")
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, nil
+		}
+		return nil, errors.Default.Wrap(err, "failed to lock the repo cache")
+	}
+	return &Lock{file: file}, nil
+}
+
+func dirSize(dir string) (int64, errors.Error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Default.Wrap(err, "failed to calculate the size of the cached repo")
+	}
+	return size, nil
+}
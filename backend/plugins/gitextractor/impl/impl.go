@@ -21,10 +21,12 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/apache/incubator-devlake/core/context"
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/plugin"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/gitextractor/api"
 	"github.com/apache/incubator-devlake/plugins/gitextractor/parser"
 	"github.com/apache/incubator-devlake/plugins/gitextractor/tasks"
 	giturls "github.com/chainguard-dev/git-urls"
@@ -32,12 +34,19 @@ import (
 
 var _ interface {
 	plugin.PluginMeta
+	plugin.PluginInit
 	plugin.PluginTask
 	plugin.PluginModel
+	plugin.PluginApi
 } = (*GitExtractor)(nil)
 
 type GitExtractor struct{}
 
+func (p GitExtractor) Init(basicRes context.BasicRes) errors.Error {
+	api.Init(basicRes)
+	return nil
+}
+
 type DynamicGitUrl interface {
 	GetDynamicGitUrl(taskCtx plugin.TaskContext, connectionId uint64, repoUrl string) (string, errors.Error)
 }
@@ -61,6 +70,7 @@ func (p GitExtractor) SubTaskMetas() []plugin.SubTaskMeta {
 		tasks.CollectGitCommitMeta,
 		tasks.CollectGitBranchMeta,
 		tasks.CollectGitTagMeta,
+		tasks.CollectGitCodeOwnersMeta,
 		tasks.CollectGitDiffLineMeta,
 	}
 }
@@ -146,6 +156,14 @@ func (p GitExtractor) RootPkgPath() string {
 	return "github.com/apache/incubator-devlake/plugins/gitextractor"
 }
 
+func (p GitExtractor) ApiResources() map[string]map[string]plugin.ApiResourceHandler {
+	return map[string]map[string]plugin.ApiResourceHandler{
+		"cache": {
+			"GET": api.GetCacheStatus,
+		},
+	}
+}
+
 func (p GitExtractor) TestConnection(id uint64) errors.Error {
 	return nil
 }
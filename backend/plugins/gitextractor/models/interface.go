@@ -32,5 +32,7 @@ type Store interface {
 	CommitFileComponents(commitFileComponent *code.CommitFileComponent) errors.Error
 	CommitLineChange(commitLineChange *code.CommitLineChange) errors.Error
 	RepoSnapshot(snapshot *code.RepoSnapshot) errors.Error
+	CodeOwnersFiles(codeOwnersFile *code.CodeOwnersFile) errors.Error
+	CodeOwnerRules(rule *code.CodeOwnerRule) errors.Error
 	Close() errors.Error
 }
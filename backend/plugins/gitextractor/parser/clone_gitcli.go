@@ -30,6 +30,7 @@ import (
 	"github.com/apache/incubator-devlake/core/log"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/gitextractor/cache"
 	giturls "github.com/chainguard-dev/git-urls"
 )
 
@@ -56,6 +57,8 @@ type GitcliCloner struct {
 	success      bool
 	syncEnvs     []string
 	syncArgs     []string
+	cache        *cache.RepoCache
+	cacheLock    *cache.Lock
 }
 
 func NewGitcliCloner(ctx plugin.SubTaskContext, localDir string) (*GitcliCloner, errors.Error) {
@@ -81,6 +84,13 @@ func NewGitcliCloner(ctx plugin.SubTaskContext, localDir string) (*GitcliCloner,
 		localDir:     localDir,
 		success:      false,
 	}
+	if !taskData.Options.NoRepoCache {
+		repoCache, err := cache.NewRepoCacheFromConfig(ctx.GetConfigReader())
+		if err != nil {
+			return nil, err
+		}
+		cloner.cache = repoCache
+	}
 	return cloner, cloner.prepareSync()
 }
 
@@ -164,6 +174,9 @@ func (g *GitcliCloner) IsIncremental() bool {
 }
 
 func (g *GitcliCloner) CloneRepo() errors.Error {
+	if g.cache != nil {
+		return g.cachedClone()
+	}
 	if g.since == nil {
 		// full sync
 		if err := g.fullClone(); err != nil {
@@ -192,6 +205,7 @@ func (g *GitcliCloner) CloneRepo() errors.Error {
 }
 
 func (g *GitcliCloner) CloseRepo() errors.Error {
+	g.cacheLock.Release()
 	if g.success {
 		g.logger.Info("save state")
 		return g.stateManager.Close()
@@ -199,6 +213,82 @@ func (g *GitcliCloner) CloseRepo() errors.Error {
 	return nil
 }
 
+// cachedClone updates the mirror of the repo in the cache and clones from it, the mirror stays locked in shared mode
+// until the repo is closed, so it won't be updated or evicted by other pipelines while being collected
+func (g *GitcliCloner) cachedClone() errors.Error {
+	ctx := g.ctx.GetContext()
+	lock, err := g.cache.LockExclusive(ctx, g.remoteUrl)
+	if err != nil {
+		return err
+	}
+	g.cacheLock = lock
+	mirrorDir := g.cache.MirrorDir(g.remoteUrl)
+	if err := g.updateMirror(mirrorDir); err != nil {
+		return err
+	}
+	if err := g.cache.Touch(g.remoteUrl); err != nil {
+		g.logger.Warn(err, "failed to update the repo cache metadata")
+	}
+	if err := lock.Downgrade(ctx); err != nil {
+		return err
+	}
+	if evicted, err := g.cache.Evict(); err != nil {
+		g.logger.Warn(err, "failed to evict repos from the cache")
+	} else if len(evicted) > 0 {
+		g.logger.Info("evicted %d repos from the cache", len(evicted))
+	}
+
+	if g.since == nil {
+		// full sync, borrow the objects from the mirror instead of copying them
+		return g.git(nil, "", "clone", "--bare", "--shared", mirrorDir, g.localDir)
+	}
+	// the mirror is local, so shallow clone is always supported
+	backup := g.remoteUrl
+	g.remoteUrl = fmt.Sprintf("file://%s", mirrorDir)
+	defer func() { g.remoteUrl = backup }()
+	if err := g.shallowClone(); err != nil {
+		return err
+	}
+	if err := g.deepen(); err != nil {
+		return err
+	}
+	g.success = true
+	return nil
+}
+
+// updateMirror fetches the new commits into the mirror, or clones the mirror if it doesn't exist or fails to be fetched
+func (g *GitcliCloner) updateMirror(mirrorDir string) errors.Error {
+	if _, e := os.Stat(mirrorDir); e == nil {
+		err := g.git(g.syncEnvs, mirrorDir, "fetch", "--prune", "--tags", g.remoteUrl, "+refs/heads/*:refs/heads/*")
+		if err == nil {
+			g.logger.Info("updated the cached repo %s", mirrorDir)
+			return nil
+		}
+		g.logger.Warn(err, "failed to update the cached repo, clone it again")
+		if err := g.cache.Remove(g.remoteUrl); err != nil {
+			return err
+		}
+	}
+	tmpDir := mirrorDir + ".tmp"
+	_ = os.RemoveAll(tmpDir)
+	// the mirror is always a full clone so it can serve all kinds of syncs
+	if err := g.git(g.syncEnvs, "", "clone", "--bare", g.remoteUrl, tmpDir); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return err
+	}
+	// the credentials should not be persisted, the url is given on every fetch
+	if err := g.gitCmdIn(tmpDir, "remote", "set-url", "origin", cache.SanitizeUrl(g.remoteUrl)); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return err
+	}
+	if err := os.Rename(tmpDir, mirrorDir); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return errors.Default.Wrap(err, "failed to move the cloned repo into the cache")
+	}
+	g.logger.Info("cloned the repo into the cache %s", mirrorDir)
+	return nil
+}
+
 func (g *GitcliCloner) fullClone() errors.Error {
 	return g.gitClone(g.remoteUrl, g.localDir, "--bare")
 }
@@ -299,7 +389,11 @@ func (g *GitcliCloner) repoIsEmpty(args ...string) (bool, errors.Error) {
 }
 
 func (g *GitcliCloner) gitCmd(gitcmd string, args ...string) errors.Error {
-	return g.git(nil, g.localDir, gitcmd, args...)
+	return g.gitCmdIn(g.localDir, gitcmd, args...)
+}
+
+func (g *GitcliCloner) gitCmdIn(dir string, gitcmd string, args ...string) errors.Error {
+	return g.git(nil, dir, gitcmd, args...)
 }
 
 func (g *GitcliCloner) git(env []string, dir string, gitcmd string, args ...string) errors.Error {
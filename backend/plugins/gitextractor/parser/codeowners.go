@@ -0,0 +1,207 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/utils"
+	"github.com/apache/incubator-devlake/plugins/gitextractor/models"
+)
+
+// CodeOwnersPaths are the locations of the CODEOWNERS file, the first existing one is used like GitHub does
+var CodeOwnersPaths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+// CodeOwnersLine is a rule parsed from a CODEOWNERS file
+type CodeOwnersLine struct {
+	LineNumber int
+	Section    string
+	Pattern    string
+	Owners     []string
+}
+
+// ParseCodeOwners parses a CODEOWNERS file of GitHub or GitLab.
+// Rules without owners inherit the default owners of their GitLab section, or unset the owners when there are none.
+func ParseCodeOwners(content []byte) []*CodeOwnersLine {
+	var rules []*CodeOwnersLine
+	section := ""
+	var sectionOwners []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// GitLab section header, i.e. `[Section]`, `^[Optional Section]` or `[Section][2] @default-owner`
+		if strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			section = line[strings.Index(line, "[")+1 : end]
+			rest := line[end+1:]
+			if strings.HasPrefix(rest, "[") {
+				if approvalsEnd := strings.Index(rest, "]"); approvalsEnd >= 0 {
+					rest = rest[approvalsEnd+1:]
+				}
+			}
+			sectionOwners = parseOwners(strings.Fields(rest))
+			continue
+		}
+		fields := splitCodeOwnersFields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		owners := parseOwners(fields[1:])
+		if len(owners) == 0 {
+			owners = sectionOwners
+		}
+		rules = append(rules, &CodeOwnersLine{
+			LineNumber: lineNumber,
+			Section:    section,
+			Pattern:    fields[0],
+			Owners:     owners,
+		})
+	}
+	return rules
+}
+
+// splitCodeOwnersFields splits the line by whitespaces, keeping the spaces escaped by `\` in the pattern
+func splitCodeOwnersFields(line string) []string {
+	var fields []string
+	var field strings.Builder
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ' ' || r == '\t':
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+func parseOwners(fields []string) []string {
+	var owners []string
+	for _, field := range fields {
+		if strings.HasPrefix(field, "#") {
+			break
+		}
+		owners = append(owners, field)
+	}
+	return owners
+}
+
+// collectCodeOwners stores every version of the CODEOWNERS file found in the history of HEAD
+func collectCodeOwners(ctx context.Context, localDir string, repoId string, store models.Store, logger log.Logger) errors.Error {
+	output, err := gitOutput(ctx, localDir, append([]string{"log", "--format=%H %cI", "--"}, CodeOwnersPaths...)...)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		sha := fields[0]
+		committedDate, e := time.Parse(time.RFC3339, fields[1])
+		if e != nil {
+			return errors.Convert(e)
+		}
+		codeOwnersFile := &code.CodeOwnersFile{
+			RepoId:        repoId,
+			CommitSha:     sha,
+			CommittedDate: committedDate,
+		}
+		output, err = gitOutput(ctx, localDir, append([]string{"ls-tree", "--name-only", sha, "--"}, CodeOwnersPaths...)...)
+		if err != nil {
+			return err
+		}
+		existing := strings.Fields(string(output))
+		for _, path := range CodeOwnersPaths {
+			if utils.StringsContains(existing, path) {
+				codeOwnersFile.FilePath = path
+				break
+			}
+		}
+		if codeOwnersFile.FilePath != "" {
+			content, err := gitOutput(ctx, localDir, "show", sha+":"+codeOwnersFile.FilePath)
+			if err != nil {
+				return err
+			}
+			rules := ParseCodeOwners(content)
+			for _, rule := range rules {
+				err = store.CodeOwnerRules(&code.CodeOwnerRule{
+					RepoId:     repoId,
+					CommitSha:  sha,
+					LineNumber: rule.LineNumber,
+					Section:    rule.Section,
+					Pattern:    rule.Pattern,
+					Owners:     strings.Join(rule.Owners, " "),
+				})
+				if err != nil {
+					return err
+				}
+			}
+			codeOwnersFile.RuleCount = len(rules)
+		}
+		logger.Debug("found %s with %d rules at %s", codeOwnersFile.FilePath, codeOwnersFile.RuleCount, sha)
+		err = store.CodeOwnersFiles(codeOwnersFile)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func gitOutput(ctx context.Context, dir string, args ...string) ([]byte, errors.Error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "git "+args[0]+" failed")
+	}
+	return output, nil
+}
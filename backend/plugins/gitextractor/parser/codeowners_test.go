@@ -0,0 +1,48 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	content := []byte(`# comment
+* @org/core
+
+/docs/ @alice docs@example.com # inline comment
+my\ file.txt @bob
+/vendor/
+
+[Frontend][2] @org/frontend
+web/
+^[Optional] @carol
+*.md @dave
+`)
+	rules := ParseCodeOwners(content)
+	assert.Equal(t, []*CodeOwnersLine{
+		{LineNumber: 2, Pattern: "*", Owners: []string{"@org/core"}},
+		{LineNumber: 4, Pattern: "/docs/", Owners: []string{"@alice", "docs@example.com"}},
+		{LineNumber: 5, Pattern: "my file.txt", Owners: []string{"@bob"}},
+		{LineNumber: 6, Pattern: "/vendor/"},
+		{LineNumber: 9, Section: "Frontend", Pattern: "web/", Owners: []string{"@org/frontend"}},
+		{LineNumber: 11, Section: "Optional", Pattern: "*.md", Owners: []string{"@dave"}},
+	}, rules)
+}
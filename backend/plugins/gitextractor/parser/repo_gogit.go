@@ -39,11 +39,12 @@ import (
 )
 
 type GogitRepoCollector struct {
-	id      string
-	logger  log.Logger
-	store   models.Store
-	repo    *gogit.Repository
-	cleanUp func()
+	id       string
+	logger   log.Logger
+	store    models.Store
+	repo     *gogit.Repository
+	localDir string
+	cleanUp  func()
 }
 
 func NewGogitRepoCollector(localDir string, repoId string, store models.Store, logger log.Logger) (*GogitRepoCollector, errors.Error) {
@@ -52,10 +53,11 @@ func NewGogitRepoCollector(localDir string, repoId string, store models.Store, l
 		return nil, errors.Convert(err)
 	}
 	return &GogitRepoCollector{
-		id:     repoId,
-		logger: logger,
-		store:  store,
-		repo:   repo,
+		id:       repoId,
+		logger:   logger,
+		store:    store,
+		repo:     repo,
+		localDir: localDir,
 	}, nil
 }
 
@@ -91,6 +93,10 @@ func (r *GogitRepoCollector) CollectAll(subtaskCtx plugin.SubTaskContext) error
 	if err != nil {
 		return err
 	}
+	err = r.CollectCodeOwners(subtaskCtx)
+	if err != nil {
+		return err
+	}
 	return r.CollectDiffLine(subtaskCtx)
 }
 
@@ -614,3 +620,8 @@ func (r *GogitRepoCollector) CollectDiffLine(subtaskCtx plugin.SubTaskContext) e
 	// So we just ignore it.
 	return nil
 }
+
+// CollectCodeOwners stores the versions of the CODEOWNERS file
+func (r *GogitRepoCollector) CollectCodeOwners(subtaskCtx plugin.SubTaskContext) error {
+	return collectCodeOwners(subtaskCtx.GetContext(), r.localDir, r.id, r.store, r.logger)
+}
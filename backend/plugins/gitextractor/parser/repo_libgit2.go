@@ -46,9 +46,10 @@ type Libgit2RepoCollector struct {
 	id     string
 	logger log.Logger
 
-	store   models.Store
-	repo    *git.Repository
-	cleanup func()
+	store    models.Store
+	repo     *git.Repository
+	localDir string
+	cleanup  func()
 }
 
 func NewLibgit2RepoCollector(localDir string, repoId string, store models.Store, logger log.Logger) (*Libgit2RepoCollector, errors.Error) {
@@ -57,10 +58,11 @@ func NewLibgit2RepoCollector(localDir string, repoId string, store models.Store,
 		return nil, errors.Convert(err)
 	}
 	return &Libgit2RepoCollector{
-		id:     repoId,
-		logger: logger,
-		store:  store,
-		repo:   repo,
+		id:       repoId,
+		logger:   logger,
+		store:    store,
+		repo:     repo,
+		localDir: localDir,
 	}, nil
 }
 
@@ -86,6 +88,10 @@ func (r *Libgit2RepoCollector) CollectAll(subtaskCtx plugin.SubTaskContext) erro
 	if err != nil {
 		return err
 	}
+	err = r.CollectCodeOwners(subtaskCtx)
+	if err != nil {
+		return err
+	}
 	opt := subtaskCtx.GetData().(*GitExtractorTaskData).Options
 	if !*opt.SkipCommitStat {
 		return r.CollectDiffLine(subtaskCtx)
@@ -93,6 +99,11 @@ func (r *Libgit2RepoCollector) CollectAll(subtaskCtx plugin.SubTaskContext) erro
 	return nil
 }
 
+// CollectCodeOwners stores the versions of the CODEOWNERS file
+func (r *Libgit2RepoCollector) CollectCodeOwners(subtaskCtx plugin.SubTaskContext) error {
+	return collectCodeOwners(subtaskCtx.GetContext(), r.localDir, r.id, r.store, r.logger)
+}
+
 // Close resources
 func (r *Libgit2RepoCollector) Close(ctx context.Context) error {
 	defer func() {
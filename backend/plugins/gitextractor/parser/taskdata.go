@@ -45,8 +45,10 @@ type GitExtractorOptions struct {
 	SkipCommitStat        *bool  `json:"skipCommitStat" mapstructure:"skipCommitStat" comment:"skip all commit stat including added/deleted lines and commit files as well"`
 	SkipCommitFiles       *bool  `json:"skipCommitFiles" mapstructure:"skipCommitFiles"`
 	NoShallowClone        bool   `json:"noShallowClone" mapstructure:"noShallowClone"`
-	ConnectionId          uint64 `json:"connectionId" mapstructure:"connectionId,omitempty"`
-	PluginName            string `json:"pluginName" mapstructure:"pluginName,omitempty"`
+	// NoRepoCache skips the repo cache configured by GIT_EXTRACTOR_CACHE_DIR
+	NoRepoCache  bool   `json:"noRepoCache" mapstructure:"noRepoCache"`
+	ConnectionId uint64 `json:"connectionId" mapstructure:"connectionId,omitempty"`
+	PluginName   string `json:"pluginName" mapstructure:"pluginName,omitempty"`
 	// Configured by upstream plugin (e.g., GitLab) to exclude file extensions from commit stats
 	ExcludeFileExtensions []string `json:"excludeFileExtensions" mapstructure:"excludeFileExtensions"`
 }
@@ -86,6 +86,8 @@ type CsvStore struct {
 	commitFileComponentWriter *csvWriter
 	commitLineChangeWriter    *csvWriter
 	snapshotWriter            *csvWriter
+	codeOwnersFileWriter      *csvWriter
+	codeOwnerRuleWriter       *csvWriter
 }
 
 func NewCsvStore(dir string) (*CsvStore, errors.Error) {
@@ -129,6 +131,14 @@ func NewCsvStore(dir string) (*CsvStore, errors.Error) {
 	if err != nil {
 		return nil, errors.Convert(err)
 	}
+	s.codeOwnersFileWriter, err = newCsvWriter(filepath.Join(dir, "code_owners_files.csv"), code.CodeOwnersFile{})
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	s.codeOwnerRuleWriter, err = newCsvWriter(filepath.Join(dir, "code_owner_rules.csv"), code.CodeOwnerRule{})
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
 	return s, nil
 }
 
@@ -163,6 +173,14 @@ func (c *CsvStore) RepoSnapshot(ss *code.RepoSnapshot) errors.Error {
 	return c.snapshotWriter.Write(ss)
 }
 
+func (c *CsvStore) CodeOwnersFiles(codeOwnersFile *code.CodeOwnersFile) errors.Error {
+	return c.codeOwnersFileWriter.Write(codeOwnersFile)
+}
+
+func (c *CsvStore) CodeOwnerRules(rule *code.CodeOwnerRule) errors.Error {
+	return c.codeOwnerRuleWriter.Write(rule)
+}
+
 func (c *CsvStore) CommitParents(pp []*code.CommitParent) errors.Error {
 	var err error
 	for _, p := range pp {
@@ -199,5 +217,11 @@ func (c *CsvStore) Close() errors.Error {
 	if c.commitLineChangeWriter != nil {
 		c.commitLineChangeWriter.Close()
 	}
+	if c.codeOwnersFileWriter != nil {
+		c.codeOwnersFileWriter.Close()
+	}
+	if c.codeOwnerRuleWriter != nil {
+		c.codeOwnerRuleWriter.Close()
+	}
 	return nil
 }
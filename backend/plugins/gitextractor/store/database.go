@@ -142,6 +142,24 @@ func (d *Database) CommitLineChange(commitLineChange *code.CommitLineChange) err
 	return batch.Add(commitLineChange)
 }
 
+func (d *Database) CodeOwnersFiles(codeOwnersFile *code.CodeOwnersFile) errors.Error {
+	batch, err := d.driver.ForType(reflect.TypeOf(codeOwnersFile))
+	if err != nil {
+		return err
+	}
+	d.updateRawDataFields(&codeOwnersFile.RawDataOrigin)
+	return batch.Add(codeOwnersFile)
+}
+
+func (d *Database) CodeOwnerRules(rule *code.CodeOwnerRule) errors.Error {
+	batch, err := d.driver.ForType(reflect.TypeOf(rule))
+	if err != nil {
+		return err
+	}
+	d.updateRawDataFields(&rule.RawDataOrigin)
+	return batch.Add(rule)
+}
+
 func (d *Database) CommitParents(pp []*code.CommitParent) errors.Error {
 	if len(pp) == 0 {
 		return nil
@@ -60,6 +60,9 @@ func CloneGitRepo(subTaskCtx plugin.SubTaskContext) errors.Error {
 	}
 	err = repoCloner.CloneRepo()
 	if err != nil {
+		// release the lock of the repo cache if any
+		_ = os.RemoveAll(localDir)
+		_ = repoCloner.CloseRepo()
 		if errors.Is(err, parser.ErrNoData) {
 			taskData.SkipAllSubtasks = true
 			return nil
@@ -69,6 +72,12 @@ func CloneGitRepo(subTaskCtx plugin.SubTaskContext) errors.Error {
 	if repoCloner.IsIncremental() {
 		storage.SetIncrementalMode(repoCloner.IsIncremental())
 	}
+	// remove the cloned dir and release the lock of the repo cache, it must be called on every path the collector
+	// doesn't take it over, or later pipelines of the repo would be blocked by the lock
+	cleanup := func() {
+		_ = os.RemoveAll(localDir)
+		_ = repoCloner.CloseRepo()
+	}
 	// We have done comparison experiments for git2go and go-git, and the results show that git2go has better performance.
 	var repoCollector parser.RepoCollector
 	if *taskData.Options.UseGoGit {
@@ -77,15 +86,13 @@ func CloneGitRepo(subTaskCtx plugin.SubTaskContext) errors.Error {
 		repoCollector, err = parser.NewLibgit2RepoCollector(localDir, op.RepoId, storage, logger)
 	}
 	if err != nil {
+		cleanup()
 		return err
 	}
 
 	// inject clean up callback to remove the cloned dir
-	cleanup := func() {
-		_ = os.RemoveAll(localDir)
-		_ = repoCloner.CloseRepo()
-	}
 	if e := repoCollector.SetCleanUp(cleanup); e != nil {
+		cleanup()
 		return errors.Convert(e)
 	}
 
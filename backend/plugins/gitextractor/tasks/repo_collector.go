@@ -81,6 +81,15 @@ func CollectGitDiffLines(subTaskCtx plugin.SubTaskContext) errors.Error {
 	return nil
 }
 
+func CollectGitCodeOwners(subTaskCtx plugin.SubTaskContext) errors.Error {
+	if subTaskCtx.TaskContext().GetData().(*parser.GitExtractorTaskData).SkipAllSubtasks {
+		return nil
+	}
+	repo := getGitRepo(subTaskCtx)
+	subTaskCtx.SetProgress(0, -1)
+	return errors.Convert(repo.CollectCodeOwners(subTaskCtx))
+}
+
 func getGitRepo(subTaskCtx plugin.SubTaskContext) parser.RepoCollector {
 	taskData, ok := subTaskCtx.GetData().(*parser.GitExtractorTaskData)
 	if !ok {
@@ -129,3 +138,12 @@ var CollectGitDiffLineMeta = plugin.SubTaskMeta{
 	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE},
 	Dependencies:     []*plugin.SubTaskMeta{&CloneGitRepoMeta},
 }
+
+var CollectGitCodeOwnersMeta = plugin.SubTaskMeta{
+	Name:             "Collect CodeOwners",
+	EntryPoint:       CollectGitCodeOwners,
+	EnabledByDefault: true,
+	Description:      "collect the history of the CODEOWNERS file into Domain Layer Tables",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE},
+	Dependencies:     []*plugin.SubTaskMeta{&CloneGitRepoMeta},
+}
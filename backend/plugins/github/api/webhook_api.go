@@ -0,0 +1,174 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	coreModels "github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/server/services"
+)
+
+var webhookDebouncer *helper.WebhookDebouncer
+
+type githubWebhookPayload struct {
+	Action     string `json:"action"`
+	Repository *struct {
+		Id       uint64 `json:"id"`
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// githubWebhookEntities returns the entities to recollect for a GitHub event, nil if the event is not handled
+func githubWebhookEntities(event string, action string) []string {
+	switch event {
+	case "push":
+		return []string{plugin.DOMAIN_TYPE_CODE}
+	case "pull_request":
+		return []string{plugin.DOMAIN_TYPE_CODE_REVIEW}
+	case "deployment", "deployment_status":
+		return []string{plugin.DOMAIN_TYPE_CICD}
+	case "workflow_run":
+		// only a finished run carries its result
+		if action == "completed" {
+			return []string{plugin.DOMAIN_TYPE_CICD}
+		}
+	}
+	return nil
+}
+
+// PostWebhook receive events from a GitHub webhook
+// @Summary receive events from a GitHub webhook
+// @Description Verify the X-Hub-Signature-256 header with the webhook secret of the connection, then schedule
+// @Description an incremental collection of the repo for the entities affected by push, pull_request, deployment and workflow_run events.
+// @Description Events of the same repo are debounced (WEBHOOK_DEBOUNCE_SECONDS) so bursts coalesce into one pipeline.
+// @Tags plugins/github
+// @Param connectionId path int true "connection ID"
+// @Success 202  {object} helper.WebhookReceipt
+// @Success 200  {object} helper.WebhookReceipt "the event is ignored"
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 403  {object} shared.ApiBody "Forbidden"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router /plugins/github/connections/{connectionId}/webhook [POST]
+func PostWebhook(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection, err := dsHelper.ConnApi.FindByPk(input)
+	if err != nil {
+		return nil, err
+	}
+	if input.Request == nil || input.Request.Body == nil {
+		return nil, errors.BadInput.New("request body is empty")
+	}
+	payload, err := errors.Convert01(io.ReadAll(input.Request.Body))
+	if err != nil {
+		return nil, err
+	}
+	if connection.WebhookSecret == "" {
+		return nil, errors.Forbidden.New("webhook secret is not configured for the connection")
+	}
+	if !helper.VerifyHmacSha256Signature(connection.WebhookSecret, payload, input.Request.Header.Get("X-Hub-Signature-256")) {
+		return nil, errors.Forbidden.New("invalid webhook signature")
+	}
+
+	event := input.Request.Header.Get("X-GitHub-Event")
+	receipt := &helper.WebhookReceipt{Event: event, Status: helper.WebhookReceiptIgnored}
+	body := &githubWebhookPayload{}
+	if err := errors.Convert(json.Unmarshal(payload, body)); err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid webhook payload")
+	}
+	entities := githubWebhookEntities(event, body.Action)
+	if entities == nil || body.Repository == nil {
+		receipt.Reason = "event is not handled"
+		return &plugin.ApiResourceOutput{Body: receipt, Status: http.StatusOK}, nil
+	}
+	receipt.ScopeId = strconv.FormatUint(body.Repository.Id, 10)
+	scopeDetail, err := dsHelper.ScopeSrv.GetScopeDetail(false, connection.ID, receipt.ScopeId)
+	if err != nil {
+		if err.GetType() == errors.NotFound {
+			receipt.Reason = fmt.Sprintf("repo %s is not a scope of the connection", body.Repository.FullName)
+			return &plugin.ApiResourceOutput{Body: receipt, Status: http.StatusOK}, nil
+		}
+		return nil, err
+	}
+	if scopeDetail.ScopeConfig != nil && len(scopeDetail.ScopeConfig.Entities) > 0 {
+		entities = helper.IntersectEntities(entities, scopeDetail.ScopeConfig.Entities)
+	}
+	if len(entities) == 0 {
+		receipt.Reason = "the scope config doesn't collect the affected entities"
+		return &plugin.ApiResourceOutput{Body: receipt, Status: http.StatusOK}, nil
+	}
+
+	connectionId, scopeId := connection.ID, receipt.ScopeId
+	webhookDebouncer.Trigger(fmt.Sprintf("%d:%s", connectionId, scopeId), entities, func(entities []string) {
+		if err := runWebhookPipeline(connectionId, scopeId, entities); err != nil {
+			basicRes.GetLogger().Error(err, "failed to run webhook pipeline for github repo %s", scopeId)
+		}
+	})
+	receipt.Entities = entities
+	receipt.Status = helper.WebhookReceiptScheduled
+	return &plugin.ApiResourceOutput{Body: receipt, Status: http.StatusAccepted}, nil
+}
+
+// runWebhookPipeline creates a pipeline collecting only the given entities of the repo, incrementally
+func runWebhookPipeline(connectionId uint64, scopeId string, entities []string) errors.Error {
+	p, err := plugin.GetPlugin("github")
+	if err != nil {
+		return err
+	}
+	pluginTask, ok := p.(plugin.PluginTask)
+	if !ok {
+		return errors.Default.New("plugin github does not support SubTaskMetas")
+	}
+	connection, err := dsHelper.ConnSrv.FindByPk(connectionId)
+	if err != nil {
+		return err
+	}
+	scopeDetails, err := dsHelper.ScopeSrv.MapScopeDetails(connectionId, []*coreModels.BlueprintScope{{ScopeId: scopeId}})
+	if err != nil {
+		return err
+	}
+	// needed for the connection to populate its access tokens
+	// if AppKey authentication method is selected
+	_, err = helper.NewApiClientFromConnection(context.TODO(), basicRes, connection)
+	if err != nil {
+		return err
+	}
+	scopeConfig := scopeDetails[0].ScopeConfig
+	scopeConfig.Entities = entities
+	if !utils.StringsContains(entities, plugin.DOMAIN_TYPE_CODE) {
+		scopeConfig.Refdiff = nil
+	}
+	plan, err := makeDataSourcePipelinePlanV200(pluginTask.SubTaskMetas(), scopeDetails, connection)
+	if err != nil {
+		return err
+	}
+	_, err = services.CreatePipeline(&coreModels.NewPipeline{
+		Name:   fmt.Sprintf("github webhook: %s", scopeDetails[0].Scope.FullName),
+		Plan:   plan,
+		Labels: []string{"webhook"},
+	}, false)
+	return err
+}
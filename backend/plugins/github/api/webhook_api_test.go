@@ -0,0 +1,34 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGithubWebhookEntities(t *testing.T) {
+	assert.Equal(t, []string{plugin.DOMAIN_TYPE_CODE}, githubWebhookEntities("push", ""))
+	assert.Equal(t, []string{plugin.DOMAIN_TYPE_CODE_REVIEW}, githubWebhookEntities("pull_request", "closed"))
+	assert.Equal(t, []string{plugin.DOMAIN_TYPE_CICD}, githubWebhookEntities("deployment_status", "created"))
+	assert.Equal(t, []string{plugin.DOMAIN_TYPE_CICD}, githubWebhookEntities("workflow_run", "completed"))
+	assert.Nil(t, githubWebhookEntities("workflow_run", "in_progress"))
+	assert.Nil(t, githubWebhookEntities("ping", ""))
+}
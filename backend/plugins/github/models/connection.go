@@ -99,6 +99,8 @@ type GithubConnection struct {
 	helper.BaseConnection `mapstructure:",squash"`
 	GithubConn            `mapstructure:",squash"`
 	EnableGraphql         bool `mapstructure:"enableGraphql" json:"enableGraphql"`
+	// WebhookSecret verifies the signature of the events sent to the webhook receiver
+	WebhookSecret string `mapstructure:"webhookSecret" json:"webhookSecret" gorm:"serializer:encdec"`
 }
 
 const (
@@ -141,6 +143,9 @@ func (connection *GithubConnection) Merge(existed, modified *GithubConnection, b
 	existed.Proxy = modified.Proxy
 	existed.Endpoint = modified.Endpoint
 	existed.RateLimitPerHour = modified.RateLimitPerHour
+	if modified.WebhookSecret != "" && modified.WebhookSecret != utils.SanitizeString(existed.WebhookSecret) {
+		existed.WebhookSecret = modified.WebhookSecret
+	}
 
 	// handle secret
 	if existSecretKey == "" {
@@ -266,6 +271,7 @@ func (conn *GithubConn) typeIs(token string) string {
 
 func (connection GithubConnection) Sanitize() GithubConnection {
 	connection.GithubConn = connection.GithubConn.Sanitize()
+	connection.WebhookSecret = utils.SanitizeString(connection.WebhookSecret)
 	return connection
 }
 
@@ -0,0 +1,169 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	coreModels "github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/server/services"
+)
+
+var webhookDebouncer *helper.WebhookDebouncer
+
+type gitlabWebhookPayload struct {
+	Project *struct {
+		Id                int    `json:"id"`
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		Status string `json:"status"`
+	} `json:"object_attributes"`
+}
+
+// gitlabWebhookEntities returns the entities to recollect for a GitLab event, nil if the event is not handled
+func gitlabWebhookEntities(event string, payload *gitlabWebhookPayload) []string {
+	switch event {
+	case "Push Hook", "Tag Push Hook":
+		return []string{plugin.DOMAIN_TYPE_CODE}
+	case "Merge Request Hook":
+		return []string{plugin.DOMAIN_TYPE_CODE_REVIEW}
+	case "Deployment Hook":
+		return []string{plugin.DOMAIN_TYPE_CICD}
+	case "Pipeline Hook":
+		// only a finished pipeline carries its result
+		if utils.StringsContains([]string{"success", "failed", "canceled", "skipped"}, payload.ObjectAttributes.Status) {
+			return []string{plugin.DOMAIN_TYPE_CICD}
+		}
+	}
+	return nil
+}
+
+// PostWebhook receive events from a GitLab webhook
+// @Summary receive events from a GitLab webhook
+// @Description Verify the X-Gitlab-Token header with the webhook secret of the connection, then schedule
+// @Description an incremental collection of the project for the entities affected by push, merge request, deployment and pipeline events.
+// @Description Events of the same project are debounced (WEBHOOK_DEBOUNCE_SECONDS) so bursts coalesce into one pipeline.
+// @Tags plugins/gitlab
+// @Param connectionId path int true "connection ID"
+// @Success 202  {object} helper.WebhookReceipt
+// @Success 200  {object} helper.WebhookReceipt "the event is ignored"
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 403  {object} shared.ApiBody "Forbidden"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router /plugins/gitlab/connections/{connectionId}/webhook [POST]
+func PostWebhook(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection, err := dsHelper.ConnApi.FindByPk(input)
+	if err != nil {
+		return nil, err
+	}
+	if input.Request == nil || input.Request.Body == nil {
+		return nil, errors.BadInput.New("request body is empty")
+	}
+	if connection.WebhookSecret == "" {
+		return nil, errors.Forbidden.New("webhook secret is not configured for the connection")
+	}
+	if !helper.VerifyWebhookToken(connection.WebhookSecret, input.Request.Header.Get("X-Gitlab-Token")) {
+		return nil, errors.Forbidden.New("invalid webhook token")
+	}
+	payload, err := errors.Convert01(io.ReadAll(input.Request.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	event := input.Request.Header.Get("X-Gitlab-Event")
+	receipt := &helper.WebhookReceipt{Event: event, Status: helper.WebhookReceiptIgnored}
+	body := &gitlabWebhookPayload{}
+	if err := errors.Convert(json.Unmarshal(payload, body)); err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid webhook payload")
+	}
+	entities := gitlabWebhookEntities(event, body)
+	if entities == nil || body.Project == nil {
+		receipt.Reason = "event is not handled"
+		return &plugin.ApiResourceOutput{Body: receipt, Status: http.StatusOK}, nil
+	}
+	receipt.ScopeId = strconv.Itoa(body.Project.Id)
+	scopeDetail, err := dsHelper.ScopeSrv.GetScopeDetail(false, connection.ID, receipt.ScopeId)
+	if err != nil {
+		if err.GetType() == errors.NotFound {
+			receipt.Reason = fmt.Sprintf("project %s is not a scope of the connection", body.Project.PathWithNamespace)
+			return &plugin.ApiResourceOutput{Body: receipt, Status: http.StatusOK}, nil
+		}
+		return nil, err
+	}
+	if scopeDetail.ScopeConfig != nil && len(scopeDetail.ScopeConfig.Entities) > 0 {
+		entities = helper.IntersectEntities(entities, scopeDetail.ScopeConfig.Entities)
+	}
+	if len(entities) == 0 {
+		receipt.Reason = "the scope config doesn't collect the affected entities"
+		return &plugin.ApiResourceOutput{Body: receipt, Status: http.StatusOK}, nil
+	}
+
+	connectionId, scopeId := connection.ID, receipt.ScopeId
+	webhookDebouncer.Trigger(fmt.Sprintf("%d:%s", connectionId, scopeId), entities, func(entities []string) {
+		if err := runWebhookPipeline(connectionId, scopeId, entities); err != nil {
+			basicRes.GetLogger().Error(err, "failed to run webhook pipeline for gitlab project %s", scopeId)
+		}
+	})
+	receipt.Entities = entities
+	receipt.Status = helper.WebhookReceiptScheduled
+	return &plugin.ApiResourceOutput{Body: receipt, Status: http.StatusAccepted}, nil
+}
+
+// runWebhookPipeline creates a pipeline collecting only the given entities of the project, incrementally
+func runWebhookPipeline(connectionId uint64, scopeId string, entities []string) errors.Error {
+	p, err := plugin.GetPlugin(pluginName)
+	if err != nil {
+		return err
+	}
+	pluginTask, ok := p.(plugin.PluginTask)
+	if !ok {
+		return errors.Default.New("plugin gitlab does not support SubTaskMetas")
+	}
+	connection, err := dsHelper.ConnSrv.FindByPk(connectionId)
+	if err != nil {
+		return err
+	}
+	scopeDetails, err := dsHelper.ScopeApi.MapScopeDetails(connectionId, []*coreModels.BlueprintScope{{ScopeId: scopeId}})
+	if err != nil {
+		return err
+	}
+	scopeConfig := scopeDetails[0].ScopeConfig
+	scopeConfig.Entities = entities
+	if !utils.StringsContains(entities, plugin.DOMAIN_TYPE_CODE) {
+		scopeConfig.Refdiff = nil
+	}
+	plan, err := makePipelinePlanV200(pluginTask.SubTaskMetas(), connection, scopeDetails)
+	if err != nil {
+		return err
+	}
+	_, err = services.CreatePipeline(&coreModels.NewPipeline{
+		Name:   fmt.Sprintf("gitlab webhook: %s", scopeDetails[0].Scope.PathWithNamespace),
+		Plan:   plan,
+		Labels: []string{"webhook"},
+	}, false)
+	return err
+}
@@ -123,6 +123,8 @@ var _ plugin.ApiConnection = (*GitlabConnection)(nil)
 type GitlabConnection struct {
 	api.BaseConnection `mapstructure:",squash"`
 	GitlabConn         `mapstructure:",squash"`
+	// WebhookSecret is compared with the `X-Gitlab-Token` of the events sent to the webhook receiver
+	WebhookSecret string `mapstructure:"webhookSecret" json:"webhookSecret" gorm:"serializer:encdec"`
 }
 
 // This object conforms to what the frontend currently expects.
@@ -149,11 +151,13 @@ func (GitlabConnection) TableName() string {
 
 func (connection GitlabConnection) Sanitize() GitlabConnection {
 	connection.GitlabConn = connection.GitlabConn.Sanitize()
+	connection.WebhookSecret = utils.SanitizeString(connection.WebhookSecret)
 	return connection
 }
 
 func (connection *GitlabConnection) MergeFromRequest(target *GitlabConnection, body map[string]interface{}) error {
 	token := target.Token
+	webhookSecret := target.WebhookSecret
 	if err := api.DecodeMapStruct(body, target, true); err != nil {
 		return err
 	}
@@ -161,5 +165,9 @@ func (connection *GitlabConnection) MergeFromRequest(target *GitlabConnection, b
 	if modifiedToken == "" || modifiedToken == utils.SanitizeString(token) {
 		target.Token = token
 	}
+	modifiedWebhookSecret := target.WebhookSecret
+	if modifiedWebhookSecret == "" || modifiedWebhookSecret == utils.SanitizeString(webhookSecret) {
+		target.WebhookSecret = webhookSecret
+	}
 	return nil
 }
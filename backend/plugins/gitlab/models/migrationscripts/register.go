@@ -53,5 +53,6 @@ func All() []plugin.MigrationScript {
 		new(changeIssueComponentType),
 		new(addIsChildToPipelines240906),
 		new(addPrSizeExcludedFileExtensions),
+		new(addWebhookSecretToConnection),
 	}
 }
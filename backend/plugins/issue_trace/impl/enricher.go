@@ -90,6 +90,10 @@ func (p IssueTrace) SubTaskMetas() []plugin.SubTaskMeta {
 		tasks.ConvertIssueStatusHistoryMeta,
 		// issue_assignee_history
 		tasks.ConvertIssueAssigneeHistoryMeta,
+		// issue_status_time, issue_flow_metrics and board_flow_snapshots
+		tasks.CalculateIssueFlowMetricsMeta,
+		// sprint_issue_history and sprint_scope_metrics
+		tasks.ConvertSprintIssueHistoryMeta,
 	}
 }
 
@@ -109,7 +113,7 @@ func (p IssueTrace) PrepareTaskData(taskCtx plugin.TaskContext, options map[stri
 		db := taskCtx.GetDal()
 		pmClauses := []dal.Clause{
 			dal.From("project_mapping pm"),
-			dal.Where("pm.project_name = ? and pm.table = ?", op.ProjectName, "boards"),
+			dal.Where("pm.project_name = ? and ? = ?", op.ProjectName, dal.ClauseColumn{Table: "pm", Name: "table"}, "boards"),
 		}
 		pm := []crossdomain.ProjectMapping{}
 		err = db.All(&pm, pmClauses...)
@@ -137,6 +141,8 @@ func (p IssueTrace) RootPkgPath() string {
 func (p IssueTrace) MigrationScripts() []plugin.MigrationScript {
 	return []plugin.MigrationScript{
 		&migrationscripts.NewIssueTable{},
+		&migrationscripts.AddFlowMetricTables{},
+		&migrationscripts.AddSprintIssueHistory{},
 	}
 }
 
@@ -148,6 +154,11 @@ func (p IssueTrace) GetTablesInfo() []dal.Tabler {
 	return []dal.Tabler{
 		&models.IssueAssigneeHistory{},
 		&models.IssueStatusHistory{},
+		&models.IssueStatusTime{},
+		&models.IssueFlowMetric{},
+		&models.BoardFlowSnapshot{},
+		&models.SprintIssueHistory{},
+		&models.SprintScopeMetric{},
 	}
 }
 
@@ -165,12 +176,17 @@ func (p IssueTrace) MakeMetricPluginPipelinePlanV200(projectName string, options
 			{
 				Plugin: "issue_trace",
 				Options: map[string]interface{}{
-					"projectName": projectName,
-					"scopeIds":    op.ScopeIds,
+					"projectName":      projectName,
+					"scopeIds":         op.ScopeIds,
+					"flowConfig":       op.FlowConfig,
+					"boardFlowConfigs": op.BoardFlowConfigs,
+					"storyPointFields": op.StoryPointFields,
 				},
 				Subtasks: []string{
 					"ConvertIssueStatusHistory",
 					"ConvertIssueAssigneeHistory",
+					"CalculateIssueFlowMetrics",
+					"ConvertSprintIssueHistory",
 				},
 			},
 		},
@@ -0,0 +1,74 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// IssueStatusTime records the total time an issue spent in each standard status
+// handled by CalculateIssueFlowMetrics task
+type IssueStatusTime struct {
+	common.NoPKModel
+	IssueId      string `gorm:"primaryKey;type:varchar(255)"`
+	Status       string `gorm:"primaryKey;type:varchar(100)"`
+	TimeMinutes  int64
+	EnteredCount int
+}
+
+func (IssueStatusTime) TableName() string {
+	return "issue_status_time"
+}
+
+// IssueFlowMetric records the cycle time and flow efficiency of an issue on a board.
+// the cycle starts when the issue enters IN_PROGRESS for the first time and ends when it enters DONE for the last time,
+// active and waiting time are classified by the flow config of the board.
+// handled by CalculateIssueFlowMetrics task
+type IssueFlowMetric struct {
+	common.NoPKModel
+	BoardId            string `gorm:"primaryKey;type:varchar(255)"`
+	IssueId            string `gorm:"primaryKey;type:varchar(255)"`
+	CycleStartDate     *time.Time
+	CycleEndDate       *time.Time
+	CycleTimeMinutes   *int64
+	ActiveTimeMinutes  int64
+	WaitingTimeMinutes int64
+	FlowEfficiency     *float64
+}
+
+func (IssueFlowMetric) TableName() string {
+	return "issue_flow_metrics"
+}
+
+// BoardFlowSnapshot records the daily work in progress and throughput of a board.
+// WipCount is the number of issues in IN_PROGRESS at the end of the day (UTC),
+// Throughput is the number of issues finished during the day.
+// handled by CalculateIssueFlowMetrics task
+type BoardFlowSnapshot struct {
+	common.NoPKModel
+	BoardId      string    `gorm:"primaryKey;type:varchar(255)"`
+	SnapshotDate time.Time `gorm:"primaryKey;type:date"`
+	WipCount     int
+	Throughput   int
+}
+
+func (BoardFlowSnapshot) TableName() string {
+	return "board_flow_snapshots"
+}
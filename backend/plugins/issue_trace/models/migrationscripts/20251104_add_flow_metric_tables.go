@@ -0,0 +1,82 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+type AddFlowMetricTables struct {
+}
+
+func (*AddFlowMetricTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &IssueStatusTime20251104{}, &IssueFlowMetric20251104{}, &BoardFlowSnapshot20251104{})
+}
+
+func (*AddFlowMetricTables) Version() uint64 {
+	return 20251104093000
+}
+
+func (*AddFlowMetricTables) Name() string {
+	return "add issue_status_time, issue_flow_metrics and board_flow_snapshots"
+}
+
+type IssueStatusTime20251104 struct {
+	archived.NoPKModel
+	IssueId      string `gorm:"primaryKey;type:varchar(255)"`
+	Status       string `gorm:"primaryKey;type:varchar(100)"`
+	TimeMinutes  int64
+	EnteredCount int
+}
+
+func (IssueStatusTime20251104) TableName() string {
+	return "issue_status_time"
+}
+
+type IssueFlowMetric20251104 struct {
+	archived.NoPKModel
+	BoardId            string `gorm:"primaryKey;type:varchar(255)"`
+	IssueId            string `gorm:"primaryKey;type:varchar(255)"`
+	CycleStartDate     *time.Time
+	CycleEndDate       *time.Time
+	CycleTimeMinutes   *int64
+	ActiveTimeMinutes  int64
+	WaitingTimeMinutes int64
+	FlowEfficiency     *float64
+}
+
+func (IssueFlowMetric20251104) TableName() string {
+	return "issue_flow_metrics"
+}
+
+type BoardFlowSnapshot20251104 struct {
+	archived.NoPKModel
+	BoardId      string    `gorm:"primaryKey;type:varchar(255)"`
+	SnapshotDate time.Time `gorm:"primaryKey;type:date"`
+	WipCount     int
+	Throughput   int
+}
+
+func (BoardFlowSnapshot20251104) TableName() string {
+	return "board_flow_snapshots"
+}
@@ -0,0 +1,75 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+type AddSprintIssueHistory struct {
+}
+
+func (*AddSprintIssueHistory) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &SprintIssueHistory20251105{}, &SprintScopeMetric20251105{})
+}
+
+func (*AddSprintIssueHistory) Version() uint64 {
+	return 20251105101000
+}
+
+func (*AddSprintIssueHistory) Name() string {
+	return "add sprint_issue_history and sprint_scope_metrics"
+}
+
+type SprintIssueHistory20251105 struct {
+	archived.NoPKModel
+	SprintId          string    `gorm:"primaryKey;type:varchar(255)"`
+	IssueId           string    `gorm:"primaryKey;type:varchar(255)"`
+	AddedDate         time.Time `gorm:"primaryKey"`
+	RemovedDate       *time.Time
+	AddedStoryPoint   *float64
+	RemovedStoryPoint *float64
+}
+
+func (SprintIssueHistory20251105) TableName() string {
+	return "sprint_issue_history"
+}
+
+type SprintScopeMetric20251105 struct {
+	archived.NoPKModel
+	SprintId            string `gorm:"primaryKey;type:varchar(255)"`
+	StartedDate         *time.Time
+	EndedDate           *time.Time
+	CommittedIssueCount int
+	CommittedStoryPoint float64
+	AddedIssueCount     int
+	AddedStoryPoint     float64
+	RemovedIssueCount   int
+	RemovedStoryPoint   float64
+	CompletedIssueCount int
+	CompletedStoryPoint float64
+}
+
+func (SprintScopeMetric20251105) TableName() string {
+	return "sprint_scope_metrics"
+}
@@ -0,0 +1,66 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// SprintIssueHistory records the periods an issue belonged to a sprint,
+// along with its story points when it was added to and removed from the sprint.
+// removed_date of the sprint the issue currently belongs to is null.
+// handled by ConvertSprintIssueHistory task
+type SprintIssueHistory struct {
+	common.NoPKModel
+	SprintId          string    `gorm:"primaryKey;type:varchar(255)"`
+	IssueId           string    `gorm:"primaryKey;type:varchar(255)"`
+	AddedDate         time.Time `gorm:"primaryKey"`
+	RemovedDate       *time.Time
+	AddedStoryPoint   *float64
+	RemovedStoryPoint *float64
+}
+
+func (SprintIssueHistory) TableName() string {
+	return "sprint_issue_history"
+}
+
+// SprintScopeMetric records the committed, added, removed and completed scope of a sprint.
+// committed issues belonged to the sprint when it started, added issues joined it after it started,
+// removed issues left it before it ended, and completed issues were resolved during the sprint and stayed till the end.
+// story points are taken at the time of the corresponding event.
+// handled by ConvertSprintIssueHistory task
+type SprintScopeMetric struct {
+	common.NoPKModel
+	SprintId            string `gorm:"primaryKey;type:varchar(255)"`
+	StartedDate         *time.Time
+	EndedDate           *time.Time
+	CommittedIssueCount int
+	CommittedStoryPoint float64
+	AddedIssueCount     int
+	AddedStoryPoint     float64
+	RemovedIssueCount   int
+	RemovedStoryPoint   float64
+	CompletedIssueCount int
+	CompletedStoryPoint float64
+}
+
+func (SprintScopeMetric) TableName() string {
+	return "sprint_scope_metrics"
+}
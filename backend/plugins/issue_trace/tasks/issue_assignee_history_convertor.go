@@ -76,8 +76,7 @@ func ConvertIssueAssigneeHistory(taskCtx plugin.SubTaskContext) errors.Error {
 		issues._raw_data_id as _raw_data_id,
 		issues.id as issue_id,
 		issues.created_date as start_date,
-		issues.assignee_id as user_id,
-		now() as end_date
+		issues.assignee_id as user_id
 	from
 		issues
 		join board_issues on board_issues.issue_id = issues.id
@@ -106,6 +105,8 @@ func ConvertIssueAssigneeHistory(taskCtx plugin.SubTaskContext) errors.Error {
 				return nil, ctxErr
 			}
 			row := inputRow.(*AssigneeHistory)
+			// the assignee is still assigned to the issue
+			row.EndDate = time.Now()
 			err := batchInsertor.Add(&models.IssueAssigneeHistory{
 				NoPKModel: common.NoPKModel{
 					RawDataOrigin: common.RawDataOrigin{
@@ -0,0 +1,343 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/models"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/utils"
+)
+
+var CalculateIssueFlowMetricsMeta = plugin.SubTaskMeta{
+	Name:             "CalculateIssueFlowMetrics",
+	EntryPoint:       CalculateIssueFlowMetrics,
+	EnabledByDefault: true,
+	Description:      "Calculate time in status, cycle time, flow efficiency, daily WIP and throughput from issue status history",
+	DependencyTables: []string{models.IssueStatusHistory{}.TableName(), ticket.BoardIssue{}.TableName()},
+	ProductTables: []string{
+		models.IssueStatusTime{}.TableName(),
+		models.IssueFlowMetric{}.TableName(),
+		models.BoardFlowSnapshot{}.TableName(),
+	},
+}
+
+type BoardIssueStatusHistory struct {
+	BoardId string
+	models.IssueStatusHistory
+}
+
+func CalculateIssueFlowMetrics(taskCtx plugin.SubTaskContext) errors.Error {
+	logger := taskCtx.GetLogger()
+	options := taskCtx.GetData().(*TaskData)
+	scopeIds := options.ScopeIds
+	db := taskCtx.GetDal()
+	now := time.Now()
+
+	// clear the metrics calculated last time
+	err := db.Delete(&models.IssueStatusTime{}, dal.Where("issue_id IN (SELECT issue_id FROM board_issues WHERE board_id in ?)", scopeIds))
+	if err != nil {
+		return err
+	}
+	err = db.Delete(&models.IssueFlowMetric{}, dal.Where("board_id in ?", scopeIds))
+	if err != nil {
+		return err
+	}
+	err = db.Delete(&models.BoardFlowSnapshot{}, dal.Where("board_id in ?", scopeIds))
+	if err != nil {
+		return err
+	}
+
+	inserter := helper.NewBatchSaveDivider(taskCtx, utils.BATCH_SIZE, "", "")
+	statusTimeInserter, err := inserter.ForType(reflect.TypeOf(&models.IssueStatusTime{}))
+	if err != nil {
+		return err
+	}
+	flowMetricInserter, err := inserter.ForType(reflect.TypeOf(&models.IssueFlowMetric{}))
+	if err != nil {
+		return err
+	}
+	snapshotInserter, err := inserter.ForType(reflect.TypeOf(&models.BoardFlowSnapshot{}))
+	if err != nil {
+		return err
+	}
+
+	logger.Info("calculate flow metrics, board %s", scopeIds)
+	cursor, err := db.Cursor(
+		dal.Select("board_issues.board_id, issue_status_history.*"),
+		dal.From("issue_status_history"),
+		dal.Join("INNER JOIN board_issues ON board_issues.issue_id = issue_status_history.issue_id"),
+		dal.Where("board_issues.board_id in ?", scopeIds),
+		dal.Orderby("board_issues.board_id ASC, issue_status_history.issue_id ASC, issue_status_history.start_date ASC"),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	var currentBoard *boardFlow
+	var currentIssue string
+	var histories []*models.IssueStatusHistory
+	flushIssue := func() errors.Error {
+		if len(histories) == 0 {
+			return nil
+		}
+		// the last status of an issue lasts till now, even if it was closed off by the previous calculation
+		histories[len(histories)-1].IsCurrentStatus = true
+		for _, statusTime := range calculateIssueStatusTimes(histories, now) {
+			if err := statusTimeInserter.Add(statusTime); err != nil {
+				return err
+			}
+		}
+		flowMetric := calculateIssueFlowMetric(currentBoard.boardId, histories, options.Options.GetFlowConfig(currentBoard.boardId), now)
+		currentBoard.addIssue(histories, flowMetric, now)
+		histories = nil
+		return flowMetricInserter.Add(flowMetric)
+	}
+	flushBoard := func() errors.Error {
+		if currentBoard == nil {
+			return nil
+		}
+		if err := flushIssue(); err != nil {
+			return err
+		}
+		for _, snapshot := range currentBoard.snapshots(now) {
+			if err := snapshotInserter.Add(snapshot); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for cursor.Next() {
+		if ctxErr := utils.CheckCancel(taskCtx); ctxErr != nil {
+			return ctxErr
+		}
+		row := &BoardIssueStatusHistory{}
+		if err := db.Fetch(cursor, row); err != nil {
+			return err
+		}
+		if currentBoard == nil || row.BoardId != currentBoard.boardId {
+			if err := flushBoard(); err != nil {
+				return err
+			}
+			currentBoard = newBoardFlow(row.BoardId)
+			currentIssue = ""
+		}
+		if row.IssueId != currentIssue {
+			if err := flushIssue(); err != nil {
+				return err
+			}
+			currentIssue = row.IssueId
+		}
+		history := row.IssueStatusHistory
+		histories = append(histories, &history)
+	}
+	if err := flushBoard(); err != nil {
+		return err
+	}
+	if err := inserter.Close(); err != nil {
+		return err
+	}
+	logger.Info("issue flow metrics calculated successfully")
+	return nil
+}
+
+// getEndDate returns the end of a status, the current status never ends
+func getEndDate(history *models.IssueStatusHistory, now time.Time) time.Time {
+	if history.IsCurrentStatus || history.EndDate == nil {
+		return now
+	}
+	return *history.EndDate
+}
+
+func minutesBetween(start, end time.Time) int64 {
+	if !end.After(start) {
+		return 0
+	}
+	return int64(end.Sub(start) / time.Minute)
+}
+
+func isActiveStatus(config *FlowConfig, history *models.IssueStatusHistory) bool {
+	if utils.StringContains(config.WaitingStatuses, history.OriginalStatus) || utils.StringContains(config.WaitingStatuses, history.Status) {
+		return false
+	}
+	if len(config.ActiveStatuses) > 0 {
+		return utils.StringContains(config.ActiveStatuses, history.OriginalStatus) || utils.StringContains(config.ActiveStatuses, history.Status)
+	}
+	return history.Status == ticket.IN_PROGRESS
+}
+
+// calculateIssueStatusTimes sums up the time of an issue in each standard status,
+// the histories must belong to the same issue and be sorted by start_date
+func calculateIssueStatusTimes(histories []*models.IssueStatusHistory, now time.Time) []*models.IssueStatusTime {
+	result := make([]*models.IssueStatusTime, 0)
+	byStatus := make(map[string]*models.IssueStatusTime)
+	lastStatus := ""
+	for _, history := range histories {
+		statusTime, ok := byStatus[history.Status]
+		if !ok {
+			statusTime = &models.IssueStatusTime{
+				IssueId: history.IssueId,
+				Status:  history.Status,
+			}
+			byStatus[history.Status] = statusTime
+			result = append(result, statusTime)
+		}
+		statusTime.TimeMinutes += minutesBetween(history.StartDate, getEndDate(history, now))
+		if history.Status != lastStatus {
+			statusTime.EnteredCount++
+		}
+		lastStatus = history.Status
+	}
+	return result
+}
+
+// calculateIssueFlowMetric calculates the cycle time and flow efficiency of an issue,
+// the histories must belong to the same issue and be sorted by start_date
+func calculateIssueFlowMetric(boardId string, histories []*models.IssueStatusHistory, config *FlowConfig, now time.Time) *models.IssueFlowMetric {
+	metric := &models.IssueFlowMetric{
+		BoardId: boardId,
+		IssueId: histories[0].IssueId,
+	}
+	for _, history := range histories {
+		if history.Status == ticket.IN_PROGRESS {
+			startDate := history.StartDate
+			metric.CycleStartDate = &startDate
+			break
+		}
+	}
+	if metric.CycleStartDate == nil {
+		return metric
+	}
+	// the cycle ends when the issue enters DONE for the last time
+	for i := len(histories) - 1; i >= 0 && histories[i].Status == ticket.DONE; i-- {
+		endDate := histories[i].StartDate
+		metric.CycleEndDate = &endDate
+	}
+	cycleEnd := now
+	if metric.CycleEndDate != nil {
+		cycleEnd = *metric.CycleEndDate
+		cycleTime := minutesBetween(*metric.CycleStartDate, cycleEnd)
+		metric.CycleTimeMinutes = &cycleTime
+	}
+	for _, history := range histories {
+		start := history.StartDate
+		if start.Before(*metric.CycleStartDate) {
+			start = *metric.CycleStartDate
+		}
+		end := getEndDate(history, now)
+		if end.After(cycleEnd) {
+			end = cycleEnd
+		}
+		minutes := minutesBetween(start, end)
+		if isActiveStatus(config, history) {
+			metric.ActiveTimeMinutes += minutes
+		} else {
+			metric.WaitingTimeMinutes += minutes
+		}
+	}
+	if total := metric.ActiveTimeMinutes + metric.WaitingTimeMinutes; total > 0 {
+		efficiency := float64(metric.ActiveTimeMinutes) / float64(total)
+		metric.FlowEfficiency = &efficiency
+	}
+	return metric
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// boardFlow accumulates the daily WIP and throughput of a board
+type boardFlow struct {
+	boardId    string
+	firstDay   *time.Time
+	wip        map[time.Time]int
+	throughput map[time.Time]int
+}
+
+func newBoardFlow(boardId string) *boardFlow {
+	return &boardFlow{
+		boardId:    boardId,
+		wip:        make(map[time.Time]int),
+		throughput: make(map[time.Time]int),
+	}
+}
+
+func (b *boardFlow) touch(day time.Time) {
+	if b.firstDay == nil || day.Before(*b.firstDay) {
+		b.firstDay = &day
+	}
+}
+
+// addIssue counts an issue into WIP of the days it was IN_PROGRESS at the end of the day,
+// and into throughput of the day its cycle ended
+func (b *boardFlow) addIssue(histories []*models.IssueStatusHistory, metric *models.IssueFlowMetric, now time.Time) {
+	today := truncateToDay(now)
+	for _, history := range histories {
+		if history.Status != ticket.IN_PROGRESS {
+			continue
+		}
+		end := getEndDate(history, now)
+		isCurrent := history.IsCurrentStatus || history.EndDate == nil
+		for day := truncateToDay(history.StartDate); !day.After(today); day = day.AddDate(0, 0, 1) {
+			snapshotTime := day.AddDate(0, 0, 1)
+			if snapshotTime.After(now) {
+				snapshotTime = now
+			}
+			if !isCurrent && !snapshotTime.Before(end) {
+				break
+			}
+			if snapshotTime.Before(history.StartDate) {
+				continue
+			}
+			b.touch(day)
+			b.wip[day]++
+		}
+	}
+	if metric.CycleEndDate != nil {
+		day := truncateToDay(*metric.CycleEndDate)
+		b.touch(day)
+		b.throughput[day]++
+	}
+}
+
+// snapshots returns the snapshots of every day from the first activity of the board till today
+func (b *boardFlow) snapshots(now time.Time) []*models.BoardFlowSnapshot {
+	result := make([]*models.BoardFlowSnapshot, 0)
+	if b.firstDay == nil {
+		return result
+	}
+	today := truncateToDay(now)
+	for day := *b.firstDay; !day.After(today); day = day.AddDate(0, 0, 1) {
+		result = append(result, &models.BoardFlowSnapshot{
+			BoardId:      b.boardId,
+			SnapshotDate: day,
+			WipCount:     b.wip[day],
+			Throughput:   b.throughput[day],
+		})
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildHistories(now time.Time, statuses ...[2]string) []*models.IssueStatusHistory {
+	// every status lasts for one day, the last one lasts till now
+	start := now.AddDate(0, 0, -len(statuses))
+	var result []*models.IssueStatusHistory
+	for i, status := range statuses {
+		endDate := start.AddDate(0, 0, 1)
+		result = append(result, &models.IssueStatusHistory{
+			IssueId:         "jira:JiraIssue:1:1",
+			Status:          status[0],
+			OriginalStatus:  status[1],
+			StartDate:       start,
+			EndDate:         &endDate,
+			IsCurrentStatus: i == len(statuses)-1,
+		})
+		start = endDate
+	}
+	return result
+}
+
+func Test_calculateIssueStatusTimes(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	histories := buildHistories(now,
+		[2]string{ticket.TODO, "Open"},
+		[2]string{ticket.IN_PROGRESS, "In Development"},
+		[2]string{ticket.IN_PROGRESS, "In Review"},
+		[2]string{ticket.TODO, "Reopened"},
+		[2]string{ticket.IN_PROGRESS, "In Development"},
+		[2]string{ticket.DONE, "Closed"},
+	)
+	statusTimes := calculateIssueStatusTimes(histories, now)
+	assert.Len(t, statusTimes, 3)
+	assert.Equal(t, ticket.TODO, statusTimes[0].Status)
+	assert.Equal(t, int64(2*24*60), statusTimes[0].TimeMinutes)
+	assert.Equal(t, 2, statusTimes[0].EnteredCount)
+	assert.Equal(t, ticket.IN_PROGRESS, statusTimes[1].Status)
+	assert.Equal(t, int64(3*24*60), statusTimes[1].TimeMinutes)
+	assert.Equal(t, 2, statusTimes[1].EnteredCount)
+	assert.Equal(t, ticket.DONE, statusTimes[2].Status)
+	assert.Equal(t, int64(24*60), statusTimes[2].TimeMinutes)
+	assert.Equal(t, 1, statusTimes[2].EnteredCount)
+}
+
+func Test_calculateIssueFlowMetric(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	histories := buildHistories(now,
+		[2]string{ticket.TODO, "Open"},
+		[2]string{ticket.IN_PROGRESS, "In Development"},
+		[2]string{ticket.IN_PROGRESS, "Waiting for Review"},
+		[2]string{ticket.IN_PROGRESS, "In Review"},
+		[2]string{ticket.DONE, "Closed"},
+	)
+
+	// by default, all IN_PROGRESS statuses are active
+	metric := calculateIssueFlowMetric("jira:JiraBoard:1:1", histories, &FlowConfig{}, now)
+	assert.Equal(t, histories[1].StartDate, *metric.CycleStartDate)
+	assert.Equal(t, histories[4].StartDate, *metric.CycleEndDate)
+	assert.Equal(t, int64(3*24*60), *metric.CycleTimeMinutes)
+	assert.Equal(t, int64(3*24*60), metric.ActiveTimeMinutes)
+	assert.Equal(t, int64(0), metric.WaitingTimeMinutes)
+	assert.Equal(t, 1.0, *metric.FlowEfficiency)
+
+	metric = calculateIssueFlowMetric("jira:JiraBoard:1:1", histories, &FlowConfig{
+		WaitingStatuses: []string{"Waiting for Review"},
+	}, now)
+	assert.Equal(t, int64(2*24*60), metric.ActiveTimeMinutes)
+	assert.Equal(t, int64(24*60), metric.WaitingTimeMinutes)
+	assert.InDelta(t, 2.0/3.0, *metric.FlowEfficiency, 0.0001)
+
+	metric = calculateIssueFlowMetric("jira:JiraBoard:1:1", histories, &FlowConfig{
+		ActiveStatuses: []string{"In Development"},
+	}, now)
+	assert.Equal(t, int64(24*60), metric.ActiveTimeMinutes)
+	assert.Equal(t, int64(2*24*60), metric.WaitingTimeMinutes)
+
+	// unfinished issues have no cycle time, but the flow efficiency so far
+	metric = calculateIssueFlowMetric("jira:JiraBoard:1:1", histories[:3], &FlowConfig{}, now)
+	assert.Nil(t, metric.CycleEndDate)
+	assert.Nil(t, metric.CycleTimeMinutes)
+	assert.Equal(t, int64(2*24*60), metric.ActiveTimeMinutes)
+
+	// issues never in progress have no cycle at all
+	metric = calculateIssueFlowMetric("jira:JiraBoard:1:1", histories[:1], &FlowConfig{}, now)
+	assert.Nil(t, metric.CycleStartDate)
+	assert.Nil(t, metric.FlowEfficiency)
+}
+
+func Test_boardFlow(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	board := newBoardFlow("jira:JiraBoard:1:1")
+	finished := buildHistories(now,
+		[2]string{ticket.TODO, "Open"},
+		[2]string{ticket.IN_PROGRESS, "In Development"},
+		[2]string{ticket.DONE, "Closed"},
+	)
+	board.addIssue(finished, calculateIssueFlowMetric(board.boardId, finished, &FlowConfig{}, now), now)
+	ongoing := buildHistories(now,
+		[2]string{ticket.TODO, "Open"},
+		[2]string{ticket.IN_PROGRESS, "In Development"},
+	)
+	board.addIssue(ongoing, calculateIssueFlowMetric(board.boardId, ongoing, &FlowConfig{}, now), now)
+
+	snapshots := board.snapshots(now)
+	assert.Len(t, snapshots, 3)
+	// 2024-06-08: the finished issue entered IN_PROGRESS at 12:00
+	assert.Equal(t, time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC), snapshots[0].SnapshotDate)
+	assert.Equal(t, 1, snapshots[0].WipCount)
+	assert.Equal(t, 0, snapshots[0].Throughput)
+	// 2024-06-09: the finished issue was closed at 12:00, the ongoing one started at 12:00
+	assert.Equal(t, 1, snapshots[1].WipCount)
+	assert.Equal(t, 1, snapshots[1].Throughput)
+	// 2024-06-10: the ongoing issue is still in progress
+	assert.Equal(t, 1, snapshots[2].WipCount)
+	assert.Equal(t, 0, snapshots[2].Throughput)
+}
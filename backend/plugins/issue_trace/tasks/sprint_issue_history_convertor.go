@@ -0,0 +1,420 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/models"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/utils"
+)
+
+// SprintChangelogFieldName is the field name ticket plugins use for sprint changes in issue_changelogs,
+// the values are comma separated domain ids of sprints
+const SprintChangelogFieldName = "Sprint"
+
+var ConvertSprintIssueHistoryMeta = plugin.SubTaskMeta{
+	Name:             "ConvertSprintIssueHistory",
+	EntryPoint:       ConvertSprintIssueHistory,
+	EnabledByDefault: true,
+	Description:      "Convert sprint changelogs to sprint issue history and calculate committed, added, removed and completed scope of sprints",
+	DependencyTables: []string{
+		ticket.IssueChangelogs{}.TableName(),
+		ticket.Issue{}.TableName(),
+		ticket.SprintIssue{}.TableName(),
+		ticket.BoardSprint{}.TableName(),
+	},
+	ProductTables: []string{models.SprintIssueHistory{}.TableName(), models.SprintScopeMetric{}.TableName()},
+}
+
+type SprintChangelogResult struct {
+	IssueId           string
+	IssueCreatedDate  *time.Time
+	StoryPoint        *float64
+	ResolutionDate    *time.Time
+	FieldName         string
+	OriginalFromValue string
+	OriginalToValue   string
+	LogCreatedDate    *time.Time
+}
+
+type sprintChangelog struct {
+	createdDate time.Time
+	from        []string
+	to          []string
+}
+
+type storyPointChangelog struct {
+	createdDate time.Time
+	from        *float64
+	to          *float64
+}
+
+// issueSprintTimeline holds the sprint and story point changes of an issue
+type issueSprintTimeline struct {
+	issueId          string
+	createdDate      time.Time
+	storyPoint       *float64
+	resolutionDate   *time.Time
+	sprintLogs       []*sprintChangelog
+	storyPointLogs   []*storyPointChangelog
+	currentSprintIds []string
+}
+
+// sprintWindow is the period of a sprint, the end of an ongoing sprint is now
+type sprintWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+func ConvertSprintIssueHistory(taskCtx plugin.SubTaskContext) errors.Error {
+	logger := taskCtx.GetLogger()
+	options := taskCtx.GetData().(*TaskData)
+	scopeIds := options.ScopeIds
+	db := taskCtx.GetDal()
+	now := time.Now()
+
+	// sprints of the boards
+	var sprints []*ticket.Sprint
+	err := db.All(&sprints,
+		dal.Select("sprints.*"),
+		dal.From(&ticket.Sprint{}),
+		dal.Join("INNER JOIN board_sprints ON board_sprints.sprint_id = sprints.id"),
+		dal.Where("board_sprints.board_id in ?", scopeIds),
+	)
+	if err != nil {
+		return err
+	}
+	windows := make(map[string]*sprintWindow)
+	metrics := make(map[string]*models.SprintScopeMetric)
+	var sprintIds []string
+	for _, sprint := range sprints {
+		if _, ok := metrics[sprint.Id]; ok {
+			continue
+		}
+		sprintIds = append(sprintIds, sprint.Id)
+		metrics[sprint.Id] = &models.SprintScopeMetric{
+			SprintId:    sprint.Id,
+			StartedDate: sprint.StartedDate,
+			EndedDate:   sprint.EndedDate,
+		}
+		if sprint.CompletedDate != nil {
+			metrics[sprint.Id].EndedDate = sprint.CompletedDate
+		}
+		if window := getSprintWindow(sprint, now); window != nil {
+			windows[sprint.Id] = window
+		}
+	}
+
+	// the sprints each issue belongs to currently
+	var sprintIssues []*ticket.SprintIssue
+	err = db.All(&sprintIssues,
+		dal.From(&ticket.SprintIssue{}),
+		dal.Where("issue_id IN (SELECT issue_id FROM board_issues WHERE board_id in ?)", scopeIds),
+	)
+	if err != nil {
+		return err
+	}
+	currentSprintIds := make(map[string][]string)
+	for _, sprintIssue := range sprintIssues {
+		currentSprintIds[sprintIssue.IssueId] = append(currentSprintIds[sprintIssue.IssueId], sprintIssue.SprintId)
+	}
+
+	// clear the history converted last time
+	err = db.Delete(&models.SprintIssueHistory{}, dal.Where("issue_id IN (SELECT issue_id FROM board_issues WHERE board_id in ?)", scopeIds))
+	if err != nil {
+		return err
+	}
+	err = db.Delete(&models.SprintScopeMetric{}, dal.Where("sprint_id in ?", sprintIds))
+	if err != nil {
+		return err
+	}
+
+	inserter := helper.NewBatchSaveDivider(taskCtx, utils.BATCH_SIZE, "", "")
+	historyInserter, err := inserter.ForType(reflect.TypeOf(&models.SprintIssueHistory{}))
+	if err != nil {
+		return err
+	}
+	metricInserter, err := inserter.ForType(reflect.TypeOf(&models.SprintScopeMetric{}))
+	if err != nil {
+		return err
+	}
+
+	logger.Info("convert sprint issue history, board %s", scopeIds)
+	storyPointFields := options.Options.GetStoryPointFields()
+	cursor, err := db.Cursor(
+		dal.Select("issues.id AS issue_id, issues.created_date AS issue_created_date, issues.story_point, issues.resolution_date, "+
+			"COALESCE(issue_changelogs.field_name, '') AS field_name, COALESCE(issue_changelogs.original_from_value, '') AS original_from_value, "+
+			"COALESCE(issue_changelogs.original_to_value, '') AS original_to_value, issue_changelogs.created_date AS log_created_date"),
+		dal.From(&ticket.Issue{}),
+		dal.Join("LEFT JOIN issue_changelogs ON issue_changelogs.issue_id = issues.id AND issue_changelogs.field_name IN ?",
+			append([]string{SprintChangelogFieldName}, storyPointFields...)),
+		dal.Where("issues.id IN (SELECT issue_id FROM board_issues WHERE board_id in ?)", scopeIds),
+		dal.Orderby("issues.id ASC, issue_changelogs.created_date ASC"),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	var timeline *issueSprintTimeline
+	flushIssue := func() errors.Error {
+		if timeline == nil {
+			return nil
+		}
+		histories := buildSprintIssueHistory(timeline)
+		for _, history := range histories {
+			if err := historyInserter.Add(history); err != nil {
+				return err
+			}
+		}
+		accumulateSprintScope(timeline, histories, windows, metrics)
+		return nil
+	}
+	for cursor.Next() {
+		if ctxErr := utils.CheckCancel(taskCtx); ctxErr != nil {
+			return ctxErr
+		}
+		row := &SprintChangelogResult{}
+		if err := db.Fetch(cursor, row); err != nil {
+			return err
+		}
+		if timeline == nil || timeline.issueId != row.IssueId {
+			if err := flushIssue(); err != nil {
+				return err
+			}
+			timeline = &issueSprintTimeline{
+				issueId:          row.IssueId,
+				storyPoint:       row.StoryPoint,
+				resolutionDate:   row.ResolutionDate,
+				currentSprintIds: currentSprintIds[row.IssueId],
+			}
+			if row.IssueCreatedDate != nil {
+				timeline.createdDate = *row.IssueCreatedDate
+			}
+		}
+		if row.LogCreatedDate == nil {
+			continue
+		}
+		if row.FieldName == SprintChangelogFieldName {
+			timeline.sprintLogs = append(timeline.sprintLogs, &sprintChangelog{
+				createdDate: *row.LogCreatedDate,
+				from:        splitIds(row.OriginalFromValue),
+				to:          splitIds(row.OriginalToValue),
+			})
+		} else {
+			timeline.storyPointLogs = append(timeline.storyPointLogs, &storyPointChangelog{
+				createdDate: *row.LogCreatedDate,
+				from:        parseStoryPoint(row.OriginalFromValue),
+				to:          parseStoryPoint(row.OriginalToValue),
+			})
+		}
+	}
+	if err := flushIssue(); err != nil {
+		return err
+	}
+	for _, sprintId := range sprintIds {
+		if err := metricInserter.Add(metrics[sprintId]); err != nil {
+			return err
+		}
+	}
+	if err := inserter.Close(); err != nil {
+		return err
+	}
+	logger.Info("sprint issue history converted successfully")
+	return nil
+}
+
+func splitIds(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func parseStoryPoint(value string) *float64 {
+	storyPoint, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+	return &storyPoint
+}
+
+func getSprintWindow(sprint *ticket.Sprint, now time.Time) *sprintWindow {
+	if sprint.StartedDate == nil || sprint.StartedDate.After(now) {
+		return nil
+	}
+	end := now
+	if sprint.CompletedDate != nil {
+		end = *sprint.CompletedDate
+	} else if sprint.EndedDate != nil && sprint.EndedDate.Before(now) {
+		end = *sprint.EndedDate
+	}
+	return &sprintWindow{start: *sprint.StartedDate, end: end}
+}
+
+// storyPointAt returns the story point of the issue at the given time
+func (t *issueSprintTimeline) storyPointAt(at time.Time) *float64 {
+	for i := len(t.storyPointLogs) - 1; i >= 0; i-- {
+		if !t.storyPointLogs[i].createdDate.After(at) {
+			return t.storyPointLogs[i].to
+		}
+	}
+	if len(t.storyPointLogs) > 0 {
+		return t.storyPointLogs[0].from
+	}
+	return t.storyPoint
+}
+
+// buildSprintIssueHistory replays the sprint changelogs of an issue, the sprints an issue belonged to
+// before its first changelog, or without any changelog, are considered to be added when the issue was created
+func buildSprintIssueHistory(timeline *issueSprintTimeline) []*models.SprintIssueHistory {
+	result := make([]*models.SprintIssueHistory, 0)
+	opened := make(map[string]*models.SprintIssueHistory)
+	add := func(sprintId string, date time.Time) {
+		history := &models.SprintIssueHistory{
+			SprintId:        sprintId,
+			IssueId:         timeline.issueId,
+			AddedDate:       date,
+			AddedStoryPoint: timeline.storyPointAt(date),
+		}
+		opened[sprintId] = history
+		result = append(result, history)
+	}
+	remove := func(sprintId string, date time.Time) {
+		if history, ok := opened[sprintId]; ok {
+			removedDate := date
+			history.RemovedDate = &removedDate
+			history.RemovedStoryPoint = timeline.storyPointAt(date)
+			delete(opened, sprintId)
+		}
+	}
+
+	var previous []string
+	if len(timeline.sprintLogs) > 0 {
+		previous = timeline.sprintLogs[0].from
+	} else {
+		previous = timeline.currentSprintIds
+	}
+	for _, sprintId := range previous {
+		add(sprintId, timeline.createdDate)
+	}
+	// the previous sprints are more reliable than the from value,
+	// since some tools only record the sprint an issue was moved to
+	for _, log := range timeline.sprintLogs {
+		for _, sprintId := range previous {
+			if !utils.StringContains(log.to, sprintId) {
+				remove(sprintId, log.createdDate)
+			}
+		}
+		for _, sprintId := range log.to {
+			if !utils.StringContains(previous, sprintId) {
+				add(sprintId, log.createdDate)
+			}
+		}
+		previous = log.to
+	}
+	// sprints missing from the changelogs
+	for _, sprintId := range timeline.currentSprintIds {
+		found := false
+		for _, history := range result {
+			if history.SprintId == sprintId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add(sprintId, timeline.createdDate)
+		}
+	}
+	return result
+}
+
+func isInSprintAt(histories []*models.SprintIssueHistory, at time.Time) bool {
+	for _, history := range histories {
+		if !history.AddedDate.After(at) && (history.RemovedDate == nil || history.RemovedDate.After(at)) {
+			return true
+		}
+	}
+	return false
+}
+
+func addStoryPoint(total *float64, storyPoint *float64) {
+	if storyPoint != nil {
+		*total += *storyPoint
+	}
+}
+
+// accumulateSprintScope adds the issue to the scope metrics of the sprints it belonged to
+func accumulateSprintScope(timeline *issueSprintTimeline, histories []*models.SprintIssueHistory, windows map[string]*sprintWindow, metrics map[string]*models.SprintScopeMetric) {
+	bySprint := make(map[string][]*models.SprintIssueHistory)
+	var sprintIds []string
+	for _, history := range histories {
+		if _, ok := bySprint[history.SprintId]; !ok {
+			sprintIds = append(sprintIds, history.SprintId)
+		}
+		bySprint[history.SprintId] = append(bySprint[history.SprintId], history)
+	}
+	for _, sprintId := range sprintIds {
+		window, ok := windows[sprintId]
+		if !ok {
+			continue
+		}
+		metric := metrics[sprintId]
+		sprintHistories := bySprint[sprintId]
+		committed := isInSprintAt(sprintHistories, window.start)
+		atEnd := isInSprintAt(sprintHistories, window.end)
+		var addedDate, removedDate *time.Time
+		for _, history := range sprintHistories {
+			if history.AddedDate.After(window.start) && !history.AddedDate.After(window.end) && addedDate == nil {
+				addedDate = &history.AddedDate
+			}
+			if history.RemovedDate != nil && !history.RemovedDate.Before(window.start) && !history.RemovedDate.After(window.end) {
+				removedDate = history.RemovedDate
+			}
+		}
+		if committed {
+			metric.CommittedIssueCount++
+			addStoryPoint(&metric.CommittedStoryPoint, timeline.storyPointAt(window.start))
+		} else if addedDate != nil {
+			metric.AddedIssueCount++
+			addStoryPoint(&metric.AddedStoryPoint, timeline.storyPointAt(*addedDate))
+		}
+		if !atEnd && removedDate != nil && (committed || addedDate != nil) {
+			metric.RemovedIssueCount++
+			addStoryPoint(&metric.RemovedStoryPoint, timeline.storyPointAt(*removedDate))
+		}
+		resolutionDate := timeline.resolutionDate
+		if atEnd && resolutionDate != nil && !resolutionDate.Before(window.start) && !resolutionDate.After(window.end) {
+			metric.CompletedIssueCount++
+			addStoryPoint(&metric.CompletedStoryPoint, timeline.storyPointAt(window.end))
+		}
+	}
+}
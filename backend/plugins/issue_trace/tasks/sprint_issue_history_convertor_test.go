@@ -0,0 +1,131 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/plugins/issue_trace/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func Test_buildSprintIssueHistory(t *testing.T) {
+	created := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return created.AddDate(0, 0, d)
+	}
+	timeline := &issueSprintTimeline{
+		issueId:     "jira:JiraIssue:1:1",
+		createdDate: created,
+		storyPoint:  floatPtr(5),
+		sprintLogs: []*sprintChangelog{
+			// carried over from sprint 1 to sprint 2
+			{createdDate: day(3), from: []string{"sprint1"}, to: []string{"sprint1", "sprint2"}},
+			// moved from sprint 2 to sprint 3, only the destination is recorded
+			{createdDate: day(5), to: []string{"sprint1", "sprint3"}},
+		},
+		storyPointLogs: []*storyPointChangelog{
+			{createdDate: day(4), from: floatPtr(3), to: floatPtr(5)},
+		},
+		currentSprintIds: []string{"sprint1", "sprint3"},
+	}
+
+	histories := buildSprintIssueHistory(timeline)
+	assert.Len(t, histories, 3)
+	assert.Equal(t, "sprint1", histories[0].SprintId)
+	assert.Equal(t, created, histories[0].AddedDate)
+	assert.Nil(t, histories[0].RemovedDate)
+	assert.Equal(t, 3.0, *histories[0].AddedStoryPoint)
+
+	assert.Equal(t, "sprint2", histories[1].SprintId)
+	assert.Equal(t, day(3), histories[1].AddedDate)
+	assert.Equal(t, day(5), *histories[1].RemovedDate)
+	assert.Equal(t, 3.0, *histories[1].AddedStoryPoint)
+	assert.Equal(t, 5.0, *histories[1].RemovedStoryPoint)
+
+	assert.Equal(t, "sprint3", histories[2].SprintId)
+	assert.Equal(t, day(5), histories[2].AddedDate)
+	assert.Nil(t, histories[2].RemovedDate)
+
+	// issues without changelogs are in their current sprints since they were created
+	histories = buildSprintIssueHistory(&issueSprintTimeline{
+		issueId:          "jira:JiraIssue:1:2",
+		createdDate:      created,
+		currentSprintIds: []string{"sprint1"},
+	})
+	assert.Len(t, histories, 1)
+	assert.Equal(t, created, histories[0].AddedDate)
+}
+
+func Test_accumulateSprintScope(t *testing.T) {
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 14)
+	windows := map[string]*sprintWindow{"sprint1": {start: start, end: end}}
+	metrics := map[string]*models.SprintScopeMetric{"sprint1": {SprintId: "sprint1"}}
+
+	accumulate := func(timeline *issueSprintTimeline) {
+		accumulateSprintScope(timeline, buildSprintIssueHistory(timeline), windows, metrics)
+	}
+	resolved := start.AddDate(0, 0, 7)
+	// committed and completed, re-estimated during the sprint
+	accumulate(&issueSprintTimeline{
+		issueId:          "1",
+		createdDate:      start.AddDate(0, 0, -7),
+		storyPoint:       floatPtr(8),
+		resolutionDate:   &resolved,
+		currentSprintIds: []string{"sprint1"},
+		storyPointLogs: []*storyPointChangelog{
+			{createdDate: start.AddDate(0, 0, 1), from: floatPtr(5), to: floatPtr(8)},
+		},
+	})
+	// added in the middle of the sprint, not completed
+	accumulate(&issueSprintTimeline{
+		issueId:          "2",
+		createdDate:      start.AddDate(0, 0, -7),
+		storyPoint:       floatPtr(3),
+		currentSprintIds: []string{"sprint1"},
+		sprintLogs: []*sprintChangelog{
+			{createdDate: start.AddDate(0, 0, 2), to: []string{"sprint1"}},
+		},
+	})
+	// committed but removed
+	accumulate(&issueSprintTimeline{
+		issueId:     "3",
+		createdDate: start.AddDate(0, 0, -7),
+		storyPoint:  floatPtr(2),
+		sprintLogs: []*sprintChangelog{
+			{createdDate: start.AddDate(0, 0, -1), to: []string{"sprint1"}},
+			{createdDate: start.AddDate(0, 0, 3), from: []string{"sprint1"}},
+		},
+	})
+
+	metric := metrics["sprint1"]
+	assert.Equal(t, 2, metric.CommittedIssueCount)
+	assert.Equal(t, 7.0, metric.CommittedStoryPoint)
+	assert.Equal(t, 1, metric.AddedIssueCount)
+	assert.Equal(t, 3.0, metric.AddedStoryPoint)
+	assert.Equal(t, 1, metric.RemovedIssueCount)
+	assert.Equal(t, 2.0, metric.RemovedStoryPoint)
+	assert.Equal(t, 1, metric.CompletedIssueCount)
+	assert.Equal(t, 8.0, metric.CompletedStoryPoint)
+}
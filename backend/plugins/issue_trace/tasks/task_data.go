@@ -22,6 +22,41 @@ type Options struct {
 	Plugin      string   `json:"plugin"`   // jira
 	ScopeIds    []string `json:"scopeIds"` // 68
 	ProjectName string   `json:"projectName"`
+	// FlowConfig applies to boards without their own flow config
+	FlowConfig *FlowConfig `json:"flowConfig"`
+	// BoardFlowConfigs maps board ids to their flow configs, i.e. jira:JiraBoard:1:68
+	BoardFlowConfigs map[string]*FlowConfig `json:"boardFlowConfigs"`
+	// StoryPointFields are the field names of story points in issue_changelogs
+	StoryPointFields []string `json:"storyPointFields"`
+}
+
+// DefaultStoryPointFields are the field names of story points used by Jira company-managed and team-managed projects
+var DefaultStoryPointFields = []string{"Story Points", "Story point estimate"}
+
+// GetStoryPointFields returns the field names of story points in issue_changelogs
+func (op *Options) GetStoryPointFields() []string {
+	if len(op.StoryPointFields) > 0 {
+		return op.StoryPointFields
+	}
+	return DefaultStoryPointFields
+}
+
+// FlowConfig classifies the original statuses of issues into active or waiting states,
+// statuses in neither list are considered active if their standard status is IN_PROGRESS
+type FlowConfig struct {
+	ActiveStatuses  []string `json:"activeStatuses"`
+	WaitingStatuses []string `json:"waitingStatuses"`
+}
+
+// GetFlowConfig returns the flow config of the given board
+func (op *Options) GetFlowConfig(boardId string) *FlowConfig {
+	if config, ok := op.BoardFlowConfigs[boardId]; ok && config != nil {
+		return config
+	}
+	if op.FlowConfig != nil {
+		return op.FlowConfig
+	}
+	return &FlowConfig{}
 }
 
 // TaskData converted parameter
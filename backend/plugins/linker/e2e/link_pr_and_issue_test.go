@@ -61,11 +61,61 @@ func TestLinkPrToIssue(t *testing.T) {
 			"pull_request_key",
 			"issue_id",
 			"issue_key",
+			"source",
+			"confidence",
 			"_raw_data_params",
 			"_raw_data_table",
 			"_raw_data_id",
 			"_raw_data_remark",
 		},
 	)
+}
+
+func TestLinkPrToIssueByStrategies(t *testing.T) {
+	var plugin impl.Linker
+	dataflowTester := e2ehelper.NewDataFlowTester(t, "linker", plugin)
+
+	re := regexp.MustCompile("#(\\d+)")
+	taskData := &tasks.LinkerTaskData{
+		Options: &tasks.LinkerOptions{
+			PrToIssueRegexp: re.String(),
+			Strategies: []string{
+				tasks.StrategyRemoteLink,
+				tasks.StrategyPrText,
+				tasks.StrategyBranchName,
+				tasks.StrategyCommitMessage,
+			},
+			ProjectName: "GitHub1",
+		},
+		PrToIssueRegexp:     re,
+		BranchToIssueRegexp: re,
+		CommitToIssueRegexp: re,
+	}
 
+	dataflowTester.ImportCsvIntoTabler("./snapshot_tables/issues.csv", &ticket.Issue{})
+	dataflowTester.ImportCsvIntoTabler("./snapshot_tables/pull_requests_for_strategies.csv", &code.PullRequest{})
+	dataflowTester.ImportCsvIntoTabler("./snapshot_tables/pull_request_commits.csv", &code.PullRequestCommit{})
+	dataflowTester.ImportCsvIntoTabler("./snapshot_tables/commits.csv", &code.Commit{})
+	dataflowTester.ImportCsvIntoTabler("./snapshot_tables/issue_commits.csv", &crossdomain.IssueCommit{})
+	dataflowTester.ImportCsvIntoTabler("./snapshot_tables/project_mapping.csv", &crossdomain.ProjectMapping{})
+	dataflowTester.ImportCsvIntoTabler("./snapshot_tables/board_issues.csv", &ticket.BoardIssue{})
+
+	dataflowTester.FlushTabler(&crossdomain.PullRequestIssue{})
+	dataflowTester.Subtask(tasks.LinkPrToIssueMeta, taskData)
+	dataflowTester.VerifyTable(
+		crossdomain.PullRequestIssue{},
+		"./snapshot_tables/pull_request_issues_by_strategies.csv",
+		[]string{
+			"pull_request_id",
+			"pull_request_key",
+			"issue_id",
+			"issue_key",
+			"source",
+			"confidence",
+			"_raw_data_params",
+			"_raw_data_table",
+			"_raw_data_id",
+			"_raw_data_remark",
+		},
+	)
 }
@@ -91,6 +91,23 @@ func (p Linker) PrepareTaskData(taskCtx plugin.TaskContext, options map[string]i
 		}
 		taskData.PrToIssueRegexp = re
 	}
+	// branch names and commit messages fall back to the regexp of pull requests
+	taskData.BranchToIssueRegexp = taskData.PrToIssueRegexp
+	if op.BranchToIssueRegexp != "" {
+		re, err := regexp.Compile(op.BranchToIssueRegexp)
+		if err != nil {
+			return taskData, errors.Convert(err)
+		}
+		taskData.BranchToIssueRegexp = re
+	}
+	taskData.CommitToIssueRegexp = taskData.PrToIssueRegexp
+	if op.CommitToIssueRegexp != "" {
+		re, err := regexp.Compile(op.CommitToIssueRegexp)
+		if err != nil {
+			return taskData, errors.Convert(err)
+		}
+		taskData.CommitToIssueRegexp = re
+	}
 	return taskData, nil
 }
 
@@ -114,8 +131,11 @@ func (p Linker) MakeMetricPluginPipelinePlanV200(projectName string, options jso
 			{
 				Plugin: "linker",
 				Options: map[string]interface{}{
-					"projectName":     projectName,
-					"prToIssueRegexp": op.PrToIssueRegexp,
+					"projectName":         projectName,
+					"prToIssueRegexp":     op.PrToIssueRegexp,
+					"branchToIssueRegexp": op.BranchToIssueRegexp,
+					"commitToIssueRegexp": op.CommitToIssueRegexp,
+					"strategies":          op.Strategies,
 				},
 				Subtasks: []string{
 					"LinkPrToIssue",
@@ -18,13 +18,14 @@ limitations under the License.
 package tasks
 
 import (
-	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
-	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
-	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"regexp"
 	"strings"
 
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 )
@@ -33,10 +34,16 @@ var LinkPrToIssueMeta = plugin.SubTaskMeta{
 	Name:             "LinkPrToIssue",
 	EntryPoint:       LinkPrToIssue,
 	EnabledByDefault: true,
-	Description:      "Try to link pull requests to issues, according to pull requests' title and description",
-	DependencyTables: []string{code.PullRequest{}.TableName(), ticket.Issue{}.TableName()},
-	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE, plugin.DOMAIN_TYPE_TICKET, plugin.DOMAIN_TYPE_CROSS},
-	ProductTables:    []string{crossdomain.PullRequestIssue{}.TableName()},
+	Description:      "Try to link pull requests to issues, according to pull requests' title, description, branch name, commit messages and issues' remote links",
+	DependencyTables: []string{
+		code.PullRequest{}.TableName(),
+		code.PullRequestCommit{}.TableName(),
+		code.Commit{}.TableName(),
+		ticket.Issue{}.TableName(),
+		crossdomain.IssueCommit{}.TableName(),
+	},
+	DomainTypes:   []string{plugin.DOMAIN_TYPE_CODE, plugin.DOMAIN_TYPE_TICKET, plugin.DOMAIN_TYPE_CROSS},
+	ProductTables: []string{crossdomain.PullRequestIssue{}.TableName()},
 }
 
 func normalizeIssueKey(issueKey string) string {
@@ -45,6 +52,19 @@ func normalizeIssueKey(issueKey string) string {
 	return issueKey
 }
 
+func findIssueKeys(re *regexp.Regexp, texts ...string) []string {
+	var issueKeys []string
+	if re == nil {
+		return issueKeys
+	}
+	for _, text := range texts {
+		for _, issueKey := range re.FindAllString(text, -1) {
+			issueKeys = append(issueKeys, normalizeIssueKey(issueKey))
+		}
+	}
+	return issueKeys
+}
+
 func clearHistoryData(db dal.Dal, data *LinkerTaskData) errors.Error {
 	sql := `
 	DELETE FROM pull_request_issues
@@ -52,17 +72,62 @@ func clearHistoryData(db dal.Dal, data *LinkerTaskData) errors.Error {
 			SELECT pr.id
 				FROM pull_requests pr
 					LEFT JOIN project_mapping pm
-					ON pm.table = 'repos'
+					ON ? = 'repos'
 						AND pm.row_id = pr.base_repo_id
 						AND pm.project_name = ?
 	)
 `
-	return db.Exec(sql, data.Options.ProjectName)
+	return db.Exec(sql, dal.ClauseColumn{Table: "pm", Name: "table"}, data.Options.ProjectName)
+}
+
+// pullRequestIssueLinks collects the links of a pull request found by all strategies,
+// an issue linked by multiple strategies would keep the one with the highest confidence
+type pullRequestIssueLinks struct {
+	pullRequest *code.PullRequest
+	links       []*crossdomain.PullRequestIssue
+	byIssueId   map[string]*crossdomain.PullRequestIssue
+}
+
+func newPullRequestIssueLinks(pullRequest *code.PullRequest) *pullRequestIssueLinks {
+	return &pullRequestIssueLinks{
+		pullRequest: pullRequest,
+		byIssueId:   make(map[string]*crossdomain.PullRequestIssue),
+	}
+}
+
+func (l *pullRequestIssueLinks) add(issue *ticket.Issue, strategy string) {
+	confidence := StrategyConfidences[strategy]
+	if existing, ok := l.byIssueId[issue.Id]; ok {
+		if existing.Confidence < confidence {
+			existing.Source = strategy
+			existing.Confidence = confidence
+		}
+		return
+	}
+	pullRequestIssue := &crossdomain.PullRequestIssue{
+		PullRequestId:  l.pullRequest.Id,
+		IssueId:        issue.Id,
+		PullRequestKey: l.pullRequest.PullRequestKey,
+		IssueKey:       issue.IssueKey,
+		Source:         strategy,
+		Confidence:     confidence,
+	}
+	l.byIssueId[issue.Id] = pullRequestIssue
+	l.links = append(l.links, pullRequestIssue)
+}
+
+func (l *pullRequestIssueLinks) result() []interface{} {
+	var result []interface{}
+	for _, link := range l.links {
+		result = append(result, link)
+	}
+	return result
 }
 
 func LinkPrToIssue(taskCtx plugin.SubTaskContext) errors.Error {
 	db := taskCtx.GetDal()
 	data := taskCtx.GetData().(*LinkerTaskData)
+	op := data.Options
 
 	if err := clearHistoryData(db, data); err != nil {
 		return err
@@ -70,8 +135,8 @@ func LinkPrToIssue(taskCtx plugin.SubTaskContext) errors.Error {
 
 	var clauses = []dal.Clause{
 		dal.From(&code.PullRequest{}),
-		dal.Join("LEFT JOIN project_mapping pm ON (pm.table = 'repos' AND pm.row_id = pull_requests.base_repo_id)"),
-		dal.Where("pm.project_name = ?", data.Options.ProjectName),
+		dal.Join("LEFT JOIN project_mapping pm ON (? = 'repos' AND pm.row_id = pull_requests.base_repo_id)", dal.ClauseColumn{Table: "pm", Name: "table"}),
+		dal.Where("pm.project_name = ?", op.ProjectName),
 	}
 	cursor, err := db.Cursor(clauses...)
 	if err != nil {
@@ -84,54 +149,96 @@ func LinkPrToIssue(taskCtx plugin.SubTaskContext) errors.Error {
 	if err := db.All(&projectIssueIds,
 		dal.From(ticket.BoardIssue{}),
 		dal.Select("board_issues.issue_id"),
-		dal.Join("LEFT JOIN project_mapping pm ON (pm.table = 'boards' AND pm.row_id = board_issues.board_id)"),
-		dal.Where("pm.project_name = ?", data.Options.ProjectName),
+		dal.Join("LEFT JOIN project_mapping pm ON (? = 'boards' AND pm.row_id = board_issues.board_id)", dal.ClauseColumn{Table: "pm", Name: "table"}),
+		dal.Where("pm.project_name = ?", op.ProjectName),
 	); err != nil {
 		return err
 	}
 
+	findIssuesByKeys := func(issueKeys []string) ([]*ticket.Issue, errors.Error) {
+		var issues []*ticket.Issue
+		if len(issueKeys) == 0 {
+			return issues, nil
+		}
+		err := db.All(&issues,
+			dal.From(&ticket.Issue{}),
+			dal.Where("issues.id in ? AND issues.issue_key in ?", projectIssueIds, issueKeys),
+		)
+		return issues, err
+	}
+
 	enricher, err := api.NewDataEnricher(api.DataEnricherArgs[code.PullRequest]{
 		Ctx:   taskCtx,
 		Name:  code.PullRequest{}.TableName(),
 		Input: cursor,
 		Enrich: func(pullRequest *code.PullRequest) ([]interface{}, errors.Error) {
+			links := newPullRequestIssueLinks(pullRequest)
+
+			// issues linking back to commits of the pull request, i.e. Jira remote links
+			if op.HasStrategy(StrategyRemoteLink) {
+				var issues []*ticket.Issue
+				err := db.All(&issues,
+					dal.Select("DISTINCT issues.*"),
+					dal.From(&ticket.Issue{}),
+					dal.Join("INNER JOIN issue_commits ic ON ic.issue_id = issues.id"),
+					dal.Join("INNER JOIN pull_request_commits prc ON prc.commit_sha = ic.commit_sha"),
+					dal.Where("prc.pull_request_id = ? AND issues.id in ?", pullRequest.Id, projectIssueIds),
+				)
+				if err != nil {
+					return nil, err
+				}
+				for _, issue := range issues {
+					links.add(issue, StrategyRemoteLink)
+				}
+			}
 
-			var issueKeys []string
-			for _, text := range []string{pullRequest.Title, pullRequest.Description} {
-				foundIssueKeys := data.PrToIssueRegexp.FindAllString(text, -1)
-				if len(foundIssueKeys) > 0 {
-					for _, issueKey := range foundIssueKeys {
-						issueKey = normalizeIssueKey(issueKey)
-						issueKeys = append(issueKeys, issueKey)
+			if op.HasStrategy(StrategyPrText) {
+				var issueKeys []string
+				for _, text := range []string{pullRequest.Title, pullRequest.Description} {
+					issueKeys = findIssueKeys(data.PrToIssueRegexp, text)
+					if len(issueKeys) > 0 {
+						break
 					}
-					break
+				}
+				issues, err := findIssuesByKeys(issueKeys)
+				if err != nil {
+					return nil, err
+				}
+				for _, issue := range issues {
+					links.add(issue, StrategyPrText)
 				}
 			}
-			var issues []*ticket.Issue
 
-			var clauses = []dal.Clause{
-				dal.From(&ticket.Issue{}),
-				dal.Where("issues.id in ? AND issues.issue_key in ?", projectIssueIds, issueKeys),
+			if op.HasStrategy(StrategyBranchName) {
+				issues, err := findIssuesByKeys(findIssueKeys(data.BranchToIssueRegexp, pullRequest.HeadRef))
+				if err != nil {
+					return nil, err
+				}
+				for _, issue := range issues {
+					links.add(issue, StrategyBranchName)
+				}
 			}
 
-			if err := db.All(&issues, clauses...); err != nil {
-				return nil, err
-			}
-			if len(issues) == 0 {
-				return nil, nil
-			}
-			var result []interface{}
-			for _, issue := range issues {
-				pullRequestIssue := &crossdomain.PullRequestIssue{
-					PullRequestId:  pullRequest.Id,
-					IssueId:        issue.Id,
-					PullRequestKey: pullRequest.PullRequestKey,
-					IssueKey:       issue.IssueKey,
+			if op.HasStrategy(StrategyCommitMessage) {
+				var messages []string
+				err := db.Pluck("commits.message", &messages,
+					dal.From(&code.PullRequestCommit{}),
+					dal.Join("INNER JOIN commits ON commits.sha = pull_request_commits.commit_sha"),
+					dal.Where("pull_request_commits.pull_request_id = ?", pullRequest.Id),
+				)
+				if err != nil {
+					return nil, err
+				}
+				issues, err := findIssuesByKeys(findIssueKeys(data.CommitToIssueRegexp, messages...))
+				if err != nil {
+					return nil, err
+				}
+				for _, issue := range issues {
+					links.add(issue, StrategyCommitMessage)
 				}
-				result = append(result, pullRequestIssue)
 			}
 
-			return result, nil
+			return links.result(), nil
 		},
 	})
 	if err != nil {
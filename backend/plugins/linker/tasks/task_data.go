@@ -18,19 +18,56 @@ limitations under the License.
 package tasks
 
 import (
+	"fmt"
+	"regexp"
+
 	"github.com/apache/incubator-devlake/core/errors"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
-	"regexp"
 )
 
+// the strategies used to link pull requests to issues, ordered by how reliable they are
+const (
+	StrategyRemoteLink    = "remote_link"
+	StrategyPrText        = "pr_text"
+	StrategyBranchName    = "branch_name"
+	StrategyCommitMessage = "commit_message"
+)
+
+// StrategyConfidences holds the confidence of a link found by each strategy
+var StrategyConfidences = map[string]float64{
+	StrategyRemoteLink:    1.0,
+	StrategyPrText:        0.9,
+	StrategyBranchName:    0.8,
+	StrategyCommitMessage: 0.6,
+}
+
 type LinkerOptions struct {
-	PrToIssueRegexp string `json:"prToIssueRegexp"`
-	ProjectName     string `json:"projectName"`
+	PrToIssueRegexp     string `json:"prToIssueRegexp"`
+	BranchToIssueRegexp string `json:"branchToIssueRegexp"`
+	CommitToIssueRegexp string `json:"commitToIssueRegexp"`
+	// Strategies to be applied, only `pr_text` would be applied if left empty
+	Strategies  []string `json:"strategies"`
+	ProjectName string   `json:"projectName"`
 }
 
 type LinkerTaskData struct {
-	Options         *LinkerOptions
-	PrToIssueRegexp *regexp.Regexp
+	Options             *LinkerOptions
+	PrToIssueRegexp     *regexp.Regexp
+	BranchToIssueRegexp *regexp.Regexp
+	CommitToIssueRegexp *regexp.Regexp
+}
+
+// HasStrategy returns true if the given strategy is enabled
+func (op *LinkerOptions) HasStrategy(strategy string) bool {
+	if len(op.Strategies) == 0 {
+		return strategy == StrategyPrText
+	}
+	for _, s := range op.Strategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
 }
 
 func DecodeAndValidateTaskOptions(options map[string]interface{}) (*LinkerOptions, errors.Error) {
@@ -39,5 +76,10 @@ func DecodeAndValidateTaskOptions(options map[string]interface{}) (*LinkerOption
 	if err != nil {
 		return nil, errors.Default.Wrap(err, "error decoding linker task options")
 	}
+	for _, strategy := range op.Strategies {
+		if _, ok := StrategyConfidences[strategy]; !ok {
+			return nil, errors.BadInput.New(fmt.Sprintf("unknown linker strategy: %s", strategy))
+		}
+	}
 	return &op, nil
 }
@@ -23,15 +23,19 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/org/models"
 	"reflect"
 )
 
 type store interface {
 	findAllUsers() ([]user, errors.Error)
 	findAllTeams() ([]team, errors.Error)
+	findAllTeamUsers() ([]crossdomain.TeamUser, errors.Error)
 	findAllAccounts() ([]account, errors.Error)
 	findAllUserAccounts() ([]userAccount, errors.Error)
 	findAllProjectMapping() ([]projectMapping, errors.Error)
+	findUserAccountCandidates(status string, limit, offset int) ([]userAccountCandidate, int64, errors.Error)
+	reviewUserAccountCandidate(candidate *models.UserAccountCandidate) errors.Error
 	deleteAll(i interface{}) errors.Error
 	save(items []interface{}) errors.Error
 }
@@ -60,6 +64,15 @@ func (d *dbStore) findAllUsers() ([]user, errors.Error) {
 	}
 	return u.fromDomainLayer(uu, tus), nil
 }
+func (d *dbStore) findAllTeamUsers() ([]crossdomain.TeamUser, errors.Error) {
+	var tus []crossdomain.TeamUser
+	err := d.db.All(&tus)
+	if err != nil {
+		return nil, err
+	}
+	return tus, nil
+}
+
 func (d *dbStore) findAllTeams() ([]team, errors.Error) {
 	var tt []crossdomain.Team
 	err := d.db.All(&tt)
@@ -104,6 +117,78 @@ func (d *dbStore) findAllProjectMapping() ([]projectMapping, errors.Error) {
 	var pm *projectMapping
 	return pm.fromDomainLayer(mapping), nil
 }
+func (d *dbStore) findUserAccountCandidates(status string, limit, offset int) ([]userAccountCandidate, int64, errors.Error) {
+	clauses := []dal.Clause{
+		dal.From("_tool_org_user_account_candidates c"),
+		dal.Join("LEFT JOIN users u ON u.id = c.user_id"),
+		dal.Join("LEFT JOIN accounts a ON a.id = c.account_id"),
+	}
+	if status != "" {
+		clauses = append(clauses, dal.Where("c.status = ?", status))
+	}
+	count, err := d.db.Count(clauses...)
+	if err != nil {
+		return nil, 0, err
+	}
+	clauses = append(clauses,
+		dal.Select("c.*, u.name AS user_name, u.email AS user_email, a.full_name AS account_full_name, a.user_name AS account_user_name, a.email AS account_email"),
+		dal.Orderby("c.score DESC, c.account_id"),
+		dal.Limit(limit),
+		dal.Offset(offset),
+	)
+	var candidates []userAccountCandidate
+	err = d.db.All(&candidates, clauses...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return candidates, count, nil
+}
+
+// reviewUserAccountCandidate saves the decision on the candidate and applies it to user_accounts
+func (d *dbStore) reviewUserAccountCandidate(candidate *models.UserAccountCandidate) errors.Error {
+	existing := &models.UserAccountCandidate{}
+	err := d.db.First(existing, dal.Where("user_id = ? AND account_id = ?", candidate.UserId, candidate.AccountId))
+	if err != nil {
+		if d.db.IsErrorNotFound(err) {
+			return errors.NotFound.New("user account candidate not found")
+		}
+		return err
+	}
+	existing.Status = candidate.Status
+	existing.ReviewedBy = candidate.ReviewedBy
+	existing.ReviewedAt = candidate.ReviewedAt
+	*candidate = *existing
+
+	tx := d.db.Begin()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	err = tx.Update(existing)
+	if err != nil {
+		return err
+	}
+	if existing.Status == models.CandidateStatusAccepted {
+		// an account belongs to a single user, the other pending candidates are obsolete
+		err = tx.Delete(&models.UserAccountCandidate{}, dal.Where("account_id = ? AND user_id != ? AND status = ?", existing.AccountId, existing.UserId, models.CandidateStatusPending))
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&crossdomain.UserAccount{}, dal.Where("account_id = ?", existing.AccountId))
+		if err != nil {
+			return err
+		}
+		err = tx.Create(existing.ToUserAccount())
+	} else {
+		err = tx.Delete(&crossdomain.UserAccount{}, dal.Where("user_id = ? AND account_id = ?", existing.UserId, existing.AccountId))
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (d *dbStore) deleteAll(i interface{}) errors.Error {
 	return d.db.Delete(i, dal.Where("1=1"))
 }
@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+
+	"github.com/gocarina/gocsv"
+)
+
+// GetTeamUser returns all team memberships in csv format
+// @Summary      Get team_users.csv file
+// @Description  get team_users.csv file
+// @Tags 		 plugins/org
+// @Produce      text/csv
+// @Success      200
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/team_users.csv [get]
+func (h *Handlers) GetTeamUser(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	teamUsers, err := h.store.findAllTeamUsers()
+	if err != nil {
+		return nil, err
+	}
+	blob, err := errors.Convert01(gocsv.MarshalBytes((&teamUser{}).fromDomainLayer(teamUsers)))
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{
+		Body:   nil,
+		Status: http.StatusOK,
+		File: &plugin.OutputFile{
+			ContentType: "text/csv",
+			Data:        blob,
+		},
+	}, nil
+}
+
+// CreateTeamUser accepts a CSV file containing team memberships with optional start and end dates and saves it to the database
+// @Summary      Upload team_users.csv file
+// @Description  upload team_users.csv file, StartDate and EndDate are in the format of 2006-01-02
+// @Tags 		 plugins/org
+// @Accept       multipart/form-data
+// @Param        file formData file true "select file to upload"
+// @Produce      json
+// @Success      200
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/team_users.csv [put]
+func (h *Handlers) CreateTeamUser(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	var tt []teamUser
+	err := h.unmarshal(input.Request, &tt)
+	if err != nil {
+		return nil, err
+	}
+	teamUsers, err := (&teamUser{}).toDomainLayer(tt)
+	if err != nil {
+		return nil, err
+	}
+	var items []interface{}
+	for _, tu := range teamUsers {
+		items = append(items, tu)
+	}
+	err = h.store.deleteAll(&crossdomain.TeamUser{})
+	if err != nil {
+		return nil, err
+	}
+	err = h.store.save(items)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Status: http.StatusOK}, nil
+}
@@ -19,7 +19,9 @@ package api
 
 import (
 	"strings"
+	"time"
 
+	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models/common"
 	"github.com/apache/incubator-devlake/core/models/domainlayer"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
@@ -125,6 +127,69 @@ func (*user) fakeData() []user {
 	return fakeUsers
 }
 
+// teamUser is the membership of a user in a team, StartDate and EndDate are optional
+type teamUser struct {
+	TeamId    string
+	UserId    string
+	StartDate string
+	EndDate   string
+}
+
+func (*teamUser) fromDomainLayer(teamUsers []crossdomain.TeamUser) []teamUser {
+	var result []teamUser
+	for _, tu := range teamUsers {
+		result = append(result, teamUser{
+			TeamId:    tu.TeamId,
+			UserId:    tu.UserId,
+			StartDate: formatDate(tu.StartDate),
+			EndDate:   formatDate(tu.EndDate),
+		})
+	}
+	return result
+}
+
+func (*teamUser) toDomainLayer(tt []teamUser) ([]*crossdomain.TeamUser, errors.Error) {
+	var teamUsers []*crossdomain.TeamUser
+	for _, t := range tt {
+		if t.TeamId == "" || t.UserId == "" {
+			continue
+		}
+		startDate, err := parseDate(t.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		endDate, err := parseDate(t.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		teamUsers = append(teamUsers, &crossdomain.TeamUser{
+			TeamId:    t.TeamId,
+			UserId:    t.UserId,
+			StartDate: startDate,
+			EndDate:   endDate,
+		})
+	}
+	return teamUsers, nil
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(TimeFormat)
+}
+
+func parseDate(s string) (*time.Time, errors.Error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(TimeFormat, s)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid date, the format should be "+TimeFormat)
+	}
+	return &t, nil
+}
+
 type account struct {
 	Id           string
 	Email        string
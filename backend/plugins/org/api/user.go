@@ -81,6 +81,20 @@ func (h *Handlers) CreateUser(input *plugin.ApiResourceInput) (*plugin.ApiResour
 	}
 	var items []interface{}
 	users, teamUsers := (&user{}).toDomainLayer(uu)
+	// keep the time bounds of the memberships which are managed by team_users.csv
+	existing, err := h.store.findAllTeamUsers()
+	if err != nil {
+		return nil, err
+	}
+	bounds := make(map[string]crossdomain.TeamUser)
+	for _, tu := range existing {
+		bounds[tu.TeamId+":"+tu.UserId] = tu
+	}
+	for _, tu := range teamUsers {
+		if b, ok := bounds[tu.TeamId+":"+tu.UserId]; ok {
+			tu.StartDate, tu.EndDate = b.StartDate, b.EndDate
+		}
+	}
 	for _, user := range users {
 		items = append(items, user)
 	}
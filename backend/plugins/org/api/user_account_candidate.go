@@ -0,0 +1,131 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+)
+
+type userAccountCandidate struct {
+	models.UserAccountCandidate
+	UserName        string `json:"userName"`
+	UserEmail       string `json:"userEmail"`
+	AccountFullName string `json:"accountFullName"`
+	AccountUserName string `json:"accountUserName"`
+	AccountEmail    string `json:"accountEmail"`
+}
+
+type userAccountCandidates struct {
+	Count      int64                  `json:"count"`
+	Candidates []userAccountCandidate `json:"candidates"`
+}
+
+type reviewUserAccountCandidateRequest struct {
+	UserId    string `json:"userId"`
+	AccountId string `json:"accountId"`
+}
+
+// GetUserAccountCandidates returns the candidates of user/account association found by fuzzy matching
+// @Summary      Get user account candidates
+// @Description  get user account candidates, sorted by score desc
+// @Tags 		 plugins/org
+// @Produce      json
+// @Param        status    query     string  false  "PENDING, ACCEPTED or REJECTED"
+// @Param        page      query     int     false  "page number"
+// @Param        pageSize  query     int     false  "page size"
+// @Success      200  {object} userAccountCandidates
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/user_account_candidates [get]
+func (h *Handlers) GetUserAccountCandidates(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	status := input.Query.Get("status")
+	if status != "" && status != models.CandidateStatusPending && status != models.CandidateStatusAccepted && status != models.CandidateStatusRejected {
+		return nil, errors.BadInput.New("status must be one of PENDING, ACCEPTED and REJECTED")
+	}
+	limit, offset := helper.GetLimitOffset(input.Query, "pageSize", "page")
+	candidates, count, err := h.store.findUserAccountCandidates(status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{
+		Body:   userAccountCandidates{Count: count, Candidates: candidates},
+		Status: http.StatusOK,
+	}, nil
+}
+
+// AcceptUserAccountCandidate associates the account with the user and keeps the decision across syncs
+// @Summary      Accept a user account candidate
+// @Description  accept a user account candidate, the account is associated with the user in user_accounts
+// @Tags 		 plugins/org
+// @Accept       application/json
+// @Param        body body reviewUserAccountCandidateRequest true "the candidate to accept"
+// @Produce      json
+// @Success      200  {object} models.UserAccountCandidate
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/user_account_candidates/accept [post]
+func (h *Handlers) AcceptUserAccountCandidate(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	return h.reviewUserAccountCandidate(input, models.CandidateStatusAccepted)
+}
+
+// RejectUserAccountCandidate prevents the account from being associated with the user across syncs
+// @Summary      Reject a user account candidate
+// @Description  reject a user account candidate, the association is removed from user_accounts if exists
+// @Tags 		 plugins/org
+// @Accept       application/json
+// @Param        body body reviewUserAccountCandidateRequest true "the candidate to reject"
+// @Produce      json
+// @Success      200  {object} models.UserAccountCandidate
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/user_account_candidates/reject [post]
+func (h *Handlers) RejectUserAccountCandidate(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	return h.reviewUserAccountCandidate(input, models.CandidateStatusRejected)
+}
+
+func (h *Handlers) reviewUserAccountCandidate(input *plugin.ApiResourceInput, status string) (*plugin.ApiResourceOutput, errors.Error) {
+	var req reviewUserAccountCandidateRequest
+	err := helper.Decode(input.Body, &req, nil)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid user account candidate")
+	}
+	if req.UserId == "" || req.AccountId == "" {
+		return nil, errors.BadInput.New("userId and accountId are required")
+	}
+	now := time.Now()
+	candidate := &models.UserAccountCandidate{
+		UserId:     req.UserId,
+		AccountId:  req.AccountId,
+		Status:     status,
+		ReviewedAt: &now,
+	}
+	if input.User != nil {
+		candidate.ReviewedBy = input.User.Name
+	}
+	err = h.store.reviewUserAccountCandidate(candidate)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: candidate, Status: http.StatusOK}, nil
+}
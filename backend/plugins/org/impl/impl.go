@@ -25,6 +25,8 @@ import (
 	"github.com/apache/incubator-devlake/core/plugin"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"github.com/apache/incubator-devlake/plugins/org/api"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+	"github.com/apache/incubator-devlake/plugins/org/models/migrationscripts"
 	"github.com/apache/incubator-devlake/plugins/org/tasks"
 )
 
@@ -33,6 +35,7 @@ var _ interface {
 	plugin.PluginInit
 	plugin.PluginTask
 	plugin.PluginModel
+	plugin.PluginMigration
 	plugin.ProjectMapper
 } = (*Org)(nil)
 
@@ -46,7 +49,9 @@ func (p *Org) Init(basicRes context.BasicRes) errors.Error {
 }
 
 func (p Org) GetTablesInfo() []dal.Tabler {
-	return []dal.Tabler{}
+	return []dal.Tabler{
+		&models.UserAccountCandidate{},
+	}
 }
 
 func (p Org) Description() string {
@@ -60,6 +65,7 @@ func (p Org) Name() string {
 func (p Org) SubTaskMetas() []plugin.SubTaskMeta {
 	return []plugin.SubTaskMeta{
 		tasks.ConnectUserAccountsExactMeta,
+		tasks.ConnectUserAccountsFuzzyMeta,
 		tasks.SetProjectMappingMeta,
 		tasks.SleepMeta,
 	}
@@ -98,6 +104,10 @@ func (p Org) PrepareTaskData(taskCtx plugin.TaskContext, options map[string]inte
 	return taskData, nil
 }
 
+func (p Org) MigrationScripts() []plugin.MigrationScript {
+	return migrationscripts.All()
+}
+
 func (p Org) RootPkgPath() string {
 	return "github.com/apache/incubator-devlake/plugins/org"
 }
@@ -112,11 +122,24 @@ func (p Org) ApiResources() map[string]map[string]plugin.ApiResourceHandler {
 			"GET": p.handlers.GetUser,
 			"PUT": p.handlers.CreateUser,
 		},
+		"team_users.csv": {
+			"GET": p.handlers.GetTeamUser,
+			"PUT": p.handlers.CreateTeamUser,
+		},
 
 		"user_account_mapping.csv": {
 			"GET": p.handlers.GetUserAccountMapping,
 			"PUT": p.handlers.CreateUserAccountMapping,
 		},
+		"user_account_candidates": {
+			"GET": p.handlers.GetUserAccountCandidates,
+		},
+		"user_account_candidates/accept": {
+			"POST": p.handlers.AcceptUserAccountCandidate,
+		},
+		"user_account_candidates/reject": {
+			"POST": p.handlers.RejectUserAccountCandidate,
+		},
 		"project_mapping.csv": {
 			"GET": p.handlers.GetProjectMapping,
 			"PUT": p.handlers.CreateProjectMapping,
@@ -0,0 +1,63 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+)
+
+const (
+	CandidateStatusPending  = "PENDING"
+	CandidateStatusAccepted = "ACCEPTED"
+	CandidateStatusRejected = "REJECTED"
+)
+
+// UserAccountCandidate is a possible association between a user and an account found by fuzzy matching.
+// Reviewed candidates keep their status across syncs, accepted ones are written to user_accounts
+// and rejected ones are never linked again.
+type UserAccountCandidate struct {
+	common.NoPKModel
+	UserId     string     `gorm:"primaryKey;type:varchar(255)" json:"userId"`
+	AccountId  string     `gorm:"primaryKey;type:varchar(255)" json:"accountId"`
+	Score      float64    `json:"score"`
+	Reasons    string     `gorm:"type:varchar(255)" json:"reasons"`
+	Status     string     `gorm:"type:varchar(20);index" json:"status"`
+	ReviewedBy string     `gorm:"type:varchar(255)" json:"reviewedBy"`
+	ReviewedAt *time.Time `json:"reviewedAt"`
+}
+
+func (UserAccountCandidate) TableName() string {
+	return "_tool_org_user_account_candidates"
+}
+
+// ToUserAccount creates the user_accounts record of an accepted candidate,
+// the raw data origin tells it apart from the records produced by connectUserAccountsExact
+func (c UserAccountCandidate) ToUserAccount() *crossdomain.UserAccount {
+	return &crossdomain.UserAccount{
+		UserId:    c.UserId,
+		AccountId: c.AccountId,
+		NoPKModel: common.NoPKModel{
+			RawDataOrigin: common.RawDataOrigin{
+				RawDataTable: c.TableName(),
+			},
+		},
+	}
+}
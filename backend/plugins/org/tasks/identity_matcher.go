@@ -0,0 +1,278 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	reasonEmail          = "email"
+	reasonCommitEmail    = "commit_email"
+	reasonGithubNoreply  = "github_noreply"
+	reasonEmailLocalPart = "email_local_part"
+	reasonNameSimilarity = "name_similarity"
+	reasonNameInitials   = "name_initials"
+
+	defaultMinScore = 0.6
+	// nameSimilarityThreshold is the minimum Jaro-Winkler similarity for two names to be considered a match
+	nameSimilarityThreshold = 0.88
+	maxCandidatesPerAccount = 3
+)
+
+var githubNoreplyPattern = regexp.MustCompile(`^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$`)
+
+// identity collects the normalized emails, names and logins known for a user
+type identity struct {
+	userId       string
+	emails       map[string]bool
+	commitEmails map[string]bool
+	localParts   map[string]bool
+	names        map[string]bool
+	logins       map[string]bool
+}
+
+func newIdentity(userId, name, email string) *identity {
+	id := &identity{
+		userId:       userId,
+		emails:       map[string]bool{},
+		commitEmails: map[string]bool{},
+		localParts:   map[string]bool{},
+		names:        map[string]bool{},
+		logins:       map[string]bool{},
+	}
+	if e := normalizeEmail(email); e != "" {
+		id.emails[e] = true
+		id.addEmailParts(e)
+	}
+	id.addName(name)
+	return id
+}
+
+func (id *identity) addName(name string) {
+	if n := normalizeName(name); n != "" {
+		id.names[n] = true
+	}
+}
+
+func (id *identity) addEmailParts(email string) {
+	if login := githubNoreplyLogin(email); login != "" {
+		id.logins[login] = true
+		return
+	}
+	if local := emailLocalPart(email); local != "" {
+		id.localParts[local] = true
+	}
+}
+
+// addCommitAuthor extends the identity with the author of a commit which is known to belong to the user
+func (id *identity) addCommitAuthor(name, email string) {
+	if e := normalizeEmail(email); e != "" && !id.emails[e] {
+		id.commitEmails[e] = true
+		id.addEmailParts(e)
+	}
+	id.addName(name)
+}
+
+// match returns the score of the account belonging to the user along with the reasons, 0 means no match
+func (id *identity) match(email, fullName, userName string) (float64, []string) {
+	var score float64
+	var reasons []string
+	hit := func(s float64, reason string) {
+		reasons = append(reasons, reason)
+		if s > score {
+			score = s
+		}
+	}
+	email = normalizeEmail(email)
+	login := strings.ToLower(strings.TrimSpace(userName))
+	if email != "" {
+		noreply := githubNoreplyLogin(email)
+		if noreply != "" {
+			login = noreply
+		}
+		if id.emails[email] {
+			hit(0.95, reasonEmail)
+		} else if id.commitEmails[email] {
+			hit(0.85, reasonCommitEmail)
+		} else if local := emailLocalPart(email); noreply == "" && local != "" && id.localParts[local] {
+			hit(0.7, reasonEmailLocalPart)
+		}
+	}
+	if login != "" && id.logins[login] {
+		hit(0.9, reasonGithubNoreply)
+	}
+	var similarity float64
+	for _, accountName := range []string{normalizeName(fullName), normalizeName(userName)} {
+		if accountName == "" {
+			continue
+		}
+		for name := range id.names {
+			if s := nameSimilarity(accountName, name); s > similarity {
+				similarity = s
+			}
+		}
+	}
+	if similarity >= nameSimilarityThreshold {
+		hit(0.8*similarity, reasonNameSimilarity)
+	}
+	if login != "" {
+		compact := compactName(login)
+		for name := range id.names {
+			if matchInitials(compact, name) {
+				hit(0.6, reasonNameInitials)
+				break
+			}
+		}
+	}
+	return score, reasons
+}
+
+// normalizeEmail lowercases the email and strips the +tag of the local part
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return ""
+	}
+	local, domain := email[:at], email[at+1:]
+	if domain != "users.noreply.github.com" {
+		if plus := strings.Index(local, "+"); plus > 0 {
+			local = local[:plus]
+		}
+	}
+	return local + "@" + domain
+}
+
+// emailLocalPart returns the part of email before @, with separators removed, jsmith@corp and j.smith@mail are the same
+func emailLocalPart(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return ""
+	}
+	return compactName(email[:at])
+}
+
+// githubNoreplyLogin extracts the login from github noreply emails like 12345+login@users.noreply.github.com
+func githubNoreplyLogin(email string) string {
+	m := githubNoreplyPattern.FindStringSubmatch(email)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// normalizeName lowercases the name and turns separators into single spaces, john-smith and John Smith are the same
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+func compactName(name string) string {
+	return strings.ReplaceAll(normalizeName(name), " ", "")
+}
+
+// matchInitials checks if login is made of the initial of the first name and the last name, e.g. jsmith or smithj
+func matchInitials(login string, name string) bool {
+	words := strings.Fields(name)
+	if len(words) < 2 || login == "" {
+		return false
+	}
+	first, last := words[0], words[len(words)-1]
+	return login == first[:1]+last || login == last+first[:1]
+}
+
+// nameSimilarity compares two normalized names with Jaro-Winkler similarity regardless of the order of words
+func nameSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	s := jaroWinkler(a, b)
+	if sorted := jaroWinkler(sortWords(a), sortWords(b)); sorted > s {
+		s = sorted
+	}
+	return s
+}
+
+func sortWords(name string) string {
+	words := strings.Fields(name)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	window := len(ra)
+	if len(rb) > window {
+		window = len(rb)
+	}
+	window = window/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := i-window, i+window+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(rb) {
+			hi = len(rb)
+		}
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	transpositions, j := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+	prefix := 0
+	for prefix < 4 && prefix < len(ra) && prefix < len(rb) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
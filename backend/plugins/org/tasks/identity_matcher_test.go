@@ -0,0 +1,105 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "jsmith@corp.com", normalizeEmail(" JSmith+jira@Corp.com "))
+	assert.Equal(t, "123+john-smith@users.noreply.github.com", normalizeEmail("123+john-smith@users.noreply.github.com"))
+	assert.Equal(t, "", normalizeEmail("john smith"))
+}
+
+func TestGithubNoreplyLogin(t *testing.T) {
+	assert.Equal(t, "john-smith", githubNoreplyLogin("123+john-smith@users.noreply.github.com"))
+	assert.Equal(t, "john-smith", githubNoreplyLogin("john-smith@users.noreply.github.com"))
+	assert.Equal(t, "", githubNoreplyLogin("john-smith@corp.com"))
+}
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, "john smith", normalizeName("John-Smith"))
+	assert.Equal(t, 1.0, nameSimilarity(normalizeName("Smith, John"), normalizeName("john smith")))
+	assert.Greater(t, nameSimilarity("jon smith", "john smith"), nameSimilarityThreshold)
+	assert.Less(t, nameSimilarity("jane doe", "john smith"), nameSimilarityThreshold)
+	assert.True(t, matchInitials("jsmith", "john smith"))
+	assert.True(t, matchInitials("smithj", "john smith"))
+	assert.False(t, matchInitials("jdoe", "john smith"))
+}
+
+func TestIdentityMatch(t *testing.T) {
+	id := newIdentity("user1", "John Smith", "jsmith@corp.com")
+	id.addCommitAuthor("John Smith", "123+john-smith@users.noreply.github.com")
+	id.addCommitAuthor("John Smith", "john@home.org")
+
+	score, reasons := id.match("JSmith+jira@corp.com", "", "")
+	assert.Equal(t, 0.95, score)
+	assert.Equal(t, []string{reasonEmail}, reasons)
+
+	score, reasons = id.match("", "", "john-smith")
+	assert.Equal(t, 0.9, score)
+	assert.Equal(t, []string{reasonGithubNoreply, reasonNameSimilarity}, reasons)
+
+	score, reasons = id.match("john@home.org", "Johnny", "johnny")
+	assert.Equal(t, 0.85, score)
+	assert.Equal(t, []string{reasonCommitEmail}, reasons)
+
+	score, reasons = id.match("", "John Smith", "")
+	assert.Equal(t, 0.8, score)
+	assert.Equal(t, []string{reasonNameSimilarity}, reasons)
+
+	score, reasons = id.match("jsmith@jenkins.local", "", "")
+	assert.Equal(t, 0.7, score)
+	assert.Equal(t, []string{reasonEmailLocalPart}, reasons)
+
+	score, reasons = id.match("", "", "smithj")
+	assert.Equal(t, 0.6, score)
+	assert.Equal(t, []string{reasonNameInitials}, reasons)
+
+	score, _ = id.match("jane@corp.com", "Jane Doe", "jdoe")
+	assert.Equal(t, 0.0, score)
+}
+
+func TestFindCandidates(t *testing.T) {
+	identities := []*identity{
+		newIdentity("user1", "John Smith", "jsmith@corp.com"),
+		newIdentity("user2", "Jon Smyth", "jon@corp.com"),
+	}
+	account := &crossdomain.Account{
+		DomainEntity: domainlayer.DomainEntity{Id: "github:GithubAccount:1:1"},
+		FullName:     "John Smith",
+	}
+
+	candidates := findCandidates(identities, account, defaultMinScore, map[string]bool{})
+	assert.Len(t, candidates, 2)
+	assert.Equal(t, "user1", candidates[0].UserId)
+	assert.Equal(t, "user2", candidates[1].UserId)
+	assert.True(t, autoAccept(candidates, 0.8))
+	assert.Equal(t, "ACCEPTED", candidates[0].Status)
+	assert.Equal(t, "PENDING", candidates[1].Status)
+
+	candidates = findCandidates(identities, account, defaultMinScore, map[string]bool{"user1:github:GithubAccount:1:1": true})
+	assert.Len(t, candidates, 1)
+	assert.Equal(t, "user2", candidates[0].UserId)
+	assert.False(t, autoAccept(candidates, 0.9))
+}
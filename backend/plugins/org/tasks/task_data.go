@@ -23,6 +23,10 @@ type Options struct {
 	ConnectionId    uint64           `json:"connectionId"`
 	ProjectMappings []ProjectMapping `json:"projectMappings"`
 	SleepSeconds    uint64           `json:"sleepSeconds"`
+	// MinScore is the minimum score for a user account candidate, 0.6 by default
+	MinScore float64 `json:"minScore"`
+	// AutoAcceptScore accepts the best candidate of an account when it reaches the score, disabled when 0
+	AutoAcceptScore float64 `json:"autoAcceptScore"`
 }
 
 // ProjectMapping represents the relations between project and scopes
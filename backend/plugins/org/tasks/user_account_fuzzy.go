@@ -0,0 +1,219 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+)
+
+var ConnectUserAccountsFuzzyMeta = plugin.SubTaskMeta{
+	Name:             "connectUserAccountsFuzzy",
+	EntryPoint:       ConnectUserAccountsFuzzy,
+	EnabledByDefault: true,
+	Description:      "find candidate associations between users and accounts by normalized emails, name similarity, commit authors and github noreply emails",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS},
+	DependencyTables: []string{"users", "accounts", "commits", "user_accounts"},
+	ProductTables:    []string{models.UserAccountCandidate{}.TableName(), "user_accounts"},
+}
+
+type commitAuthor struct {
+	AuthorName  string
+	AuthorEmail string
+}
+
+// ConnectUserAccountsFuzzy generates scored candidates for accounts which are not associated with any user yet.
+// decisions made on the candidates are kept: accepted ones are restored into user_accounts and rejected ones are skipped.
+func ConnectUserAccountsFuzzy(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+	data := taskCtx.GetData().(*TaskData)
+
+	var reviewed []models.UserAccountCandidate
+	err := db.All(&reviewed, dal.Where("status != ?", models.CandidateStatusPending))
+	if err != nil {
+		return err
+	}
+	rejected := make(map[string]bool)
+	for _, candidate := range reviewed {
+		switch candidate.Status {
+		case models.CandidateStatusRejected:
+			rejected[candidate.UserId+":"+candidate.AccountId] = true
+		case models.CandidateStatusAccepted:
+			err = db.CreateOrUpdate(candidate.ToUserAccount())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// pending candidates are recalculated from scratch
+	err = db.Delete(&models.UserAccountCandidate{}, dal.Where("status = ?", models.CandidateStatusPending))
+	if err != nil {
+		return err
+	}
+
+	identities, err := loadIdentities(db)
+	if err != nil {
+		return err
+	}
+	if len(identities) == 0 {
+		return nil
+	}
+
+	cursor, err := db.Cursor(
+		dal.From(&crossdomain.Account{}),
+		dal.Where("id NOT IN (SELECT account_id FROM user_accounts)"),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	divider := api.NewBatchSaveDivider(taskCtx, 500, "", "")
+	candidateSaver, err := divider.ForType(reflect.TypeOf(&models.UserAccountCandidate{}))
+	if err != nil {
+		return err
+	}
+	userAccountSaver, err := divider.ForType(reflect.TypeOf(&crossdomain.UserAccount{}))
+	if err != nil {
+		return err
+	}
+	minScore := data.Options.MinScore
+	if minScore <= 0 {
+		minScore = defaultMinScore
+	}
+	count := 0
+	for cursor.Next() {
+		account := &crossdomain.Account{}
+		err = db.Fetch(cursor, account)
+		if err != nil {
+			return err
+		}
+		candidates := findCandidates(identities, account, minScore, rejected)
+		if len(candidates) == 0 {
+			continue
+		}
+		if autoAccept(candidates, data.Options.AutoAcceptScore) {
+			err = userAccountSaver.Add(candidates[0].ToUserAccount())
+			if err != nil {
+				return err
+			}
+		}
+		for _, candidate := range candidates {
+			err = candidateSaver.Add(candidate)
+			if err != nil {
+				return err
+			}
+		}
+		count += len(candidates)
+	}
+	logger.Info("found %d user account candidates", count)
+	return divider.Close()
+}
+
+// loadIdentities builds identities of all users, extended with the commit authors sharing their emails or names
+func loadIdentities(db dal.Dal) ([]*identity, errors.Error) {
+	var users []crossdomain.User
+	err := db.All(&users)
+	if err != nil {
+		return nil, err
+	}
+	identities := make([]*identity, 0, len(users))
+	byEmail := make(map[string]*identity)
+	byName := make(map[string]*identity)
+	for _, user := range users {
+		id := newIdentity(user.Id, user.Name, user.Email)
+		identities = append(identities, id)
+		if email := normalizeEmail(user.Email); email != "" {
+			byEmail[email] = id
+		}
+		if name := normalizeName(user.Name); name != "" {
+			if _, ok := byName[name]; ok {
+				// ambiguous names are not used to attribute commit authors
+				byName[name] = nil
+			} else {
+				byName[name] = id
+			}
+		}
+	}
+	var authors []commitAuthor
+	err = db.All(&authors, dal.Select("DISTINCT author_name, author_email"), dal.From("commits"))
+	if err != nil {
+		return nil, err
+	}
+	for _, author := range authors {
+		if id := byEmail[normalizeEmail(author.AuthorEmail)]; id != nil {
+			id.addCommitAuthor(author.AuthorName, author.AuthorEmail)
+		} else if id := byName[normalizeName(author.AuthorName)]; id != nil {
+			id.addCommitAuthor(author.AuthorName, author.AuthorEmail)
+		}
+	}
+	return identities, nil
+}
+
+// findCandidates returns the best scored candidates of the account, sorted by score desc
+func findCandidates(identities []*identity, account *crossdomain.Account, minScore float64, rejected map[string]bool) []*models.UserAccountCandidate {
+	var candidates []*models.UserAccountCandidate
+	for _, id := range identities {
+		if rejected[id.userId+":"+account.Id] {
+			continue
+		}
+		score, reasons := id.match(account.Email, account.FullName, account.UserName)
+		if score < minScore {
+			continue
+		}
+		candidates = append(candidates, &models.UserAccountCandidate{
+			UserId:    id.userId,
+			AccountId: account.Id,
+			Score:     score,
+			Reasons:   strings.Join(reasons, ","),
+			Status:    models.CandidateStatusPending,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > maxCandidatesPerAccount {
+		candidates = candidates[:maxCandidatesPerAccount]
+	}
+	return candidates
+}
+
+// autoAccept accepts the top candidate if it reaches the threshold and no other candidate has the same score
+func autoAccept(candidates []*models.UserAccountCandidate, threshold float64) bool {
+	if threshold <= 0 || len(candidates) == 0 || candidates[0].Score < threshold {
+		return false
+	}
+	if len(candidates) > 1 && candidates[1].Score >= candidates[0].Score {
+		return false
+	}
+	now := time.Now()
+	candidates[0].Status = models.CandidateStatusAccepted
+	candidates[0].ReviewedBy = "auto"
+	candidates[0].ReviewedAt = &now
+	return true
+}
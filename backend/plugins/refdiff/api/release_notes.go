@@ -0,0 +1,410 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/refdiff/models"
+	"github.com/apache/incubator-devlake/plugins/refdiff/utils"
+)
+
+const otherChanges = "other"
+
+// commitTypeTitles are the sections of the release notes, in order, for the conventional commit types
+var commitTypeTitles = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+	{"refactor", "Code Refactoring"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"style", "Styles"},
+	{"chore", "Chores"},
+	{otherChanges, "Other Changes"},
+}
+
+type ReleaseNoteCommit struct {
+	Sha            string    `json:"sha"`
+	Message        string    `json:"message"`
+	Type           string    `json:"type"`
+	Scope          string    `json:"scope"`
+	Breaking       bool      `json:"breaking"`
+	Description    string    `json:"description"`
+	AuthorName     string    `json:"authorName"`
+	AuthoredDate   time.Time `json:"authoredDate"`
+	PullRequestIds []string  `json:"pullRequestIds"`
+}
+
+type ReleaseNoteCommitGroup struct {
+	Type    string               `json:"type"`
+	Title   string               `json:"title"`
+	Commits []*ReleaseNoteCommit `json:"commits"`
+}
+
+type ReleaseNotePullRequest struct {
+	Id             string     `json:"id"`
+	PullRequestKey int        `json:"pullRequestKey"`
+	Title          string     `json:"title"`
+	Url            string     `json:"url"`
+	AuthorName     string     `json:"authorName"`
+	MergedDate     *time.Time `json:"mergedDate"`
+}
+
+type ReleaseNoteIssue struct {
+	Id       string `json:"id"`
+	IssueKey string `json:"issueKey"`
+	Title    string `json:"title"`
+	Url      string `json:"url"`
+	Type     string `json:"type"`
+}
+
+type ReleaseNoteIssueGroup struct {
+	Type   string              `json:"type"`
+	Issues []*ReleaseNoteIssue `json:"issues"`
+}
+
+type ReleaseNotes struct {
+	RepoId          string                    `json:"repoId"`
+	From            string                    `json:"from"`
+	To              string                    `json:"to"`
+	FromCommitSha   string                    `json:"fromCommitSha"`
+	ToCommitSha     string                    `json:"toCommitSha"`
+	BreakingChanges []*ReleaseNoteCommit      `json:"breakingChanges"`
+	CommitGroups    []*ReleaseNoteCommitGroup `json:"commitGroups"`
+	PullRequests    []*ReleaseNotePullRequest `json:"pullRequests"`
+	IssueGroups     []*ReleaseNoteIssueGroup  `json:"issueGroups"`
+}
+
+type releaseNoteCommitPr struct {
+	CommitSha     string
+	PullRequestId string
+}
+
+type releaseNoteCommitRow struct {
+	Sha          string
+	Message      string
+	AuthorName   string
+	AuthoredDate time.Time
+}
+
+type releaseNoteIssuePr struct {
+	ReleaseNoteIssue
+	PullRequestId string
+}
+
+// GetReleaseNotes renders the release notes between two refs of a repo
+// @Summary get the release notes between two refs
+// @Description render the commits, pull requests and issues between two refs calculated by refdiff,
+// @Description the commits are grouped by their conventional commit type and the issues by their type
+// @Tags plugins/refdiff
+// @Produce json,text/markdown
+// @Param repoId path string true "repo id"
+// @Param from query string true "the previous tag or ref, e.g. v1.2.0"
+// @Param to query string true "the new tag or ref, e.g. v1.3.0"
+// @Param format query string false "markdown or json, json by default"
+// @Success 200  {object} ReleaseNotes
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router /plugins/refdiff/repos/{repoId}/release-notes [get]
+func GetReleaseNotes(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	repoId := input.Params["repoId"]
+	from := input.Query.Get("from")
+	to := input.Query.Get("to")
+	format := input.Query.Get("format")
+	if from == "" || to == "" {
+		return nil, errors.BadInput.New("both from and to are required")
+	}
+	if format != "" && format != "json" && format != "markdown" {
+		return nil, errors.BadInput.New("format must be markdown or json")
+	}
+	notes, err := loadReleaseNotes(basicRes.GetDal(), repoId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if format == "markdown" {
+		return &plugin.ApiResourceOutput{
+			File: &plugin.OutputFile{
+				ContentType: "text/markdown; charset=utf-8",
+				Data:        []byte(RenderReleaseNotesMarkdown(notes)),
+			},
+		}, nil
+	}
+	return &plugin.ApiResourceOutput{Body: notes, Status: http.StatusOK}, nil
+}
+
+// findRefCommitSha returns the commit of the ref, tags may be given without the `refs/tags/` prefix
+func findRefCommitSha(db dal.Dal, repoId, refName string) (string, errors.Error) {
+	for _, name := range []string{"refs/tags/" + refName, refName} {
+		ref := &code.Ref{}
+		err := db.First(ref, dal.Where("id = ?", fmt.Sprintf("%s:%s", repoId, name)))
+		if err == nil {
+			return ref.CommitSha, nil
+		}
+		if !db.IsErrorNotFound(err) {
+			return "", err
+		}
+	}
+	return "", errors.NotFound.New(fmt.Sprintf("ref %s not found in repo %s", refName, repoId))
+}
+
+func loadReleaseNotes(db dal.Dal, repoId, from, to string) (*ReleaseNotes, errors.Error) {
+	notes := &ReleaseNotes{RepoId: repoId, From: from, To: to}
+	var err errors.Error
+	notes.FromCommitSha, err = findRefCommitSha(db, repoId, from)
+	if err != nil {
+		return nil, err
+	}
+	notes.ToCommitSha, err = findRefCommitSha(db, repoId, to)
+	if err != nil {
+		return nil, err
+	}
+	finished, err := db.Count(
+		dal.From(&models.FinishedCommitsDiff{}),
+		dal.Where("new_commit_sha = ? AND old_commit_sha = ?", notes.ToCommitSha, notes.FromCommitSha),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if finished == 0 {
+		return nil, errors.NotFound.New(fmt.Sprintf("the diff between %s and %s has not been calculated by refdiff yet", from, to))
+	}
+
+	var commits []releaseNoteCommitRow
+	err = db.All(&commits,
+		dal.Select("c.sha, c.message, c.author_name, c.authored_date"),
+		dal.From("commits_diffs cd"),
+		dal.Join("JOIN commits c ON c.sha = cd.commit_sha"),
+		dal.Where("cd.new_commit_sha = ? AND cd.old_commit_sha = ?", notes.ToCommitSha, notes.FromCommitSha),
+		dal.Orderby("cd.sorting_index"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var commitPrs []releaseNoteCommitPr
+	var pullRequests []*ReleaseNotePullRequest
+	var issuePrs []releaseNoteIssuePr
+	if len(commits) > 0 {
+		shas := make([]string, 0, len(commits))
+		for _, commit := range commits {
+			shas = append(shas, commit.Sha)
+		}
+		err = db.All(&commitPrs,
+			dal.Select("_combine_pr.commit_sha, _combine_pr.pull_request_id"),
+			dal.From(`(
+			select prc.pull_request_id, prc.commit_sha from pull_request_commits prc
+				join pull_requests p on prc.pull_request_id = p.id
+				where p.base_repo_id = ?
+			union
+			select id as pull_request_id, merge_commit_sha as commit_sha from pull_requests where base_repo_id = ?) _combine_pr`, repoId, repoId),
+			dal.Where("_combine_pr.commit_sha IN ?", shas),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(commitPrs) > 0 {
+		prIds := make([]string, 0, len(commitPrs))
+		for _, commitPr := range commitPrs {
+			prIds = append(prIds, commitPr.PullRequestId)
+		}
+		err = db.All(&pullRequests,
+			dal.Select("id, pull_request_key, title, url, author_name, merged_date"),
+			dal.From("pull_requests"),
+			dal.Where("id IN ?", prIds),
+			dal.Orderby("pull_request_key"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = db.All(&issuePrs,
+			dal.Select("i.id, i.issue_key, i.title, i.url, i.type, pri.pull_request_id"),
+			dal.From("pull_request_issues pri"),
+			dal.Join("JOIN issues i ON i.id = pri.issue_id"),
+			dal.Where("pri.pull_request_id IN ?", prIds),
+			dal.Orderby("i.issue_key"),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buildReleaseNotes(notes, commits, commitPrs, pullRequests, issuePrs)
+	return notes, nil
+}
+
+// buildReleaseNotes links the commits to their pull requests and groups the commits and the issues
+func buildReleaseNotes(
+	notes *ReleaseNotes,
+	commitRows []releaseNoteCommitRow,
+	commitPrs []releaseNoteCommitPr,
+	pullRequests []*ReleaseNotePullRequest,
+	issuePrs []releaseNoteIssuePr,
+) {
+	prsByCommit := make(map[string][]string)
+	for _, commitPr := range commitPrs {
+		prsByCommit[commitPr.CommitSha] = append(prsByCommit[commitPr.CommitSha], commitPr.PullRequestId)
+	}
+	groups := make(map[string]*ReleaseNoteCommitGroup)
+	for _, row := range commitRows {
+		commit := &ReleaseNoteCommit{
+			Sha:            row.Sha,
+			Message:        strings.TrimSpace(strings.SplitN(row.Message, "\n", 2)[0]),
+			AuthorName:     row.AuthorName,
+			AuthoredDate:   row.AuthoredDate,
+			PullRequestIds: prsByCommit[row.Sha],
+		}
+		sort.Strings(commit.PullRequestIds)
+		commit.Type = otherChanges
+		commit.Description = commit.Message
+		if cc := utils.ParseConventionalCommit(row.Message); cc != nil {
+			commit.Type = cc.Type
+			commit.Scope = cc.Scope
+			commit.Breaking = cc.Breaking
+			commit.Description = cc.Description
+		}
+		if commit.Breaking {
+			notes.BreakingChanges = append(notes.BreakingChanges, commit)
+		}
+		groupType := commit.Type
+		if !isKnownCommitType(groupType) {
+			// keep the whole header so the unknown type is still visible among the other changes
+			groupType = otherChanges
+			commit.Description = commit.Message
+		}
+		if groups[groupType] == nil {
+			groups[groupType] = &ReleaseNoteCommitGroup{Type: groupType}
+		}
+		groups[groupType].Commits = append(groups[groupType].Commits, commit)
+	}
+	for _, t := range commitTypeTitles {
+		if group := groups[t.Type]; group != nil {
+			group.Title = t.Title
+			notes.CommitGroups = append(notes.CommitGroups, group)
+		}
+	}
+
+	notes.PullRequests = pullRequests
+	issueGroups := make(map[string]*ReleaseNoteIssueGroup)
+	seen := make(map[string]bool)
+	var issueTypes []string
+	for i := range issuePrs {
+		issue := &issuePrs[i].ReleaseNoteIssue
+		if seen[issue.Id] {
+			continue
+		}
+		seen[issue.Id] = true
+		issueType := issue.Type
+		if issueType == "" {
+			issueType = "OTHER"
+		}
+		if issueGroups[issueType] == nil {
+			issueGroups[issueType] = &ReleaseNoteIssueGroup{Type: issueType}
+			issueTypes = append(issueTypes, issueType)
+		}
+		issueGroups[issueType].Issues = append(issueGroups[issueType].Issues, issue)
+	}
+	sort.Strings(issueTypes)
+	for _, issueType := range issueTypes {
+		notes.IssueGroups = append(notes.IssueGroups, issueGroups[issueType])
+	}
+}
+
+func isKnownCommitType(commitType string) bool {
+	for _, t := range commitTypeTitles {
+		if t.Type == commitType {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderReleaseNotesMarkdown renders the release notes as markdown
+func RenderReleaseNotesMarkdown(notes *ReleaseNotes) string {
+	prs := make(map[string]*ReleaseNotePullRequest, len(notes.PullRequests))
+	for _, pr := range notes.PullRequests {
+		prs[pr.Id] = pr
+	}
+	renderCommit := func(sb *strings.Builder, commit *ReleaseNoteCommit) {
+		sb.WriteString("- ")
+		if commit.Scope != "" {
+			sb.WriteString(fmt.Sprintf("**%s:** ", commit.Scope))
+		}
+		sb.WriteString(commit.Description)
+		sha := commit.Sha
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		sb.WriteString(fmt.Sprintf(" (%s)", sha))
+		for _, prId := range commit.PullRequestIds {
+			if pr := prs[prId]; pr != nil {
+				sb.WriteString(fmt.Sprintf(" [#%d](%s)", pr.PullRequestKey, pr.Url))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString(fmt.Sprintf("# %s\n\nChanges since %s\n", notes.To, notes.From))
+	if len(notes.BreakingChanges) > 0 {
+		sb.WriteString("\n## Breaking Changes\n\n")
+		for _, commit := range notes.BreakingChanges {
+			renderCommit(sb, commit)
+		}
+	}
+	for _, group := range notes.CommitGroups {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", group.Title))
+		for _, commit := range group.Commits {
+			renderCommit(sb, commit)
+		}
+	}
+	if len(notes.IssueGroups) > 0 {
+		sb.WriteString("\n## Issues\n")
+		for _, group := range notes.IssueGroups {
+			sb.WriteString(fmt.Sprintf("\n### %s\n\n", group.Type))
+			for _, issue := range group.Issues {
+				sb.WriteString(fmt.Sprintf("- [%s](%s) %s\n", issue.IssueKey, issue.Url, issue.Title))
+			}
+		}
+	}
+	if len(notes.PullRequests) > 0 {
+		sb.WriteString("\n## Pull Requests\n\n")
+		for _, pr := range notes.PullRequests {
+			sb.WriteString(fmt.Sprintf("- [#%d](%s) %s", pr.PullRequestKey, pr.Url, pr.Title))
+			if pr.AuthorName != "" {
+				sb.WriteString(fmt.Sprintf(" (@%s)", pr.AuthorName))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
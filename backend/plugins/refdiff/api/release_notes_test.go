@@ -0,0 +1,89 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildReleaseNotes(t *testing.T) {
+	notes := &ReleaseNotes{RepoId: "repo", From: "v1.2.0", To: "v1.3.0"}
+	buildReleaseNotes(notes,
+		[]releaseNoteCommitRow{
+			{Sha: "aaaaaaaaaa", Message: "feat(api): add release notes\n\nbody"},
+			{Sha: "bbbbbbbbbb", Message: "fix!: drop the legacy flag"},
+			{Sha: "cccccccccc", Message: "Merge branch 'main'"},
+			{Sha: "dddddddddd", Message: "wip: unknown type"},
+		},
+		[]releaseNoteCommitPr{{CommitSha: "aaaaaaaaaa", PullRequestId: "pr1"}},
+		[]*ReleaseNotePullRequest{{Id: "pr1", PullRequestKey: 12, Title: "Add release notes", Url: "http://pr/12", AuthorName: "alice"}},
+		[]releaseNoteIssuePr{
+			{ReleaseNoteIssue: ReleaseNoteIssue{Id: "i1", IssueKey: "DL-1", Title: "Release notes", Url: "http://issue/1", Type: "REQUIREMENT"}, PullRequestId: "pr1"},
+			{ReleaseNoteIssue: ReleaseNoteIssue{Id: "i2", IssueKey: "DL-2", Title: "Crash", Url: "http://issue/2", Type: "BUG"}, PullRequestId: "pr1"},
+		},
+	)
+	assert.Len(t, notes.BreakingChanges, 1)
+	assert.Equal(t, "bbbbbbbbbb", notes.BreakingChanges[0].Sha)
+	assert.Len(t, notes.CommitGroups, 3)
+	assert.Equal(t, "feat", notes.CommitGroups[0].Type)
+	assert.Equal(t, "api", notes.CommitGroups[0].Commits[0].Scope)
+	assert.Equal(t, []string{"pr1"}, notes.CommitGroups[0].Commits[0].PullRequestIds)
+	assert.Equal(t, "fix", notes.CommitGroups[1].Type)
+	assert.Equal(t, otherChanges, notes.CommitGroups[2].Type)
+	assert.Len(t, notes.CommitGroups[2].Commits, 2)
+	assert.Equal(t, "BUG", notes.IssueGroups[0].Type)
+	assert.Equal(t, "REQUIREMENT", notes.IssueGroups[1].Type)
+
+	assert.Equal(t, `# v1.3.0
+
+Changes since v1.2.0
+
+## Breaking Changes
+
+- drop the legacy flag (bbbbbbb)
+
+## Features
+
+- **api:** add release notes (aaaaaaa) [#12](http://pr/12)
+
+## Bug Fixes
+
+- drop the legacy flag (bbbbbbb)
+
+## Other Changes
+
+- Merge branch 'main' (ccccccc)
+- wip: unknown type (ddddddd)
+
+## Issues
+
+### BUG
+
+- [DL-2](http://issue/2) Crash
+
+### REQUIREMENT
+
+- [DL-1](http://issue/1) Release notes
+
+## Pull Requests
+
+- [#12](http://pr/12) Add release notes (@alice)
+`, RenderReleaseNotesMarkdown(notes))
+}
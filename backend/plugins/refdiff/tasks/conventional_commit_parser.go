@@ -0,0 +1,98 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/refdiff/utils"
+)
+
+type commitMessage struct {
+	Sha     string
+	Message string
+}
+
+func ParseConventionalCommits(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*RefdiffTaskData)
+	repoId := data.Options.RepoId
+	db := taskCtx.GetDal()
+
+	if data.Options.ProjectName != "" || repoId == "" {
+		return nil
+	}
+	cursor, err := db.Cursor(
+		dal.Select("c.sha, c.message"),
+		dal.From("commits c"),
+		dal.Join("JOIN repo_commits rc ON rc.commit_sha = c.sha"),
+		dal.Where("rc.repo_id = ?", repoId),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	converter, err := api.NewDataConverter(api.DataConverterArgs{
+		InputRowType: reflect.TypeOf(commitMessage{}),
+		Input:        cursor,
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx:    taskCtx,
+			Table:  "commits",
+			Params: repoId,
+		},
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			return []interface{}{
+				parseCommitConventionalMetadata(inputRow.(*commitMessage)),
+			}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return converter.Execute()
+}
+
+func parseCommitConventionalMetadata(commit *commitMessage) *code.CommitConventionalMetadata {
+	metadata := &code.CommitConventionalMetadata{
+		CommitSha: commit.Sha,
+		IssueRefs: strings.Join(utils.ParseIssueRefs(commit.Message), ","),
+	}
+	if cc := utils.ParseConventionalCommit(commit.Message); cc != nil {
+		metadata.IsConventional = true
+		metadata.Type = cc.Type
+		metadata.Scope = cc.Scope
+		metadata.Breaking = cc.Breaking
+		metadata.Description = cc.Description
+	}
+	return metadata
+}
+
+var ParseConventionalCommitsMeta = plugin.SubTaskMeta{
+	Name:             "parseConventionalCommits",
+	EntryPoint:       ParseConventionalCommits,
+	EnabledByDefault: true,
+	Description:      "Parse the Conventional Commit headers and footers of the commits of the repo",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE},
+}
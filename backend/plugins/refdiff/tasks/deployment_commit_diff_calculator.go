@@ -54,7 +54,7 @@ func CalculateDeploymentCommitsDiff(taskCtx plugin.SubTaskContext) errors.Error
 		&pairs,
 		dal.Select("dc.id, dc.commit_sha, p.commit_sha as prev_commit_sha"),
 		dal.From("cicd_deployment_commits dc"),
-		dal.Join("LEFT JOIN project_mapping pm ON (pm.table = 'cicd_scopes' AND pm.row_id = dc.cicd_scope_id)"),
+		dal.Join("LEFT JOIN project_mapping pm ON (? = 'cicd_scopes' AND pm.row_id = dc.cicd_scope_id)", dal.ClauseColumn{Table: "pm", Name: "table"}),
 		dal.Join("LEFT JOIN cicd_deployment_commits p ON (dc.prev_success_deployment_commit_id = p.id)"),
 		dal.Where(
 			`
@@ -148,7 +148,7 @@ func loadCommitGraph(ctx context.Context, db dal.Dal, data *RefdiffTaskData) (*u
 		dal.Select("cp.commit_sha, cp.parent_commit_sha"),
 		dal.From("commit_parents cp"),
 		dal.Join("LEFT JOIN repo_commits rc ON (rc.commit_sha = cp.commit_sha)"),
-		dal.Join("LEFT JOIN project_mapping pm ON (pm.table = 'repos' AND pm.row_id = rc.repo_id)"),
+		dal.Join("LEFT JOIN project_mapping pm ON (? = 'repos' AND pm.row_id = rc.repo_id)", dal.ClauseColumn{Table: "pm", Name: "table"}),
 		dal.Where("pm.project_name = ?", data.Options.ProjectName),
 	)
 	if err != nil {
@@ -0,0 +1,237 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/refdiff/models"
+)
+
+// only release versions are checked, pre-releases and build metadata are skipped
+var releaseVersionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)$`)
+
+type semver [3]int
+
+// parseReleaseVersion parses the version of a tag like `refs/tags/v1.2.3`, ok is false for other tags
+func parseReleaseVersion(refName string) (version semver, ok bool) {
+	matches := releaseVersionPattern.FindStringSubmatch(strings.TrimPrefix(refName, "refs/tags/"))
+	if matches == nil {
+		return version, false
+	}
+	for i := 0; i < 3; i++ {
+		version[i], _ = strconv.Atoi(matches[i+1])
+	}
+	return version, true
+}
+
+var bumpRanks = map[string]int{
+	code.SEMVER_BUMP_INVALID: -1,
+	code.SEMVER_BUMP_NONE:    0,
+	code.SEMVER_BUMP_PATCH:   1,
+	code.SEMVER_BUMP_MINOR:   2,
+	code.SEMVER_BUMP_MAJOR:   3,
+}
+
+// actualBump returns the bump between the versions, INVALID when the new version is lower
+func actualBump(oldVersion, newVersion semver) string {
+	bumps := []string{code.SEMVER_BUMP_MAJOR, code.SEMVER_BUMP_MINOR, code.SEMVER_BUMP_PATCH}
+	for i := 0; i < 3; i++ {
+		if newVersion[i] > oldVersion[i] {
+			return bumps[i]
+		}
+		if newVersion[i] < oldVersion[i] {
+			return code.SEMVER_BUMP_INVALID
+		}
+	}
+	return code.SEMVER_BUMP_NONE
+}
+
+// expectedBump returns the bump implied by the commits: breaking changes bump the major version,
+// or the minor version before 1.0.0, features bump the minor version and fixes the patch version
+func expectedBump(oldVersion semver, commits []code.CommitConventionalMetadata) string {
+	bump := code.SEMVER_BUMP_NONE
+	for _, commit := range commits {
+		implied := code.SEMVER_BUMP_NONE
+		switch {
+		case commit.Breaking && oldVersion[0] == 0:
+			implied = code.SEMVER_BUMP_MINOR
+		case commit.Breaking:
+			implied = code.SEMVER_BUMP_MAJOR
+		case commit.Type == "feat":
+			implied = code.SEMVER_BUMP_MINOR
+		case commit.Type == "fix" || commit.Type == "perf":
+			implied = code.SEMVER_BUMP_PATCH
+		}
+		if bumpRanks[implied] > bumpRanks[bump] {
+			bump = implied
+		}
+	}
+	return bump
+}
+
+// checkSemver fills the bumps of the check, a tag pair complies when it bumps the version at least as much as its commits imply
+func checkSemver(check *code.RefSemverCheck, oldVersion, newVersion semver, commits []code.CommitConventionalMetadata) {
+	check.ActualBump = actualBump(oldVersion, newVersion)
+	check.ExpectedBump = expectedBump(oldVersion, commits)
+	check.CommitCount = len(commits)
+	check.ConventionalCommitCount = 0
+	for _, commit := range commits {
+		if commit.IsConventional {
+			check.ConventionalCommitCount++
+		}
+	}
+	check.Compliant = check.ActualBump != code.SEMVER_BUMP_INVALID &&
+		check.ActualBump != code.SEMVER_BUMP_NONE &&
+		bumpRanks[check.ActualBump] >= bumpRanks[check.ExpectedBump]
+}
+
+func CheckSemverCompliance(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*RefdiffTaskData)
+	repoId := data.Options.RepoId
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+
+	if data.Options.ProjectName != "" || repoId == "" {
+		return nil
+	}
+	err := db.Delete(&code.RefSemverCheck{}, dal.Where("repo_id = ?", repoId))
+	if err != nil {
+		return err
+	}
+
+	var tags Refs
+	err = db.All(&tags, dal.Where("repo_id = ? AND ref_type = ?", repoId, "TAG"))
+	if err != nil {
+		return err
+	}
+	var releases Refs
+	versions := make(map[string]semver)
+	for _, tag := range tags {
+		if version, ok := parseReleaseVersion(tag.Name); ok {
+			releases = append(releases, tag)
+			versions[tag.Id] = version
+		}
+	}
+	sort.Sort(RefsSemver(releases))
+
+	divider := api.NewBatchSaveDivider(taskCtx, 500, "", "")
+	checkSaver, err := divider.ForType(reflect.TypeOf(&code.RefSemverCheck{}))
+	if err != nil {
+		return err
+	}
+	compliance := &code.RepoConventionalCompliance{RepoId: repoId}
+	for i := 1; i < len(releases); i++ {
+		oldRef, newRef := releases[i-1], releases[i]
+		if oldRef.CommitSha == newRef.CommitSha {
+			continue
+		}
+		finished, err := db.Count(
+			dal.From(&models.FinishedCommitsDiff{}),
+			dal.Where("new_commit_sha = ? AND old_commit_sha = ?", newRef.CommitSha, oldRef.CommitSha),
+		)
+		if err != nil {
+			return err
+		}
+		if finished == 0 {
+			logger.Info("skip %s..%s whose commits diff is not calculated, set tagsOrder to semver to calculate it", oldRef.Name, newRef.Name)
+			continue
+		}
+		var commits []code.CommitConventionalMetadata
+		err = db.All(&commits,
+			dal.Select("ccm.*"),
+			dal.From("commits_diffs cd"),
+			dal.Join("JOIN commit_conventional_metadata ccm ON ccm.commit_sha = cd.commit_sha"),
+			dal.Where("cd.new_commit_sha = ? AND cd.old_commit_sha = ?", newRef.CommitSha, oldRef.CommitSha),
+		)
+		if err != nil {
+			return err
+		}
+		check := &code.RefSemverCheck{
+			NewRefId:   newRef.Id,
+			OldRefId:   oldRef.Id,
+			RepoId:     repoId,
+			NewVersion: fmt.Sprintf("%d.%d.%d", versions[newRef.Id][0], versions[newRef.Id][1], versions[newRef.Id][2]),
+			OldVersion: fmt.Sprintf("%d.%d.%d", versions[oldRef.Id][0], versions[oldRef.Id][1], versions[oldRef.Id][2]),
+		}
+		checkSemver(check, versions[oldRef.Id], versions[newRef.Id], commits)
+		err = checkSaver.Add(check)
+		if err != nil {
+			return err
+		}
+		compliance.TagPairCount++
+		if check.Compliant {
+			compliance.CompliantTagPairCount++
+		}
+	}
+
+	compliance.CommitCount, err = countRepoCommits(db, repoId, false)
+	if err != nil {
+		return err
+	}
+	compliance.ConventionalCommitCount, err = countRepoCommits(db, repoId, true)
+	if err != nil {
+		return err
+	}
+	if compliance.CommitCount > 0 {
+		compliance.ConventionalCommitRate = float64(compliance.ConventionalCommitCount) / float64(compliance.CommitCount)
+	}
+	if compliance.TagPairCount > 0 {
+		compliance.SemverComplianceRate = float64(compliance.CompliantTagPairCount) / float64(compliance.TagPairCount)
+	}
+	complianceSaver, err := divider.ForType(reflect.TypeOf(compliance))
+	if err != nil {
+		return err
+	}
+	err = complianceSaver.Add(compliance)
+	if err != nil {
+		return err
+	}
+	return divider.Close()
+}
+
+func countRepoCommits(db dal.Dal, repoId string, conventionalOnly bool) (int, errors.Error) {
+	clauses := []dal.Clause{
+		dal.From("repo_commits rc"),
+		dal.Join("JOIN commit_conventional_metadata ccm ON ccm.commit_sha = rc.commit_sha"),
+		dal.Where("rc.repo_id = ?", repoId),
+	}
+	if conventionalOnly {
+		clauses[2] = dal.Where("rc.repo_id = ? AND ccm.is_conventional = ?", repoId, true)
+	}
+	count, err := db.Count(clauses...)
+	return int(count), err
+}
+
+var CheckSemverComplianceMeta = plugin.SubTaskMeta{
+	Name:             "checkSemverCompliance",
+	EntryPoint:       CheckSemverCompliance,
+	EnabledByDefault: true,
+	Description:      "Check the version bumps between the release tags against the Conventional Commits between them",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CODE},
+}
@@ -0,0 +1,82 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReleaseVersion(t *testing.T) {
+	version, ok := parseReleaseVersion("refs/tags/v1.12.3")
+	assert.True(t, ok)
+	assert.Equal(t, semver{1, 12, 3}, version)
+	_, ok = parseReleaseVersion("refs/tags/v1.2.0-rc.1")
+	assert.False(t, ok)
+	_, ok = parseReleaseVersion("refs/tags/latest")
+	assert.False(t, ok)
+}
+
+func TestCheckSemver(t *testing.T) {
+	feat := code.CommitConventionalMetadata{IsConventional: true, Type: "feat"}
+	fix := code.CommitConventionalMetadata{IsConventional: true, Type: "fix"}
+	breaking := code.CommitConventionalMetadata{IsConventional: true, Type: "refactor", Breaking: true}
+	other := code.CommitConventionalMetadata{}
+
+	cases := []struct {
+		old, new  semver
+		commits   []code.CommitConventionalMetadata
+		actual    string
+		expected  string
+		compliant bool
+	}{
+		{semver{1, 2, 0}, semver{1, 2, 1}, []code.CommitConventionalMetadata{fix, other}, code.SEMVER_BUMP_PATCH, code.SEMVER_BUMP_PATCH, true},
+		{semver{1, 2, 0}, semver{1, 2, 1}, []code.CommitConventionalMetadata{fix, feat}, code.SEMVER_BUMP_PATCH, code.SEMVER_BUMP_MINOR, false},
+		{semver{1, 2, 3}, semver{2, 0, 0}, []code.CommitConventionalMetadata{fix, breaking}, code.SEMVER_BUMP_MAJOR, code.SEMVER_BUMP_MAJOR, true},
+		{semver{0, 2, 3}, semver{0, 3, 0}, []code.CommitConventionalMetadata{breaking}, code.SEMVER_BUMP_MINOR, code.SEMVER_BUMP_MINOR, true},
+		{semver{1, 2, 3}, semver{1, 3, 0}, []code.CommitConventionalMetadata{other}, code.SEMVER_BUMP_MINOR, code.SEMVER_BUMP_NONE, true},
+		{semver{1, 2, 3}, semver{1, 2, 2}, []code.CommitConventionalMetadata{fix}, code.SEMVER_BUMP_INVALID, code.SEMVER_BUMP_PATCH, false},
+	}
+	for _, c := range cases {
+		check := &code.RefSemverCheck{}
+		checkSemver(check, c.old, c.new, c.commits)
+		assert.Equal(t, c.actual, check.ActualBump, "%v..%v", c.old, c.new)
+		assert.Equal(t, c.expected, check.ExpectedBump, "%v..%v", c.old, c.new)
+		assert.Equal(t, c.compliant, check.Compliant, "%v..%v", c.old, c.new)
+		assert.Equal(t, len(c.commits), check.CommitCount)
+	}
+}
+
+func TestParseCommitConventionalMetadata(t *testing.T) {
+	metadata := parseCommitConventionalMetadata(&commitMessage{
+		Sha:     "sha",
+		Message: "feat(api)!: drop v1\n\nBREAKING CHANGE: v1 is gone\nCloses #12, #13\nRefs: PROJ-34",
+	})
+	assert.True(t, metadata.IsConventional)
+	assert.Equal(t, "feat", metadata.Type)
+	assert.Equal(t, "api", metadata.Scope)
+	assert.True(t, metadata.Breaking)
+	assert.Equal(t, "drop v1", metadata.Description)
+	assert.Equal(t, "#12,#13,PROJ-34", metadata.IssueRefs)
+
+	metadata = parseCommitConventionalMetadata(&commitMessage{Sha: "sha", Message: "Update README\n\nFixes #7"})
+	assert.False(t, metadata.IsConventional)
+	assert.Equal(t, "#7", metadata.IssueRefs)
+}
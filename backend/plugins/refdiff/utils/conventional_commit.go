@@ -0,0 +1,73 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ConventionalCommit is a commit message following https://www.conventionalcommits.org
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+	// IssueRefs are the issues referred by the footers, e.g. `Closes #12` or `Refs: PROJ-34`
+	IssueRefs []string
+}
+
+var conventionalHeaderPattern = regexp.MustCompile(`^(\w+)(?:\(([^()]*)\))?(!)?: *(.+)$`)
+var issueFooterPattern = regexp.MustCompile(`(?i)^(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?|refs?|related|see|issues?)(?:: *| +)(.+)$`)
+var issueRefPattern = regexp.MustCompile(`#\d+|\b[A-Z][A-Z0-9]+-\d+\b`)
+
+// ParseConventionalCommit parses the first line of the commit message, it returns nil when the header doesn't follow the convention
+func ParseConventionalCommit(message string) *ConventionalCommit {
+	header := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	matches := conventionalHeaderPattern.FindStringSubmatch(header)
+	if matches == nil {
+		return nil
+	}
+	return &ConventionalCommit{
+		Type:        strings.ToLower(matches[1]),
+		Scope:       strings.TrimSpace(matches[2]),
+		Breaking:    matches[3] == "!" || strings.Contains(message, "\nBREAKING CHANGE:") || strings.Contains(message, "\nBREAKING-CHANGE:"),
+		Description: strings.TrimSpace(matches[4]),
+		IssueRefs:   ParseIssueRefs(message),
+	}
+}
+
+// ParseIssueRefs returns the distinct issues referred by the footers of the commit message
+func ParseIssueRefs(message string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	lines := strings.Split(message, "\n")
+	for _, line := range lines[1:] {
+		matches := issueFooterPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		for _, ref := range issueRefPattern.FindAllString(matches[1], -1) {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
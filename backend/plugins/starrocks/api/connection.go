@@ -52,5 +52,27 @@ type StarRocksPipelinePlan [][]struct {
 		OrderBy      map[string]string `json:"order_by"`
 		Extra        string            `json:"extra"`
 		DomainLayer  string            `json:"domain_layer"`
+		Sink         string            `json:"sink"`
+		Incremental  bool              `json:"incremental"`
+		Parquet      struct {
+			Path              string `json:"path"`
+			Compression       string `json:"compression"`
+			RowGroupSize      int    `json:"row_group_size"`
+			S3Endpoint        string `json:"s3_endpoint"`
+			S3Region          string `json:"s3_region"`
+			S3AccessKeyId     string `json:"s3_access_key_id"`
+			S3SecretAccessKey string `json:"s3_secret_access_key"`
+			S3ForcePathStyle  bool   `json:"s3_force_path_style"`
+		} `json:"parquet"`
+		ClickHouse struct {
+			Endpoint string `json:"endpoint"`
+			User     string `json:"user"`
+			Password string `json:"password"`
+			Database string `json:"database"`
+		} `json:"clickhouse"`
+		DuckDB struct {
+			Path   string `json:"path"`
+			Binary string `json:"binary"`
+		} `json:"duckdb"`
 	} `json:"options"`
 }
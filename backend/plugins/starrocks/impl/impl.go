@@ -18,10 +18,15 @@ limitations under the License.
 package impl
 
 import (
+	"fmt"
+	"os/exec"
+
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/plugin"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/starrocks/models"
+	"github.com/apache/incubator-devlake/plugins/starrocks/models/migrationscripts"
 	"github.com/apache/incubator-devlake/plugins/starrocks/tasks"
 )
 
@@ -32,6 +37,7 @@ var _ interface {
 	plugin.PluginMeta
 	plugin.PluginTask
 	plugin.PluginModel
+	plugin.PluginMigration
 } = (*StarRocks)(nil)
 
 func (s StarRocks) SubTaskMetas() []plugin.SubTaskMeta {
@@ -49,15 +55,45 @@ func (s StarRocks) PrepareTaskData(taskCtx plugin.TaskContext, options map[strin
 	if op.BeHost == "" {
 		op.BeHost = op.Host
 	}
+	if op.Sink == "" {
+		op.Sink = tasks.SinkStarRocks
+	}
+	if op.Incremental && op.UpdateColumn == "" {
+		return nil, errors.BadInput.New("update_column is required by incremental export")
+	}
+	if op.Incremental && op.Sink == tasks.SinkStarRocks {
+		return nil, errors.BadInput.New("incremental export is not supported by the starrocks sink")
+	}
+	if op.Sink == tasks.SinkDuckDB {
+		// the duckdb sink runs the duckdb command line, fail before exporting anything if it is not installed
+		if op.DuckDB == nil {
+			op.DuckDB = &tasks.DuckDBConfig{}
+		}
+		if op.DuckDB.Binary == "" {
+			op.DuckDB.Binary = taskCtx.GetConfig("DUCKDB_BINARY")
+		}
+		if op.DuckDB.Binary == "" {
+			op.DuckDB.Binary = "duckdb"
+		}
+		if _, err := exec.LookPath(op.DuckDB.Binary); err != nil {
+			return nil, errors.BadInput.Wrap(err, fmt.Sprintf("the duckdb sink requires the duckdb command line, %s is not found", op.DuckDB.Binary))
+		}
+	}
 	return &op, nil
 }
 
 func (s StarRocks) GetTablesInfo() []dal.Tabler {
-	return []dal.Tabler{}
+	return []dal.Tabler{
+		&models.ExportWatermark{},
+	}
 }
 
 func (s StarRocks) Description() string {
-	return "Sync data from database to StarRocks"
+	return "Sync data from database to StarRocks, Parquet files, ClickHouse or DuckDB"
+}
+
+func (s StarRocks) MigrationScripts() []plugin.MigrationScript {
+	return migrationscripts.All()
 }
 
 func (s StarRocks) Name() string {
@@ -0,0 +1,228 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/starrocks/models"
+	"github.com/lib/pq"
+)
+
+const defaultExportBatchSize = 1000
+
+// exportToSink copies the tables to a sink other than StarRocks, tables with the UpdateColumn are exported
+// incrementally from the watermark of the last export if config.Incremental is set
+func exportToSink(c plugin.SubTaskContext, db dal.Dal, tables []string, sink ExportSink) errors.Error {
+	logger := c.GetLogger()
+	for _, table := range tables {
+		select {
+		case <-c.GetContext().Done():
+			return errors.Convert(c.GetContext().Err())
+		default:
+		}
+		exported, err := exportTableToSink(c, db, table, sink)
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("failed to export %s to %s", table, sink.Destination()))
+		}
+		logger.Info("exported %d rows of %s to %s", exported, table, sink.Destination())
+	}
+	return nil
+}
+
+func exportTableToSink(c plugin.SubTaskContext, db dal.Dal, table string, sink ExportSink) (int64, errors.Error) {
+	config := c.GetData().(*StarRocksConfig)
+	columnMetas, err := db.GetColumns(&Table{name: table}, nil)
+	if err != nil {
+		return 0, err
+	}
+	columnMetas = filterColumns(config, table, columnMetas)
+	if len(columnMetas) == 0 {
+		return 0, errors.Default.New("no column to export")
+	}
+	quote := "`"
+	if db.Dialect() == "postgres" {
+		quote = "\""
+	}
+	columns := make([]ExportColumn, 0, len(columnMetas))
+	columnTypes := make(map[string]string, len(columnMetas))
+	var names, pks []string
+	hasUpdateColumn := false
+	for _, cm := range columnMetas {
+		dataType, _ := cm.ColumnType()
+		isPrimaryKey, _ := cm.PrimaryKey()
+		columns = append(columns, ExportColumn{Name: cm.Name(), Kind: getColumnKind(dataType), PrimaryKey: isPrimaryKey})
+		columnTypes[cm.Name()] = dataType
+		names = append(names, quote+cm.Name()+quote)
+		if isPrimaryKey {
+			pks = append(pks, quote+cm.Name()+quote)
+		}
+		if config.UpdateColumn != "" && cm.Name() == config.UpdateColumn {
+			hasUpdateColumn = true
+		}
+	}
+
+	watermark := &models.ExportWatermark{Destination: sink.Destination(), TbName: table, UpdateColumn: config.UpdateColumn}
+	if hasUpdateColumn {
+		err = c.GetDal().First(watermark, dal.Where("destination = ? AND tb_name = ?", watermark.Destination, table))
+		if err != nil && !c.GetDal().IsErrorNotFound(err) {
+			return 0, err
+		}
+		// the watermark is only valid for the same update column
+		if watermark.UpdateColumn != config.UpdateColumn {
+			watermark.UpdateColumn = config.UpdateColumn
+			watermark.Watermark = nil
+		}
+	}
+	incremental := config.Incremental && hasUpdateColumn && watermark.Watermark != nil
+
+	var wheres []string
+	var params []interface{}
+	if tableConfig, ok := config.TableConfigs[table]; ok && tableConfig.Where != "" {
+		wheres = append(wheres, fmt.Sprintf("(%s)", tableConfig.Where))
+	}
+	if incremental {
+		wheres = append(wheres, fmt.Sprintf("%s%s%s > ?", quote, config.UpdateColumn, quote))
+		params = append(params, *watermark.Watermark)
+	}
+	orderBy := strings.Join(pks, ", ")
+	if v, ok := config.OrderBy[table]; ok {
+		orderBy = v
+	}
+	clauses := []dal.Clause{dal.Select(strings.Join(names, ", ")), dal.From(table)}
+	if len(wheres) > 0 {
+		clauses = append(clauses, dal.Where(strings.Join(wheres, " AND "), params...))
+	}
+	if orderBy != "" {
+		clauses = append(clauses, dal.Orderby(orderBy))
+	}
+	rows, err := db.Cursor(clauses...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	writer, err := sink.OpenTable(table, columns, incremental)
+	if err != nil {
+		return 0, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			writer.Abort()
+		}
+	}()
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultExportBatchSize
+	}
+	var exported int64
+	var maxUpdated *time.Time
+	batch := make([]map[string]interface{}, 0, batchSize)
+	for rows.Next() {
+		select {
+		case <-c.GetContext().Done():
+			return 0, errors.Convert(c.GetContext().Err())
+		default:
+		}
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i, column := range columns {
+			if strings.HasSuffix(columnTypes[column.Name], "[]") {
+				var arr []string
+				values[i] = &arr
+				pointers[i] = pq.Array(&arr)
+			} else {
+				pointers[i] = &values[i]
+			}
+		}
+		if e := rows.Scan(pointers...); e != nil {
+			return 0, errors.Convert(e)
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if row[column.Name], err = normalizeValue(column.Kind, values[i]); err != nil {
+				return 0, errors.Default.Wrap(err, fmt.Sprintf("invalid value of %s", column.Name))
+			}
+		}
+		if hasUpdateColumn {
+			if updated, ok := row[config.UpdateColumn].(time.Time); ok && (maxUpdated == nil || updated.After(*maxUpdated)) {
+				maxUpdated = &updated
+			}
+		}
+		batch = append(batch, row)
+		if len(batch) == batchSize {
+			if err = writer.Write(batch); err != nil {
+				return 0, err
+			}
+			exported += int64(len(batch))
+			batch = make([]map[string]interface{}, 0, batchSize)
+		}
+	}
+	if e := rows.Err(); e != nil {
+		return 0, errors.Convert(e)
+	}
+	if len(batch) > 0 {
+		if err = writer.Write(batch); err != nil {
+			return 0, err
+		}
+		exported += int64(len(batch))
+	}
+	if err = writer.Commit(); err != nil {
+		return 0, err
+	}
+	committed = true
+
+	if hasUpdateColumn {
+		if maxUpdated != nil {
+			watermark.Watermark = maxUpdated
+		}
+		watermark.ExportedRows = exported
+		if err = c.GetDal().CreateOrUpdate(watermark); err != nil {
+			return 0, err
+		}
+	}
+	return exported, nil
+}
+
+// filterColumns applies the IncludedColumns and ExcludedColumns of the TableConfig of the table
+func filterColumns(config *StarRocksConfig, table string, columnMetas []dal.ColumnMeta) []dal.ColumnMeta {
+	tableConfig, ok := config.TableConfigs[table]
+	if !ok {
+		return columnMetas
+	}
+	var filtered []dal.ColumnMeta
+	for _, cm := range columnMetas {
+		name := cm.Name()
+		if len(tableConfig.ExcludedColumns) > 0 && slices.Contains(tableConfig.ExcludedColumns, name) {
+			continue
+		}
+		if len(tableConfig.IncludedColumns) > 0 && !slices.Contains(tableConfig.IncludedColumns, name) {
+			continue
+		}
+		filtered = append(filtered, cm)
+	}
+	return filtered
+}
@@ -0,0 +1,146 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/compress"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+const defaultRowGroupSize = 100000
+
+// parquetWriter converts rows into arrow records and writes them with the parquet writer of Apache Arrow,
+// every column is nullable and row groups are cut by the number of rows
+type parquetWriter struct {
+	columns []ExportColumn
+	schema  *arrow.Schema
+	writer  *pqarrow.FileWriter
+}
+
+func newParquetWriter(w io.Writer, columns []ExportColumn, compression string, rowGroupSize int) (*parquetWriter, errors.Error) {
+	codec := compress.Codecs.Uncompressed
+	switch compression {
+	case "", "none":
+	case "gzip":
+		codec = compress.Codecs.Gzip
+	default:
+		return nil, errors.BadInput.New(fmt.Sprintf("unsupported parquet compression %s", compression))
+	}
+	if rowGroupSize <= 0 {
+		rowGroupSize = defaultRowGroupSize
+	}
+	fields := make([]arrow.Field, len(columns))
+	for i, column := range columns {
+		fields[i] = arrow.Field{Name: column.Name, Type: arrowDataType(column.Kind), Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+	props := parquet.NewWriterProperties(
+		parquet.WithCompression(codec),
+		parquet.WithMaxRowGroupLength(int64(rowGroupSize)),
+		parquet.WithCreatedBy("Apache DevLake"),
+	)
+	// hide the Close method of w from the arrow writer, which would otherwise close it together with the footer
+	writer, err := pqarrow.NewFileWriter(schema, struct{ io.Writer }{w}, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to create parquet writer")
+	}
+	return &parquetWriter{columns: columns, schema: schema, writer: writer}, nil
+}
+
+// WriteRows buffers the rows in the current row group, a new row group is started whenever it is full
+func (pw *parquetWriter) WriteRows(rows []map[string]interface{}) errors.Error {
+	if len(rows) == 0 {
+		return nil
+	}
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, pw.schema)
+	defer builder.Release()
+	for i, column := range pw.columns {
+		for _, row := range rows {
+			if err := appendArrowValue(builder.Field(i), column.Kind, row[column.Name]); err != nil {
+				return errors.Default.Wrap(err, fmt.Sprintf("failed to write column %s", column.Name))
+			}
+		}
+	}
+	record := builder.NewRecord()
+	defer record.Release()
+	return errors.Convert(pw.writer.WriteBuffered(record))
+}
+
+// Close flushes the buffered rows and writes the footer, the underlying writer is not closed
+func (pw *parquetWriter) Close() errors.Error {
+	return errors.Convert(pw.writer.Close())
+}
+
+func arrowDataType(kind ColumnKind) arrow.DataType {
+	switch kind {
+	case KindInt:
+		return arrow.PrimitiveTypes.Int64
+	case KindFloat:
+		return arrow.PrimitiveTypes.Float64
+	case KindBool:
+		return arrow.FixedWidthTypes.Boolean
+	case KindTime:
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	}
+	return arrow.BinaryTypes.String
+}
+
+func appendArrowValue(builder array.Builder, kind ColumnKind, v interface{}) errors.Error {
+	if v == nil {
+		builder.AppendNull()
+		return nil
+	}
+	switch kind {
+	case KindInt:
+		i, ok := v.(int64)
+		if !ok {
+			return errors.Default.New(fmt.Sprintf("%v is not an int64", v))
+		}
+		builder.(*array.Int64Builder).Append(i)
+	case KindFloat:
+		f, ok := v.(float64)
+		if !ok {
+			return errors.Default.New(fmt.Sprintf("%v is not a float64", v))
+		}
+		builder.(*array.Float64Builder).Append(f)
+	case KindBool:
+		b, ok := v.(bool)
+		if !ok {
+			return errors.Default.New(fmt.Sprintf("%v is not a bool", v))
+		}
+		builder.(*array.BooleanBuilder).Append(b)
+	case KindTime:
+		t, ok := v.(time.Time)
+		if !ok {
+			return errors.Default.New(fmt.Sprintf("%v is not a time", v))
+		}
+		builder.(*array.TimestampBuilder).Append(arrow.Timestamp(t.UnixMicro()))
+	default:
+		builder.(*array.StringBuilder).Append(fmt.Sprintf("%v", v))
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet/compress"
+	"github.com/apache/arrow/go/v14/parquet/file"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParquetWriter(t *testing.T) {
+	columns := []ExportColumn{
+		{Name: "id", Kind: KindString, PrimaryKey: true},
+		{Name: "count", Kind: KindInt},
+		{Name: "ratio", Kind: KindFloat},
+		{Name: "done", Kind: KindBool},
+		{Name: "updated_at", Kind: KindTime},
+	}
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	var rows []map[string]interface{}
+	for i := 0; i < 20; i++ {
+		row := map[string]interface{}{"id": string(rune('a' + i)), "count": int64(i), "ratio": float64(i) / 2, "done": i%2 == 0, "updated_at": updatedAt}
+		if i%3 == 0 {
+			row["count"] = nil
+		}
+		rows = append(rows, row)
+	}
+	for compression, expectedCodec := range map[string]compress.Compression{"none": compress.Codecs.Uncompressed, "gzip": compress.Codecs.Gzip} {
+		var buf bytes.Buffer
+		w, err := newParquetWriter(&buf, columns, compression, 8)
+		assert.Nil(t, err)
+		assert.Nil(t, w.WriteRows(rows[:5]))
+		assert.Nil(t, w.WriteRows(rows[5:]))
+		assert.Nil(t, w.Close())
+
+		reader, e := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+		assert.Nil(t, e)
+		assert.Equal(t, int64(20), reader.NumRows())
+		// 20 rows in row groups of 8
+		assert.Equal(t, 3, reader.NumRowGroups())
+		assert.Equal(t, int64(4), reader.RowGroup(2).NumRows())
+		chunk, e := reader.MetaData().RowGroup(0).ColumnChunk(1)
+		assert.Nil(t, e)
+		assert.Equal(t, expectedCodec, chunk.Compression())
+
+		fileReader, e := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+		assert.Nil(t, e)
+		table, e := fileReader.ReadTable(context.Background())
+		assert.Nil(t, e)
+		assert.Equal(t, int64(20), table.NumRows())
+		assert.Equal(t, "updated_at", table.Schema().Field(4).Name)
+		record := array.NewTableReader(table, 20)
+		assert.True(t, record.Next())
+		ids := record.Record().Column(0).(*array.String)
+		counts := record.Record().Column(1).(*array.Int64)
+		ratios := record.Record().Column(2).(*array.Float64)
+		dones := record.Record().Column(3).(*array.Boolean)
+		times := record.Record().Column(4).(*array.Timestamp)
+		for i := 0; i < 20; i++ {
+			assert.Equal(t, string(rune('a'+i)), ids.Value(i))
+			assert.Equal(t, i%3 == 0, counts.IsNull(i))
+			if i%3 != 0 {
+				assert.Equal(t, int64(i), counts.Value(i))
+			}
+			assert.Equal(t, float64(i)/2, ratios.Value(i))
+			assert.Equal(t, i%2 == 0, dones.Value(i))
+			assert.Equal(t, arrow.Timestamp(updatedAt.UnixMicro()), times.Value(i))
+		}
+		record.Release()
+		table.Release()
+	}
+}
+
+func TestParquetWriterInvalid(t *testing.T) {
+	_, err := newParquetWriter(&bytes.Buffer{}, nil, "zstd", 0)
+	assert.NotNil(t, err)
+
+	w, err := newParquetWriter(&bytes.Buffer{}, []ExportColumn{{Name: "count", Kind: KindInt}}, "", 0)
+	assert.Nil(t, err)
+	assert.NotNil(t, w.WriteRows([]map[string]interface{}{{"count": "1"}}))
+}
+
+func TestParquetSinkLocal(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := newParquetSink(nil, &ParquetConfig{Path: dir})
+	assert.Nil(t, err)
+	columns := []ExportColumn{{Name: "id", Kind: KindInt, PrimaryKey: true}}
+	export := func(incremental bool) {
+		w, err := sink.OpenTable("issues", columns, incremental)
+		assert.Nil(t, err)
+		assert.Nil(t, w.Write([]map[string]interface{}{{"id": int64(1)}}))
+		assert.Nil(t, w.Commit())
+	}
+	export(false)
+	export(true)
+	files, _ := filepath.Glob(filepath.Join(dir, "issues", "*"))
+	assert.Len(t, files, 2)
+	export(false)
+	files, _ = filepath.Glob(filepath.Join(dir, "issues", "*"))
+	assert.Len(t, files, 1)
+
+	w, err := sink.OpenTable("issues", columns, true)
+	assert.Nil(t, err)
+	w.Abort()
+	entries, _ := os.ReadDir(filepath.Join(dir, "issues"))
+	assert.Len(t, entries, 1)
+}
+
+// fakeS3 serves the objects of a bucket by path style requests, listing returns one object a page to cover pagination
+type fakeS3 struct {
+	mu        sync.Mutex
+	bucket    string
+	objects   map[string][]byte
+	listPages int
+}
+
+type fakeS3ListResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Name                  string
+	Prefix                string
+	KeyCount              int
+	IsTruncated           bool
+	NextContinuationToken string `xml:",omitempty"`
+	Contents              []struct {
+		Key  string
+		Size int
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(r.URL.Path, "/"+f.bucket) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+f.bucket), "/")
+	switch {
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = body
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.listPages++
+		prefix := r.URL.Query().Get("prefix")
+		var keys []string
+		for k := range f.objects {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		start, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+		result := fakeS3ListResult{Name: f.bucket, Prefix: prefix}
+		if start < len(keys) {
+			result.KeyCount = 1
+			result.Contents = append(result.Contents, struct {
+				Key  string
+				Size int
+			}{keys[start], len(f.objects[keys[start]])})
+		}
+		if start+1 < len(keys) {
+			result.IsTruncated = true
+			result.NextContinuationToken = strconv.Itoa(start + 1)
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(w).Encode(result)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) keys(prefix string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for k := range f.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestParquetSinkS3(t *testing.T) {
+	s3 := &fakeS3{bucket: "lake", objects: map[string][]byte{
+		"exports/issues/_SUCCESS":           {},
+		"exports/boards/part-0001.parquet":  []byte("PAR1"),
+		"exports/issues/part-0001.parquet":  []byte("PAR1"),
+		"exports/issues2/part-0001.parquet": []byte("PAR1"),
+	}}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+	sink, err := newParquetSink(context.Background(), &ParquetConfig{
+		Path:              "s3://lake/exports",
+		S3Endpoint:        server.URL,
+		S3AccessKeyId:     "minio",
+		S3SecretAccessKey: "minio123",
+		S3ForcePathStyle:  true,
+	})
+	assert.Nil(t, err)
+	columns := []ExportColumn{{Name: "id", Kind: KindInt, PrimaryKey: true}}
+	export := func(incremental bool) string {
+		w, err := sink.OpenTable("issues", columns, incremental)
+		assert.Nil(t, err)
+		assert.Nil(t, w.Write([]map[string]interface{}{{"id": int64(1)}}))
+		assert.Nil(t, w.Commit())
+		return "exports/issues/" + w.(*parquetTableWriter).name
+	}
+
+	// incremental exports keep the previous files
+	first := export(true)
+	second := export(true)
+	assert.Equal(t, []string{"exports/issues/_SUCCESS", "exports/issues/part-0001.parquet", first, second}, s3.keys("exports/issues/"))
+	assert.Equal(t, "PAR1", string(s3.objects[first][:4]))
+
+	// a full export removes the previous files listed across pages, other files and tables are kept
+	last := export(false)
+	assert.Equal(t, []string{"exports/issues/_SUCCESS", last}, s3.keys("exports/issues/"))
+	assert.Len(t, s3.keys("exports/boards/"), 1)
+	assert.Len(t, s3.keys("exports/issues2/"), 1)
+	assert.Equal(t, 5, s3.listPages)
+}
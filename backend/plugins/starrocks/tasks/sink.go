@@ -0,0 +1,217 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/plugins/starrocks/utils"
+)
+
+const (
+	SinkStarRocks  = "starrocks"
+	SinkParquet    = "parquet"
+	SinkClickHouse = "clickhouse"
+	SinkDuckDB     = "duckdb"
+)
+
+// ColumnKind is the type of an exported column, every sink maps it to its own data type
+type ColumnKind int
+
+const (
+	KindString ColumnKind = iota
+	KindInt
+	KindFloat
+	KindBool
+	KindTime
+)
+
+// ExportColumn describes a column of an exported table
+type ExportColumn struct {
+	Name       string
+	Kind       ColumnKind
+	PrimaryKey bool
+}
+
+// ExportSink is the destination the tables are exported to other than StarRocks
+type ExportSink interface {
+	// Destination identifies where the data go, the watermarks are kept per destination and table
+	Destination() string
+	// OpenTable prepares the table in the destination, the written rows replace all the existing ones unless incremental,
+	// in which case they are appended, or upserted if the sink supports primary keys
+	OpenTable(table string, columns []ExportColumn, incremental bool) (TableWriter, errors.Error)
+}
+
+// TableWriter receives the rows of a table, the values are normalized by normalizeValue
+type TableWriter interface {
+	Write(rows []map[string]interface{}) errors.Error
+	// Commit makes the written rows visible in the destination
+	Commit() errors.Error
+	// Abort discards the written rows
+	Abort()
+}
+
+// NewExportSink creates the sink configured by config.Sink
+func NewExportSink(ctx context.Context, config *StarRocksConfig) (ExportSink, errors.Error) {
+	switch config.Sink {
+	case SinkParquet:
+		if config.Parquet == nil || config.Parquet.Path == "" {
+			return nil, errors.BadInput.New("parquet.path is required by the parquet sink")
+		}
+		return newParquetSink(ctx, config.Parquet)
+	case SinkClickHouse:
+		if config.ClickHouse == nil || config.ClickHouse.Endpoint == "" || config.ClickHouse.Database == "" {
+			return nil, errors.BadInput.New("clickhouse.endpoint and clickhouse.database are required by the clickhouse sink")
+		}
+		return newClickHouseSink(ctx, config.ClickHouse), nil
+	case SinkDuckDB:
+		if config.DuckDB == nil || config.DuckDB.Path == "" {
+			return nil, errors.BadInput.New("duckdb.path is required by the duckdb sink")
+		}
+		return newDuckDBSink(ctx, config.DuckDB), nil
+	}
+	return nil, errors.BadInput.New(fmt.Sprintf("unsupported sink %s", config.Sink))
+}
+
+// getColumnKind maps the data type of the source database to a ColumnKind
+func getColumnKind(dataType string) ColumnKind {
+	switch starrocksDataType := utils.GetStarRocksDataType(dataType); starrocksDataType {
+	case "datetime", "date":
+		return KindTime
+	case "bigint", "int", "smallint":
+		return KindInt
+	case "float", "double", "decimal":
+		return KindFloat
+	case "boolean":
+		return KindBool
+	}
+	return KindString
+}
+
+// normalizeValue converts a value scanned from the source database to nil, string, int64, float64, bool or time.Time
+// according to the kind of the column
+func normalizeValue(kind ColumnKind, value interface{}) (interface{}, errors.Error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return normalizeString(kind, string(v))
+	case string:
+		return normalizeString(kind, v)
+	case *[]string:
+		if v == nil {
+			return nil, nil
+		}
+		b, err := json.Marshal(*v)
+		return string(b), errors.Convert(err)
+	case time.Time:
+		if kind == KindString {
+			return v.UTC().Format(time.RFC3339Nano), nil
+		}
+		return v.UTC(), nil
+	case bool:
+		switch kind {
+		case KindInt:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case KindString:
+			return strconv.FormatBool(v), nil
+		}
+		return v, nil
+	case float32:
+		return normalizeNumber(kind, float64(v))
+	case float64:
+		return normalizeNumber(kind, v)
+	case int:
+		return normalizeNumber(kind, int64(v))
+	case int8:
+		return normalizeNumber(kind, int64(v))
+	case int16:
+		return normalizeNumber(kind, int64(v))
+	case int32:
+		return normalizeNumber(kind, int64(v))
+	case int64:
+		return normalizeNumber(kind, v)
+	case uint8:
+		return normalizeNumber(kind, int64(v))
+	case uint16:
+		return normalizeNumber(kind, int64(v))
+	case uint32:
+		return normalizeNumber(kind, int64(v))
+	case uint64:
+		return normalizeNumber(kind, int64(v))
+	}
+	return normalizeString(kind, fmt.Sprintf("%v", value))
+}
+
+func normalizeNumber[T int64 | float64](kind ColumnKind, v T) (interface{}, errors.Error) {
+	switch kind {
+	case KindInt:
+		return int64(v), nil
+	case KindFloat:
+		return float64(v), nil
+	case KindBool:
+		return v != 0, nil
+	case KindTime:
+		return nil, errors.Default.New(fmt.Sprintf("%v is not a time", v))
+	}
+	return fmt.Sprintf("%v", v), nil
+}
+
+func normalizeString(kind ColumnKind, s string) (interface{}, errors.Error) {
+	switch kind {
+	case KindInt:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, fmt.Sprintf("%s is not an integer", s))
+		}
+		return v, nil
+	case KindFloat:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, fmt.Sprintf("%s is not a number", s))
+		}
+		return v, nil
+	case KindBool:
+		return s == "1" || strings.EqualFold(s, "true") || s == "t", nil
+	case KindTime:
+		if s == "" || strings.HasPrefix(s, "0000-00-00") {
+			return nil, nil
+		}
+		for _, layout := range []string{"2006-01-02 15:04:05.999999999", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.UTC(), nil
+			}
+		}
+		t, err := common.ConvertStringToTime(s)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, fmt.Sprintf("%s is not a time", s))
+		}
+		return t.UTC(), nil
+	}
+	return s, nil
+}
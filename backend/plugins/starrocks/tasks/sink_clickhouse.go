@@ -0,0 +1,161 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// clickHouseSink loads the tables through the HTTP interface of ClickHouse, a full export is loaded into a temporary
+// table which replaces the old one at last, while an incremental export is inserted into a ReplacingMergeTree table
+// which keeps the latest version of the rows sharing the same primary key
+type clickHouseSink struct {
+	ctx    context.Context
+	config *ClickHouseConfig
+	client *http.Client
+}
+
+func newClickHouseSink(ctx context.Context, config *ClickHouseConfig) *clickHouseSink {
+	return &clickHouseSink{ctx: ctx, config: config, client: &http.Client{Timeout: 10 * time.Minute}}
+}
+
+func (s *clickHouseSink) Destination() string {
+	return fmt.Sprintf("%s:%s/%s", SinkClickHouse, strings.TrimSuffix(s.config.Endpoint, "/"), s.config.Database)
+}
+
+// exec runs the query with the body, which is the data of an INSERT query
+func (s *clickHouseSink) exec(query string, body io.Reader) errors.Error {
+	params := url.Values{}
+	params.Set("database", s.config.Database)
+	params.Set("query", query)
+	params.Set("date_time_input_format", "best_effort")
+	if body == nil {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, fmt.Sprintf("%s/?%s", strings.TrimSuffix(s.config.Endpoint, "/"), params.Encode()), body)
+	if err != nil {
+		return errors.Convert(err)
+	}
+	if s.config.User != "" {
+		req.Header.Set("X-ClickHouse-User", s.config.User)
+		req.Header.Set("X-ClickHouse-Key", s.config.Password)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return errors.Convert(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(res.Body)
+		return errors.Default.New(fmt.Sprintf("clickhouse responded %d: %s", res.StatusCode, strings.TrimSpace(string(message))))
+	}
+	return nil
+}
+
+func (s *clickHouseSink) OpenTable(table string, columns []ExportColumn, incremental bool) (TableWriter, errors.Error) {
+	w := &clickHouseTableWriter{sink: s, table: table, target: table, incremental: incremental}
+	create := "CREATE TABLE IF NOT EXISTS"
+	if !incremental {
+		w.target = table + "_tmp"
+		if err := s.exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteClickHouse(w.target)), nil); err != nil {
+			return nil, err
+		}
+		create = "CREATE TABLE"
+	}
+	return w, s.exec(fmt.Sprintf("%s %s %s", create, quoteClickHouse(w.target), clickHouseTableDefinition(columns)), nil)
+}
+
+// clickHouseTableDefinition returns the columns and the engine of the table
+func clickHouseTableDefinition(columns []ExportColumn) string {
+	var definitions, pks []string
+	for _, column := range columns {
+		dataType := clickHouseDataType(column.Kind)
+		if column.PrimaryKey {
+			pks = append(pks, quoteClickHouse(column.Name))
+		} else {
+			dataType = fmt.Sprintf("Nullable(%s)", dataType)
+		}
+		definitions = append(definitions, fmt.Sprintf("%s %s", quoteClickHouse(column.Name), dataType))
+	}
+	if len(pks) == 0 {
+		return fmt.Sprintf("(%s) ENGINE = MergeTree ORDER BY tuple()", strings.Join(definitions, ", "))
+	}
+	return fmt.Sprintf("(%s) ENGINE = ReplacingMergeTree ORDER BY (%s)", strings.Join(definitions, ", "), strings.Join(pks, ", "))
+}
+
+func clickHouseDataType(kind ColumnKind) string {
+	switch kind {
+	case KindInt:
+		return "Int64"
+	case KindFloat:
+		return "Float64"
+	case KindBool:
+		return "Bool"
+	case KindTime:
+		return "DateTime64(6, 'UTC')"
+	}
+	return "String"
+}
+
+func quoteClickHouse(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
+
+type clickHouseTableWriter struct {
+	sink        *clickHouseSink
+	table       string
+	target      string
+	incremental bool
+}
+
+func (w *clickHouseTableWriter) Write(rows []map[string]interface{}) errors.Error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return errors.Convert(err)
+		}
+	}
+	return w.sink.exec(fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", quoteClickHouse(w.target)), &body)
+}
+
+func (w *clickHouseTableWriter) Commit() errors.Error {
+	if w.incremental {
+		return nil
+	}
+	if err := w.sink.exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteClickHouse(w.table)), nil); err != nil {
+		return err
+	}
+	return w.sink.exec(fmt.Sprintf("RENAME TABLE %s TO %s", quoteClickHouse(w.target), quoteClickHouse(w.table)), nil)
+}
+
+func (w *clickHouseTableWriter) Abort() {
+	if !w.incremental {
+		_ = w.sink.exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteClickHouse(w.target)), nil)
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// duckDBSink stages the rows of a table in a newline delimited JSON file and loads it with the duckdb command line,
+// which spares the cgo driver. A full export recreates the table while an incremental one upserts the rows by primary key
+type duckDBSink struct {
+	ctx    context.Context
+	config *DuckDBConfig
+}
+
+func newDuckDBSink(ctx context.Context, config *DuckDBConfig) *duckDBSink {
+	return &duckDBSink{ctx: ctx, config: config}
+}
+
+func (s *duckDBSink) Destination() string {
+	return fmt.Sprintf("%s:%s", SinkDuckDB, s.config.Path)
+}
+
+// exec runs the sql script with the duckdb command line, the script stops at the first error
+func (s *duckDBSink) exec(script string) errors.Error {
+	binary := s.config.Binary
+	if binary == "" {
+		binary = "duckdb"
+	}
+	cmd := exec.CommandContext(s.ctx, binary, "-bail", s.config.Path)
+	cmd.Stdin = strings.NewReader(script)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("duckdb failed: %s", strings.TrimSpace(string(output))))
+	}
+	return nil
+}
+
+func (s *duckDBSink) OpenTable(table string, columns []ExportColumn, incremental bool) (TableWriter, errors.Error) {
+	file, err := os.CreateTemp("", fmt.Sprintf("%s-*.ndjson", table))
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	return &duckDBTableWriter{
+		sink:        s,
+		table:       table,
+		columns:     columns,
+		incremental: incremental,
+		file:        file,
+		buf:         bufio.NewWriter(file),
+	}, nil
+}
+
+type duckDBTableWriter struct {
+	sink        *duckDBSink
+	table       string
+	columns     []ExportColumn
+	incremental bool
+	file        *os.File
+	buf         *bufio.Writer
+	rows        int
+}
+
+func (w *duckDBTableWriter) Write(rows []map[string]interface{}) errors.Error {
+	encoder := json.NewEncoder(w.buf)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return errors.Convert(err)
+		}
+	}
+	w.rows += len(rows)
+	return nil
+}
+
+func (w *duckDBTableWriter) Commit() errors.Error {
+	defer os.Remove(w.file.Name())
+	if err := w.buf.Flush(); err != nil {
+		_ = w.file.Close()
+		return errors.Convert(err)
+	}
+	if err := w.file.Close(); err != nil {
+		return errors.Convert(err)
+	}
+	return w.sink.exec(duckDBLoadScript(w.table, w.columns, w.file.Name(), w.rows > 0, w.incremental))
+}
+
+func (w *duckDBTableWriter) Abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
+
+// duckDBLoadScript returns the sql script loading the json file into the table in a transaction
+func duckDBLoadScript(table string, columns []ExportColumn, jsonFile string, hasRows, incremental bool) string {
+	var definitions, pks, types []string
+	for _, column := range columns {
+		dataType := duckDBDataType(column.Kind)
+		definitions = append(definitions, fmt.Sprintf("%s %s", quoteDuckDB(column.Name), dataType))
+		types = append(types, fmt.Sprintf("%s: '%s'", quoteDuckDBString(column.Name), dataType))
+		if column.PrimaryKey {
+			pks = append(pks, quoteDuckDB(column.Name))
+		}
+	}
+	if len(pks) > 0 {
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pks, ", ")))
+	}
+	var script strings.Builder
+	script.WriteString("BEGIN TRANSACTION;\n")
+	if !incremental {
+		script.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", quoteDuckDB(table)))
+	}
+	script.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s);\n", quoteDuckDB(table), strings.Join(definitions, ", ")))
+	if hasRows {
+		insert := "INSERT INTO"
+		if incremental && len(pks) > 0 {
+			insert = "INSERT OR REPLACE INTO"
+		}
+		script.WriteString(fmt.Sprintf(
+			"%s %s SELECT * FROM read_json(%s, format = 'newline_delimited', columns = {%s});\n",
+			insert, quoteDuckDB(table), quoteDuckDBString(jsonFile), strings.Join(types, ", "),
+		))
+	}
+	script.WriteString("COMMIT;\n")
+	return script.String()
+}
+
+func duckDBDataType(kind ColumnKind) string {
+	switch kind {
+	case KindInt:
+		return "BIGINT"
+	case KindFloat:
+		return "DOUBLE"
+	case KindBool:
+		return "BOOLEAN"
+	case KindTime:
+		return "TIMESTAMPTZ"
+	}
+	return "VARCHAR"
+}
+
+func quoteDuckDB(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteDuckDBString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
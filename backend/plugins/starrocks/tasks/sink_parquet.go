@@ -0,0 +1,215 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// parquetSink writes every export of a table to a new file under `<path>/<table>/`, a full export removes the files
+// of the previous exports once the new one is in place, so readers could always query `<path>/<table>/*.parquet`
+type parquetSink struct {
+	ctx    context.Context
+	config *ParquetConfig
+	// bucket and prefix are set when the path is an S3 location
+	bucket string
+	prefix string
+	s3     *s3.S3
+	sess   *session.Session
+}
+
+func newParquetSink(ctx context.Context, config *ParquetConfig) (*parquetSink, errors.Error) {
+	sink := &parquetSink{ctx: ctx, config: config}
+	if !strings.HasPrefix(config.Path, "s3://") {
+		return sink, nil
+	}
+	u, err := url.Parse(config.Path)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid parquet.path")
+	}
+	sink.bucket = u.Host
+	sink.prefix = strings.Trim(u.Path, "/")
+	awsConfig := &aws.Config{
+		Region:           aws.String(config.S3Region),
+		S3ForcePathStyle: aws.Bool(config.S3ForcePathStyle),
+	}
+	if config.S3Region == "" {
+		awsConfig.Region = aws.String("us-east-1")
+	}
+	if config.S3Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.S3Endpoint)
+	}
+	if config.S3AccessKeyId != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(config.S3AccessKeyId, config.S3SecretAccessKey, "")
+	}
+	sink.sess, err = session.NewSession(awsConfig)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to create aws session")
+	}
+	sink.s3 = s3.New(sink.sess)
+	return sink, nil
+}
+
+func (s *parquetSink) Destination() string {
+	return fmt.Sprintf("%s:%s", SinkParquet, s.config.Path)
+}
+
+func (s *parquetSink) OpenTable(table string, columns []ExportColumn, incremental bool) (TableWriter, errors.Error) {
+	// a local export is written to a hidden file next to the final one so readers never see a partial file
+	tempDir := ""
+	if s.s3 == nil {
+		tempDir = filepath.Join(s.config.Path, table)
+		if err := os.MkdirAll(tempDir, 0755); err != nil {
+			return nil, errors.Convert(err)
+		}
+	}
+	file, err := os.CreateTemp(tempDir, ".part-*.parquet.tmp")
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	writer, e := newParquetWriter(file, columns, s.config.Compression, s.config.RowGroupSize)
+	if e != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, e
+	}
+	return &parquetTableWriter{
+		sink:        s,
+		table:       table,
+		incremental: incremental,
+		file:        file,
+		writer:      writer,
+		// sortable and unique among the exports
+		name: fmt.Sprintf("part-%s.parquet", time.Now().UTC().Format("20060102T150405.000000000")),
+	}, nil
+}
+
+type parquetTableWriter struct {
+	sink        *parquetSink
+	table       string
+	incremental bool
+	file        *os.File
+	writer      *parquetWriter
+	name        string
+}
+
+func (w *parquetTableWriter) Write(rows []map[string]interface{}) errors.Error {
+	return w.writer.WriteRows(rows)
+}
+
+func (w *parquetTableWriter) Commit() errors.Error {
+	defer os.Remove(w.file.Name())
+	if err := w.writer.Close(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return errors.Convert(err)
+	}
+	if w.sink.s3 != nil {
+		return w.commitToS3()
+	}
+	return w.commitToLocal()
+}
+
+func (w *parquetTableWriter) commitToLocal() errors.Error {
+	dir := filepath.Join(w.sink.config.Path, w.table)
+	if err := os.Rename(w.file.Name(), filepath.Join(dir, w.name)); err != nil {
+		return errors.Convert(err)
+	}
+	if w.incremental {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return errors.Convert(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != w.name && strings.HasSuffix(entry.Name(), ".parquet") {
+			if err = os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return errors.Convert(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (w *parquetTableWriter) commitToS3() errors.Error {
+	file, err := os.Open(w.file.Name())
+	if err != nil {
+		return errors.Convert(err)
+	}
+	defer file.Close()
+	dir := path.Join(w.sink.prefix, w.table) + "/"
+	key := dir + w.name
+	_, err = s3manager.NewUploader(w.sink.sess).UploadWithContext(w.sink.ctx, &s3manager.UploadInput{
+		Bucket: aws.String(w.sink.bucket),
+		Key:    aws.String(key),
+		Body:   file,
+	})
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("failed to upload %s", key))
+	}
+	if w.incremental {
+		return nil
+	}
+	var staleKeys []string
+	err = w.sink.s3.ListObjectsV2PagesWithContext(w.sink.ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(w.sink.bucket),
+		Prefix: aws.String(dir),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			if k := aws.StringValue(object.Key); k != key && strings.HasSuffix(k, ".parquet") {
+				staleKeys = append(staleKeys, k)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("failed to list %s", dir))
+	}
+	for _, k := range staleKeys {
+		_, err = w.sink.s3.DeleteObjectWithContext(w.sink.ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(w.sink.bucket),
+			Key:    aws.String(k),
+		})
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("failed to delete %s", k))
+		}
+	}
+	return nil
+}
+
+func (w *parquetTableWriter) Abort() {
+	_ = w.file.Close()
+	_ = os.Remove(w.file.Name())
+}
@@ -0,0 +1,118 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	testCases := []struct {
+		kind     ColumnKind
+		value    interface{}
+		expected interface{}
+	}{
+		{KindInt, []byte("42"), int64(42)},
+		{KindInt, int32(42), int64(42)},
+		{KindFloat, "1.5", 1.5},
+		{KindBool, int64(1), true},
+		{KindBool, []byte("0"), false},
+		{KindString, int64(7), "7"},
+		{KindString, &[]string{"a", "b"}, `["a","b"]`},
+		{KindTime, []byte("2024-01-02 03:04:05"), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{KindTime, "0000-00-00 00:00:00", nil},
+		{KindTime, nil, nil},
+	}
+	for _, tc := range testCases {
+		v, err := normalizeValue(tc.kind, tc.value)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, v)
+	}
+	_, err := normalizeValue(KindInt, "abc")
+	assert.NotNil(t, err)
+}
+
+func TestClickHouseSink(t *testing.T) {
+	var mu sync.Mutex
+	var queries, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "lake", r.URL.Query().Get("database"))
+		assert.Equal(t, "default", r.Header.Get("X-ClickHouse-User"))
+		queries = append(queries, r.URL.Query().Get("query"))
+		bodies = append(bodies, string(body))
+		if strings.Contains(r.URL.Query().Get("query"), "broken") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("Code: 62. Syntax error"))
+		}
+	}))
+	defer server.Close()
+
+	sink := newClickHouseSink(context.Background(), &ClickHouseConfig{Endpoint: server.URL, User: "default", Database: "lake"})
+	columns := []ExportColumn{{Name: "id", Kind: KindString, PrimaryKey: true}, {Name: "updated_at", Kind: KindTime}}
+	w, err := sink.OpenTable("issues", columns, false)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Write([]map[string]interface{}{{"id": "1", "updated_at": nil}, {"id": "2", "updated_at": nil}}))
+	assert.Nil(t, w.Commit())
+	assert.Equal(t, []string{
+		"DROP TABLE IF EXISTS `issues_tmp`",
+		"CREATE TABLE `issues_tmp` (`id` String, `updated_at` Nullable(DateTime64(6, 'UTC'))) ENGINE = ReplacingMergeTree ORDER BY (`id`)",
+		"INSERT INTO `issues_tmp` FORMAT JSONEachRow",
+		"DROP TABLE IF EXISTS `issues`",
+		"RENAME TABLE `issues_tmp` TO `issues`",
+	}, queries)
+	assert.Equal(t, "{\"id\":\"1\",\"updated_at\":null}\n{\"id\":\"2\",\"updated_at\":null}\n", bodies[2])
+
+	queries = nil
+	w, err = sink.OpenTable("issues", columns, true)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Commit())
+	assert.Equal(t, []string{
+		"CREATE TABLE IF NOT EXISTS `issues` (`id` String, `updated_at` Nullable(DateTime64(6, 'UTC'))) ENGINE = ReplacingMergeTree ORDER BY (`id`)",
+	}, queries)
+
+	_, err = sink.OpenTable("broken", columns, true)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Syntax error")
+}
+
+func TestDuckDBLoadScript(t *testing.T) {
+	columns := []ExportColumn{{Name: "id", Kind: KindString, PrimaryKey: true}, {Name: "story_point", Kind: KindFloat}}
+	assert.Equal(t, "BEGIN TRANSACTION;\n"+
+		"DROP TABLE IF EXISTS \"issues\";\n"+
+		"CREATE TABLE IF NOT EXISTS \"issues\" (\"id\" VARCHAR, \"story_point\" DOUBLE, PRIMARY KEY (\"id\"));\n"+
+		"INSERT INTO \"issues\" SELECT * FROM read_json('/tmp/issues.ndjson', format = 'newline_delimited', columns = {'id': 'VARCHAR', 'story_point': 'DOUBLE'});\n"+
+		"COMMIT;\n",
+		duckDBLoadScript("issues", columns, "/tmp/issues.ndjson", true, false))
+	assert.Equal(t, "BEGIN TRANSACTION;\n"+
+		"CREATE TABLE IF NOT EXISTS \"issues\" (\"id\" VARCHAR, \"story_point\" DOUBLE, PRIMARY KEY (\"id\"));\n"+
+		"INSERT OR REPLACE INTO \"issues\" SELECT * FROM read_json('/tmp/issues.ndjson', format = 'newline_delimited', columns = {'id': 'VARCHAR', 'story_point': 'DOUBLE'});\n"+
+		"COMMIT;\n",
+		duckDBLoadScript("issues", columns, "/tmp/issues.ndjson", true, true))
+}
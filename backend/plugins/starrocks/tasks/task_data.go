@@ -40,4 +40,40 @@ type StarRocksConfig struct {
 	OrderBy      map[string]string      `mapstructure:"order_by"`
 	DomainLayer  string                 `mapstructure:"domain_layer"`
 	Extra        map[string]string
+	// Sink is where the tables are exported to, one of starrocks(default), parquet, clickhouse and duckdb
+	Sink string
+	// Incremental exports only the rows whose UpdateColumn is greater than the watermark of the last export,
+	// not supported by the starrocks sink
+	Incremental bool
+	Parquet     *ParquetConfig
+	ClickHouse  *ClickHouseConfig `mapstructure:"clickhouse"`
+	DuckDB      *DuckDBConfig     `mapstructure:"duckdb"`
+}
+
+type ParquetConfig struct {
+	// Path is a local directory or an S3 location like s3://bucket/prefix, each table is exported to a sub directory
+	Path         string
+	Compression  string // none(default) or gzip
+	RowGroupSize int    `mapstructure:"row_group_size"`
+	// S3 compatible storage settings, e.g. MinIO, the default credential chain of AWS is used if the keys are empty
+	S3Endpoint        string `mapstructure:"s3_endpoint"`
+	S3Region          string `mapstructure:"s3_region"`
+	S3AccessKeyId     string `mapstructure:"s3_access_key_id"`
+	S3SecretAccessKey string `mapstructure:"s3_secret_access_key"`
+	S3ForcePathStyle  bool   `mapstructure:"s3_force_path_style"`
+}
+
+type ClickHouseConfig struct {
+	// Endpoint of the HTTP interface, e.g. http://localhost:8123
+	Endpoint string
+	User     string
+	Password string
+	Database string
+}
+
+type DuckDBConfig struct {
+	// Path of the database file
+	Path string
+	// Binary is the duckdb command line executable, default to DUCKDB_BINARY of the env or `duckdb` in PATH
+	Binary string
 }
@@ -29,8 +29,6 @@ import (
 	"strings"
 	"time"
 
-	"golang.org/x/exp/slices"
-
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/plugin"
@@ -87,6 +85,13 @@ func ExportData(c plugin.SubTaskContext) errors.Error {
 	if err != nil {
 		return errors.Convert(err)
 	}
+	if config.Sink != "" && config.Sink != SinkStarRocks {
+		sink, err := NewExportSink(c.GetContext(), config)
+		if err != nil {
+			return err
+		}
+		return exportToSink(c, db, starrocksTables, sink)
+	}
 	// 3. copy devlake data to starrocks
 	sr, err := gorm.Open(mysql.Open(fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local", config.User, config.Password, config.Host, config.Port, config.Database)))
 	if err != nil {
@@ -164,21 +169,8 @@ func createTmpTableInStarrocks(dc *DataConfigParams) (map[string]string, string,
 	} else {
 		return nil, "", false, errors.NotFound.New(fmt.Sprintf("unsupported dialect %s", db.Dialect()))
 	}
-	tableConfig, ok := config.TableConfigs[table]
-	for _, cm := range columnMetas {
+	for _, cm := range filterColumns(config, table, columnMetas) {
 		name := cm.Name()
-		if ok {
-			if len(tableConfig.ExcludedColumns) > 0 {
-				if slices.Contains(tableConfig.ExcludedColumns, name) {
-					continue
-				}
-			}
-			if len(tableConfig.IncludedColumns) > 0 {
-				if !slices.Contains(tableConfig.IncludedColumns, name) {
-					continue
-				}
-			}
-		}
 		if name == updateColumn {
 			// check update column to detect skip or not
 			var updatedFrom time.Time
@@ -518,5 +510,5 @@ var ExportDataTaskMeta = plugin.SubTaskMeta{
 	Name:             "ExportData",
 	EntryPoint:       ExportData,
 	EnabledByDefault: true,
-	Description:      "Load data to StarRocks",
+	Description:      "Load data to StarRocks or the other configured sink",
 }
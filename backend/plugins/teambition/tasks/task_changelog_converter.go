@@ -18,6 +18,8 @@ limitations under the License.
 package tasks
 
 import (
+	"encoding/json"
+
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models/domainlayer"
@@ -28,6 +30,14 @@ import (
 	"reflect"
 )
 
+// sprintActivityContent is the content of `update.sprint` activities
+type sprintActivityContent struct {
+	Sprint *struct {
+		Id   string `json:"_id"`
+		Name string `json:"name"`
+	} `json:"sprint"`
+}
+
 var ConvertTaskChangelogMeta = plugin.SubTaskMeta{
 	Name:             "convertTaskChangelog",
 	EntryPoint:       ConvertTaskChangelog,
@@ -67,6 +77,19 @@ func ConvertTaskChangelog(taskCtx plugin.SubTaskContext) errors.Error {
 				FieldId:         userTool.Action,
 				OriginalToValue: userTool.Content,
 			}
+			// normalize sprint changes the same way as other ticket plugins do
+			if userTool.Action == "update.sprint" {
+				var content sprintActivityContent
+				if err := json.Unmarshal([]byte(userTool.Content), &content); err != nil {
+					return nil, errors.Convert(err)
+				}
+				issueComment.FieldName = "Sprint"
+				issueComment.OriginalToValue = ""
+				if content.Sprint != nil && content.Sprint.Id != "" {
+					issueComment.OriginalToValue = getSprintIdGen().Generate(userTool.ConnectionId, content.Sprint.Id)
+					issueComment.ToValue = content.Sprint.Name
+				}
+			}
 			return []interface{}{
 				issueComment,
 			}, nil
@@ -0,0 +1,94 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/dbhelper"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+)
+
+// webhookRecordSaver decodes, validates and saves a single record, returning its id
+type webhookRecordSaver func(connection *models.WebhookConnection, record map[string]interface{}, tx dal.Transaction) (string, errors.Error)
+
+type WebhookBatchItemResult struct {
+	Index   int    `json:"index"`
+	Type    string `json:"type,omitempty"`
+	Id      string `json:"id,omitempty"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+type WebhookBatchResponse struct {
+	Total     int                      `json:"total"`
+	Succeeded int                      `json:"succeeded"`
+	Failed    int                      `json:"failed"`
+	Results   []WebhookBatchItemResult `json:"results"`
+}
+
+func (r *WebhookBatchResponse) add(result WebhookBatchItemResult, err errors.Error) {
+	r.Total++
+	if err != nil {
+		result.Error = err.Messages().Format()
+		r.Failed++
+	} else {
+		result.Success = true
+		r.Succeeded++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// output answers 200 when every record was saved, 207 when only some of them were and 400 when none was
+func (r *WebhookBatchResponse) output() *plugin.ApiResourceOutput {
+	status := http.StatusOK
+	if r.Failed > 0 {
+		status = http.StatusMultiStatus
+		if r.Succeeded == 0 {
+			status = http.StatusBadRequest
+		}
+	}
+	return &plugin.ApiResourceOutput{Body: r, Status: status}
+}
+
+// saveBatch saves every record of a json array in its own transaction, so a bad record doesn't reject the others
+func saveBatch(items []interface{}, connection *models.WebhookConnection, saver webhookRecordSaver) *plugin.ApiResourceOutput {
+	response := &WebhookBatchResponse{Results: make([]WebhookBatchItemResult, 0, len(items))}
+	for i, item := range items {
+		result := WebhookBatchItemResult{Index: i}
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			response.add(result, errors.BadInput.New("record must be a json object"))
+			continue
+		}
+		id, err := saveRecord(connection, record, saver)
+		result.Id = id
+		response.add(result, err)
+	}
+	return response.output()
+}
+
+func saveRecord(connection *models.WebhookConnection, record map[string]interface{}, saver webhookRecordSaver) (id string, err errors.Error) {
+	txHelper := dbhelper.NewTxHelper(basicRes, &err)
+	defer txHelper.End()
+	tx := txHelper.Begin()
+	return saver(connection, record, tx)
+}
@@ -0,0 +1,297 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+)
+
+const (
+	cdEventTypePrefix       = "dev.cdevents."
+	cdEventServiceDeployed  = "service.deployed"
+	cdEventChangeMerged     = "change.merged"
+	cdEventIncidentDetected = "incident.detected"
+)
+
+var trailingNumberPattern = regexp.MustCompile(`(\d+)$`)
+
+// cdEvent holds the parts of a CDEvent (https://cdevents.dev) the webhook maps to domain records
+type cdEvent struct {
+	Type       string
+	Id         string
+	Timestamp  string
+	SubjectId  string
+	Content    map[string]interface{}
+	CustomData map[string]interface{}
+}
+
+// PostEvents
+// @Summary receive CDEvents by webhook
+// @Description Receive CDEvents, either as plain json or wrapped in a CloudEvent (structured, binary or batch mode).<br/>
+// @Description Supported types: dev.cdevents.service.deployed, dev.cdevents.change.merged and dev.cdevents.incident.detected.<br/>
+// @Description Fields of "customData" override the mapped deployment, pull request or issue, e.g. {"deploymentCommits":[{"repoUrl":"...","commitSha":"..."}]}
+// @Tags plugins/webhook
+// @Param body body object true "CDEvent, CloudEvent or an array of them"
+// @Success 200  {object} WebhookBatchResponse
+// @Success 207  {object} WebhookBatchResponse
+// @Failure 400  {object} WebhookBatchResponse
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/events [POST]
+func PostEvents(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+
+	return postEvents(input, connection, err)
+}
+
+// PostEventsByName
+// @Summary receive CDEvents by webhook name
+// @Description Receive CDEvents, either as plain json or wrapped in a CloudEvent (structured, binary or batch mode).<br/>
+// @Description Supported types: dev.cdevents.service.deployed, dev.cdevents.change.merged and dev.cdevents.incident.detected.<br/>
+// @Description Fields of "customData" override the mapped deployment, pull request or issue, e.g. {"deploymentCommits":[{"repoUrl":"...","commitSha":"..."}]}
+// @Tags plugins/webhook
+// @Param body body object true "CDEvent, CloudEvent or an array of them"
+// @Success 200  {object} WebhookBatchResponse
+// @Success 207  {object} WebhookBatchResponse
+// @Failure 400  {object} WebhookBatchResponse
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/events [POST]
+func PostEventsByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+
+	return postEvents(input, connection, err)
+}
+
+func postEvents(input *plugin.ApiResourceInput, connection *models.WebhookConnection, err errors.Error) (*plugin.ApiResourceOutput, errors.Error) {
+	if err != nil {
+		return nil, err
+	}
+	return withIdempotencyKey(input, connection, saveEvents)
+}
+
+func saveEvents(input *plugin.ApiResourceInput, connection *models.WebhookConnection) (*plugin.ApiResourceOutput, errors.Error) {
+	items := input.BodyList
+	if items == nil {
+		if input.Body == nil {
+			return nil, errors.BadInput.New("request body is empty")
+		}
+		items = []interface{}{withBinaryCloudEventHeaders(input.Body, input.Request)}
+	}
+	response := &WebhookBatchResponse{Results: make([]WebhookBatchItemResult, 0, len(items))}
+	for i, item := range items {
+		result := WebhookBatchItemResult{Index: i}
+		raw, ok := item.(map[string]interface{})
+		if !ok {
+			response.add(result, errors.BadInput.New("event must be a json object"))
+			continue
+		}
+		event, err := parseCDEvent(raw)
+		if err != nil {
+			response.add(result, err)
+			continue
+		}
+		result.Type = event.Type
+		record, saver, err := event.toRecord()
+		if err == nil {
+			result.Id, err = saveRecord(connection, record, saver)
+		}
+		response.add(result, err)
+	}
+	return response.output(), nil
+}
+
+// withBinaryCloudEventHeaders turns a CloudEvent sent in binary mode, where the attributes are `ce-` headers
+// and the body is the event data, into its structured form
+func withBinaryCloudEventHeaders(body map[string]interface{}, request *http.Request) map[string]interface{} {
+	if request == nil || request.Header.Get("ce-specversion") == "" {
+		return body
+	}
+	return map[string]interface{}{
+		"specversion": request.Header.Get("ce-specversion"),
+		"id":          request.Header.Get("ce-id"),
+		"source":      request.Header.Get("ce-source"),
+		"type":        request.Header.Get("ce-type"),
+		"time":        request.Header.Get("ce-time"),
+		"data":        body,
+	}
+}
+
+// parseCDEvent accepts either a plain CDEvent or a structured CloudEvent whose data is a CDEvent
+func parseCDEvent(raw map[string]interface{}) (*cdEvent, errors.Error) {
+	event := &cdEvent{}
+	if _, ok := raw["specversion"]; ok {
+		event.Type = stringOf(raw["type"])
+		event.Id = stringOf(raw["id"])
+		event.Timestamp = stringOf(raw["time"])
+		data, ok := raw["data"].(map[string]interface{})
+		if !ok {
+			return nil, errors.BadInput.New("cloudevent data must be a CDEvent")
+		}
+		raw = data
+	}
+	context := mapOf(raw["context"])
+	subject := mapOf(raw["subject"])
+	if context == nil || subject == nil {
+		return nil, errors.BadInput.New("CDEvent must have a context and a subject")
+	}
+	if t := stringOf(context["type"]); t != "" {
+		event.Type = t
+	}
+	if id := stringOf(context["id"]); id != "" {
+		event.Id = id
+	}
+	if ts := stringOf(context["timestamp"]); ts != "" {
+		event.Timestamp = ts
+	}
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().Format(time.RFC3339)
+	}
+	event.Type = normalizeCDEventType(event.Type)
+	event.SubjectId = stringOf(subject["id"])
+	event.Content = mapOf(subject["content"])
+	event.CustomData = mapOf(raw["customData"])
+	return event, nil
+}
+
+// normalizeCDEventType strips the prefix and the version, e.g. dev.cdevents.service.deployed.0.1.1 -> service.deployed
+func normalizeCDEventType(eventType string) string {
+	parts := strings.Split(strings.TrimPrefix(eventType, cdEventTypePrefix), ".")
+	if len(parts) < 2 {
+		return eventType
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// toRecord maps the event to the request body of the matching webhook endpoint, customData overrides the mapped fields
+func (e *cdEvent) toRecord() (map[string]interface{}, webhookRecordSaver, errors.Error) {
+	var record map[string]interface{}
+	var saver webhookRecordSaver
+	switch e.Type {
+	case cdEventServiceDeployed:
+		environment := stringOf(mapOf(e.Content["environment"])["id"])
+		record = map[string]interface{}{
+			"id":                  e.Id,
+			"name":                e.SubjectId,
+			"result":              devops.RESULT_SUCCESS,
+			"environment":         standardEnvironment(environment),
+			"originalEnvironment": environment,
+			"startedDate":         e.Timestamp,
+			"finishedDate":        e.Timestamp,
+		}
+		if e.CustomData["deploymentCommits"] == nil && e.CustomData["commitSha"] != nil {
+			record["deploymentCommits"] = []interface{}{map[string]interface{}{
+				"repoUrl":   e.CustomData["repoUrl"],
+				"refName":   e.CustomData["refName"],
+				"commitSha": e.CustomData["commitSha"],
+			}}
+		}
+		saver = saveDeploymentRecord
+	case cdEventChangeMerged:
+		record = map[string]interface{}{
+			"id":             e.SubjectId,
+			"displayTitle":   fmt.Sprintf("change %s", e.SubjectId),
+			"status":         code.MERGED,
+			"originalStatus": "merged",
+			"headRepoId":     stringOf(mapOf(e.Content["repository"])["id"]),
+			"createdDate":    e.Timestamp,
+			"mergedDate":     e.Timestamp,
+		}
+		if match := trailingNumberPattern.FindString(e.SubjectId); match != "" {
+			record["pullRequestKey"] = match
+		}
+		saver = savePullRequestRecord
+	case cdEventIncidentDetected:
+		description := stringOf(e.Content["description"])
+		title := description
+		if title == "" {
+			title = fmt.Sprintf("incident %s", e.SubjectId)
+		}
+		record = map[string]interface{}{
+			"issueKey":       e.SubjectId,
+			"title":          title,
+			"description":    description,
+			"type":           ticket.INCIDENT,
+			"status":         ticket.TODO,
+			"originalStatus": "detected",
+			"component":      stringOf(mapOf(e.Content["service"])["id"]),
+			"createdDate":    e.Timestamp,
+		}
+		saver = saveIssueRecord
+	default:
+		return nil, nil, errors.BadInput.New(fmt.Sprintf("unsupported event type: %s", e.Type))
+	}
+	for k, v := range e.CustomData {
+		record[k] = v
+	}
+	if e.Type == cdEventServiceDeployed {
+		// the dates of deployment commits are required, they default to the ones of the deployment
+		commits, _ := record["deploymentCommits"].([]interface{})
+		for _, commit := range commits {
+			if c := mapOf(commit); c != nil {
+				for _, field := range []string{"startedDate", "finishedDate"} {
+					if c[field] == nil {
+						c[field] = record[field]
+					}
+				}
+			}
+		}
+	}
+	return record, saver, nil
+}
+
+func standardEnvironment(environment string) string {
+	env := strings.ToUpper(environment)
+	switch {
+	case strings.Contains(env, "PROD"):
+		return devops.PRODUCTION
+	case strings.Contains(env, "STAG"):
+		return devops.STAGING
+	case strings.Contains(env, "TEST"), strings.Contains(env, "QA"):
+		return devops.TESTING
+	case strings.Contains(env, "DEV"):
+		return "DEVELOPMENT"
+	}
+	return ""
+}
+
+func mapOf(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func stringOf(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,138 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCDEventFromCloudEvent(t *testing.T) {
+	event, err := parseCDEvent(map[string]interface{}{
+		"specversion": "1.0",
+		"id":          "ce-1",
+		"type":        "dev.cdevents.service.deployed.0.1.1",
+		"time":        "2024-01-01T00:00:00Z",
+		"data": map[string]interface{}{
+			"context": map[string]interface{}{
+				"id":        "cd-1",
+				"type":      "dev.cdevents.service.deployed.0.1.1",
+				"timestamp": "2024-01-02T00:00:00Z",
+			},
+			"subject": map[string]interface{}{
+				"id": "service/payments",
+				"content": map[string]interface{}{
+					"environment": map[string]interface{}{"id": "prod-eu"},
+				},
+			},
+			"customData": map[string]interface{}{
+				"repoUrl":   "https://github.com/org/payments",
+				"commitSha": "abc123",
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, cdEventServiceDeployed, event.Type)
+	assert.Equal(t, "cd-1", event.Id)
+	assert.Equal(t, "2024-01-02T00:00:00Z", event.Timestamp)
+
+	record, _, err := event.toRecord()
+	assert.Nil(t, err)
+	assert.Equal(t, "cd-1", record["id"])
+	assert.Equal(t, "PRODUCTION", record["environment"])
+	assert.Equal(t, "prod-eu", record["originalEnvironment"])
+	commits := record["deploymentCommits"].([]interface{})
+	assert.Len(t, commits, 1)
+	commit := commits[0].(map[string]interface{})
+	assert.Equal(t, "abc123", commit["commitSha"])
+	assert.Equal(t, "2024-01-02T00:00:00Z", commit["startedDate"])
+}
+
+func TestCDEventToRecord(t *testing.T) {
+	merged := &cdEvent{
+		Type:       cdEventChangeMerged,
+		Id:         "cd-2",
+		Timestamp:  "2024-01-02T00:00:00Z",
+		SubjectId:  "org/payments/pull/42",
+		CustomData: map[string]interface{}{"displayTitle": "Add refunds"},
+	}
+	record, _, err := merged.toRecord()
+	assert.Nil(t, err)
+	assert.Equal(t, "42", record["pullRequestKey"])
+	assert.Equal(t, "MERGED", record["status"])
+	assert.Equal(t, "Add refunds", record["displayTitle"])
+
+	incident := &cdEvent{
+		Type:      cdEventIncidentDetected,
+		Timestamp: "2024-01-02T00:00:00Z",
+		SubjectId: "INC-7",
+		Content: map[string]interface{}{
+			"description": "payments are failing",
+			"service":     map[string]interface{}{"id": "service/payments"},
+		},
+	}
+	record, _, err = incident.toRecord()
+	assert.Nil(t, err)
+	assert.Equal(t, "INC-7", record["issueKey"])
+	assert.Equal(t, "INCIDENT", record["type"])
+	assert.Equal(t, "payments are failing", record["title"])
+	assert.Equal(t, "service/payments", record["component"])
+
+	_, _, err = (&cdEvent{Type: "build.finished"}).toRecord()
+	assert.NotNil(t, err)
+}
+
+func TestParseCDEventErrors(t *testing.T) {
+	_, err := parseCDEvent(map[string]interface{}{"specversion": "1.0", "data": "plain text"})
+	assert.NotNil(t, err)
+	_, err = parseCDEvent(map[string]interface{}{"context": map[string]interface{}{}})
+	assert.NotNil(t, err)
+}
+
+func TestWithBinaryCloudEventHeaders(t *testing.T) {
+	body := map[string]interface{}{"context": map[string]interface{}{}, "subject": map[string]interface{}{}}
+	assert.Equal(t, body, withBinaryCloudEventHeaders(body, nil))
+
+	request, _ := http.NewRequest(http.MethodPost, "/", nil)
+	request.Header.Set("ce-specversion", "1.0")
+	request.Header.Set("ce-type", "dev.cdevents.change.merged.0.1.2")
+	request.Header.Set("ce-id", "ce-3")
+	event := withBinaryCloudEventHeaders(body, request)
+	assert.Equal(t, "dev.cdevents.change.merged.0.1.2", event["type"])
+	assert.Equal(t, body, event["data"])
+}
+
+func TestNormalizeCDEventType(t *testing.T) {
+	assert.Equal(t, "service.deployed", normalizeCDEventType("dev.cdevents.service.deployed.0.1.1"))
+	assert.Equal(t, "incident.detected", normalizeCDEventType("dev.cdevents.incident.detected"))
+	assert.Equal(t, "unknown", normalizeCDEventType("unknown"))
+}
+
+func TestBatchResponseStatus(t *testing.T) {
+	response := &WebhookBatchResponse{}
+	response.add(WebhookBatchItemResult{Index: 0}, nil)
+	assert.Equal(t, http.StatusOK, response.output().Status)
+	response.add(WebhookBatchItemResult{Index: 1}, errors.BadInput.New("record must be a json object"))
+	assert.Equal(t, http.StatusMultiStatus, response.output().Status)
+	assert.Equal(t, 1, response.Failed)
+	assert.False(t, response.Results[1].Success)
+	assert.NotEmpty(t, response.Results[1].Error)
+}
@@ -214,9 +214,16 @@ func postDeployments(input *plugin.ApiResourceInput, connection *models.WebhookC
 	if err != nil {
 		return nil, err
 	}
+	return withIdempotencyKey(input, connection, saveDeployments)
+}
+
+func saveDeployments(input *plugin.ApiResourceInput, connection *models.WebhookConnection) (*plugin.ApiResourceOutput, errors.Error) {
+	if input.BodyList != nil {
+		return saveBatch(input.BodyList, connection, saveDeploymentRecord), nil
+	}
 	// get request
 	request := &WebhookDeploymentReq{}
-	err = api.DecodeMapStruct(input.Body, request, true)
+	err := api.DecodeMapStruct(input.Body, request, true)
 	if err != nil {
 		return &plugin.ApiResourceOutput{Body: err.Error(), Status: http.StatusBadRequest}, nil
 	}
@@ -237,6 +244,17 @@ func postDeployments(input *plugin.ApiResourceInput, connection *models.WebhookC
 	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
 }
 
+func saveDeploymentRecord(connection *models.WebhookConnection, record map[string]interface{}, tx dal.Transaction) (string, errors.Error) {
+	request := &WebhookDeploymentReq{}
+	if err := api.DecodeMapStruct(record, request, true); err != nil {
+		return "", errors.BadInput.Wrap(err, `input json error`)
+	}
+	if err := vld.Struct(request); err != nil {
+		return request.Id, errors.BadInput.Wrap(err, `input json error`)
+	}
+	return request.Id, CreateDeploymentAndDeploymentCommits(connection, request, tx, logger)
+}
+
 func CreateDeploymentAndDeploymentCommits(connection *models.WebhookConnection, request *WebhookDeploymentReq, tx dal.Transaction, logger log.Logger) errors.Error {
 	// validation
 	if request == nil {
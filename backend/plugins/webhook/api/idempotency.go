@@ -0,0 +1,189 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+)
+
+const (
+	idempotencyKeyHeader   = "Idempotency-Key"
+	idempotentReplayHeader = "Idempotent-Replayed"
+	idempotencyKeyTTL      = 24 * time.Hour
+	// a reservation left by a request which never finished, e.g. the server crashed, expires after the timeout
+	idempotencyPendingTimeout = 10 * time.Minute
+	// the status of a reserved key whose request is still being handled
+	idempotencyPending = 0
+)
+
+type webhookHandler func(input *plugin.ApiResourceInput, connection *models.WebhookConnection) (*plugin.ApiResourceOutput, errors.Error)
+
+// withIdempotencyKey runs the handler once per `Idempotency-Key`: the key is reserved before running the handler,
+// a concurrent delivery with the same key is rejected while the handler is running, a retried delivery with the same
+// key and payload is answered with the stored response, and reusing a key with a different payload is rejected
+func withIdempotencyKey(input *plugin.ApiResourceInput, connection *models.WebhookConnection, handler webhookHandler) (*plugin.ApiResourceOutput, errors.Error) {
+	key := getIdempotencyKey(input)
+	if key == "" {
+		return handler(input, connection)
+	}
+	if len(key) > 255 {
+		return nil, errors.BadInput.New("Idempotency-Key must not exceed 255 characters")
+	}
+	requestHash, err := hashRequestBody(input)
+	if err != nil {
+		return nil, err
+	}
+	reserved, output, err := reserveIdempotencyKey(connection.ID, key, requestHash)
+	if err != nil || !reserved {
+		return output, err
+	}
+
+	db := basicRes.GetDal()
+	where := dal.Where("connection_id = ? AND idempotency_key = ?", connection.ID, key)
+	output, err = handler(input, connection)
+	if err != nil || output == nil || output.Status >= http.StatusInternalServerError {
+		// release the key so the delivery could be retried
+		if e := db.Delete(&models.WebhookIdempotencyKey{}, where); e != nil {
+			logger.Error(e, "failed to release idempotency key %s", key)
+		}
+		return output, err
+	}
+	response, marshalErr := json.Marshal(output.Body)
+	if marshalErr != nil {
+		return nil, errors.Convert(marshalErr)
+	}
+	err = db.UpdateColumns(&models.WebhookIdempotencyKey{}, []dal.DalSet{
+		{ColumnName: "status", Value: output.Status},
+		{ColumnName: "response", Value: string(response)},
+	}, where)
+	if err != nil {
+		logger.Error(err, "failed to save the response of idempotency key %s", key)
+	}
+	return output, nil
+}
+
+// reserveIdempotencyKey inserts a pending record of the key, the unique primary key makes sure only one of the
+// concurrent deliveries succeeds. The others get the response to be returned instead of running the handler
+func reserveIdempotencyKey(connectionId uint64, key, requestHash string) (bool, *plugin.ApiResourceOutput, errors.Error) {
+	db := basicRes.GetDal()
+	where := dal.Where("connection_id = ? AND idempotency_key = ?", connectionId, key)
+	// the key could be reserved again after the previous record expired
+	for attempt := 0; attempt < 2; attempt++ {
+		err := db.Create(&models.WebhookIdempotencyKey{
+			ConnectionId:   connectionId,
+			IdempotencyKey: key,
+			RequestHash:    requestHash,
+			Status:         idempotencyPending,
+		})
+		if err == nil {
+			return true, nil, nil
+		}
+		if !db.IsDuplicationError(err) {
+			return false, nil, err
+		}
+		record := &models.WebhookIdempotencyKey{}
+		err = db.First(record, where)
+		if db.IsErrorNotFound(err) {
+			// released by the other delivery in the meantime
+			continue
+		}
+		if err != nil {
+			return false, nil, err
+		}
+		if !isIdempotencyKeyExpired(record, time.Now()) {
+			output, err := replayIdempotentResponse(record, requestHash)
+			return false, output, err
+		}
+		// only the expired record is deleted, not the one just reserved by another delivery
+		now := time.Now()
+		err = db.Delete(&models.WebhookIdempotencyKey{}, where, dal.Where(
+			"((status = ? AND created_at < ?) OR created_at < ?)",
+			idempotencyPending, now.Add(-idempotencyPendingTimeout), now.Add(-idempotencyKeyTTL),
+		))
+		if err != nil {
+			return false, nil, err
+		}
+	}
+	return false, idempotencyKeyInProgress(key), nil
+}
+
+func isIdempotencyKeyExpired(record *models.WebhookIdempotencyKey, now time.Time) bool {
+	if record.Status == idempotencyPending {
+		return now.Sub(record.CreatedAt) > idempotencyPendingTimeout
+	}
+	return now.Sub(record.CreatedAt) > idempotencyKeyTTL
+}
+
+func idempotencyKeyInProgress(key string) *plugin.ApiResourceOutput {
+	return &plugin.ApiResourceOutput{
+		Body:   fmt.Sprintf("a request with Idempotency-Key %s is being processed", key),
+		Status: http.StatusConflict,
+	}
+}
+
+func replayIdempotentResponse(record *models.WebhookIdempotencyKey, requestHash string) (*plugin.ApiResourceOutput, errors.Error) {
+	if record.RequestHash != requestHash {
+		return &plugin.ApiResourceOutput{
+			Body:   fmt.Sprintf("Idempotency-Key %s has already been used with a different payload", record.IdempotencyKey),
+			Status: http.StatusUnprocessableEntity,
+		}, nil
+	}
+	if record.Status == idempotencyPending {
+		return idempotencyKeyInProgress(record.IdempotencyKey), nil
+	}
+	var body interface{}
+	if record.Response != "" {
+		if err := json.Unmarshal([]byte(record.Response), &body); err != nil {
+			return nil, errors.Convert(err)
+		}
+	}
+	return &plugin.ApiResourceOutput{
+		Body:   body,
+		Status: record.Status,
+		Header: http.Header{idempotentReplayHeader: []string{"true"}},
+	}, nil
+}
+
+func getIdempotencyKey(input *plugin.ApiResourceInput) string {
+	if input.Request == nil {
+		return ""
+	}
+	return strings.TrimSpace(input.Request.Header.Get(idempotencyKeyHeader))
+}
+
+func hashRequestBody(input *plugin.ApiResourceInput) (string, errors.Error) {
+	var body interface{} = input.Body
+	if input.BodyList != nil {
+		body = input.BodyList
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", errors.Convert(err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
@@ -0,0 +1,133 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/config"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/runner"
+	contextimpl "github.com/apache/incubator-devlake/impls/context"
+	"github.com/apache/incubator-devlake/impls/dalgorm"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupIdempotencyTest(t *testing.T) dal.Dal {
+	gormDb, err := runner.MakeDbConnection("sqlite://"+filepath.Join(t.TempDir(), "test.db"), &gorm.Config{})
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	db := dalgorm.NewDalgorm(gormDb)
+	if !assert.Nil(t, db.AutoMigrate(&models.WebhookIdempotencyKey{})) {
+		t.FailNow()
+	}
+	originBasicRes, originLogger := basicRes, logger
+	basicRes = contextimpl.NewDefaultBasicRes(config.GetConfig(), logruslog.Global, db)
+	logger = logruslog.Global
+	t.Cleanup(func() { basicRes, logger = originBasicRes, originLogger })
+	return db
+}
+
+func idempotentInput(key string, body map[string]interface{}) *plugin.ApiResourceInput {
+	request, _ := http.NewRequest(http.MethodPost, "/", nil)
+	request.Header.Set(idempotencyKeyHeader, key)
+	return &plugin.ApiResourceInput{Request: request, Body: body}
+}
+
+func TestWithIdempotencyKeyConcurrently(t *testing.T) {
+	setupIdempotencyTest(t)
+	connection := &models.WebhookConnection{}
+	connection.ID = 1
+	var runs int32
+	release := make(chan struct{})
+	handler := func(input *plugin.ApiResourceInput, connection *models.WebhookConnection) (*plugin.ApiResourceOutput, errors.Error) {
+		atomic.AddInt32(&runs, 1)
+		<-release
+		return &plugin.ApiResourceOutput{Body: map[string]interface{}{"saved": 1}, Status: http.StatusOK}, nil
+	}
+	body := map[string]interface{}{"id": "1"}
+
+	var wg sync.WaitGroup
+	var first *plugin.ApiResourceOutput
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var err errors.Error
+		first, err = withIdempotencyKey(idempotentInput("k1", body), connection, handler)
+		assert.Nil(t, err)
+	}()
+	// the key is reserved by the first delivery which is still running
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == 1 }, 5*time.Second, 10*time.Millisecond)
+	output, err := withIdempotencyKey(idempotentInput("k1", body), connection, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusConflict, output.Status)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, first.Status)
+
+	// retries are answered with the saved response
+	output, err = withIdempotencyKey(idempotentInput("k1", body), connection, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, output.Status)
+	assert.Equal(t, map[string]interface{}{"saved": float64(1)}, output.Body)
+	assert.Equal(t, "true", output.Header.Get(idempotentReplayHeader))
+	output, err = withIdempotencyKey(idempotentInput("k1", map[string]interface{}{"id": "2"}), connection, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, output.Status)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+}
+
+func TestWithIdempotencyKeyReleased(t *testing.T) {
+	db := setupIdempotencyTest(t)
+	connection := &models.WebhookConnection{}
+	connection.ID = 1
+	failing := func(input *plugin.ApiResourceInput, connection *models.WebhookConnection) (*plugin.ApiResourceOutput, errors.Error) {
+		return nil, errors.Default.New("failed to save")
+	}
+	succeeding := func(input *plugin.ApiResourceInput, connection *models.WebhookConnection) (*plugin.ApiResourceOutput, errors.Error) {
+		return &plugin.ApiResourceOutput{Body: "ok", Status: http.StatusOK}, nil
+	}
+	body := map[string]interface{}{"id": "1"}
+
+	// the key is released when the handler fails, so the delivery could be retried
+	_, err := withIdempotencyKey(idempotentInput("k1", body), connection, failing)
+	assert.NotNil(t, err)
+	output, err := withIdempotencyKey(idempotentInput("k1", body), connection, succeeding)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, output.Status)
+	assert.Empty(t, output.Header.Get(idempotentReplayHeader))
+
+	// the reservation of a request which never finished expires
+	assert.Nil(t, db.Create(&models.WebhookIdempotencyKey{ConnectionId: 1, IdempotencyKey: "k2", RequestHash: "x"}))
+	assert.Nil(t, db.UpdateColumn(&models.WebhookIdempotencyKey{}, "created_at", time.Now().Add(-idempotencyPendingTimeout-time.Minute), dal.Where("idempotency_key = ?", "k2")))
+	output, err = withIdempotencyKey(idempotentInput("k2", body), connection, succeeding)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, output.Status)
+	assert.Empty(t, output.Header.Get(idempotentReplayHeader))
+}
@@ -116,9 +116,16 @@ func postIssue(input *plugin.ApiResourceInput, err errors.Error, connection *mod
 	if err != nil {
 		return nil, err
 	}
+	return withIdempotencyKey(input, connection, saveIssues)
+}
+
+func saveIssues(input *plugin.ApiResourceInput, connection *models.WebhookConnection) (*plugin.ApiResourceOutput, errors.Error) {
+	if input.BodyList != nil {
+		return saveBatch(input.BodyList, connection, saveIssueRecord), nil
+	}
 	// get request
 	request := &WebhookIssueRequest{}
-	err = helper.DecodeMapStruct(input.Body, request, true)
+	err := helper.DecodeMapStruct(input.Body, request, true)
 	if err != nil {
 		return &plugin.ApiResourceOutput{Body: err.Error(), Status: http.StatusBadRequest}, nil
 	}
@@ -131,6 +138,26 @@ func postIssue(input *plugin.ApiResourceInput, err errors.Error, connection *mod
 	txHelper := dbhelper.NewTxHelper(basicRes, &err)
 	defer txHelper.End()
 	tx := txHelper.Begin()
+	err = CreateIssue(connection, request, tx, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
+}
+
+func saveIssueRecord(connection *models.WebhookConnection, record map[string]interface{}, tx dal.Transaction) (string, errors.Error) {
+	request := &WebhookIssueRequest{}
+	if err := helper.DecodeMapStruct(record, request, true); err != nil {
+		return "", errors.BadInput.Wrap(err, `input json error`)
+	}
+	if err := vld.Struct(request); err != nil {
+		return request.IssueKey, errors.BadInput.Wrap(err, `input json error`)
+	}
+	return request.IssueKey, CreateIssue(connection, request, tx, logger)
+}
+
+func CreateIssue(connection *models.WebhookConnection, request *WebhookIssueRequest, tx dal.Transaction, logger log.Logger) errors.Error {
 	domainIssue := &ticket.Issue{
 		DomainEntity: domainlayer.DomainEntity{
 			Id: fmt.Sprintf("%s:%d:%s", "webhook", connection.ID, request.IssueKey),
@@ -184,7 +211,7 @@ func postIssue(input *plugin.ApiResourceInput, err errors.Error, connection *mod
 	// check if board exists
 	count, err := tx.Count(dal.From(&ticket.Board{}), dal.Where("id = ?", domainBoardId))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// only create board with domainBoard non-existent
@@ -196,28 +223,27 @@ func postIssue(input *plugin.ApiResourceInput, err errors.Error, connection *mod
 		}
 		err = tx.Create(domainBoard)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	// save
 	err = tx.CreateOrUpdate(domainIssue)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	err = tx.CreateOrUpdate(boardIssue)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if domainIssue.IsIncident() {
 		if err := saveIncidentRelatedRecordsFromIssue(tx, logger, domainBoardId, domainIssue); err != nil {
 			logger.Error(err, "failed to save incident related records")
-			return nil, errors.Convert(err)
+			return errors.Convert(err)
 		}
 	}
-
-	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
+	return nil
 }
 
 // CloseIssue
@@ -108,9 +108,16 @@ func postPullRequests(input *plugin.ApiResourceInput, connection *models.Webhook
 	if err != nil {
 		return nil, err
 	}
+	return withIdempotencyKey(input, connection, savePullRequests)
+}
+
+func savePullRequests(input *plugin.ApiResourceInput, connection *models.WebhookConnection) (*plugin.ApiResourceOutput, errors.Error) {
+	if input.BodyList != nil {
+		return saveBatch(input.BodyList, connection, savePullRequestRecord), nil
+	}
 	// get request
 	request := &WebhookPullRequestReq{}
-	err = api.DecodeMapStruct(input.Body, request, true)
+	err := api.DecodeMapStruct(input.Body, request, true)
 	if err != nil {
 		return &plugin.ApiResourceOutput{Body: err.Error(), Status: http.StatusBadRequest}, nil
 	}
@@ -131,6 +138,17 @@ func postPullRequests(input *plugin.ApiResourceInput, connection *models.Webhook
 	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
 }
 
+func savePullRequestRecord(connection *models.WebhookConnection, record map[string]interface{}, tx dal.Transaction) (string, errors.Error) {
+	request := &WebhookPullRequestReq{}
+	if err := api.DecodeMapStruct(record, request, true); err != nil {
+		return "", errors.BadInput.Wrap(err, `input json error`)
+	}
+	if err := vld.Struct(request); err != nil {
+		return request.Id, errors.BadInput.Wrap(err, `input json error`)
+	}
+	return request.Id, CreatePullRequest(connection, request, tx, logger)
+}
+
 func CreatePullRequest(connection *models.WebhookConnection, request *WebhookPullRequestReq, tx dal.Transaction, logger log.Logger) errors.Error {
 	// validation
 	if request == nil {
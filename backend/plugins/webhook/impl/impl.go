@@ -57,6 +57,7 @@ func (p Webhook) Init(basicRes context.BasicRes) errors.Error {
 func (p Webhook) GetTablesInfo() []dal.Tabler {
 	return []dal.Tabler{
 		&models.WebhookConnection{},
+		&models.WebhookIdempotencyKey{},
 	}
 }
 
@@ -96,6 +97,9 @@ func (p Webhook) ApiResources() map[string]map[string]plugin.ApiResourceHandler
 		"connections/:connectionId/issues": {
 			"POST": api.PostIssue,
 		},
+		"connections/:connectionId/events": {
+			"POST": api.PostEvents,
+		},
 		"connections/:connectionId/issue/:issueKey/close": {
 			"POST": api.CloseIssue,
 		},
@@ -108,6 +112,9 @@ func (p Webhook) ApiResources() map[string]map[string]plugin.ApiResourceHandler
 		":connectionId/issues": {
 			"POST": api.PostIssue,
 		},
+		":connectionId/events": {
+			"POST": api.PostEvents,
+		},
 		":connectionId/issue/:issueKey/close": {
 			"POST": api.CloseIssue,
 		},
@@ -125,6 +132,9 @@ func (p Webhook) ApiResources() map[string]map[string]plugin.ApiResourceHandler
 		"connections/by-name/:connectionName/issues": {
 			"POST": api.PostIssueByName,
 		},
+		"connections/by-name/:connectionName/events": {
+			"POST": api.PostEventsByName,
+		},
 		"connections/by-name/:connectionName/issue/:issueKey/close": {
 			"POST": api.CloseIssueByName,
 		},
@@ -107,6 +107,8 @@ func CreateApiServer() *gin.Engine {
 	// Api keys
 	router.Use(RestAuthentication(router, basicRes))
 	router.Use(OAuth2ProxyAuthentication(basicRes))
+	router.Use(RoleBasedAuthorization(basicRes))
+	router.Use(ApiKeyProjectAuthorization(basicRes))
 
 	return router
 }
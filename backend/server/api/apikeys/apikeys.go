@@ -140,3 +140,40 @@ func PostApiKey(c *gin.Context) {
 
 	shared.ApiOutputSuccess(c, apiKeyOutput, http.StatusCreated)
 }
+
+// @Summary Rotate an api key
+// @Description Replace an api key with a new one, the replaced key keeps working for the grace period
+// @Tags framework/api-keys
+// @Accept application/json
+// @Param apiKeyId path int true "api key id"
+// @Param rotation body models.ApiInputRotateApiKey false "json"
+// @Success 200  {object} models.ApiOutputApiKey
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /api-keys/{apiKeyId}/rotate [post]
+func PostRotateApiKey(c *gin.Context) {
+	apiKeyId := c.Param("apiKeyId")
+	id, err := strconv.ParseUint(apiKeyId, 10, 64)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad apiKeyId format supplied"))
+		return
+	}
+	input := &models.ApiInputRotateApiKey{}
+	if c.Request.ContentLength != 0 {
+		err = c.ShouldBindJSON(input)
+		if err != nil {
+			shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+			return
+		}
+	}
+	user, exist := shared.GetUser(c)
+	if !exist {
+		logruslog.Global.Warn(nil, "user doesn't exist")
+	}
+	apiOutputApiKey, err := services.RotateApiKey(user, id, input)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error rotating api key"))
+		return
+	}
+	shared.ApiOutputSuccess(c, apiOutputApiKey, http.StatusOK)
+}
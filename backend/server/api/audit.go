@@ -0,0 +1,233 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// responses larger than this are recorded without the body
+const maxAuditBodySize = 1 << 20
+
+var auditLogger = logruslog.Global.Nested("audit")
+
+// auditSnapshotFunc loads the current state of the target resource
+type auditSnapshotFunc func(c *gin.Context) (interface{}, errors.Error)
+
+// auditRule describes how requests to a route are recorded into the audit log
+type auditRule struct {
+	pluginName   string
+	resourceType string
+	action       string
+	idParam      string
+	snapshot     auditSnapshotFunc
+}
+
+// auditResponseWriter keeps a copy of the response body, which is the state after the change
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	if w.body.Len()+len(data) <= maxAuditBodySize {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	if w.body.Len()+len(s) <= maxAuditBodySize {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// audit returns a handler recording the route into the audit log once the request succeeded
+func audit(resourceType, action, idParam string, snapshot auditSnapshotFunc) gin.HandlerFunc {
+	return auditRule{resourceType: resourceType, action: action, idParam: idParam, snapshot: snapshot}.handler()
+}
+
+func (rule auditRule) handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var before interface{}
+		if rule.snapshot != nil && rule.action != models.AUDIT_ACTION_CREATE {
+			var err errors.Error
+			before, err = rule.snapshot(c)
+			if err != nil {
+				// the handler would most likely fail as well
+				auditLogger.Debug("failed to load %s before %s: %s", rule.resourceType, rule.action, err.Error())
+			}
+		}
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		// dry runs change nothing
+		if writer.Status() >= http.StatusBadRequest || c.Query("dryRun") == "true" {
+			return
+		}
+		var after interface{}
+		if rule.action != models.AUDIT_ACTION_DELETE {
+			after = decodeAuditBody(writer.body.Bytes())
+		}
+		auditLog := &models.AuditLog{
+			Action:       rule.action,
+			ResourceType: rule.resourceType,
+			ResourceId:   getAuditResourceId(c, rule.idParam, after),
+			PluginName:   rule.pluginName,
+			Method:       c.Request.Method,
+			Path:         c.Request.URL.Path,
+		}
+		if user, exist := shared.GetUser(c); exist {
+			auditLog.Actor = user.Name
+			auditLog.ActorEmail = user.Email
+		}
+		if apiKey, exist := shared.GetApiKey(c); exist {
+			auditLog.ApiKeyId = apiKey.ID
+			auditLog.ApiKeyName = apiKey.Name
+		}
+		err := services.RecordAuditLog(auditLog, before, after)
+		if err != nil {
+			auditLogger.Error(err, "failed to record audit log for %s %s", c.Request.Method, c.Request.URL.Path)
+		}
+	}
+}
+
+func decodeAuditBody(body []byte) interface{} {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil
+	}
+	var decoded interface{}
+	if json.Unmarshal(body, &decoded) != nil {
+		return nil
+	}
+	return decoded
+}
+
+// getAuditResourceId takes the id from the route or the created resource
+func getAuditResourceId(c *gin.Context, idParam string, after interface{}) string {
+	if idParam != "" {
+		return strings.TrimPrefix(c.Param(idParam), "/")
+	}
+	if resource, ok := after.(map[string]interface{}); ok {
+		for _, key := range []string{"id", "name"} {
+			switch value := resource[key].(type) {
+			case float64:
+				return strconv.FormatUint(uint64(value), 10)
+			case string:
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+func blueprintSnapshot(c *gin.Context) (interface{}, errors.Error) {
+	blueprintId, err := uintParam(c, "blueprintId")
+	if err != nil {
+		return nil, err
+	}
+	return services.GetBlueprint(blueprintId, true)
+}
+
+func projectSnapshot(c *gin.Context) (interface{}, errors.Error) {
+	return services.GetProject(c.Param("projectName"))
+}
+
+func apiKeySnapshot(c *gin.Context) (interface{}, errors.Error) {
+	apiKeyId, err := uintParam(c, "apiKeyId")
+	if err != nil {
+		return nil, err
+	}
+	return services.GetApiKey(apiKeyId)
+}
+
+// getPluginAuditRule returns the audit rule of plugin routes managing connections, scopes and scope configs
+func getPluginAuditRule(pluginName, resourcePath, method string, resourceHandlers map[string]plugin.ApiResourceHandler) *auditRule {
+	if method == http.MethodGet {
+		return nil
+	}
+	segments := strings.Split(strings.Trim(resourcePath, "/"), "/")
+	if segments[0] != "connections" || len(segments) > 4 || len(segments) == 3 && segments[2] != "scopes" && segments[2] != "scope-configs" {
+		return nil
+	}
+	rule := &auditRule{pluginName: pluginName, resourceType: models.AUDIT_RESOURCE_CONNECTION}
+	if len(segments) > 2 {
+		switch segments[2] {
+		case "scopes":
+			rule.resourceType = models.AUDIT_RESOURCE_SCOPE
+		case "scope-configs":
+			rule.resourceType = models.AUDIT_RESOURCE_SCOPE_CONFIG
+		default:
+			return nil
+		}
+	}
+	last := segments[len(segments)-1]
+	if strings.HasPrefix(last, ":") || strings.HasPrefix(last, "*") {
+		rule.idParam = last[1:]
+	}
+	switch method {
+	case http.MethodPost:
+		rule.action = models.AUDIT_ACTION_CREATE
+	case http.MethodDelete:
+		rule.action = models.AUDIT_ACTION_DELETE
+	default:
+		rule.action = models.AUDIT_ACTION_UPDATE
+	}
+	if getHandler := resourceHandlers[http.MethodGet]; getHandler != nil && rule.idParam != "" {
+		rule.snapshot = pluginSnapshot(pluginName, getHandler)
+	}
+	return rule
+}
+
+// pluginSnapshot loads the resource with the GET handler of the same route, which sanitizes secrets already
+func pluginSnapshot(pluginName string, getHandler plugin.ApiResourceHandler) auditSnapshotFunc {
+	return func(c *gin.Context) (interface{}, errors.Error) {
+		input := &plugin.ApiResourceInput{
+			Params:  map[string]string{"plugin": pluginName},
+			Query:   url.Values{},
+			Request: c.Request,
+		}
+		for _, param := range c.Params {
+			input.Params[param.Key] = param.Value
+		}
+		output, err := getHandler(input)
+		if err != nil {
+			return nil, err
+		}
+		if output == nil {
+			return nil, errors.Default.New(fmt.Sprintf("empty response from GET %s", c.FullPath()))
+		}
+		return output.Body, nil
+	}
+}
@@ -0,0 +1,326 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+)
+
+// access scopes of routes, decide how the project of the target resource is resolved
+const (
+	accessPublic        = "public"        // authenticated by the handler itself, e.g. webhooks with signatures
+	accessAuthenticated = "authenticated" // any known user, list endpoints filter the result by readable projects
+	accessMaintainer    = "maintainer"    // admins or maintainers of any project
+	accessAdmin         = "admin"
+	accessProject       = "project"
+	accessBlueprint     = "blueprint"
+	accessPipeline      = "pipeline"
+	accessTask          = "task"
+	accessConnection    = "connection"
+	accessNewBlueprint  = "new-blueprint" // project is taken from the request body
+)
+
+type routeAccess struct {
+	scope string
+	write bool
+}
+
+// routes verifying requests on their own
+var publicRoutes = map[string]bool{
+	"POST /plugins/github/connections/:connectionId/webhook": true,
+	"POST /plugins/gitlab/connections/:connectionId/webhook": true,
+}
+
+// getRouteAccess returns how the route should be authorized, routes unknown to the table below are readable by
+// any authenticated user and writable by admins only
+func getRouteAccess(method, fullPath string) routeAccess {
+	write := method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+	if publicRoutes[method+" "+fullPath] {
+		return routeAccess{accessPublic, write}
+	}
+	switch {
+	case strings.HasPrefix(fullPath, "/api-keys"),
+		strings.HasPrefix(fullPath, "/rbac/roles"),
+		strings.HasPrefix(fullPath, "/audit-logs"),
+		strings.HasPrefix(fullPath, "/config/"),
+		strings.HasPrefix(fullPath, "/backup"),
+		fullPath == "/proceed-db-migration":
+		return routeAccess{accessAdmin, write}
+	case fullPath == "/rbac/me", fullPath == "/store/:storeKey":
+		return routeAccess{accessAuthenticated, write}
+	case fullPath == "/projects", fullPath == "/blueprints", fullPath == "/pipelines":
+		if !write {
+			return routeAccess{accessAuthenticated, write}
+		}
+		if fullPath == "/blueprints" {
+			return routeAccess{accessNewBlueprint, write}
+		}
+		return routeAccess{accessAdmin, write}
+	case strings.HasPrefix(fullPath, "/projects/:projectName"):
+		return routeAccess{accessProject, write}
+	case strings.HasPrefix(fullPath, "/blueprints/:blueprintId"):
+		return routeAccess{accessBlueprint, write}
+	case strings.HasPrefix(fullPath, "/pipelines/:pipelineId"):
+		return routeAccess{accessPipeline, write}
+	case strings.HasPrefix(fullPath, "/tasks/:taskId"):
+		return routeAccess{accessTask, write}
+	case strings.HasPrefix(fullPath, "/plugins/") && strings.Contains(fullPath, "/connections/:connectionId"):
+		return routeAccess{accessConnection, write}
+	case strings.HasPrefix(fullPath, "/plugins/") && write &&
+		(strings.HasSuffix(fullPath, "/connections") || strings.HasSuffix(fullPath, "/test")):
+		return routeAccess{accessMaintainer, write}
+	}
+	if write {
+		return routeAccess{accessAdmin, write}
+	}
+	return routeAccess{accessAuthenticated, write}
+}
+
+// RoleBasedAuthorization enforces roles and project grants on all routes registered after it when RBAC_ENABLED
+func RoleBasedAuthorization(basicRes context.BasicRes) gin.HandlerFunc {
+	logger := basicRes.GetLogger()
+	return func(c *gin.Context) {
+		if !services.RbacEnabled() || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+		access := getRouteAccess(c.Request.Method, c.FullPath())
+		if access.scope == accessPublic {
+			c.Next()
+			return
+		}
+		user, exist := shared.GetUser(c)
+		if !exist || user.Name == "" {
+			shared.ApiOutputError(c, errors.Unauthorized.New("authentication is required"))
+			c.Abort()
+			return
+		}
+		principal, err := services.GetPrincipal(user)
+		if err != nil {
+			shared.ApiOutputError(c, err)
+			c.Abort()
+			return
+		}
+		c.Set(common.PRINCIPAL, principal)
+		allowed, err := authorize(c, principal, access)
+		if err != nil {
+			shared.ApiOutputError(c, err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			logger.Info("user %s is not allowed to %s %s", user.Name, c.Request.Method, c.Request.URL.Path)
+			shared.ApiOutputError(c, errors.Forbidden.New(fmt.Sprintf("permission denied: %s %s", c.Request.Method, c.FullPath())))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ApiKeyProjectAuthorization limits requests authenticated by project scoped api keys to the resources of the project,
+// it is enforced whether RBAC_ENABLED or not
+func ApiKeyProjectAuthorization(basicRes context.BasicRes) gin.HandlerFunc {
+	logger := basicRes.GetLogger()
+	return func(c *gin.Context) {
+		apiKey, ok := shared.GetApiKey(c)
+		if !ok || apiKey.ProjectName == "" || c.FullPath() == "" {
+			c.Next()
+			return
+		}
+		allowed, err := authorizeApiKeyProject(c, apiKey.ProjectName, getRouteAccess(c.Request.Method, c.FullPath()))
+		if err != nil {
+			shared.ApiOutputError(c, err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			logger.Info("api key %s is not allowed to %s %s", apiKey.Name, c.Request.Method, c.Request.URL.Path)
+			shared.ApiOutputError(c, errors.Forbidden.New(fmt.Sprintf("api key is limited to project %s: %s %s", apiKey.ProjectName, c.Request.Method, c.FullPath())))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// authorizeApiKeyProject allows routes of the project and of the connections used by the project only
+func authorizeApiKeyProject(c *gin.Context, projectName string, access routeAccess) (bool, errors.Error) {
+	switch access.scope {
+	case accessPublic:
+		return true, nil
+	case accessAuthenticated, accessMaintainer, accessAdmin:
+		return false, nil
+	case accessConnection:
+		connectionId, err := uintParam(c, "connectionId")
+		if err != nil {
+			return false, err
+		}
+		connectionProjects, err := services.GetConnectionProjects(pluginNameOfRoute(c.FullPath()))
+		if err != nil {
+			return false, err
+		}
+		return slices.Contains(connectionProjects[connectionId], projectName), nil
+	}
+	resolved, err := resolveProjectName(c, access.scope)
+	if err != nil {
+		return false, err
+	}
+	return resolved == projectName, nil
+}
+
+func authorize(c *gin.Context, principal *models.Principal, access routeAccess) (bool, errors.Error) {
+	if principal.IsAdmin() {
+		return true, nil
+	}
+	switch access.scope {
+	case accessAuthenticated:
+		return true, nil
+	case accessMaintainer:
+		return principal.IsMaintainer(), nil
+	case accessAdmin:
+		return false, nil
+	case accessConnection:
+		connectionId, err := uintParam(c, "connectionId")
+		if err != nil {
+			return false, err
+		}
+		connectionProjects, err := services.GetConnectionProjects(pluginNameOfRoute(c.FullPath()))
+		if err != nil {
+			return false, err
+		}
+		return services.CanAccessConnection(principal, connectionProjects[connectionId], access.write), nil
+	}
+	projectName, err := resolveProjectName(c, access.scope)
+	if err != nil {
+		return false, err
+	}
+	if access.write {
+		return projectName != "" && principal.CanWrite(projectName), nil
+	}
+	return projectName != "" && principal.CanRead(projectName) || principal.CanReadAll(), nil
+}
+
+// resolveProjectName returns the project owning the target resource of the request
+func resolveProjectName(c *gin.Context, scope string) (string, errors.Error) {
+	switch scope {
+	case accessProject:
+		return c.Param("projectName"), nil
+	case accessBlueprint:
+		blueprintId, err := uintParam(c, "blueprintId")
+		if err != nil {
+			return "", err
+		}
+		return services.GetProjectNameOfBlueprint(blueprintId)
+	case accessPipeline:
+		pipelineId, err := uintParam(c, "pipelineId")
+		if err != nil {
+			return "", err
+		}
+		return services.GetProjectNameOfPipeline(pipelineId)
+	case accessTask:
+		taskId, err := uintParam(c, "taskId")
+		if err != nil {
+			return "", err
+		}
+		return services.GetProjectNameOfTask(taskId)
+	case accessNewBlueprint:
+		data, err := c.GetRawData()
+		if err != nil {
+			return "", errors.BadInput.Wrap(err, shared.BadRequestBody)
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(data))
+		body := &struct {
+			ProjectName string `json:"projectName"`
+		}{}
+		if len(bytes.TrimSpace(data)) > 0 {
+			if err := json.Unmarshal(data, body); err != nil {
+				return "", errors.BadInput.Wrap(err, shared.BadRequestBody)
+			}
+		}
+		return body.ProjectName, nil
+	}
+	return "", errors.Internal.New(fmt.Sprintf("unknown access scope %s", scope))
+}
+
+// pluginNameOfRoute extracts the plugin name from routes like /plugins/:name/...
+func pluginNameOfRoute(fullPath string) string {
+	parts := strings.SplitN(strings.TrimPrefix(fullPath, "/plugins/"), "/", 2)
+	return parts[0]
+}
+
+func uintParam(c *gin.Context, name string) (uint64, errors.Error) {
+	value, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, errors.BadInput.Wrap(err, fmt.Sprintf("invalid %s", name))
+	}
+	return value, nil
+}
+
+// filterReadableConnections removes connections the principal has no access to from the list returned by plugins
+func filterReadableConnections(principal *models.Principal, connectionProjects map[uint64][]string, body interface{}) interface{} {
+	list := reflect.ValueOf(body)
+	if list.Kind() != reflect.Slice {
+		return body
+	}
+	filtered := reflect.MakeSlice(list.Type(), 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i)
+		connectionId, ok := getConnectionId(item)
+		if !ok || services.CanAccessConnection(principal, connectionProjects[connectionId], false) {
+			filtered = reflect.Append(filtered, item)
+		}
+	}
+	return filtered.Interface()
+}
+
+func getConnectionId(item reflect.Value) (uint64, bool) {
+	if connection, ok := item.Interface().(interface{ ConnectionId() uint64 }); ok {
+		return connection.ConnectionId(), true
+	}
+	for item.Kind() == reflect.Ptr || item.Kind() == reflect.Interface {
+		if item.IsNil() {
+			return 0, false
+		}
+		item = item.Elem()
+	}
+	if item.Kind() != reflect.Struct {
+		return 0, false
+	}
+	id := item.FieldByName("ID")
+	if !id.IsValid() || id.Kind() != reflect.Uint64 {
+		return 0, false
+	}
+	return id.Uint(), true
+}
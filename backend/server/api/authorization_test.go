@@ -0,0 +1,117 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRouteAccess(t *testing.T) {
+	tests := []struct {
+		method   string
+		fullPath string
+		want     routeAccess
+	}{
+		{"GET", "/projects", routeAccess{accessAuthenticated, false}},
+		{"POST", "/projects", routeAccess{accessAdmin, true}},
+		{"PATCH", "/projects/:projectName", routeAccess{accessProject, true}},
+		{"GET", "/projects/:projectName/grants", routeAccess{accessProject, false}},
+		{"DELETE", "/projects/:projectName/data", routeAccess{accessProject, true}},
+		{"DELETE", "/scopes/:plugin/:connectionId/:scopeId/data", routeAccess{accessAdmin, true}},
+		{"POST", "/blueprints", routeAccess{accessNewBlueprint, true}},
+		{"POST", "/blueprints/:blueprintId/trigger", routeAccess{accessBlueprint, true}},
+		{"GET", "/pipelines/:pipelineId/tasks", routeAccess{accessPipeline, false}},
+		{"POST", "/tasks/:taskId/rerun", routeAccess{accessTask, true}},
+		{"GET", "/api-keys", routeAccess{accessAdmin, false}},
+		{"POST", "/api-keys/:apiKeyId/rotate", routeAccess{accessAdmin, true}},
+		{"PUT", "/rbac/roles", routeAccess{accessAdmin, true}},
+		{"GET", "/audit-logs", routeAccess{accessAdmin, false}},
+		{"GET", "/domainlayer/tables/:table", routeAccess{accessAuthenticated, false}},
+		{"GET", "/config/export", routeAccess{accessAdmin, false}},
+		{"POST", "/config/apply", routeAccess{accessAdmin, true}},
+		{"GET", "/backup", routeAccess{accessAdmin, false}},
+		{"POST", "/backup/restore", routeAccess{accessAdmin, true}},
+		{"GET", "/rbac/me", routeAccess{accessAuthenticated, false}},
+		{"GET", "/plugins/github/connections", routeAccess{accessAuthenticated, false}},
+		{"POST", "/plugins/github/connections", routeAccess{accessMaintainer, true}},
+		{"POST", "/plugins/github/test", routeAccess{accessMaintainer, true}},
+		{"PATCH", "/plugins/github/connections/:connectionId", routeAccess{accessConnection, true}},
+		{"GET", "/plugins/github/connections/:connectionId/scopes", routeAccess{accessConnection, false}},
+		{"POST", "/plugins/github/connections/:connectionId/webhook", routeAccess{accessPublic, true}},
+		{"POST", "/push/:tableName", routeAccess{accessAdmin, true}},
+		{"GET", "/plugins", routeAccess{accessAuthenticated, false}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, getRouteAccess(tt.method, tt.fullPath), tt.method+" "+tt.fullPath)
+	}
+}
+
+type testConnection struct {
+	common.Model
+	Name string
+}
+
+func TestFilterReadableConnections(t *testing.T) {
+	connections := []*testConnection{
+		{Model: common.Model{ID: 1}, Name: "team-a"},
+		{Model: common.Model{ID: 2}, Name: "team-b"},
+		{Model: common.Model{ID: 3}, Name: "unused"},
+	}
+	connectionProjects := map[uint64][]string{
+		1: {"team-a"},
+		2: {"team-b"},
+	}
+
+	viewer := &models.Principal{ProjectRoles: map[string]string{"team-a": models.ROLE_VIEWER}}
+	filtered := filterReadableConnections(viewer, connectionProjects, connections).([]*testConnection)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "team-a", filtered[0].Name)
+
+	maintainer := &models.Principal{ProjectRoles: map[string]string{"team-b": models.ROLE_MAINTAINER}}
+	filtered = filterReadableConnections(maintainer, connectionProjects, connections).([]*testConnection)
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, "team-b", filtered[0].Name)
+	assert.Equal(t, "unused", filtered[1].Name)
+}
+
+func TestAuthorizeApiKeyProject(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Params = gin.Params{{Key: "projectName", Value: "team-a"}}
+
+	allowed, err := authorizeApiKeyProject(c, "team-a", routeAccess{accessProject, true})
+	assert.Nil(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = authorizeApiKeyProject(c, "team-b", routeAccess{accessProject, false})
+	assert.Nil(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = authorizeApiKeyProject(c, "team-a", routeAccess{accessAuthenticated, false})
+	assert.Nil(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = authorizeApiKeyProject(c, "team-a", routeAccess{accessPublic, true})
+	assert.Nil(t, err)
+	assert.True(t, allowed)
+}
@@ -0,0 +1,90 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Back up the instance
+// @Description Download a zip archive of the configurations, connections, scopes and scope configs, along with the migration history, optionally with the domain layer data
+// @Tags framework/backup
+// @Produce application/zip
+// @Param domain query bool false "include the domain layer data"
+// @Success 200  "The archive file"
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /backup [get]
+func GetBackup(c *gin.Context) {
+	var query services.BackupQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	manifest, err := services.NewBackupManifest(query.Domain)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error preparing backup"))
+		return
+	}
+	fileName := fmt.Sprintf("devlake-backup-%s.zip", manifest.CreatedAt.UTC().Format("20060102150405"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+	err = services.WriteBackup(c.Writer, manifest)
+	if err != nil {
+		// the archive is partially sent already, all we can do is to abort the download
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// @Summary Restore the instance
+// @Description Restore a backup archive created by the same version of DevLake, archives of older versions have to be restored by the `lake restore` command
+// @Tags framework/backup
+// @Accept multipart/form-data
+// @Param file formData file true "the backup archive"
+// @Success 200  {object} services.RestoreResult
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /backup/restore [post]
+func PostRestore(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "the backup archive is required"))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "failed to read the backup archive"))
+		return
+	}
+	defer file.Close()
+	result, err := services.RestoreBackup(file, fileHeader.Size)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error restoring backup"))
+		return
+	}
+	shared.ApiOutputSuccess(c, result, http.StatusOK)
+}
@@ -50,7 +50,8 @@ func Post(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
-	err = services.CreateBlueprint(blueprint)
+	principal, _ := shared.GetPrincipal(c)
+	err = services.CreateBlueprint(principal, blueprint)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error creating blueprint"))
 		return
@@ -77,6 +78,7 @@ func Index(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
+	query.ProjectNames = shared.GetReadableProjects(c)
 	blueprints, count, err := services.GetBlueprints(&query, true)
 	if err != nil {
 		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting blueprints"))
@@ -152,7 +154,8 @@ func Patch(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
-	blueprint, err := services.PatchBlueprint(id, body)
+	principal, _ := shared.GetPrincipal(c)
+	blueprint, err := services.PatchBlueprint(principal, id, body)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error patching the blueprint"))
 		return
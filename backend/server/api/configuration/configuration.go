@@ -0,0 +1,99 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configuration
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Export configurations
+// @Description Export connections, scope configs, scopes, projects and blueprints as a document, secrets of connections are replaced with references to environment variables prefixed with DEVLAKE_CONFIG_
+// @Tags framework/config
+// @Param format query string false "json (default) or yaml"
+// @Success 200  {object} services.ConfigDocument
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /config/export [get]
+func GetExport(c *gin.Context) {
+	var query services.ConfigExportQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	if query.Format != "" && query.Format != "json" && query.Format != "yaml" {
+		shared.ApiOutputError(c, errors.BadInput.New("format must be json or yaml"))
+		return
+	}
+	doc, err := services.ExportConfig()
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error exporting configurations"))
+		return
+	}
+	if query.Format == "yaml" {
+		blob, err := services.MarshalConfigDocumentYaml(doc)
+		if err != nil {
+			shared.ApiOutputError(c, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/yaml; charset=utf-8", blob)
+		return
+	}
+	shared.ApiOutputSuccess(c, doc, http.StatusOK)
+}
+
+// @Summary Apply configurations
+// @Description Reconcile connections, scope configs, scopes, projects and blueprints with the document in YAML or JSON by names, references in secret fields are resolved from environment variables prefixed with DEVLAKE_CONFIG_
+// @Tags framework/config
+// @Accept application/json
+// @Param dryRun query bool false "report the changes without applying them"
+// @Param document body services.ConfigDocument true "json or yaml"
+// @Success 200  {object} services.ConfigApplyResult
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /config/apply [post]
+func PostApply(c *gin.Context) {
+	var query services.ConfigApplyQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	doc, err := services.ParseConfigDocument(body)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	result, err := services.ApplyConfig(doc, query.DryRun)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error applying configurations"))
+		return
+	}
+	shared.ApiOutputSuccess(c, result, http.StatusOK)
+}
@@ -0,0 +1,77 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package domainlayer
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get domain layer tables
+// @Description Get all domain layer tables which can be queried along with their columns
+// @Tags framework/domainlayer
+// @Success 200  {array} services.DomainTable
+// @Router /domainlayer/tables [get]
+func TablesIndex(c *gin.Context) {
+	shared.ApiOutputSuccess(c, services.GetDomainTables(), http.StatusOK)
+}
+
+// @Summary Query a domain layer table
+// @Description Read rows of a domain layer table, rows are limited to the projects readable by the current user
+// @Tags framework/domainlayer
+// @Param table path string true "domain table name, i.e. pull_requests"
+// @Param fields query string false "comma separated columns"
+// @Param filter query []string false "column:op:value, op is one of eq, ne, gt, gte, lt, lte, like, in, null and notnull" collectionFormat(multi)
+// @Param sort query string false "comma separated columns, prefixed with - for the descending order"
+// @Param project query string false "project name"
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param pageSize query int false "rows per page"
+// @Success 200  {object} services.DomainQueryResult
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 403  {string} errcode.Error "Forbidden"
+// @Failure 404  {string} errcode.Error "Not Found"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /domainlayer/tables/{table} [get]
+func TableRows(c *gin.Context) {
+	var query services.DomainQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	query.ReadableProjects = shared.GetReadableProjects(c)
+	result, err := services.QueryDomainTable(c.Param("table"), &query)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	shared.ApiOutputSuccess(c, result, http.StatusOK)
+}
+
+// @Summary Get the OpenAPI description of the domain layer query api
+// @Description The description is generated from the domain layer models
+// @Tags framework/domainlayer
+// @Success 200  {object} map[string]interface{}
+// @Router /domainlayer/openapi.json [get]
+func OpenApiSpec(c *gin.Context) {
+	shared.ApiOutputSuccess(c, services.GetDomainOpenApiSpec(), http.StatusOK)
+}
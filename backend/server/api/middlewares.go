@@ -21,8 +21,10 @@ import (
 	"encoding/base64"
 	"fmt"
 	"github.com/apache/incubator-devlake/core/log"
+	"math"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +33,7 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models/common"
 	"github.com/apache/incubator-devlake/helpers/apikeyhelper"
+	"github.com/apache/incubator-devlake/server/api/shared"
 	"github.com/gin-gonic/gin"
 )
 
@@ -76,7 +79,14 @@ func OAuth2ProxyAuthentication(basicRes context.BasicRes) gin.HandlerFunc {
 	logger := basicRes.GetLogger()
 	return func(c *gin.Context) {
 		_, exist := c.Get(common.USER)
-		if !exist {
+		if apiKey, ok := shared.GetApiKey(c); ok && !exist {
+			// the request was authenticated by RestAuthentication and then rerouted
+			c.Set(common.API_KEY, apiKey)
+			c.Set(common.USER, &common.User{
+				Name:  apiKey.Creator.Creator,
+				Email: apiKey.Creator.CreatorEmail,
+			})
+		} else if !exist {
 			user, err := getOAuthUserInfo(c)
 			if err != nil {
 				logger.Error(err, "getOAuthUserInfo")
@@ -96,6 +106,10 @@ func OAuth2ProxyAuthentication(basicRes context.BasicRes) gin.HandlerFunc {
 	}
 }
 
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 type apiBody struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
@@ -109,6 +123,9 @@ func RestAuthentication(router *gin.Engine, basicRes context.BasicRes) gin.Handl
 		panic(fmt.Errorf("db is not initialised"))
 	}
 	apiKeyHelper := apikeyhelper.NewApiKeyHelper(basicRes, logger)
+	rateLimiter := apikeyhelper.NewRateLimiter()
+	usageRecorder := apikeyhelper.NewUsageRecorder(apiKeyHelper, logger)
+	go usageRecorder.Run(apikeyhelper.UsageFlushInterval)
 	return func(c *gin.Context) {
 		path := c.Request.URL.Path
 		// Only open api needs to check api key
@@ -119,7 +136,7 @@ func RestAuthentication(router *gin.Engine, basicRes context.BasicRes) gin.Handl
 		}
 		path = strings.TrimPrefix(path, "/rest")
 		authHeader := c.GetHeader("Authorization")
-		ok := CheckAuthorizationHeader(c, logger, db, apiKeyHelper, authHeader, path)
+		ok := CheckAuthorizationHeader(c, logger, db, apiKeyHelper, rateLimiter, usageRecorder, authHeader, path)
 		if !ok {
 			c.Abort()
 			return
@@ -131,7 +148,7 @@ func RestAuthentication(router *gin.Engine, basicRes context.BasicRes) gin.Handl
 	}
 }
 
-func CheckAuthorizationHeader(c *gin.Context, logger log.Logger, db dal.Dal, apiKeyHelper *apikeyhelper.ApiKeyHelper, authHeader, path string) bool {
+func CheckAuthorizationHeader(c *gin.Context, logger log.Logger, db dal.Dal, apiKeyHelper *apikeyhelper.ApiKeyHelper, rateLimiter *apikeyhelper.RateLimiter, usageRecorder *apikeyhelper.UsageRecorder, authHeader, path string) bool {
 	if authHeader == "" {
 		c.Abort()
 		c.JSON(http.StatusUnauthorized, &apiBody{
@@ -150,18 +167,7 @@ func CheckAuthorizationHeader(c *gin.Context, logger log.Logger, db dal.Dal, api
 		return false
 	}
 
-	hashedApiKey, err := apiKeyHelper.DigestToken(apiKeyStr)
-	if err != nil {
-		logger.Error(err, "DigestToken")
-		c.Abort()
-		c.JSON(http.StatusInternalServerError, &apiBody{
-			Success: false,
-			Message: err.Error(),
-		})
-		return false
-	}
-
-	apiKey, err := apiKeyHelper.GetApiKey(nil, dal.Where("api_key = ?", hashedApiKey))
+	apiKey, err := apiKeyHelper.GetApiKeyByToken(nil, apiKeyStr)
 	if err != nil {
 		c.Abort()
 		if db.IsErrorNotFound(err) {
@@ -204,9 +210,30 @@ func CheckAuthorizationHeader(c *gin.Context, logger log.Logger, db dal.Dal, api
 		})
 		return false
 	}
+	if apiKey.IsReadOnly() && !isSafeMethod(c.Request.Method) {
+		c.Abort()
+		c.JSON(http.StatusForbidden, &apiBody{
+			Success: false,
+			Message: "api key is read-only",
+		})
+		return false
+	}
+	now := time.Now()
+	if allowed, retryAfter := rateLimiter.Allow(apiKey.ID, apiKey.RateLimitPerMinute, now); !allowed {
+		c.Abort()
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, &apiBody{
+			Success: false,
+			Message: "api key has exceeded its rate limit",
+		})
+		return false
+	}
+	usageRecorder.Record(apiKey.ID, c.ClientIP(), now)
 
 	logger.Info("redirect path: %s to: %s", c.Request.URL.Path, path)
 	c.Request.URL.Path = path
+	apiKey.RemoveHashedApiKey()
+	shared.SetApiKey(c, apiKey)
 	c.Set(common.USER, &common.User{
 		Name:  apiKey.Creator.Creator,
 		Email: apiKey.Creator.CreatorEmail,
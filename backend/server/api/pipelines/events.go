@@ -0,0 +1,100 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/runner"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+const pipelineEventsHeartbeatInterval = 15 * time.Second
+
+// @Summary Stream the live progress of a pipeline
+// @Description GET /pipelines/:pipelineId/events
+// @Description Server-Sent Events stream, starts with a `snapshot` event carrying the pipeline and its tasks, followed by
+// @Description `pipeline-status`, `task-status`, `subtask-started`, `subtask-finished`, `progress` and `log` events.
+// @Description The stream is closed once the pipeline is finished.
+// @Tags framework/pipelines
+// @Produce text/event-stream
+// @Param pipelineId path int true "pipeline ID"
+// @Success 200  {object} runner.PipelineEvent
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 404  {string} errcode.Error "Pipeline not found"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /pipelines/{pipelineId}/events [get]
+func Events(c *gin.Context) {
+	pipelineId := c.Param("pipelineId")
+	id, err := strconv.ParseUint(pipelineId, 10, 64)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad pipelineID format supplied"))
+		return
+	}
+	snapshot, subscription, err := services.SubscribePipelineEvents(id)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error subscribing pipeline events"))
+		return
+	}
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("snapshot", snapshot)
+	c.Writer.Flush()
+	if services.IsPipelineFinished(snapshot.Pipeline.Status) {
+		return
+	}
+
+	heartbeat := time.NewTicker(pipelineEventsHeartbeatInterval)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return event.Type != runner.PIPELINE_EVENT_PIPELINE_STATUS || !services.IsPipelineFinished(event.Status)
+		case <-heartbeat.C:
+			// the pipeline might be executed by another worker, whose events are not visible to this process
+			pipeline, err := services.GetDbPipeline(id)
+			if err == nil && services.IsPipelineFinished(pipeline.Status) {
+				c.SSEvent(runner.PIPELINE_EVENT_PIPELINE_STATUS, &runner.PipelineEvent{
+					Type:       runner.PIPELINE_EVENT_PIPELINE_STATUS,
+					PipelineId: pipeline.ID,
+					Status:     pipeline.Status,
+					Message:    pipeline.Message,
+					Time:       time.Now(),
+				})
+				return false
+			}
+			_, _ = io.WriteString(w, ": heartbeat\n\n")
+			return true
+		}
+	})
+}
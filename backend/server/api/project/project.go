@@ -92,6 +92,7 @@ func GetProjects(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
+	query.Names = shared.GetReadableProjects(c)
 	projects, count, err := services.GetProjects(&query)
 	if err != nil {
 		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting projects"))
@@ -163,15 +164,23 @@ func PatchProject(c *gin.Context) {
 }
 
 // @Summary Delete a project
-// @Description Delete a project
+// @Description Delete a project, along with the data of the project and its scopes when purgeData is set
 // @Tags framework/projects
 // @Accept application/json
+// @Param purgeData query bool false "purge the data of the project before deleting it"
 // @Success 200
 // @Failure 400  {string} errcode.Error "Bad Request"
 // @Failure 500  {string} errcode.Error "Internal Error"
 // @Router /projects/:projectName [delete]
 func DeleteProject(c *gin.Context) {
 	projectName := c.Param("projectName")
+	if c.Query("purgeData") == "true" {
+		_, err := services.PurgeProjectData(projectName, false)
+		if err != nil {
+			shared.ApiOutputError(c, errors.Default.Wrap(err, "error purging project data"))
+			return
+		}
+	}
 	err := services.DeleteProject(projectName)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error deleting project"))
@@ -0,0 +1,85 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package purge
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Purge data of a project
+// @Description Remove the raw, tool layer and domain layer rows of the project and of the scopes in its blueprint, scopes shared with other blueprints are skipped. The project itself is kept. The request returns once the purge is committed, the progress of every table (rows deleted, batches, duration) is reported in the tables of the result; no progress is streamed while the purge runs
+// @Tags framework/projects
+// @Param projectName path string true "project name"
+// @Param dryRun query bool false "count the rows without removing them"
+// @Success 200  {object} services.PurgeResult
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 409  {string} errcode.Error "Conflict"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /projects/{projectName}/data [delete]
+func DeleteProjectData(c *gin.Context) {
+	var query services.PurgeQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	result, err := services.PurgeProjectData(c.Param("projectName"), query.DryRun)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error purging project data"))
+		return
+	}
+	shared.ApiOutputSuccess(c, result, http.StatusOK)
+}
+
+// @Summary Purge data of a scope
+// @Description Remove the raw, tool layer and domain layer rows collected for a scope which is not used by any blueprint. The scope itself is kept. The request returns once the purge is committed, the progress of every table (rows deleted, batches, duration) is reported in the tables of the result; no progress is streamed while the purge runs
+// @Tags framework/scopes
+// @Param plugin path string true "plugin name"
+// @Param connectionId path int true "connection id"
+// @Param scopeId path string true "scope id"
+// @Param dryRun query bool false "count the rows without removing them"
+// @Success 200  {object} services.PurgeResult
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 409  {string} errcode.Error "Conflict"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /scopes/{plugin}/{connectionId}/{scopeId}/data [delete]
+func DeleteScopeData(c *gin.Context) {
+	var query services.PurgeQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	connectionId, err := strconv.ParseUint(c.Param("connectionId"), 10, 64)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad connectionId format supplied"))
+		return
+	}
+	result, err := services.PurgeScopeData(c.Param("plugin"), connectionId, c.Param("scopeId"), query.DryRun)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error purging scope data"))
+		return
+	}
+	shared.ApiOutputSuccess(c, result, http.StatusOK)
+}
@@ -0,0 +1,183 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PaginatedRoleAssignments struct {
+	RoleAssignments []*models.RoleAssignment `json:"roleAssignments"`
+	Count           int64                    `json:"count"`
+}
+
+// @Summary Get permissions of the current user
+// @Description Get the global role and project roles of the current user
+// @Tags framework/rbac
+// @Success 200  {object} models.Principal
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /rbac/me [get]
+func GetPrincipal(c *gin.Context) {
+	principal, exist := shared.GetPrincipal(c)
+	if !exist {
+		user, _ := shared.GetUser(c)
+		var err errors.Error
+		principal, err = services.GetPrincipal(user)
+		if err != nil {
+			shared.ApiOutputError(c, errors.Default.Wrap(err, "error getting principal"))
+			return
+		}
+	}
+	shared.ApiOutputSuccess(c, principal, http.StatusOK)
+}
+
+// @Summary Get list of role assignments
+// @Description GET /rbac/roles?page=1&pageSize=10
+// @Tags framework/rbac
+// @Param page query int false "query"
+// @Param pageSize query int false "query"
+// @Success 200  {object} PaginatedRoleAssignments
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /rbac/roles [get]
+func GetRoleAssignments(c *gin.Context) {
+	var query services.RoleAssignmentsQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	assignments, count, err := services.GetRoleAssignments(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error getting role assignments"))
+		return
+	}
+	shared.ApiOutputSuccess(c, PaginatedRoleAssignments{
+		RoleAssignments: assignments,
+		Count:           count,
+	}, http.StatusOK)
+}
+
+// @Summary Assign a global role
+// @Description Assign a global role (admin or viewer) to a user name or email
+// @Tags framework/rbac
+// @Accept application/json
+// @Param assignment body models.RoleAssignment true "json"
+// @Success 200  {object} models.RoleAssignment
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /rbac/roles [put]
+func PutRoleAssignment(c *gin.Context) {
+	input := &models.RoleAssignment{}
+	err := c.ShouldBindJSON(input)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	user, _ := shared.GetUser(c)
+	assignment, err := services.PutRoleAssignment(user, input)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error assigning role"))
+		return
+	}
+	shared.ApiOutputSuccess(c, assignment, http.StatusOK)
+}
+
+// @Summary Revoke a global role
+// @Description Revoke the global role of a user name or email
+// @Tags framework/rbac
+// @Param subject path string true "user name or email"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /rbac/roles/{subject} [delete]
+func DeleteRoleAssignment(c *gin.Context) {
+	err := services.DeleteRoleAssignment(c.Param("subject"))
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error revoking role"))
+		return
+	}
+	shared.ApiOutputSuccess(c, nil, http.StatusOK)
+}
+
+// @Summary Get grants of a project
+// @Description Get users granted with project roles
+// @Tags framework/rbac
+// @Param projectName path string true "project name"
+// @Success 200  {object} []models.ProjectGrant
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /projects/{projectName}/grants [get]
+func GetProjectGrants(c *gin.Context) {
+	grants, err := services.GetProjectGrants(c.Param("projectName"))
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error getting project grants"))
+		return
+	}
+	shared.ApiOutputSuccess(c, grants, http.StatusOK)
+}
+
+// @Summary Grant a project role
+// @Description Grant a project role (maintainer or viewer) to a user name or email
+// @Tags framework/rbac
+// @Accept application/json
+// @Param projectName path string true "project name"
+// @Param grant body models.ProjectGrant true "json"
+// @Success 200  {object} models.ProjectGrant
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /projects/{projectName}/grants [put]
+func PutProjectGrant(c *gin.Context) {
+	input := &models.ProjectGrant{}
+	err := c.ShouldBindJSON(input)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	user, _ := shared.GetUser(c)
+	grant, err := services.PutProjectGrant(user, c.Param("projectName"), input)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error granting project role"))
+		return
+	}
+	shared.ApiOutputSuccess(c, grant, http.StatusOK)
+}
+
+// @Summary Revoke a project role
+// @Description Revoke the project role of a user name or email
+// @Tags framework/rbac
+// @Param projectName path string true "project name"
+// @Param subject path string true "user name or email"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /projects/{projectName}/grants/{subject} [delete]
+func DeleteProjectGrant(c *gin.Context) {
+	err := services.DeleteProjectGrant(c.Param("projectName"), c.Param("subject"))
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error revoking project role"))
+		return
+	}
+	shared.ApiOutputSuccess(c, nil, http.StatusOK)
+}
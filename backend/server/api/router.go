@@ -18,23 +18,32 @@ limitations under the License.
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
 	"github.com/apache/incubator-devlake/core/context"
 	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/impls/logruslog"
 	"github.com/apache/incubator-devlake/server/api/apikeys"
+	"github.com/apache/incubator-devlake/server/api/auditlog"
 	"github.com/apache/incubator-devlake/server/api/store"
 
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/server/api/backup"
 	"github.com/apache/incubator-devlake/server/api/blueprints"
+	"github.com/apache/incubator-devlake/server/api/configuration"
 	"github.com/apache/incubator-devlake/server/api/domainlayer"
 	"github.com/apache/incubator-devlake/server/api/pipelines"
 	"github.com/apache/incubator-devlake/server/api/plugininfo"
 	"github.com/apache/incubator-devlake/server/api/project"
+	"github.com/apache/incubator-devlake/server/api/purge"
 	"github.com/apache/incubator-devlake/server/api/push"
+	"github.com/apache/incubator-devlake/server/api/rbac"
 	"github.com/apache/incubator-devlake/server/api/shared"
 	"github.com/apache/incubator-devlake/server/api/task"
 	"github.com/apache/incubator-devlake/server/services"
@@ -44,26 +53,30 @@ import (
 
 func RegisterRouter(r *gin.Engine, basicRes context.BasicRes) {
 	r.GET("/pipelines", pipelines.Index)
-	r.POST("/pipelines", pipelines.Post)
+	r.POST("/pipelines", audit(models.AUDIT_RESOURCE_PIPELINE, models.AUDIT_ACTION_TRIGGER, "", nil), pipelines.Post)
 	r.GET("/pipelines/:pipelineId", pipelines.Get)
-	r.DELETE("/pipelines/:pipelineId", pipelines.Delete)
+	r.DELETE("/pipelines/:pipelineId", audit(models.AUDIT_RESOURCE_PIPELINE, models.AUDIT_ACTION_CANCEL, "pipelineId", nil), pipelines.Delete)
 	r.GET("/pipelines/:pipelineId/tasks", task.GetTaskByPipeline)
 	r.GET("/pipelines/:pipelineId/subtasks", task.GetSubtaskByPipeline)
-	r.POST("/pipelines/:pipelineId/rerun", pipelines.PostRerun)
+	r.POST("/pipelines/:pipelineId/rerun", audit(models.AUDIT_RESOURCE_PIPELINE, models.AUDIT_ACTION_RERUN, "pipelineId", nil), pipelines.PostRerun)
 	r.GET("/pipelines/:pipelineId/logging.tar.gz", pipelines.DownloadLogs)
+	r.GET("/pipelines/:pipelineId/events", pipelines.Events)
 
 	r.GET("/blueprints", blueprints.Index)
-	r.POST("/blueprints", blueprints.Post)
-	r.PATCH("/blueprints/:blueprintId", blueprints.Patch)
-	r.DELETE("/blueprints/:blueprintId", blueprints.Delete)
+	r.POST("/blueprints", audit(models.AUDIT_RESOURCE_BLUEPRINT, models.AUDIT_ACTION_CREATE, "", nil), blueprints.Post)
+	r.PATCH("/blueprints/:blueprintId", audit(models.AUDIT_RESOURCE_BLUEPRINT, models.AUDIT_ACTION_UPDATE, "blueprintId", blueprintSnapshot), blueprints.Patch)
+	r.DELETE("/blueprints/:blueprintId", audit(models.AUDIT_RESOURCE_BLUEPRINT, models.AUDIT_ACTION_DELETE, "blueprintId", blueprintSnapshot), blueprints.Delete)
 	r.GET("/blueprints/:blueprintId", blueprints.Get)
-	r.POST("/blueprints/:blueprintId/trigger", blueprints.Trigger)
+	r.POST("/blueprints/:blueprintId/trigger", audit(models.AUDIT_RESOURCE_BLUEPRINT, models.AUDIT_ACTION_TRIGGER, "blueprintId", nil), blueprints.Trigger)
 	r.GET("/blueprints/:blueprintId/pipelines", blueprints.GetBlueprintPipelines)
 
-	r.POST("/tasks/:taskId/rerun", task.PostRerun)
+	r.POST("/tasks/:taskId/rerun", audit(models.AUDIT_RESOURCE_TASK, models.AUDIT_ACTION_RERUN, "taskId", nil), task.PostRerun)
 
 	r.POST("/push/:tableName", push.Post)
 	r.GET("/domainlayer/repos", domainlayer.ReposIndex)
+	r.GET("/domainlayer/tables", domainlayer.TablesIndex)
+	r.GET("/domainlayer/tables/:table", domainlayer.TableRows)
+	r.GET("/domainlayer/openapi.json", domainlayer.OpenApiSpec)
 
 	// plugin api
 	r.GET("/plugininfo", plugininfo.Get)
@@ -72,19 +85,43 @@ func RegisterRouter(r *gin.Engine, basicRes context.BasicRes) {
 	// project api
 	r.GET("/projects/:projectName", project.GetProject)
 	r.GET("/projects/:projectName/check", project.GetProjectCheck)
-	r.PATCH("/projects/:projectName", project.PatchProject)
-	r.DELETE("/projects/:projectName", project.DeleteProject)
-	r.POST("/projects", project.PostProject)
+	r.PATCH("/projects/:projectName", audit(models.AUDIT_RESOURCE_PROJECT, models.AUDIT_ACTION_UPDATE, "projectName", projectSnapshot), project.PatchProject)
+	r.DELETE("/projects/:projectName", audit(models.AUDIT_RESOURCE_PROJECT, models.AUDIT_ACTION_DELETE, "projectName", projectSnapshot), project.DeleteProject)
+	r.DELETE("/projects/:projectName/data", audit(models.AUDIT_RESOURCE_PROJECT, models.AUDIT_ACTION_PURGE, "projectName", nil), purge.DeleteProjectData)
+	r.POST("/projects", audit(models.AUDIT_RESOURCE_PROJECT, models.AUDIT_ACTION_CREATE, "", nil), project.PostProject)
 	r.GET("/projects", project.GetProjects)
+	// scope data api
+	r.DELETE("/scopes/:plugin/:connectionId/:scopeId/data", audit(models.AUDIT_RESOURCE_SCOPE, models.AUDIT_ACTION_PURGE, "scopeId", nil), purge.DeleteScopeData)
 	// on board api
 	r.GET("/store/:storeKey", store.GetStore)
 	r.PUT("/store/:storeKey", store.PutStore)
 
+	// rbac api
+	r.GET("/rbac/me", rbac.GetPrincipal)
+	r.GET("/rbac/roles", rbac.GetRoleAssignments)
+	r.PUT("/rbac/roles", audit(models.AUDIT_RESOURCE_ROLE, models.AUDIT_ACTION_UPDATE, "", nil), rbac.PutRoleAssignment)
+	r.DELETE("/rbac/roles/:subject", audit(models.AUDIT_RESOURCE_ROLE, models.AUDIT_ACTION_DELETE, "subject", nil), rbac.DeleteRoleAssignment)
+	r.GET("/projects/:projectName/grants", rbac.GetProjectGrants)
+	r.PUT("/projects/:projectName/grants", audit(models.AUDIT_RESOURCE_GRANT, models.AUDIT_ACTION_UPDATE, "projectName", nil), rbac.PutProjectGrant)
+	r.DELETE("/projects/:projectName/grants/:subject", audit(models.AUDIT_RESOURCE_GRANT, models.AUDIT_ACTION_DELETE, "projectName", nil), rbac.DeleteProjectGrant)
+
+	// audit logs api
+	r.GET("/audit-logs", auditlog.GetAuditLogs)
+
+	// configuration-as-code api
+	r.GET("/config/export", configuration.GetExport)
+	r.POST("/config/apply", audit(models.AUDIT_RESOURCE_CONFIG, models.AUDIT_ACTION_APPLY, "", nil), configuration.PostApply)
+
+	// backup api
+	r.GET("/backup", backup.GetBackup)
+	r.POST("/backup/restore", audit(models.AUDIT_RESOURCE_BACKUP, models.AUDIT_ACTION_RESTORE, "", nil), backup.PostRestore)
+
 	// api keys api
 	r.GET("/api-keys", apikeys.GetApiKeys)
-	r.POST("/api-keys", apikeys.PostApiKey)
-	r.PUT("/api-keys/:apiKeyId", apikeys.PutApiKey)
-	r.DELETE("/api-keys/:apiKeyId", apikeys.DeleteApiKey)
+	r.POST("/api-keys", audit(models.AUDIT_RESOURCE_API_KEY, models.AUDIT_ACTION_CREATE, "", nil), apikeys.PostApiKey)
+	r.PUT("/api-keys/:apiKeyId", audit(models.AUDIT_RESOURCE_API_KEY, models.AUDIT_ACTION_UPDATE, "apiKeyId", apiKeySnapshot), apikeys.PutApiKey)
+	r.POST("/api-keys/:apiKeyId/rotate", audit(models.AUDIT_RESOURCE_API_KEY, models.AUDIT_ACTION_ROTATE, "apiKeyId", apiKeySnapshot), apikeys.PostRotateApiKey)
+	r.DELETE("/api-keys/:apiKeyId", audit(models.AUDIT_RESOURCE_API_KEY, models.AUDIT_ACTION_DELETE, "apiKeyId", apiKeySnapshot), apikeys.DeleteApiKey)
 
 	// mount all api resources for all plugins
 	resources, err := services.GetPluginsApiResources()
@@ -100,10 +137,14 @@ func RegisterRouter(r *gin.Engine, basicRes context.BasicRes) {
 func registerPluginEndpoints(r *gin.Engine, basicRes context.BasicRes, pluginName string, apiResources map[string]map[string]plugin.ApiResourceHandler) {
 	for resourcePath, resourceHandlers := range apiResources {
 		for method, h := range resourceHandlers {
+			handlers := []gin.HandlerFunc{handlePluginCall(basicRes, pluginName, h)}
+			if rule := getPluginAuditRule(pluginName, resourcePath, method, resourceHandlers); rule != nil {
+				handlers = append([]gin.HandlerFunc{rule.handler()}, handlers...)
+			}
 			r.Handle(
 				method,
 				fmt.Sprintf("/plugins/%s/%s", pluginName, resourcePath),
-				handlePluginCall(basicRes, pluginName, h),
+				handlers...,
 			)
 		}
 	}
@@ -127,18 +168,25 @@ func handlePluginCall(basicRes context.BasicRes, pluginName string, handler plug
 		} else {
 			input.User = user
 		}
-		if c.Request.Body != nil {
-			if strings.HasPrefix(c.Request.Header.Get("Content-Type"), "multipart/form-data;") {
-				input.Request = c.Request
-			} else {
-				shouldBindJSONErr := c.ShouldBindJSON(&input.Body)
-				if shouldBindJSONErr != nil && shouldBindJSONErr.Error() != "EOF" {
-					shared.ApiOutputError(c, shouldBindJSONErr)
+		input.Request = c.Request
+		if c.Request.Body != nil && !strings.HasPrefix(c.Request.Header.Get("Content-Type"), "multipart/form-data;") {
+			bindJSONErr := bindJSONBody(c, input)
+			if bindJSONErr != nil {
+				shared.ApiOutputError(c, bindJSONErr)
+				return
+			}
+		}
+		output, err := handler(input)
+		if err == nil && output != nil && c.Request.Method == http.MethodGet && strings.HasSuffix(c.FullPath(), "/connections") {
+			if principal, ok := shared.GetPrincipal(c); ok && !principal.CanReadAll() {
+				connectionProjects, e := services.GetConnectionProjects(pluginName)
+				if e != nil {
+					shared.ApiOutputError(c, e)
 					return
 				}
+				output.Body = filterReadableConnections(principal, connectionProjects, output.Body)
 			}
 		}
-		output, err := handler(input)
 		if err != nil {
 			if output != nil && output.Body != nil {
 				logruslog.Global.Error(err, "")
@@ -172,3 +220,21 @@ func handlePluginCall(basicRes context.BasicRes, pluginName string, handler plug
 		}
 	}
 }
+
+// bindJSONBody decodes the request body into input.Body, or into input.BodyList when the payload is a json array.
+// The raw body stays readable from input.Request for handlers verifying payload signatures
+func bindJSONBody(c *gin.Context, input *plugin.ApiResourceInput) error {
+	data, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	if data[0] == '[' {
+		return json.Unmarshal(data, &input.BodyList)
+	}
+	return json.Unmarshal(data, &input.Body)
+}
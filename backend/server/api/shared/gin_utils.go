@@ -18,6 +18,9 @@ limitations under the License.
 package shared
 
 import (
+	"context"
+
+	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/models/common"
 	"github.com/gin-gonic/gin"
 )
@@ -30,3 +33,43 @@ func GetUser(c *gin.Context) (*common.User, bool) {
 	user := userObj.(*common.User)
 	return user, true
 }
+
+// GetPrincipal returns the permissions of the current user resolved by the authorization middleware,
+// it doesn't exist when RBAC is disabled
+func GetPrincipal(c *gin.Context) (*models.Principal, bool) {
+	principalObj, exist := c.Get(common.PRINCIPAL)
+	if !exist {
+		return nil, false
+	}
+	return principalObj.(*models.Principal), true
+}
+
+// GetReadableProjects returns names of projects the current user can read, nil means all projects
+func GetReadableProjects(c *gin.Context) []string {
+	principal, exist := GetPrincipal(c)
+	if !exist {
+		return nil
+	}
+	return principal.ReadableProjects()
+}
+
+type apiKeyContextKey struct{}
+
+// SetApiKey records the api key authenticating the request. It is attached to the request context as well
+// since gin keys are reset when the request is rerouted by router.HandleContext
+func SetApiKey(c *gin.Context, apiKey *models.ApiKey) {
+	c.Set(common.API_KEY, apiKey)
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), apiKeyContextKey{}, apiKey))
+}
+
+// GetApiKey returns the api key authenticating the request, the hashed key is removed
+func GetApiKey(c *gin.Context) (*models.ApiKey, bool) {
+	if apiKeyObj, exist := c.Get(common.API_KEY); exist {
+		return apiKeyObj.(*models.ApiKey), true
+	}
+	if c.Request == nil {
+		return nil, false
+	}
+	apiKey, ok := c.Request.Context().Value(apiKeyContextKey{}).(*models.ApiKey)
+	return apiKey, ok
+}
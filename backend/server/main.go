@@ -18,17 +18,68 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+
 	"github.com/apache/incubator-devlake/core/config"
+	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/plugin"
 	_ "github.com/apache/incubator-devlake/core/version"
 	"github.com/apache/incubator-devlake/server/api"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/spf13/cobra"
 )
 
 func main() {
+	rootCmd := &cobra.Command{
+		Use:   "lake",
+		Short: "Run the DevLake api server",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkEncryptionSecret()
+			api.CreateAndRunApiServer()
+		},
+	}
+
+	var includeDomain bool
+	backupCmd := &cobra.Command{
+		Use:   "backup <archive>",
+		Short: "Back up configurations, connections, scopes and scope configs into a zip archive",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkEncryptionSecret()
+			errors.Must(services.BackupStandalone(args[0], includeDomain))
+		},
+	}
+	backupCmd.Flags().BoolVar(&includeDomain, "domain", false, "include the domain layer data")
+
+	var force bool
+	restoreCmd := &cobra.Command{
+		Use:   "restore <archive>",
+		Short: "Restore a backup archive, archives of older versions are migrated forward",
+		Long: "Restore a backup archive into an empty database or one used by the same version of DevLake. " +
+			"The DevLake server must be stopped during the restoration.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkEncryptionSecret()
+			result := errors.Must1(services.RestoreBackupStandalone(args[0], force))
+			output := errors.Must1(json.MarshalIndent(result, "", "  "))
+			fmt.Println(string(output))
+		},
+	}
+	restoreCmd.Flags().BoolVar(&force, "force", false, "wipe out the database if it is used by a different version of DevLake")
+
+	rootCmd.AddCommand(backupCmd, restoreCmd)
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func checkEncryptionSecret() {
 	v := config.GetConfig()
 	encryptionSecret := v.GetString(plugin.EncodeKeyEnvStr)
 	if encryptionSecret == "" {
 		panic("ENCRYPTION_SECRET must be set in environment variable or .env file")
 	}
-	api.CreateAndRunApiServer()
 }
@@ -18,6 +18,9 @@ limitations under the License.
 package services
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
@@ -93,6 +96,24 @@ func PutApiKey(user *common.User, id uint64) (*models.ApiOutputApiKey, errors.Er
 	return apiKey, nil
 }
 
+// RotateApiKey replaces the api key with a new one, the replaced key keeps working for the grace period
+func RotateApiKey(user *common.User, id uint64, input *models.ApiInputRotateApiKey) (*models.ApiOutputApiKey, errors.Error) {
+	// verify input
+	if id == 0 {
+		return nil, errors.BadInput.New("api key's id is missing")
+	}
+	if err := VerifyStruct(input); err != nil {
+		return nil, err
+	}
+	apiKeyHelper := apikeyhelper.NewApiKeyHelper(basicRes, logger)
+	apiKey, err := apiKeyHelper.Rotate(user, id, time.Duration(input.GracePeriodSeconds)*time.Second)
+	if err != nil {
+		logger.Error(err, "api key helper rotate: %d", id)
+		return nil, err
+	}
+	return apiKey, nil
+}
+
 // CreateApiKey accepts an api key instance and insert it to database
 func CreateApiKey(user *common.User, apiKeyInput *models.ApiInputApiKey) (*models.ApiOutputApiKey, errors.Error) {
 	// verify input
@@ -101,9 +122,15 @@ func CreateApiKey(user *common.User, apiKeyInput *models.ApiInputApiKey) (*model
 		return nil, err
 	}
 
+	if apiKeyInput.ProjectName != "" {
+		if _, err := getProjectByName(db, apiKeyInput.ProjectName); err != nil {
+			return nil, errors.BadInput.Wrap(err, fmt.Sprintf("project %s of the api key is invalid", apiKeyInput.ProjectName))
+		}
+	}
+
 	apiKeyHelper := apikeyhelper.NewApiKeyHelper(basicRes, logger)
 	tx := basicRes.GetDal().Begin()
-	apiKey, err := apiKeyHelper.Create(tx, user, apiKeyInput.Name, apiKeyInput.ExpiredAt, apiKeyInput.AllowedPath, apiKeyInput.Type, "")
+	apiKey, err := apiKeyHelper.CreateFromInput(tx, user, apiKeyInput, "")
 	if err != nil {
 		if err := tx.Rollback(); err != nil {
 			logger.Error(err, "transaction Rollback")
@@ -116,3 +143,17 @@ func CreateApiKey(user *common.User, apiKeyInput *models.ApiInputApiKey) (*model
 	}
 	return apiKey, nil
 }
+
+// GetApiKey returns the api key without the hashed key
+func GetApiKey(id uint64) (*models.ApiKey, errors.Error) {
+	if id == 0 {
+		return nil, errors.BadInput.New("api key's id is missing")
+	}
+	apiKeyHelper := apikeyhelper.NewApiKeyHelper(basicRes, logger)
+	apiKey, err := apiKeyHelper.GetApiKey(nil, dal.Where("id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	apiKey.RemoveHashedApiKey()
+	return apiKey, nil
+}
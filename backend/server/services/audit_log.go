@@ -0,0 +1,246 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+)
+
+const redactedValue = "******"
+
+// fields whose values never show up in audit logs, compared after lowercasing and removing `_` and `-`
+var sensitiveFieldPatterns = []string{
+	"password", "passphrase", "token", "secret", "privatekey", "apikey", "accesskey", "credential", "authorization",
+}
+
+// fields changed by every update, not worth recording
+var ignoredAuditFields = map[string]bool{
+	"createdAt": true,
+	"updatedAt": true,
+}
+
+// AuditLogsQuery used to query audit logs
+type AuditLogsQuery struct {
+	Pagination
+	Actor        string     `form:"actor"`
+	Action       string     `form:"action"`
+	ResourceType string     `form:"resourceType"`
+	ResourceId   string     `form:"resourceId"`
+	PluginName   string     `form:"pluginName"`
+	Since        *time.Time `form:"since"`
+	Until        *time.Time `form:"until"`
+}
+
+// RecordAuditLog redacts the snapshots, computes the changes in between when both exist and appends the log
+func RecordAuditLog(auditLog *models.AuditLog, before, after interface{}) errors.Error {
+	beforeMap, err := toAuditSnapshot(before)
+	if err != nil {
+		return err
+	}
+	afterMap, err := toAuditSnapshot(after)
+	if err != nil {
+		return err
+	}
+	if beforeMap != nil && afterMap != nil {
+		auditLog.Changes = ComputeAuditChanges(beforeMap, afterMap)
+	}
+	auditLog.Before = RedactAuditSnapshot(beforeMap)
+	auditLog.After = RedactAuditSnapshot(afterMap)
+	auditLog.ID = 0
+	err = db.Create(auditLog)
+	if err != nil {
+		return errors.Default.Wrap(err, "error saving audit log")
+	}
+	return nil
+}
+
+// GetAuditLogs returns a paginated list of audit logs matching the query, newest first
+func GetAuditLogs(query *AuditLogsQuery) ([]*models.AuditLog, int64, errors.Error) {
+	if err := VerifyStruct(query); err != nil {
+		return nil, 0, err
+	}
+	clauses := []dal.Clause{dal.From(&models.AuditLog{})}
+	if query.Actor != "" {
+		clauses = append(clauses, dal.Where("actor = ? OR actor_email = ?", query.Actor, query.Actor))
+	}
+	if query.Action != "" {
+		clauses = append(clauses, dal.Where("action = ?", query.Action))
+	}
+	if query.ResourceType != "" {
+		clauses = append(clauses, dal.Where("resource_type = ?", query.ResourceType))
+	}
+	if query.ResourceId != "" {
+		clauses = append(clauses, dal.Where("resource_id = ?", query.ResourceId))
+	}
+	if query.PluginName != "" {
+		clauses = append(clauses, dal.Where("plugin_name = ?", query.PluginName))
+	}
+	if query.Since != nil {
+		clauses = append(clauses, dal.Where("created_at >= ?", *query.Since))
+	}
+	if query.Until != nil {
+		clauses = append(clauses, dal.Where("created_at < ?", *query.Until))
+	}
+	count, err := db.Count(clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error getting DB count of audit logs")
+	}
+	clauses = append(clauses,
+		dal.Orderby("id DESC"),
+		dal.Offset(query.GetSkip()),
+		dal.Limit(query.GetPageSize()),
+	)
+	auditLogs := make([]*models.AuditLog, 0)
+	err = db.All(&auditLogs, clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error finding DB audit logs")
+	}
+	return auditLogs, count, nil
+}
+
+// toAuditSnapshot converts models or decoded json into a generic map by a json round trip
+func toAuditSnapshot(value interface{}) (map[string]interface{}, errors.Error) {
+	if value == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(value)
+	if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice) && rv.IsNil() {
+		return nil, nil
+	}
+	if snapshot, ok := value.(map[string]interface{}); ok {
+		return snapshot, nil
+	}
+	blob, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error encoding audit snapshot")
+	}
+	var decoded interface{}
+	err = json.Unmarshal(blob, &decoded)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error decoding audit snapshot")
+	}
+	if snapshot, ok := decoded.(map[string]interface{}); ok {
+		return snapshot, nil
+	}
+	return map[string]interface{}{"value": decoded}, nil
+}
+
+func isSensitiveField(name string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	for _, pattern := range sensitiveFieldPatterns {
+		if strings.Contains(normalized, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactAuditSnapshot returns a copy of the snapshot with values of sensitive fields masked
+func RedactAuditSnapshot(snapshot map[string]interface{}) map[string]interface{} {
+	if snapshot == nil {
+		return nil
+	}
+	return redactAuditValue(snapshot).(map[string]interface{})
+}
+
+func redactAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if isSensitiveField(key) && item != nil && item != "" {
+				redacted[key] = redactedValue
+			} else {
+				redacted[key] = redactAuditValue(item)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactAuditValue(item)
+		}
+		return redacted
+	}
+	return value
+}
+
+// ComputeAuditChanges compares two snapshots field by field, nested objects are flattened with `.` joined paths
+// while arrays are compared as a whole. Sensitive fields are reported as changed without revealing the values
+func ComputeAuditChanges(before, after map[string]interface{}) []models.AuditChange {
+	beforeFields := make(map[string]interface{})
+	afterFields := make(map[string]interface{})
+	flattenAuditSnapshot("", before, beforeFields)
+	flattenAuditSnapshot("", after, afterFields)
+	paths := make([]string, 0, len(beforeFields)+len(afterFields))
+	for path := range beforeFields {
+		paths = append(paths, path)
+	}
+	for path := range afterFields {
+		if _, ok := beforeFields[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	changes := make([]models.AuditChange, 0)
+	for _, path := range paths {
+		beforeValue, afterValue := beforeFields[path], afterFields[path]
+		if reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		name := path[strings.LastIndex(path, ".")+1:]
+		if isSensitiveField(name) {
+			if beforeValue != nil && beforeValue != "" {
+				beforeValue = redactedValue
+			}
+			if afterValue != nil && afterValue != "" {
+				afterValue = redactedValue
+			}
+		} else {
+			beforeValue = redactAuditValue(beforeValue)
+			afterValue = redactAuditValue(afterValue)
+		}
+		changes = append(changes, models.AuditChange{Path: path, Before: beforeValue, After: afterValue})
+	}
+	return changes
+}
+
+func flattenAuditSnapshot(prefix string, snapshot map[string]interface{}, fields map[string]interface{}) {
+	for key, value := range snapshot {
+		if ignoredAuditFields[key] {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && !isSensitiveField(key) {
+			flattenAuditSnapshot(path, nested, fields)
+			continue
+		}
+		fields[path] = value
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactAuditSnapshot(t *testing.T) {
+	redacted := RedactAuditSnapshot(map[string]interface{}{
+		"name":  "github",
+		"token": "ghp_xxx",
+		"proxy": "",
+		"auth": map[string]interface{}{
+			"password":   "123",
+			"secret_key": "abc",
+		},
+		"keys": []interface{}{map[string]interface{}{"accessKey": "k1"}},
+	})
+	assert.Equal(t, map[string]interface{}{
+		"name":  "github",
+		"token": redactedValue,
+		"proxy": "",
+		"auth": map[string]interface{}{
+			"password":   redactedValue,
+			"secret_key": redactedValue,
+		},
+		"keys": []interface{}{map[string]interface{}{"accessKey": redactedValue}},
+	}, redacted)
+}
+
+func TestComputeAuditChanges(t *testing.T) {
+	before := map[string]interface{}{
+		"name":       "bp",
+		"cronConfig": "0 0 * * *",
+		"token":      "old",
+		"updatedAt":  "2025-01-01T00:00:00Z",
+		"settings":   map[string]interface{}{"timeAfter": nil, "connections": []interface{}{float64(1)}},
+	}
+	after := map[string]interface{}{
+		"name":       "bp",
+		"cronConfig": "0 1 * * *",
+		"token":      "new",
+		"updatedAt":  "2025-01-02T00:00:00Z",
+		"settings":   map[string]interface{}{"timeAfter": "2024-01-01", "connections": []interface{}{float64(1)}},
+		"enable":     true,
+	}
+	assert.Equal(t, []models.AuditChange{
+		{Path: "cronConfig", Before: "0 0 * * *", After: "0 1 * * *"},
+		{Path: "enable", Before: nil, After: true},
+		{Path: "settings.timeAfter", Before: nil, After: "2024-01-01"},
+		{Path: "token", Before: redactedValue, After: redactedValue},
+	}, ComputeAuditChanges(before, after))
+}
+
+func TestToAuditSnapshot(t *testing.T) {
+	snapshot, err := toAuditSnapshot(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, snapshot)
+
+	var apiKey *models.ApiKey
+	snapshot, err = toAuditSnapshot(apiKey)
+	assert.Nil(t, err)
+	assert.Nil(t, snapshot)
+
+	snapshot, err = toAuditSnapshot(&models.AuditChange{Path: "name", Before: "a", After: "b"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"path": "name", "before": "a", "after": "b"}, snapshot)
+
+	snapshot, err = toAuditSnapshot([]interface{}{"a"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"value": []interface{}{"a"}}, snapshot)
+}
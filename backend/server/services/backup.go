@@ -0,0 +1,665 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/migration"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/domaininfo"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/runner"
+	"github.com/apache/incubator-devlake/core/version"
+)
+
+// BACKUP_FORMAT_VERSION is bumped whenever the layout of the backup archive changes
+const BACKUP_FORMAT_VERSION = 1
+
+const backupManifestFile = "manifest.json"
+const backupTablesDir = "tables/"
+
+// encrypted into the manifest to tell whether the archive was made with the same ENCRYPTION_SECRET
+const backupEncryptionProbe = "devlake-backup"
+
+// rows inserted by a single statement, bounded by the number of placeholders allowed by the databases
+const backupInsertBatchSize = 500
+const backupMaxPlaceholders = 60000
+
+// backupCoreTables are the configuration and history of the instance, the runtime state like locks, leases and
+// the collector states are left out since the tool layer data they refer to is not part of the backup
+var backupCoreTables = []dal.Tabler{
+	&models.Project{},
+	&models.ProjectMetricSetting{},
+	&models.Blueprint{},
+	&models.BlueprintLabel{},
+	&models.BlueprintConnection{},
+	&models.BlueprintScope{},
+	&models.Pipeline{},
+	&models.DbPipelineLabel{},
+	&models.Task{},
+	&models.Subtask{},
+	&models.Notification{},
+	&models.Store{},
+	&models.ApiKey{},
+	&models.RoleAssignment{},
+	&models.ProjectGrant{},
+	&models.AuditLog{},
+}
+
+// tables kept by a forced restoration, they are created by the instance itself rather than the migration scripts
+var backupPreservedTables = map[string]bool{
+	models.LockingStub{}.TableName():    true,
+	models.LockingHistory{}.TableName(): true,
+}
+
+// BackupManifest describes the content of a backup archive
+type BackupManifest struct {
+	FormatVersion   int               `json:"formatVersion"`
+	Version         string            `json:"version"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	Dialect         string            `json:"dialect"`
+	IncludeDomain   bool              `json:"includeDomain"`
+	EncryptionProbe string            `json:"encryptionProbe"`
+	Migrations      []BackupMigration `json:"migrations"`
+	Tables          []string          `json:"tables"`
+}
+
+// BackupMigration is a migration script applied to the database the backup was taken from
+type BackupMigration struct {
+	ScriptVersion uint64 `json:"scriptVersion"`
+	ScriptName    string `json:"scriptName"`
+	Comment       string `json:"comment"`
+}
+
+// BackupQuery is the query of the backup api
+type BackupQuery struct {
+	Domain bool `form:"domain"`
+}
+
+// RestoreResult summarizes a restoration
+type RestoreResult struct {
+	Version        string           `json:"version"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	AppliedScripts int              `json:"appliedScripts"`
+	Tables         []*RestoredTable `json:"tables"`
+}
+
+// RestoredTable is the number of rows restored into a table
+type RestoredTable struct {
+	Name           string   `json:"name"`
+	Rows           int      `json:"rows"`
+	DroppedColumns []string `json:"droppedColumns,omitempty"`
+}
+
+// NewBackupManifest collects the tables to be backed up along with the current migration history
+func NewBackupManifest(includeDomain bool) (*BackupManifest, errors.Error) {
+	probe, err := plugin.Encrypt(cfg.GetString(plugin.EncodeKeyEnvStr), backupEncryptionProbe)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrationHistory()
+	if err != nil {
+		return nil, err
+	}
+	tables, err := getBackupTables(includeDomain)
+	if err != nil {
+		return nil, err
+	}
+	return &BackupManifest{
+		FormatVersion:   BACKUP_FORMAT_VERSION,
+		Version:         version.Version,
+		CreatedAt:       time.Now(),
+		Dialect:         db.Dialect(),
+		IncludeDomain:   includeDomain,
+		EncryptionProbe: probe,
+		Migrations:      migrations,
+		Tables:          tables,
+	}, nil
+}
+
+// WriteBackup writes the manifest and the rows of its tables into a zip archive
+func WriteBackup(w io.Writer, manifest *BackupManifest) errors.Error {
+	archive := zip.NewWriter(w)
+	entry, e := archive.Create(backupManifestFile)
+	if e != nil {
+		return errors.Default.Wrap(e, "failed to create the manifest of the backup")
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if e := encoder.Encode(manifest); e != nil {
+		return errors.Default.Wrap(e, "failed to write the manifest of the backup")
+	}
+	for _, table := range manifest.Tables {
+		entry, e = archive.Create(backupTablesDir + table + ".jsonl")
+		if e != nil {
+			return errors.Default.Wrap(e, fmt.Sprintf("failed to create the backup of table %s", table))
+		}
+		if err := writeBackupTable(entry, table); err != nil {
+			return err
+		}
+	}
+	if e := archive.Close(); e != nil {
+		return errors.Default.Wrap(e, "failed to finish the backup")
+	}
+	return nil
+}
+
+// BackupStandalone backs up the database into the file, it is meant to be run by the `lake backup` command
+func BackupStandalone(path string, includeDomain bool) errors.Error {
+	InitResources()
+	errors.Must(runner.LoadPlugins(basicRes))
+	manifest, err := NewBackupManifest(includeDomain)
+	if err != nil {
+		return err
+	}
+	file, e := os.Create(path)
+	if e != nil {
+		return errors.Default.Wrap(e, fmt.Sprintf("failed to create %s", path))
+	}
+	defer file.Close()
+	err = WriteBackup(file, manifest)
+	if err != nil {
+		return err
+	}
+	logger.Info("backed up %d tables into %s", len(manifest.Tables), path)
+	return nil
+}
+
+// RestoreBackup restores an archive into the running instance, the archive must be created by an instance
+// with the same migration scripts applied, older ones have to be restored with the `lake restore` command
+func RestoreBackup(archive io.ReaderAt, size int64) (*RestoreResult, errors.Error) {
+	reader, manifest, err := openBackupArchive(archive, size)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrationHistory()
+	if err != nil {
+		return nil, err
+	}
+	if !sameBackupMigrations(manifest.Migrations, migrations) {
+		return nil, errors.BadInput.New(fmt.Sprintf(
+			"the archive was created by DevLake %s with a different database schema, restore it with the `lake restore` command instead",
+			manifest.Version,
+		))
+	}
+	running, err := db.Count(dal.From(&models.Pipeline{}), dal.Where("status = ?", models.TASK_RUNNING))
+	if err != nil {
+		return nil, err
+	}
+	if running > 0 {
+		return nil, errors.BadInput.New("pipelines are running, wait for them to finish or cancel them before restoring")
+	}
+	result, err := restoreBackupTables(reader, manifest)
+	if err != nil {
+		return nil, err
+	}
+	resetInterruptedPipelines()
+	err = ReloadBlueprints()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RestoreBackupStandalone restores the archive before the api server starts, it is meant to be run by the
+// `lake restore` command. Archives of older versions are restored into an empty database by applying the
+// migration scripts the archive was made with, loading the data and then applying the rest of the scripts.
+// A database being used is wiped out first when force is set.
+func RestoreBackupStandalone(path string, force bool) (*RestoreResult, errors.Error) {
+	InitResources()
+	lockDatabase()
+	errors.Must(runner.LoadPlugins(basicRes))
+	registerPluginsMigrationScripts()
+
+	file, e := os.Open(path)
+	if e != nil {
+		return nil, errors.BadInput.Wrap(e, fmt.Sprintf("failed to open %s", path))
+	}
+	defer file.Close()
+	info, e := file.Stat()
+	if e != nil {
+		return nil, errors.Default.Wrap(e, fmt.Sprintf("failed to open %s", path))
+	}
+	reader, manifest, err := openBackupArchive(file, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	archived := make(map[string]bool)
+	for _, m := range manifest.Migrations {
+		archived[backupScriptId(m.ScriptName, m.ScriptVersion)] = true
+	}
+	known := make(map[string]bool)
+	for _, script := range migrator.Scripts() {
+		known[backupScriptId(script.Name(), script.Version())] = true
+	}
+	for scriptId := range archived {
+		if !known[scriptId] {
+			return nil, errors.BadInput.New(fmt.Sprintf(
+				"the archive was created by DevLake %s with migration script %s which is unknown to this version, the plugin might be missing",
+				manifest.Version, scriptId,
+			))
+		}
+	}
+
+	migrations, err := loadMigrationHistory()
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) > 0 {
+		if !force {
+			if sameBackupMigrations(manifest.Migrations, migrations) {
+				return restoreBackupTables(reader, manifest)
+			}
+			return nil, errors.BadInput.New("the database is in use by a different version of DevLake, restore into an empty database or use --force to wipe it out")
+		}
+		logger.Warn(nil, "wiping out the database")
+		err = wipeDatabase()
+		if err != nil {
+			return nil, err
+		}
+		err = migrator.Refresh()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// bring the database to the schema the archive was made with
+	err = migrator.ExecuteOnly(func(script plugin.MigrationScript) bool {
+		return archived[backupScriptId(script.Name(), script.Version())]
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, err := restoreBackupTables(reader, manifest)
+	if err != nil {
+		return nil, err
+	}
+	// then migrate the restored data forward
+	result.AppliedScripts = len(known) - len(archived)
+	err = migrator.Execute()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func getBackupTables(includeDomain bool) ([]string, errors.Error) {
+	candidates := make([]dal.Tabler, 0, len(backupCoreTables))
+	candidates = append(candidates, backupCoreTables...)
+	for _, pluginInst := range plugin.AllPlugins() {
+		source, ok := pluginInst.(plugin.PluginSource)
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, source.Connection(), source.ScopeConfig())
+		if scope := source.Scope(); !isNilTabler(scope) {
+			candidates = append(candidates, scope)
+		}
+	}
+	if includeDomain {
+		candidates = append(candidates, domaininfo.GetDomainTablesInfo()...)
+	}
+	seen := make(map[string]bool)
+	tables := make([]string, 0, len(candidates))
+	for _, tabler := range candidates {
+		if isNilTabler(tabler) {
+			continue
+		}
+		table := tabler.TableName()
+		if seen[table] || !db.HasTable(table) {
+			continue
+		}
+		seen[table] = true
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+func isNilTabler(tabler dal.Tabler) bool {
+	if tabler == nil {
+		return true
+	}
+	value := reflect.ValueOf(tabler)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+func loadMigrationHistory() ([]BackupMigration, errors.Error) {
+	var records []migration.MigrationHistory
+	err := db.All(&records, dal.Orderby("script_version, script_name"))
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]BackupMigration, 0, len(records))
+	for _, record := range records {
+		migrations = append(migrations, BackupMigration{
+			ScriptVersion: record.ScriptVersion,
+			ScriptName:    record.ScriptName,
+			Comment:       record.Comment,
+		})
+	}
+	return migrations, nil
+}
+
+func backupScriptId(scriptName string, scriptVersion uint64) string {
+	return fmt.Sprintf("%s:%d", scriptName, scriptVersion)
+}
+
+func sameBackupMigrations(a, b []BackupMigration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := make(map[string]bool, len(a))
+	for _, m := range a {
+		ids[backupScriptId(m.ScriptName, m.ScriptVersion)] = true
+	}
+	for _, m := range b {
+		if !ids[backupScriptId(m.ScriptName, m.ScriptVersion)] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeBackupTable(w io.Writer, table string) errors.Error {
+	cursor, err := db.Cursor(dal.From(table))
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("failed to read table %s", table))
+	}
+	defer cursor.Close()
+	encoder := json.NewEncoder(w)
+	for cursor.Next() {
+		row := make(map[string]interface{})
+		err = db.Fetch(cursor, &row)
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("failed to read table %s", table))
+		}
+		for column, value := range row {
+			if bytes, ok := value.([]byte); ok {
+				row[column] = string(bytes)
+			}
+		}
+		if e := encoder.Encode(row); e != nil {
+			return errors.Default.Wrap(e, fmt.Sprintf("failed to write the backup of table %s", table))
+		}
+	}
+	return nil
+}
+
+func openBackupArchive(archive io.ReaderAt, size int64) (*zip.Reader, *BackupManifest, errors.Error) {
+	reader, e := zip.NewReader(archive, size)
+	if e != nil {
+		return nil, nil, errors.BadInput.Wrap(e, "the backup is not a valid archive")
+	}
+	entry, e := reader.Open(backupManifestFile)
+	if e != nil {
+		return nil, nil, errors.BadInput.Wrap(e, "the manifest of the backup is missing")
+	}
+	defer entry.Close()
+	manifest := &BackupManifest{}
+	if e := json.NewDecoder(entry).Decode(manifest); e != nil {
+		return nil, nil, errors.BadInput.Wrap(e, "the manifest of the backup is malformed")
+	}
+	if manifest.FormatVersion != BACKUP_FORMAT_VERSION {
+		return nil, nil, errors.BadInput.New(fmt.Sprintf("unsupported backup format version %d", manifest.FormatVersion))
+	}
+	probe, err := plugin.Decrypt(cfg.GetString(plugin.EncodeKeyEnvStr), manifest.EncryptionProbe)
+	if err != nil || probe != backupEncryptionProbe {
+		return nil, nil, errors.BadInput.New("the backup was created with a different ENCRYPTION_SECRET")
+	}
+	return reader, manifest, nil
+}
+
+// restoreBackupTables replaces the rows of the tables in the archive within a transaction
+func restoreBackupTables(reader *zip.Reader, manifest *BackupManifest) (result *RestoreResult, err errors.Error) {
+	result = &RestoreResult{
+		Version:   manifest.Version,
+		CreatedAt: manifest.CreatedAt,
+		Tables:    make([]*RestoredTable, 0, len(manifest.Tables)),
+	}
+	tx := db.Begin()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	for _, table := range manifest.Tables {
+		var restored *RestoredTable
+		restored, err = restoreBackupTable(tx, reader, table)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("restored %d rows into %s", restored.Rows, table)
+		result.Tables = append(result.Tables, restored)
+	}
+	return result, nil
+}
+
+func restoreBackupTable(tx dal.Transaction, reader *zip.Reader, table string) (*RestoredTable, errors.Error) {
+	if !tx.HasTable(table) {
+		return nil, errors.BadInput.New(fmt.Sprintf("table %s of the backup doesn't exist", table))
+	}
+	columnMetas, err := tx.GetColumns(dal.DefaultTabler{Name: table}, nil)
+	if err != nil {
+		return nil, err
+	}
+	columnTypes := make(map[string]string, len(columnMetas))
+	columnOrder := make([]string, 0, len(columnMetas))
+	for _, columnMeta := range columnMetas {
+		columnTypes[columnMeta.Name()] = strings.ToUpper(columnMeta.DatabaseTypeName())
+		columnOrder = append(columnOrder, columnMeta.Name())
+	}
+	entry, e := reader.Open(backupTablesDir + table + ".jsonl")
+	if e != nil {
+		return nil, errors.BadInput.Wrap(e, fmt.Sprintf("the backup of table %s is missing", table))
+	}
+	defer entry.Close()
+
+	dialect := tx.Dialect()
+	quotedTable := quoteBackupIdentifier(dialect, table)
+	err = tx.Exec("DELETE FROM " + quotedTable)
+	if err != nil {
+		return nil, err
+	}
+	restored := &RestoredTable{Name: table}
+	var columns []string
+	batch := make([][]interface{}, 0)
+	batchSize := backupInsertBatchSize
+	decoder := json.NewDecoder(entry)
+	decoder.UseNumber()
+	for decoder.More() {
+		row := make(map[string]interface{})
+		if e := decoder.Decode(&row); e != nil {
+			return nil, errors.BadInput.Wrap(e, fmt.Sprintf("the backup of table %s is malformed", table))
+		}
+		if columns == nil {
+			// the rows share the same columns, the ones removed from the table are dropped
+			for _, column := range columnOrder {
+				if _, ok := row[column]; ok {
+					columns = append(columns, column)
+				}
+			}
+			for column := range row {
+				if _, ok := columnTypes[column]; !ok {
+					restored.DroppedColumns = append(restored.DroppedColumns, column)
+				}
+			}
+			sort.Strings(restored.DroppedColumns)
+			if len(columns) > 0 && len(columns)*batchSize > backupMaxPlaceholders {
+				batchSize = backupMaxPlaceholders / len(columns)
+			}
+		}
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			values[i] = coerceBackupValue(row[column], columnTypes[column])
+		}
+		batch = append(batch, values)
+		if len(batch) >= batchSize {
+			err = insertBackupRows(tx, quotedTable, columns, batch)
+			if err != nil {
+				return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to restore table %s", table))
+			}
+			restored.Rows += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		err = insertBackupRows(tx, quotedTable, columns, batch)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to restore table %s", table))
+		}
+		restored.Rows += len(batch)
+	}
+	if dialect == "postgres" && columnTypes["id"] != "" {
+		err = resetBackupSequence(tx, table, quotedTable)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return restored, nil
+}
+
+func insertBackupRows(tx dal.Transaction, quotedTable string, columns []string, rows [][]interface{}) errors.Error {
+	if len(columns) == 0 {
+		return nil
+	}
+	quotedColumns := make([]string, len(columns))
+	for i, column := range columns {
+		quotedColumns[i] = quoteBackupIdentifier(tx.Dialect(), column)
+	}
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+	placeholders := make([]string, len(rows))
+	params := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		placeholders[i] = placeholder
+		params = append(params, row...)
+	}
+	return tx.Exec(
+		fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", quotedTable, strings.Join(quotedColumns, ","), strings.Join(placeholders, ",")),
+		params...,
+	)
+}
+
+// resetBackupSequence moves the sequence of the auto increment id past the restored rows
+func resetBackupSequence(tx dal.Transaction, table, quotedTable string) errors.Error {
+	cursor, err := tx.RawCursor("SELECT pg_get_serial_sequence(?, 'id')", table)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	var sequence sql.NullString
+	if cursor.Next() {
+		if e := cursor.Scan(&sequence); e != nil {
+			return errors.Convert(e)
+		}
+	}
+	if !sequence.Valid {
+		return nil
+	}
+	return tx.Exec(fmt.Sprintf("SELECT setval(?, (SELECT COALESCE(MAX(id), 0) + 1 FROM %s), false)", quotedTable), sequence.String)
+}
+
+func quoteBackupIdentifier(dialect, name string) string {
+	if dialect == "postgres" {
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// coerceBackupValue converts the decoded json value into the type of the column
+func coerceBackupValue(value interface{}, columnType string) interface{} {
+	isBool := columnType == "BOOL" || columnType == "BOOLEAN"
+	switch v := value.(type) {
+	case json.Number:
+		if i, e := v.Int64(); e == nil {
+			if isBool {
+				return i != 0
+			}
+			return i
+		}
+		if u, e := strconv.ParseUint(v.String(), 10, 64); e == nil {
+			return u
+		}
+		f, _ := v.Float64()
+		return f
+	case bool:
+		if !isBool && strings.Contains(columnType, "INT") {
+			if v {
+				return 1
+			}
+			return 0
+		}
+	case string:
+		if strings.Contains(columnType, "TIME") || strings.Contains(columnType, "DATE") {
+			if t, e := time.Parse(time.RFC3339Nano, v); e == nil {
+				return t
+			}
+		}
+	case map[string]interface{}, []interface{}:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+	return value
+}
+
+// wipeDatabase drops all tables except the ones holding the lock of the instance
+func wipeDatabase() errors.Error {
+	var query string
+	if db.Dialect() == "postgres" {
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'"
+	} else if db.Dialect() == "sqlite" {
+		query = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"
+	} else {
+		query = "SHOW TABLES"
+	}
+	cursor, err := db.RawCursor(query)
+	if err != nil {
+		return err
+	}
+	tables := make([]interface{}, 0)
+	for cursor.Next() {
+		var table string
+		if e := cursor.Scan(&table); e != nil {
+			cursor.Close()
+			return errors.Convert(e)
+		}
+		if !backupPreservedTables[table] {
+			tables = append(tables, table)
+		}
+	}
+	cursor.Close()
+	if len(tables) == 0 {
+		return nil
+	}
+	return db.DropTables(tables...)
+}
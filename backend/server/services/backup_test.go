@@ -0,0 +1,57 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerceBackupValue(t *testing.T) {
+	assert.Equal(t, int64(42), coerceBackupValue(json.Number("42"), "BIGINT"))
+	assert.Equal(t, uint64(18446744073709551615), coerceBackupValue(json.Number("18446744073709551615"), "BIGINT UNSIGNED"))
+	assert.Equal(t, 1.5, coerceBackupValue(json.Number("1.5"), "DOUBLE"))
+	assert.Equal(t, true, coerceBackupValue(json.Number("1"), "BOOL"))
+	assert.Equal(t, 1, coerceBackupValue(true, "TINYINT"))
+	assert.Equal(t, false, coerceBackupValue(false, "BOOLEAN"))
+	assert.Equal(t, `{"a":1}`, coerceBackupValue(map[string]interface{}{"a": 1}, "JSON"))
+	assert.Equal(t, "2023-01-01T00:00:00Z", coerceBackupValue("2023-01-01T00:00:00Z", "VARCHAR"))
+	assert.Nil(t, coerceBackupValue(nil, "DATETIME"))
+
+	parsed, ok := coerceBackupValue("2023-01-02T03:04:05.123+08:00", "DATETIME").(time.Time)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2023, 1, 1, 19, 4, 5, 123000000, time.UTC), parsed.UTC())
+	_, ok = coerceBackupValue("2023-01-02T03:04:05Z", "TIMESTAMPTZ").(time.Time)
+	assert.True(t, ok)
+}
+
+func TestSameBackupMigrations(t *testing.T) {
+	a := []BackupMigration{{ScriptName: "A", ScriptVersion: 1}, {ScriptName: "B", ScriptVersion: 2}}
+	assert.True(t, sameBackupMigrations(a, []BackupMigration{{ScriptName: "B", ScriptVersion: 2}, {ScriptName: "A", ScriptVersion: 1}}))
+	assert.False(t, sameBackupMigrations(a, a[:1]))
+	assert.False(t, sameBackupMigrations(a, []BackupMigration{{ScriptName: "A", ScriptVersion: 1}, {ScriptName: "B", ScriptVersion: 3}}))
+}
+
+func TestQuoteBackupIdentifier(t *testing.T) {
+	assert.Equal(t, "`_devlake_pipelines`", quoteBackupIdentifier("mysql", "_devlake_pipelines"))
+	assert.Equal(t, `"table"`, quoteBackupIdentifier("postgres", "table"))
+	assert.Equal(t, `"a""b"`, quoteBackupIdentifier("postgres", `a"b`))
+}
@@ -46,6 +46,8 @@ type BlueprintQuery struct {
 	Label    string `form:"label"`
 	// isManual must be omitted or `null` for type to take effect
 	Type string `form:"type" enums:"ALL,MANUAL,DAILY,WEEKLY,MONTHLY,CUSTOM" validate:"oneof=ALL MANUAL DAILY WEEKLY MONTHLY CUSTOM"`
+	// ProjectNames restricts the result to blueprints of the given projects, nil means no restriction
+	ProjectNames []string `form:"-"`
 }
 
 type BlueprintJob struct {
@@ -66,21 +68,26 @@ func (bj BlueprintJob) Run() {
 	}
 }
 
-// CreateBlueprint accepts a Blueprint instance and insert it to database
-func CreateBlueprint(blueprint *models.Blueprint) errors.Error {
-	_, err := saveBlueprint(blueprint)
+// CreateBlueprint accepts a Blueprint instance and insert it to database, principal is nil when RBAC is disabled
+func CreateBlueprint(principal *models.Principal, blueprint *models.Blueprint) errors.Error {
+	err := authorizeBlueprint(principal, blueprint, nil)
+	if err != nil {
+		return err
+	}
+	_, err = saveBlueprint(blueprint)
 	return err
 }
 
 // GetBlueprints returns a paginated list of Blueprints based on `query`
 func GetBlueprints(query *BlueprintQuery, shouldSanitize bool) ([]*models.Blueprint, int64, errors.Error) {
 	blueprints, count, err := bpManager.GetDbBlueprints(&services.GetBlueprintQuery{
-		Enable:      query.Enable,
-		IsManual:    query.IsManual,
-		Label:       query.Label,
-		SkipRecords: query.GetSkip(),
-		PageSize:    query.GetPageSize(),
-		Type:        query.Type,
+		Enable:       query.Enable,
+		IsManual:     query.IsManual,
+		Label:        query.Label,
+		SkipRecords:  query.GetSkip(),
+		PageSize:     query.GetPageSize(),
+		Type:         query.Type,
+		ProjectNames: query.ProjectNames,
 	})
 	if err != nil {
 		return nil, 0, err
@@ -199,7 +206,7 @@ func saveBlueprint(blueprint *models.Blueprint) (*models.Blueprint, errors.Error
 }
 
 // PatchBlueprint FIXME ...
-func PatchBlueprint(id uint64, body map[string]interface{}) (*models.Blueprint, errors.Error) {
+func PatchBlueprint(principal *models.Principal, id uint64, body map[string]interface{}) (*models.Blueprint, errors.Error) {
 	// load record from db
 	blueprint, err := GetBlueprint(id, false)
 	if err != nil {
@@ -207,6 +214,7 @@ func PatchBlueprint(id uint64, body map[string]interface{}) (*models.Blueprint,
 	}
 
 	originMode := blueprint.Mode
+	originRefs := blueprintRefs(blueprint)
 	err = helper.DecodeMapStruct(body, blueprint, true)
 	if err != nil {
 		return nil, err
@@ -224,6 +232,11 @@ func PatchBlueprint(id uint64, body map[string]interface{}) (*models.Blueprint,
 	if blueprint.SyncPolicy.TimeAfter != nil && blueprint.SyncPolicy.TimeAfter.IsZero() {
 		blueprint.SyncPolicy.TimeAfter = nil
 	}
+	// the project and connections may be changed by the body as well
+	err = authorizeBlueprint(principal, blueprint, originRefs)
+	if err != nil {
+		return nil, err
+	}
 
 	blueprint, err = saveBlueprint(blueprint)
 	if err != nil {
@@ -280,7 +293,10 @@ func ReloadBlueprints() (err errors.Error) {
 			return err
 		}
 	}
-	cronManager.Start()
+	// in HA mode, only the elected scheduler triggers blueprints
+	if !haEnabled() || haIsLeader() {
+		cronManager.Start()
+	}
 	logger.Info("total %d blueprints were scheduled", len(blueprints))
 	return nil
 }
@@ -0,0 +1,942 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/srvhelper"
+	"gopkg.in/yaml.v3"
+)
+
+const configDocumentVersion = 1
+
+const (
+	CONFIG_ACTION_CREATE    = "create"
+	CONFIG_ACTION_UPDATE    = "update"
+	CONFIG_ACTION_UNCHANGED = "unchanged"
+)
+
+// references to secrets in the form of `${ENV_NAME}`, only environment variables with the prefix could be referred
+// so that other settings of the server wouldn't be leaked into connections
+var configSecretRefPattern = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+const CONFIG_SECRET_ENV_PREFIX = "DEVLAKE_CONFIG_"
+
+var configEnvNameInvalidChars = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// keys maintained by the framework instead of the document
+var configConnectionIgnoredKeys = []string{"id", "name", "createdAt", "updatedAt"}
+var configScopeConfigIgnoredKeys = []string{"id", "name", "connectionId", "createdAt", "updatedAt"}
+var configScopeIgnoredKeys = []string{
+	"connectionId", "scopeConfigId", "createdAt", "updatedAt",
+	"_raw_data_params", "_raw_data_table", "_raw_data_id", "_raw_data_remark",
+}
+
+// ConfigDocument describes connections, scope configs, scopes, projects and blueprints declaratively,
+// resources are identified by names so the document can be applied to another DevLake instance
+type ConfigDocument struct {
+	Version     int                 `json:"version"`
+	Connections []*ConfigConnection `json:"connections,omitempty" validate:"dive"`
+	Projects    []*ConfigProject    `json:"projects,omitempty" validate:"dive"`
+	Blueprints  []*ConfigBlueprint  `json:"blueprints,omitempty" validate:"dive"`
+}
+
+type ConfigConnection struct {
+	Plugin       string                 `json:"plugin" validate:"required"`
+	Name         string                 `json:"name" validate:"required"`
+	Settings     map[string]interface{} `json:"settings,omitempty"`
+	ScopeConfigs []*ConfigScopeConfig   `json:"scopeConfigs,omitempty" validate:"dive"`
+	Scopes       []*ConfigScope         `json:"scopes,omitempty" validate:"dive"`
+}
+
+type ConfigScopeConfig struct {
+	Name     string                 `json:"name" validate:"required"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+type ConfigScope struct {
+	ScopeId     string                 `json:"scopeId" validate:"required"`
+	ScopeConfig string                 `json:"scopeConfig,omitempty"`
+	Settings    map[string]interface{} `json:"settings,omitempty"`
+}
+
+type ConfigProject struct {
+	Name        string               `json:"name" validate:"required"`
+	Description string               `json:"description,omitempty"`
+	Metrics     []*models.BaseMetric `json:"metrics,omitempty"`
+	Blueprint   *ConfigBlueprint     `json:"blueprint,omitempty"`
+}
+
+type ConfigBlueprint struct {
+	Name        string                       `json:"name"`
+	Mode        string                       `json:"mode" validate:"omitempty,oneof=NORMAL ADVANCED"`
+	Enable      bool                         `json:"enable"`
+	CronConfig  string                       `json:"cronConfig"`
+	IsManual    bool                         `json:"isManual"`
+	Priority    int                          `json:"priority,omitempty"`
+	Labels      []string                     `json:"labels,omitempty"`
+	SyncPolicy  models.SyncPolicy            `json:"syncPolicy"`
+	Plan        models.PipelinePlan          `json:"plan,omitempty"`
+	BeforePlan  models.PipelinePlan          `json:"beforePlan,omitempty"`
+	AfterPlan   models.PipelinePlan          `json:"afterPlan,omitempty"`
+	Connections []*ConfigBlueprintConnection `json:"connections,omitempty" validate:"dive"`
+}
+
+// ConfigBlueprintConnection refers to a connection and its scopes by names instead of ids
+type ConfigBlueprintConnection struct {
+	Plugin     string   `json:"plugin" validate:"required"`
+	Connection string   `json:"connection" validate:"required"`
+	Scopes     []string `json:"scopes,omitempty"`
+}
+
+// ConfigChange is the outcome of reconciling one resource of the document
+type ConfigChange struct {
+	Kind       string               `json:"kind"`
+	PluginName string               `json:"pluginName,omitempty"`
+	Parent     string               `json:"parent,omitempty"`
+	Name       string               `json:"name"`
+	Action     string               `json:"action"`
+	Changes    []models.AuditChange `json:"changes,omitempty"`
+}
+
+type ConfigApplyResult struct {
+	DryRun  bool            `json:"dryRun"`
+	Changes []*ConfigChange `json:"changes"`
+}
+
+type ConfigExportQuery struct {
+	Format string `form:"format"`
+}
+
+type ConfigApplyQuery struct {
+	DryRun bool `form:"dryRun"`
+}
+
+// configProjectState is the part of a project managed by the document
+type configProjectState struct {
+	Description string               `json:"description,omitempty"`
+	Metrics     []*models.BaseMetric `json:"metrics,omitempty"`
+}
+
+// ParseConfigDocument decodes the document from YAML or JSON
+func ParseConfigDocument(data []byte) (*ConfigDocument, errors.Error) {
+	var raw interface{}
+	err := yaml.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid configuration document")
+	}
+	blob, err := json.Marshal(raw)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid configuration document")
+	}
+	doc := &ConfigDocument{}
+	err = json.Unmarshal(blob, doc)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid configuration document")
+	}
+	return doc, nil
+}
+
+// MarshalConfigDocumentYaml encodes the document into YAML with fields in the same order as JSON
+func MarshalConfigDocumentYaml(doc *ConfigDocument) ([]byte, errors.Error) {
+	blob, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error encoding configuration document")
+	}
+	// JSON is a subset of YAML, decoding into a node keeps the order of fields
+	var node yaml.Node
+	err = yaml.Unmarshal(blob, &node)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error encoding configuration document")
+	}
+	resetYamlStyle(&node)
+	blob, err = yaml.Marshal(&node)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error encoding configuration document")
+	}
+	return blob, nil
+}
+
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
+
+// ExportConfig dumps all connections, scope configs, scopes, projects and blueprints into a document,
+// secrets of connections are replaced with references to environment variables
+func ExportConfig() (*ConfigDocument, errors.Error) {
+	sources := newConfigPluginSources()
+	doc := &ConfigDocument{
+		Version:     configDocumentVersion,
+		Connections: make([]*ConfigConnection, 0),
+		Projects:    make([]*ConfigProject, 0),
+		Blueprints:  make([]*ConfigBlueprint, 0),
+	}
+	for _, pluginName := range sources.exportedPluginNames() {
+		src := sources.helpers[pluginName]
+		connections, err := src.GetAllConnections()
+		if err != nil {
+			return nil, err
+		}
+		for _, connection := range connections {
+			configConnection, err := exportConfigConnection(src, connection)
+			if err != nil {
+				return nil, err
+			}
+			doc.Connections = append(doc.Connections, configConnection)
+		}
+	}
+
+	projects := make([]*models.Project, 0)
+	err := db.All(&projects, dal.Orderby("name"))
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		projectOutput, err := makeProjectOutput(project, false)
+		if err != nil {
+			return nil, err
+		}
+		state := toConfigProjectState(projectOutput.Description, projectOutput.Metrics)
+		configProject := &ConfigProject{Name: project.Name, Description: state.Description, Metrics: state.Metrics}
+		if projectOutput.Blueprint != nil {
+			configProject.Blueprint, err = sources.toConfigBlueprint(projectOutput.Blueprint)
+			if err != nil {
+				return nil, err
+			}
+		}
+		doc.Projects = append(doc.Projects, configProject)
+	}
+
+	blueprints := make([]*models.Blueprint, 0)
+	err = db.All(&blueprints, dal.Where("project_name = ''"), dal.Orderby("id"))
+	if err != nil {
+		return nil, err
+	}
+	for _, bp := range blueprints {
+		blueprint, err := GetBlueprint(bp.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		configBlueprint, err := sources.toConfigBlueprint(blueprint)
+		if err != nil {
+			return nil, err
+		}
+		doc.Blueprints = append(doc.Blueprints, configBlueprint)
+	}
+	return doc, nil
+}
+
+func exportConfigConnection(src *srvhelper.PluginSourceSrvHelper, connection plugin.ToolLayerConnection) (*ConfigConnection, errors.Error) {
+	settings, err := toAuditSnapshot(connection)
+	if err != nil {
+		return nil, err
+	}
+	name := getConfigModelName(connection)
+	encrypted := make(map[string]bool)
+	for _, field := range srvhelper.GetSecretFields(connection) {
+		encrypted[field] = true
+	}
+	for field := range getConfigSecretFields(connection, settings) {
+		if !encrypted[field] {
+			// references are resolved for encrypted fields only, others are left out and kept as is when applying
+			delete(settings, field)
+		} else if value, ok := settings[field].(string); ok && value != "" {
+			settings[field] = fmt.Sprintf("${%s}", getConfigSecretEnvName(src.GetPluginName(), name, field))
+		}
+	}
+	configConnection := &ConfigConnection{
+		Plugin:   src.GetPluginName(),
+		Name:     name,
+		Settings: omitConfigKeys(settings, configConnectionIgnoredKeys),
+	}
+
+	scopeConfigs, err := src.GetScopeConfigsByConnectionId(connection.ConnectionId())
+	if err != nil {
+		return nil, err
+	}
+	scopeConfigNames := make(map[uint64]string)
+	for _, scopeConfig := range scopeConfigs {
+		settings, err := toAuditSnapshot(scopeConfig)
+		if err != nil {
+			return nil, err
+		}
+		name := getConfigModelName(scopeConfig)
+		scopeConfigNames[scopeConfig.ScopeConfigId()] = name
+		configConnection.ScopeConfigs = append(configConnection.ScopeConfigs, &ConfigScopeConfig{
+			Name:     name,
+			Settings: omitConfigKeys(settings, configScopeConfigIgnoredKeys),
+		})
+	}
+
+	scopes, err := src.GetScopesByConnectionId(connection.ConnectionId())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].ScopeId() < scopes[j].ScopeId() })
+	for _, scope := range scopes {
+		settings, err := toAuditSnapshot(scope)
+		if err != nil {
+			return nil, err
+		}
+		configConnection.Scopes = append(configConnection.Scopes, &ConfigScope{
+			ScopeId:     scope.ScopeId(),
+			ScopeConfig: scopeConfigNames[scope.ScopeScopeConfigId()],
+			Settings:    omitConfigKeys(settings, configScopeIgnoredKeys),
+		})
+	}
+	return configConnection, nil
+}
+
+// ApplyConfig reconciles resources in the document with the database by names, resources which are not in the
+// document are left untouched. Nothing is written in dry-run mode while the changes are reported all the same.
+// Applying stops at the first error, since every step is idempotent, applying the fixed document again is safe.
+func ApplyConfig(doc *ConfigDocument, dryRun bool) (*ConfigApplyResult, errors.Error) {
+	if doc.Version != 0 && doc.Version != configDocumentVersion {
+		return nil, errors.BadInput.New(fmt.Sprintf("unsupported configuration document version %d", doc.Version))
+	}
+	if err := VerifyStruct(doc); err != nil {
+		return nil, err
+	}
+	applier := &configApplier{
+		sources:  newConfigPluginSources(),
+		dryRun:   dryRun,
+		declared: make(map[string]bool),
+		result:   &ConfigApplyResult{DryRun: dryRun, Changes: make([]*ConfigChange, 0)},
+	}
+	for _, connection := range doc.Connections {
+		applier.declared[connection.Plugin+"/"+connection.Name] = true
+	}
+	for _, connection := range doc.Connections {
+		if err := applier.applyConnection(connection); err != nil {
+			return nil, err
+		}
+	}
+	for _, project := range doc.Projects {
+		if err := applier.applyProject(project); err != nil {
+			return nil, err
+		}
+	}
+	for _, blueprint := range doc.Blueprints {
+		if blueprint.Name == "" {
+			return nil, errors.BadInput.New("name of blueprint is required")
+		}
+		existing := &models.Blueprint{}
+		err := db.First(existing, dal.Where("name = ? AND project_name = ''", blueprint.Name))
+		if err != nil {
+			if !db.IsErrorNotFound(err) {
+				return nil, err
+			}
+			existing = nil
+		} else {
+			existing, err = GetBlueprint(existing.ID, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := applier.applyBlueprint(blueprint, "", existing); err != nil {
+			return nil, err
+		}
+	}
+	return applier.result, nil
+}
+
+type configApplier struct {
+	sources *configPluginSources
+	dryRun  bool
+	// connections declared in the document, which might not be created yet in dry-run mode
+	declared map[string]bool
+	result   *ConfigApplyResult
+}
+
+func (a *configApplier) applyConnection(cfg *ConfigConnection) errors.Error {
+	src, err := a.sources.get(cfg.Plugin)
+	if err != nil {
+		return err
+	}
+	existing, err := a.sources.findConnection(cfg.Plugin, func(connection plugin.ToolLayerConnection) bool {
+		return getConfigModelName(connection) == cfg.Name
+	})
+	if err != nil {
+		return err
+	}
+	connection := src.NewConnection()
+	var before map[string]interface{}
+	if existing != nil {
+		if before, err = toAuditSnapshot(existing); err != nil {
+			return err
+		}
+		copyConfigModel(connection, existing)
+	}
+	settings := newConfigSettings(cfg.Settings, configConnectionIgnoredKeys)
+	if err = resolveConfigSecrets(connection, settings, existing != nil); err != nil {
+		return errors.BadInput.Wrap(err, fmt.Sprintf("connection %s of plugin %s", cfg.Name, cfg.Plugin))
+	}
+	settings["name"] = cfg.Name
+	if err = overlayConfigModel(connection, settings); err != nil {
+		return err
+	}
+	after, err := toAuditSnapshot(connection)
+	if err != nil {
+		return err
+	}
+	secretFields := getConfigSecretFields(connection, after)
+	change := a.record(models.AUDIT_RESOURCE_CONNECTION, cfg.Plugin, "", cfg.Name,
+		omitConfigKeys(before, []string{"id"}), omitConfigKeys(after, []string{"id"}), secretFields)
+	if !a.dryRun && change.Action != CONFIG_ACTION_UNCHANGED {
+		if err = src.Save(connection); err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("failed to save connection %s of plugin %s", cfg.Name, cfg.Plugin))
+		}
+	}
+	connectionId := connection.ConnectionId()
+
+	// scope configs are referred by scopes with names
+	scopeConfigIds := make(map[string]uint64)
+	scopeConfigNames := make(map[uint64]string)
+	existingScopeConfigs := make(map[string]plugin.ToolLayerScopeConfig)
+	if connectionId != 0 {
+		scopeConfigs, err := src.GetScopeConfigsByConnectionId(connectionId)
+		if err != nil {
+			return err
+		}
+		for _, scopeConfig := range scopeConfigs {
+			name := getConfigModelName(scopeConfig)
+			existingScopeConfigs[name] = scopeConfig
+			scopeConfigIds[name] = scopeConfig.ScopeConfigId()
+			scopeConfigNames[scopeConfig.ScopeConfigId()] = name
+		}
+	}
+	if len(cfg.ScopeConfigs) > 0 && !src.HasScopeConfig() {
+		return errors.BadInput.New(fmt.Sprintf("plugin %s doesn't support scope configs", cfg.Plugin))
+	}
+	for _, scopeConfigCfg := range cfg.ScopeConfigs {
+		existing := existingScopeConfigs[scopeConfigCfg.Name]
+		scopeConfig := src.NewScopeConfig()
+		var before map[string]interface{}
+		if existing != nil {
+			if before, err = toAuditSnapshot(existing); err != nil {
+				return err
+			}
+			copyConfigModel(scopeConfig, existing)
+		}
+		settings := newConfigSettings(scopeConfigCfg.Settings, configScopeConfigIgnoredKeys)
+		settings["name"] = scopeConfigCfg.Name
+		settings["connectionId"] = connectionId
+		if err = overlayConfigModel(scopeConfig, settings); err != nil {
+			return err
+		}
+		after, err := toAuditSnapshot(scopeConfig)
+		if err != nil {
+			return err
+		}
+		ignoredKeys := []string{"id", "connectionId"}
+		change := a.record(models.AUDIT_RESOURCE_SCOPE_CONFIG, cfg.Plugin, cfg.Name, scopeConfigCfg.Name,
+			omitConfigKeys(before, ignoredKeys), omitConfigKeys(after, ignoredKeys), nil)
+		if !a.dryRun && change.Action != CONFIG_ACTION_UNCHANGED {
+			if err = src.Save(scopeConfig); err != nil {
+				return errors.Default.Wrap(err, fmt.Sprintf("failed to save scope config %s of connection %s", scopeConfigCfg.Name, cfg.Name))
+			}
+		}
+		scopeConfigIds[scopeConfigCfg.Name] = scopeConfig.ScopeConfigId()
+	}
+
+	existingScopes := make(map[string]plugin.ToolLayerScope)
+	if connectionId != 0 {
+		scopes, err := src.GetScopesByConnectionId(connectionId)
+		if err != nil {
+			return err
+		}
+		for _, scope := range scopes {
+			existingScopes[scope.ScopeId()] = scope
+		}
+	}
+	if len(cfg.Scopes) > 0 && !src.HasScope() {
+		return errors.BadInput.New(fmt.Sprintf("plugin %s doesn't support scopes", cfg.Plugin))
+	}
+	for _, scopeCfg := range cfg.Scopes {
+		scopeConfigId, ok := scopeConfigIds[scopeCfg.ScopeConfig]
+		if scopeCfg.ScopeConfig != "" && !ok {
+			return errors.BadInput.New(fmt.Sprintf("scope config %s of scope %s not found", scopeCfg.ScopeConfig, scopeCfg.ScopeId))
+		}
+		existing := existingScopes[scopeCfg.ScopeId]
+		scope := src.NewScope()
+		var before map[string]interface{}
+		if existing != nil {
+			if before, err = toAuditSnapshot(existing); err != nil {
+				return err
+			}
+			before["scopeConfig"] = scopeConfigNames[existing.ScopeScopeConfigId()]
+			copyConfigModel(scope, existing)
+		}
+		settings := newConfigSettings(scopeCfg.Settings, configScopeIgnoredKeys)
+		settings["connectionId"] = connectionId
+		settings["scopeConfigId"] = scopeConfigId
+		if err = overlayConfigModel(scope, settings); err != nil {
+			return err
+		}
+		if scope.ScopeId() != scopeCfg.ScopeId {
+			return errors.BadInput.New(fmt.Sprintf("settings of scope %s must contain the id of the scope", scopeCfg.ScopeId))
+		}
+		after, err := toAuditSnapshot(scope)
+		if err != nil {
+			return err
+		}
+		after["scopeConfig"] = scopeCfg.ScopeConfig
+		change := a.record(models.AUDIT_RESOURCE_SCOPE, cfg.Plugin, cfg.Name, scopeCfg.ScopeId,
+			omitConfigKeys(before, configScopeIgnoredKeys), omitConfigKeys(after, configScopeIgnoredKeys), nil)
+		if !a.dryRun && change.Action != CONFIG_ACTION_UNCHANGED {
+			if err = src.Save(scope); err != nil {
+				return errors.Default.Wrap(err, fmt.Sprintf("failed to save scope %s of connection %s", scopeCfg.ScopeId, cfg.Name))
+			}
+		}
+	}
+	return nil
+}
+
+func (a *configApplier) applyProject(cfg *ConfigProject) errors.Error {
+	project, err := getProjectByName(db, cfg.Name)
+	if err != nil {
+		if err.GetType() != errors.NotFound {
+			return err
+		}
+		project = nil
+	}
+	var before map[string]interface{}
+	var blueprint *models.Blueprint
+	if project != nil {
+		projectOutput, err := makeProjectOutput(project, false)
+		if err != nil {
+			return err
+		}
+		before, err = toAuditSnapshot(toConfigProjectState(projectOutput.Description, projectOutput.Metrics))
+		if err != nil {
+			return err
+		}
+		blueprint = projectOutput.Blueprint
+	}
+	state := toConfigProjectState(cfg.Description, cfg.Metrics)
+	after, err := toAuditSnapshot(state)
+	if err != nil {
+		return err
+	}
+	change := a.record(models.AUDIT_RESOURCE_PROJECT, "", "", cfg.Name, before, after, nil)
+	if !a.dryRun && change.Action != CONFIG_ACTION_UNCHANGED {
+		projectInput := &models.ApiInputProject{
+			BaseProject: models.BaseProject{Name: cfg.Name, Description: state.Description},
+			Metrics:     state.Metrics,
+		}
+		if project == nil {
+			if _, err = CreateProject(projectInput); err != nil {
+				return err
+			}
+			// a default blueprint comes with the new project
+			if blueprint, err = GetBlueprintByProjectName(cfg.Name); err != nil {
+				return err
+			}
+		} else if err = updateConfigProject(project, projectInput); err != nil {
+			return err
+		}
+	}
+	if cfg.Blueprint != nil {
+		return a.applyBlueprint(cfg.Blueprint, cfg.Name, blueprint)
+	}
+	return nil
+}
+
+func updateConfigProject(project *models.Project, projectInput *models.ApiInputProject) (err errors.Error) {
+	tx := db.Begin()
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			if e := tx.Rollback(); e != nil {
+				logger.Error(e, "updateConfigProject: failed to rollback")
+			}
+		}
+	}()
+	project.Description = projectInput.Description
+	err = tx.Update(project)
+	if err != nil {
+		return err
+	}
+	err = refreshProjectMetrics(tx, projectInput)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (a *configApplier) applyBlueprint(cfg *ConfigBlueprint, projectName string, existing *models.Blueprint) errors.Error {
+	desired := normalizeConfigBlueprint(cfg, projectName)
+	for _, connection := range desired.Connections {
+		if a.declared[connection.Plugin+"/"+connection.Connection] {
+			continue
+		}
+		found, err := a.sources.findConnectionByName(connection.Plugin, connection.Connection)
+		if err != nil {
+			return err
+		}
+		if found == nil {
+			return errors.BadInput.New(fmt.Sprintf("connection %s of plugin %s used by blueprint %s not found", connection.Connection, connection.Plugin, desired.Name))
+		}
+	}
+	var before map[string]interface{}
+	if existing != nil {
+		current, err := a.sources.toConfigBlueprint(existing)
+		if err != nil {
+			return err
+		}
+		if before, err = toAuditSnapshot(current); err != nil {
+			return err
+		}
+	}
+	after, err := toAuditSnapshot(desired)
+	if err != nil {
+		return err
+	}
+	change := a.record(models.AUDIT_RESOURCE_BLUEPRINT, "", projectName, desired.Name, before, after, nil)
+	if a.dryRun || change.Action == CONFIG_ACTION_UNCHANGED {
+		return nil
+	}
+	blueprint := &models.Blueprint{}
+	if existing != nil {
+		// the plan of the existing one was sanitized
+		if blueprint, err = GetBlueprint(existing.ID, false); err != nil {
+			return err
+		}
+	}
+	blueprint.Name = desired.Name
+	blueprint.ProjectName = projectName
+	blueprint.Mode = desired.Mode
+	blueprint.Enable = desired.Enable
+	blueprint.CronConfig = desired.CronConfig
+	blueprint.IsManual = desired.IsManual
+	blueprint.Priority = desired.Priority
+	blueprint.Labels = desired.Labels
+	blueprint.SyncPolicy = desired.SyncPolicy
+	blueprint.BeforePlan = desired.BeforePlan
+	blueprint.AfterPlan = desired.AfterPlan
+	if desired.Mode == models.BLUEPRINT_MODE_ADVANCED {
+		blueprint.Plan = desired.Plan
+	}
+	blueprint.Connections = make([]*models.BlueprintConnection, 0, len(desired.Connections))
+	for _, connectionCfg := range desired.Connections {
+		connection, err := a.sources.findConnectionByName(connectionCfg.Plugin, connectionCfg.Connection)
+		if err != nil {
+			return err
+		}
+		if connection == nil {
+			return errors.BadInput.New(fmt.Sprintf("connection %s of plugin %s not found", connectionCfg.Connection, connectionCfg.Plugin))
+		}
+		bpConnection := &models.BlueprintConnection{
+			PluginName:   connectionCfg.Plugin,
+			ConnectionId: connection.ConnectionId(),
+			Scopes:       make([]*models.BlueprintScope, 0, len(connectionCfg.Scopes)),
+		}
+		for _, scopeId := range connectionCfg.Scopes {
+			bpConnection.Scopes = append(bpConnection.Scopes, &models.BlueprintScope{ScopeId: scopeId})
+		}
+		blueprint.Connections = append(blueprint.Connections, bpConnection)
+	}
+	_, err = saveBlueprint(blueprint)
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("failed to save blueprint %s", desired.Name))
+	}
+	return nil
+}
+
+// record appends the change of a resource to the result, secrets are redacted in the same way as audit logs
+func (a *configApplier) record(kind, pluginName, parent, name string, before, after map[string]interface{}, secretFields map[string]bool) *ConfigChange {
+	change := &ConfigChange{
+		Kind:       kind,
+		PluginName: pluginName,
+		Parent:     parent,
+		Name:       name,
+		Action:     CONFIG_ACTION_CREATE,
+		Changes:    ComputeAuditChanges(before, after),
+	}
+	for i := range change.Changes {
+		if !secretFields[change.Changes[i].Path] {
+			continue
+		}
+		if change.Changes[i].Before != nil && change.Changes[i].Before != "" {
+			change.Changes[i].Before = redactedValue
+		}
+		if change.Changes[i].After != nil && change.Changes[i].After != "" {
+			change.Changes[i].After = redactedValue
+		}
+	}
+	if before != nil {
+		change.Action = CONFIG_ACTION_UPDATE
+		if len(change.Changes) == 0 {
+			change.Action = CONFIG_ACTION_UNCHANGED
+			change.Changes = nil
+		}
+	}
+	a.result.Changes = append(a.result.Changes, change)
+	return change
+}
+
+// configPluginSources holds srvhelpers of all plugins managing connections
+type configPluginSources struct {
+	helpers map[string]*srvhelper.PluginSourceSrvHelper
+}
+
+func newConfigPluginSources() *configPluginSources {
+	sources := &configPluginSources{helpers: make(map[string]*srvhelper.PluginSourceSrvHelper)}
+	for pluginName := range plugin.AllPlugins() {
+		if helper, err := srvhelper.NewPluginSourceSrvHelper(basicRes, pluginName); err == nil {
+			sources.helpers[pluginName] = helper
+		}
+	}
+	return sources
+}
+
+func (sources *configPluginSources) get(pluginName string) (*srvhelper.PluginSourceSrvHelper, errors.Error) {
+	helper, ok := sources.helpers[pluginName]
+	if !ok {
+		return nil, errors.BadInput.New(fmt.Sprintf("plugin %s doesn't support configuration-as-code", pluginName))
+	}
+	return helper, nil
+}
+
+// exportedPluginNames returns plugin names in order, plugins sharing the connection table with a former one are skipped
+func (sources *configPluginSources) exportedPluginNames() []string {
+	pluginNames := make([]string, 0, len(sources.helpers))
+	for pluginName := range sources.helpers {
+		pluginNames = append(pluginNames, pluginName)
+	}
+	sort.Strings(pluginNames)
+	tables := make(map[string]bool)
+	exported := make([]string, 0, len(pluginNames))
+	for _, pluginName := range pluginNames {
+		table := sources.helpers[pluginName].ConnectionTable()
+		if !tables[table] {
+			tables[table] = true
+			exported = append(exported, pluginName)
+		}
+	}
+	return exported
+}
+
+func (sources *configPluginSources) findConnection(pluginName string, match func(connection plugin.ToolLayerConnection) bool) (plugin.ToolLayerConnection, errors.Error) {
+	src, err := sources.get(pluginName)
+	if err != nil {
+		return nil, err
+	}
+	connections, err := src.GetAllConnections()
+	if err != nil {
+		return nil, err
+	}
+	for _, connection := range connections {
+		if match(connection) {
+			return connection, nil
+		}
+	}
+	return nil, nil
+}
+
+func (sources *configPluginSources) findConnectionByName(pluginName, name string) (plugin.ToolLayerConnection, errors.Error) {
+	return sources.findConnection(pluginName, func(connection plugin.ToolLayerConnection) bool {
+		return getConfigModelName(connection) == name
+	})
+}
+
+// toConfigBlueprint converts the blueprint into the document form which refers to connections by names
+func (sources *configPluginSources) toConfigBlueprint(blueprint *models.Blueprint) (*ConfigBlueprint, errors.Error) {
+	configBlueprint := &ConfigBlueprint{
+		Name:       blueprint.Name,
+		Mode:       blueprint.Mode,
+		Enable:     blueprint.Enable,
+		CronConfig: blueprint.CronConfig,
+		IsManual:   blueprint.IsManual,
+		Priority:   blueprint.Priority,
+		Labels:     blueprint.Labels,
+		SyncPolicy: blueprint.SyncPolicy,
+		Plan:       blueprint.Plan,
+		BeforePlan: blueprint.BeforePlan,
+		AfterPlan:  blueprint.AfterPlan,
+	}
+	for _, bpConnection := range blueprint.Connections {
+		connectionId := bpConnection.ConnectionId
+		connection, err := sources.findConnection(bpConnection.PluginName, func(connection plugin.ToolLayerConnection) bool {
+			return connection.ConnectionId() == connectionId
+		})
+		if err != nil {
+			return nil, err
+		}
+		if connection == nil {
+			return nil, errors.NotFound.New(fmt.Sprintf("connection %d of plugin %s used by blueprint %s not found", connectionId, bpConnection.PluginName, blueprint.Name))
+		}
+		configConnection := &ConfigBlueprintConnection{
+			Plugin:     bpConnection.PluginName,
+			Connection: getConfigModelName(connection),
+		}
+		for _, scope := range bpConnection.Scopes {
+			configConnection.Scopes = append(configConnection.Scopes, scope.ScopeId)
+		}
+		configBlueprint.Connections = append(configBlueprint.Connections, configConnection)
+	}
+	return normalizeConfigBlueprint(configBlueprint, blueprint.ProjectName), nil
+}
+
+// normalizeConfigBlueprint fills in the defaults so blueprints in the document and the database are comparable
+func normalizeConfigBlueprint(cfg *ConfigBlueprint, projectName string) *ConfigBlueprint {
+	normalized := *cfg
+	if normalized.Name == "" && projectName != "" {
+		normalized.Name = projectName + "-Blueprint"
+	}
+	if normalized.Mode == "" {
+		normalized.Mode = models.BLUEPRINT_MODE_NORMAL
+	}
+	// the plan of normal blueprints is generated from connections
+	if normalized.Mode == models.BLUEPRINT_MODE_NORMAL {
+		normalized.Plan = nil
+	}
+	if normalized.SyncPolicy.TimeAfter != nil {
+		timeAfter := normalized.SyncPolicy.TimeAfter.UTC()
+		normalized.SyncPolicy.TimeAfter = &timeAfter
+	}
+	if len(normalized.Labels) == 0 {
+		normalized.Labels = nil
+	}
+	return &normalized
+}
+
+func toConfigProjectState(description string, metrics []*models.BaseMetric) *configProjectState {
+	state := &configProjectState{Description: description}
+	for _, metric := range metrics {
+		state.Metrics = append(state.Metrics, metric)
+	}
+	sort.Slice(state.Metrics, func(i, j int) bool { return state.Metrics[i].PluginName < state.Metrics[j].PluginName })
+	return state
+}
+
+// resolveConfigSecrets replaces references in encrypted fields of the connection with values of the environment
+// variables, references which can't be resolved keep the current values of the existing connection
+func resolveConfigSecrets(connection interface{}, settings map[string]interface{}, exists bool) errors.Error {
+	secretFields := make(map[string]bool)
+	for _, field := range srvhelper.GetSecretFields(connection) {
+		secretFields[field] = true
+	}
+	for key, value := range settings {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		matches := configSecretRefPattern.FindStringSubmatch(str)
+		if matches == nil {
+			continue
+		}
+		if !secretFields[key] {
+			return errors.BadInput.New(fmt.Sprintf("%s is not a secret field, environment variables could not be referred", key))
+		}
+		if !strings.HasPrefix(matches[1], CONFIG_SECRET_ENV_PREFIX) {
+			return errors.BadInput.New(fmt.Sprintf("environment variable %s referred by %s must be prefixed with %s", matches[1], key, CONFIG_SECRET_ENV_PREFIX))
+		}
+		if resolved, ok := os.LookupEnv(matches[1]); ok {
+			settings[key] = resolved
+		} else if exists {
+			delete(settings, key)
+		} else {
+			return errors.BadInput.New(fmt.Sprintf("environment variable %s referred by %s is not set", matches[1], key))
+		}
+	}
+	return nil
+}
+
+// getConfigSecretFields returns fields encrypted in database along with those named like secrets
+func getConfigSecretFields(model interface{}, snapshot map[string]interface{}) map[string]bool {
+	fields := make(map[string]bool)
+	for _, field := range srvhelper.GetSecretFields(model) {
+		fields[field] = true
+	}
+	for key := range snapshot {
+		if isSensitiveField(key) {
+			fields[key] = true
+		}
+	}
+	return fields
+}
+
+func getConfigSecretEnvName(pluginName, connectionName, field string) string {
+	name := strings.ToUpper(strings.Join([]string{pluginName, connectionName, field}, "_"))
+	return CONFIG_SECRET_ENV_PREFIX + strings.Trim(configEnvNameInvalidChars.ReplaceAllString(name, "_"), "_")
+}
+
+// getConfigModelName returns the name of connections and scope configs
+func getConfigModelName(model interface{}) string {
+	value := reflect.Indirect(reflect.ValueOf(model))
+	name := value.FieldByName("Name")
+	if name.IsValid() && name.Kind() == reflect.String {
+		return name.String()
+	}
+	return ""
+}
+
+// copyConfigModel copies fields of src into dst, both are pointers of the same type
+func copyConfigModel(dst, src interface{}) {
+	reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(src).Elem())
+}
+
+// overlayConfigModel overrides fields of the model with the settings
+func overlayConfigModel(model interface{}, settings map[string]interface{}) errors.Error {
+	blob, err := json.Marshal(settings)
+	if err != nil {
+		return errors.BadInput.Wrap(err, "invalid settings")
+	}
+	err = json.Unmarshal(blob, model)
+	if err != nil {
+		return errors.BadInput.Wrap(err, "invalid settings")
+	}
+	return nil
+}
+
+// newConfigSettings returns a copy of the settings without the keys maintained by the framework
+func newConfigSettings(settings map[string]interface{}, ignoredKeys []string) map[string]interface{} {
+	if settings == nil {
+		return make(map[string]interface{})
+	}
+	return omitConfigKeys(settings, ignoredKeys)
+}
+
+// omitConfigKeys returns a copy of the snapshot without the keys
+func omitConfigKeys(snapshot map[string]interface{}, keys []string) map[string]interface{} {
+	if snapshot == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(snapshot))
+	for key, value := range snapshot {
+		copied[key] = value
+	}
+	for _, key := range keys {
+		delete(copied, key)
+	}
+	return copied
+}
@@ -0,0 +1,135 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfigDocument(t *testing.T) {
+	yamlDoc := `
+version: 1
+connections:
+  - plugin: github
+    name: github-prod
+    settings:
+      endpoint: https://api.github.com/
+      token: ${DEVLAKE_CONFIG_GITHUB_PROD_TOKEN}
+    scopes:
+      - scopeId: "384111310"
+        scopeConfig: default
+projects:
+  - name: devlake
+    blueprint:
+      cronConfig: 0 0 * * *
+      syncPolicy:
+        timeAfter: 2024-01-01T00:00:00Z
+      connections:
+        - plugin: github
+          connection: github-prod
+          scopes: ["384111310"]
+`
+	doc, err := ParseConfigDocument([]byte(yamlDoc))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, doc.Version)
+	assert.Equal(t, "${DEVLAKE_CONFIG_GITHUB_PROD_TOKEN}", doc.Connections[0].Settings["token"])
+	assert.Equal(t, "384111310", doc.Connections[0].Scopes[0].ScopeId)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), doc.Projects[0].Blueprint.SyncPolicy.TimeAfter.UTC())
+	assert.Equal(t, []string{"384111310"}, doc.Projects[0].Blueprint.Connections[0].Scopes)
+
+	jsonDoc := `{"version": 1, "projects": [{"name": "devlake", "description": "dora"}]}`
+	doc, err = ParseConfigDocument([]byte(jsonDoc))
+	assert.Nil(t, err)
+	assert.Equal(t, "dora", doc.Projects[0].Description)
+
+	_, err = ParseConfigDocument([]byte("projects: {name: devlake"))
+	assert.NotNil(t, err)
+}
+
+func TestMarshalConfigDocumentYaml(t *testing.T) {
+	doc := &ConfigDocument{
+		Version: 1,
+		Connections: []*ConfigConnection{
+			{Plugin: "jira", Name: "jira <cloud>", Settings: map[string]interface{}{"password": "${DEVLAKE_CONFIG_JIRA_JIRA_CLOUD_PASSWORD}"}},
+		},
+	}
+	blob, err := MarshalConfigDocumentYaml(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, `version: 1
+connections:
+    - plugin: jira
+      name: jira <cloud>
+      settings:
+        password: ${DEVLAKE_CONFIG_JIRA_JIRA_CLOUD_PASSWORD}
+`, string(blob))
+	parsed, err := ParseConfigDocument(blob)
+	assert.Nil(t, err)
+	assert.Equal(t, doc, parsed)
+}
+
+type configTestConnection struct {
+	Endpoint string `json:"endpoint"`
+	Token    string `json:"token" gorm:"serializer:encdec"`
+	Password string `json:"password" gorm:"serializer:encdec"`
+}
+
+func TestResolveConfigSecrets(t *testing.T) {
+	t.Setenv("DEVLAKE_CONFIG_TEST_TOKEN", "secret")
+	t.Setenv("CONFIG_TEST_SERVER_SECRET", "server secret")
+	connection := &configTestConnection{}
+	settings := map[string]interface{}{
+		"token":    "${DEVLAKE_CONFIG_TEST_TOKEN}",
+		"password": "${DEVLAKE_CONFIG_TEST_MISSING}",
+		"endpoint": "https://${host}/api",
+	}
+	assert.Nil(t, resolveConfigSecrets(connection, settings, true))
+	assert.Equal(t, map[string]interface{}{"token": "secret", "endpoint": "https://${host}/api"}, settings)
+
+	assert.NotNil(t, resolveConfigSecrets(connection, map[string]interface{}{"password": "${DEVLAKE_CONFIG_TEST_MISSING}"}, false))
+	// only secret fields could refer to environment variables with the prefix
+	assert.NotNil(t, resolveConfigSecrets(connection, map[string]interface{}{"token": "${CONFIG_TEST_SERVER_SECRET}"}, true))
+	assert.NotNil(t, resolveConfigSecrets(connection, map[string]interface{}{"endpoint": "${DEVLAKE_CONFIG_TEST_TOKEN}"}, true))
+}
+
+func TestGetConfigSecretEnvName(t *testing.T) {
+	assert.Equal(t, "DEVLAKE_CONFIG_GITHUB_GITHUB_PROD_TOKEN", getConfigSecretEnvName("github", "github-prod", "token"))
+	assert.Equal(t, "DEVLAKE_CONFIG_JIRA_MY_JIRA_CLOUD_SECRETKEY", getConfigSecretEnvName("jira", "My Jira (cloud)", "secretKey"))
+}
+
+func TestNormalizeConfigBlueprint(t *testing.T) {
+	timeAfter := time.Date(2024, 1, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	normalized := normalizeConfigBlueprint(&ConfigBlueprint{
+		Labels:     []string{},
+		Plan:       models.PipelinePlan{{{Plugin: "github"}}},
+		SyncPolicy: models.SyncPolicy{TimeAfter: &timeAfter},
+	}, "devlake")
+	assert.Equal(t, "devlake-Blueprint", normalized.Name)
+	assert.Equal(t, models.BLUEPRINT_MODE_NORMAL, normalized.Mode)
+	assert.Nil(t, normalized.Plan)
+	assert.Nil(t, normalized.Labels)
+	assert.Equal(t, time.UTC, normalized.SyncPolicy.TimeAfter.Location())
+	assert.True(t, timeAfter.Equal(*normalized.SyncPolicy.TimeAfter))
+
+	advanced := normalizeConfigBlueprint(&ConfigBlueprint{Name: "bp", Mode: models.BLUEPRINT_MODE_ADVANCED, Plan: models.PipelinePlan{{{Plugin: "github"}}}}, "")
+	assert.Equal(t, "bp", advanced.Name)
+	assert.Len(t, advanced.Plan, 1)
+}
@@ -0,0 +1,730 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/domaininfo"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	domainQueryDefaultPageSize = 100
+	domainQueryMaxPageSize     = 1000
+)
+
+// tables registered in project_mapping, rows of the other tables belong to projects through them
+var domainProjectMappingTables = map[string]bool{
+	"repos":       true,
+	"boards":      true,
+	"cicd_scopes": true,
+	"cq_projects": true,
+	"qa_projects": true,
+}
+
+// columns referring to rows of tables in project_mapping, in the order of precedence
+var domainProjectMappingColumns = []struct {
+	column string
+	table  string
+}{
+	{"repo_id", "repos"},
+	{"base_repo_id", "repos"},
+	{"board_id", "boards"},
+	{"cicd_scope_id", "cicd_scopes"},
+	{"project_key", "cq_projects"},
+	{"qa_project_id", "qa_projects"},
+}
+
+// DomainQuery describes a read of a domain layer table
+type DomainQuery struct {
+	// comma separated columns, all columns are returned by default
+	Fields string `form:"fields"`
+	// conditions in the form of `column:op:value`, op is one of eq, ne, gt, gte, lt, lte, like, in, null and notnull
+	Filters []string `form:"filter"`
+	// comma separated columns, prefixed with `-` for the descending order
+	Sort     string `form:"sort"`
+	Project  string `form:"project"`
+	Cursor   string `form:"cursor"`
+	PageSize int    `form:"pageSize"`
+	// projects readable by the current user, nil means all projects
+	ReadableProjects []string `form:"-"`
+}
+
+type DomainQueryResult struct {
+	Table      string                   `json:"table"`
+	Rows       []map[string]interface{} `json:"rows"`
+	NextCursor string                   `json:"nextCursor,omitempty"`
+}
+
+type DomainTable struct {
+	Name          string          `json:"name"`
+	ProjectScoped bool            `json:"projectScoped"`
+	Columns       []*DomainColumn `json:"columns"`
+}
+
+type DomainColumn struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Format     string `json:"format,omitempty"`
+	PrimaryKey bool   `json:"primaryKey,omitempty"`
+}
+
+type domainTableSchema struct {
+	name           string
+	fields         map[string]*schema.Field
+	columns        []string
+	primaryKeys    []string
+	scopeCondition *domainScopeCondition
+}
+
+// domainScopeCondition restricts rows of the table to projects, domainProjectsParam in params would be replaced by
+// names of the projects
+type domainScopeCondition struct {
+	sql    string
+	params []interface{}
+}
+
+type domainProjectsParam struct{}
+
+func (condition *domainScopeCondition) where(projects []string) dal.Clause {
+	params := make([]interface{}, len(condition.params))
+	for i, param := range condition.params {
+		if _, ok := param.(domainProjectsParam); ok {
+			param = projects
+		}
+		params[i] = param
+	}
+	return dal.Where(condition.sql, params...)
+}
+
+type domainOrder struct {
+	column string
+	desc   bool
+	// primary keys are never null
+	nullable bool
+}
+
+type domainCursor struct {
+	Sort   string              `json:"s"`
+	Values []domainCursorValue `json:"v"`
+}
+
+// domainCursorValue keeps the type of timestamps which would be strings after a json round trip
+type domainCursorValue struct {
+	Time  *time.Time  `json:"t,omitempty"`
+	Value interface{} `json:"v"`
+}
+
+var domainTableSchemas map[string]*domainTableSchema
+var domainTableNames []string
+var domainTableSchemasOnce sync.Once
+
+func getDomainTableSchemas() map[string]*domainTableSchema {
+	domainTableSchemasOnce.Do(func() {
+		domainTableSchemas = make(map[string]*domainTableSchema)
+		for _, table := range domaininfo.GetDomainTablesInfo() {
+			tableSchema, err := schema.Parse(table, &sync.Map{}, schema.NamingStrategy{})
+			if err != nil {
+				logger.Warn(err, "failed to parse the schema of domain table %s", table.TableName())
+				continue
+			}
+			tableName := table.TableName()
+			domainTable := &domainTableSchema{
+				name:        tableName,
+				fields:      make(map[string]*schema.Field),
+				primaryKeys: tableSchema.PrimaryFieldDBNames,
+			}
+			for _, column := range tableSchema.DBNames {
+				domainTable.fields[column] = tableSchema.FieldsByDBName[column]
+				domainTable.columns = append(domainTable.columns, column)
+			}
+			domainTable.scopeCondition = getDomainScopeCondition(tableName, domainTable.fields)
+			domainTableSchemas[tableName] = domainTable
+			domainTableNames = append(domainTableNames, tableName)
+		}
+		sort.Strings(domainTableNames)
+	})
+	return domainTableSchemas
+}
+
+func getDomainTableSchema(tableName string) (*domainTableSchema, errors.Error) {
+	domainTable, ok := getDomainTableSchemas()[tableName]
+	if !ok {
+		return nil, errors.NotFound.New(fmt.Sprintf("domain table %s not found", tableName))
+	}
+	return domainTable, nil
+}
+
+// getDomainScopeCondition returns the condition restricting rows of the table to projects, nil means the table
+// doesn't belong to projects
+func getDomainScopeCondition(tableName string, fields map[string]*schema.Field) *domainScopeCondition {
+	// `table` is a reserved word, it has to be quoted
+	mappingTableColumn := dal.ClauseColumn{Table: "pm", Name: "table"}
+	inProjects := func(mappingTable string) string {
+		return fmt.Sprintf("(SELECT pm.row_id FROM project_mapping pm WHERE ? = '%s' AND pm.project_name IN ?)", mappingTable)
+	}
+	mapped := func(sql string) *domainScopeCondition {
+		return &domainScopeCondition{sql, []interface{}{mappingTableColumn, domainProjectsParam{}}}
+	}
+	switch tableName {
+	case "commits":
+		return mapped(fmt.Sprintf("commits.sha IN (SELECT rc.commit_sha FROM repo_commits rc WHERE rc.repo_id IN %s)", inProjects("repos")))
+	case "issues":
+		return mapped(fmt.Sprintf("issues.id IN (SELECT bi.issue_id FROM board_issues bi WHERE bi.board_id IN %s)", inProjects("boards")))
+	case "sprints":
+		return mapped(fmt.Sprintf("sprints.id IN (SELECT bs.sprint_id FROM board_sprints bs WHERE bs.board_id IN %s)", inProjects("boards")))
+	case "incidents":
+		return &domainScopeCondition{
+			"EXISTS (SELECT 1 FROM project_mapping pm WHERE ? = ? AND pm.row_id = incidents.scope_id AND pm.project_name IN ?)",
+			[]interface{}{mappingTableColumn, dal.ClauseColumn{Table: "incidents", Name: "table"}, domainProjectsParam{}},
+		}
+	}
+	if domainProjectMappingTables[tableName] {
+		return mapped(fmt.Sprintf("%s.id IN %s", tableName, inProjects(tableName)))
+	}
+	if fields["project_name"] != nil {
+		return &domainScopeCondition{fmt.Sprintf("%s.project_name IN ?", tableName), []interface{}{domainProjectsParam{}}}
+	}
+	for _, mapping := range domainProjectMappingColumns {
+		if fields[mapping.column] != nil {
+			return mapped(fmt.Sprintf("%s.%s IN %s", tableName, mapping.column, inProjects(mapping.table)))
+		}
+	}
+	switch {
+	case fields["pull_request_id"] != nil:
+		return mapped(fmt.Sprintf("%s.pull_request_id IN (SELECT pr.id FROM pull_requests pr WHERE pr.base_repo_id IN %s)", tableName, inProjects("repos")))
+	case fields["issue_id"] != nil:
+		return mapped(fmt.Sprintf("%s.issue_id IN (SELECT bi.issue_id FROM board_issues bi WHERE bi.board_id IN %s)", tableName, inProjects("boards")))
+	case fields["sprint_id"] != nil:
+		return mapped(fmt.Sprintf("%s.sprint_id IN (SELECT bs.sprint_id FROM board_sprints bs WHERE bs.board_id IN %s)", tableName, inProjects("boards")))
+	case fields["pipeline_id"] != nil:
+		return mapped(fmt.Sprintf("%s.pipeline_id IN (SELECT cp.id FROM cicd_pipelines cp WHERE cp.cicd_scope_id IN %s)", tableName, inProjects("cicd_scopes")))
+	case fields["commit_sha"] != nil:
+		return mapped(fmt.Sprintf("%s.commit_sha IN (SELECT rc.commit_sha FROM repo_commits rc WHERE rc.repo_id IN %s)", tableName, inProjects("repos")))
+	}
+	return nil
+}
+
+// GetDomainTables returns all domain layer tables which can be queried
+func GetDomainTables() []*DomainTable {
+	domainTables := getDomainTableSchemas()
+	tables := make([]*DomainTable, 0, len(domainTableNames))
+	for _, tableName := range domainTableNames {
+		tables = append(tables, domainTables[tableName].toDomainTable())
+	}
+	return tables
+}
+
+func (domainTable *domainTableSchema) toDomainTable() *DomainTable {
+	table := &DomainTable{
+		Name:          domainTable.name,
+		ProjectScoped: domainTable.scopeCondition != nil,
+		Columns:       make([]*DomainColumn, 0, len(domainTable.columns)),
+	}
+	for _, column := range domainTable.columns {
+		field := domainTable.fields[column]
+		columnType, format := getDomainColumnType(field)
+		table.Columns = append(table.Columns, &DomainColumn{
+			Name:       column,
+			Type:       columnType,
+			Format:     format,
+			PrimaryKey: field.PrimaryKey,
+		})
+	}
+	return table
+}
+
+// getDomainColumnType returns the OpenAPI type and format of the column
+func getDomainColumnType(field *schema.Field) (string, string) {
+	switch field.DataType {
+	case schema.Bool:
+		return "boolean", ""
+	case schema.Int, schema.Uint:
+		return "integer", "int64"
+	case schema.Float:
+		return "number", "double"
+	case schema.Time:
+		return "string", "date-time"
+	}
+	return "string", ""
+}
+
+// QueryDomainTable reads a page of rows from the domain layer table, rows are limited to readable projects
+func QueryDomainTable(tableName string, query *DomainQuery) (*DomainQueryResult, errors.Error) {
+	domainTable, err := getDomainTableSchema(tableName)
+	if err != nil {
+		return nil, err
+	}
+	result := &DomainQueryResult{Table: tableName, Rows: make([]map[string]interface{}, 0)}
+
+	// restrict rows to projects
+	projects := query.ReadableProjects
+	if query.Project != "" {
+		if projects != nil && !containsString(projects, query.Project) {
+			return nil, errors.Forbidden.New(fmt.Sprintf("no read access to project %s", query.Project))
+		}
+		projects = []string{query.Project}
+	}
+	clauses := []dal.Clause{dal.From(tableName)}
+	if projects != nil {
+		if domainTable.scopeCondition == nil {
+			if query.Project != "" {
+				return nil, errors.BadInput.New(fmt.Sprintf("domain table %s doesn't belong to projects", tableName))
+			}
+			return nil, errors.Forbidden.New(fmt.Sprintf("domain table %s requires read access to all projects", tableName))
+		}
+		if len(projects) == 0 {
+			return result, nil
+		}
+		clauses = append(clauses, domainTable.scopeCondition.where(projects))
+	}
+
+	for _, filter := range query.Filters {
+		clause, err := domainTable.parseFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	orders, err := domainTable.parseSort(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+	sortSignature := makeDomainSortSignature(orders)
+	if query.Cursor != "" {
+		values, err := decodeDomainCursor(query.Cursor, sortSignature)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(orders) {
+			return nil, errors.BadInput.New("invalid cursor")
+		}
+		clauses = append(clauses, makeDomainKeysetClause(tableName, orders, values))
+	}
+
+	// columns for ordering are selected as well to make the cursor
+	fields, err := domainTable.parseFields(query.Fields)
+	if err != nil {
+		return nil, err
+	}
+	selected := append([]string{}, fields...)
+	for _, order := range orders {
+		if !containsString(selected, order.column) {
+			selected = append(selected, order.column)
+		}
+	}
+	selectExprs := make([]string, len(selected))
+	for i, column := range selected {
+		selectExprs[i] = fmt.Sprintf("%s.%s", tableName, column)
+	}
+	orderExprs := make([]string, 0, len(orders)*2)
+	for _, order := range orders {
+		direction := "ASC"
+		if order.desc {
+			direction = "DESC"
+		}
+		// nulls come first in the ascending order and last in the descending order regardless of the database
+		if order.nullable {
+			nullDirection := "DESC"
+			if order.desc {
+				nullDirection = "ASC"
+			}
+			orderExprs = append(orderExprs, fmt.Sprintf("%s.%s IS NULL %s", tableName, order.column, nullDirection))
+		}
+		orderExprs = append(orderExprs, fmt.Sprintf("%s.%s %s", tableName, order.column, direction))
+	}
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = domainQueryDefaultPageSize
+	}
+	if pageSize > domainQueryMaxPageSize {
+		pageSize = domainQueryMaxPageSize
+	}
+	clauses = append(clauses,
+		dal.Select(strings.Join(selectExprs, ", ")),
+		dal.Orderby(strings.Join(orderExprs, ", ")),
+		dal.Limit(pageSize+1),
+	)
+	rows := make([]map[string]interface{}, 0)
+	err = db.All(&rows, clauses...)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("error querying domain table %s", tableName))
+	}
+
+	if len(rows) > pageSize {
+		rows = rows[:pageSize]
+		last := rows[pageSize-1]
+		values := make([]interface{}, len(orders))
+		for i, order := range orders {
+			values[i] = last[order.column]
+		}
+		result.NextCursor, err = encodeDomainCursor(sortSignature, values)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range rows {
+		for column := range row {
+			if !containsString(fields, column) {
+				delete(row, column)
+			}
+		}
+	}
+	result.Rows = rows
+	return result, nil
+}
+
+func (domainTable *domainTableSchema) getField(column string) (*schema.Field, errors.Error) {
+	field, ok := domainTable.fields[column]
+	if !ok {
+		return nil, errors.BadInput.New(fmt.Sprintf("column %s not found in domain table %s", column, domainTable.name))
+	}
+	return field, nil
+}
+
+func (domainTable *domainTableSchema) parseFields(fields string) ([]string, errors.Error) {
+	if strings.TrimSpace(fields) == "" {
+		return domainTable.columns, nil
+	}
+	columns := make([]string, 0)
+	for _, column := range strings.Split(fields, ",") {
+		column = strings.TrimSpace(column)
+		if _, err := domainTable.getField(column); err != nil {
+			return nil, err
+		}
+		if !containsString(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+// parseSort returns the order of rows, primary keys are always appended to make the order total for cursors
+func (domainTable *domainTableSchema) parseSort(sortBy string) ([]*domainOrder, errors.Error) {
+	orders := make([]*domainOrder, 0)
+	sorted := make(map[string]bool)
+	for _, column := range strings.Split(sortBy, ",") {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		order := &domainOrder{column: strings.TrimPrefix(column, "-"), desc: strings.HasPrefix(column, "-")}
+		field, err := domainTable.getField(order.column)
+		if err != nil {
+			return nil, err
+		}
+		if sorted[order.column] {
+			continue
+		}
+		order.nullable = !field.PrimaryKey && !field.NotNull
+		sorted[order.column] = true
+		orders = append(orders, order)
+	}
+	for _, column := range domainTable.primaryKeys {
+		if !sorted[column] {
+			orders = append(orders, &domainOrder{column: column})
+		}
+	}
+	return orders, nil
+}
+
+func (domainTable *domainTableSchema) parseFilter(filter string) (dal.Clause, errors.Error) {
+	parts := strings.SplitN(filter, ":", 3)
+	if len(parts) < 2 {
+		return dal.Clause{}, errors.BadInput.New(fmt.Sprintf("invalid filter %s, it should be in the form of column:op:value", filter))
+	}
+	field, err := domainTable.getField(parts[0])
+	if err != nil {
+		return dal.Clause{}, err
+	}
+	column := fmt.Sprintf("%s.%s", domainTable.name, parts[0])
+	op := parts[1]
+	switch op {
+	case "null":
+		return dal.Where(column + " IS NULL"), nil
+	case "notnull":
+		return dal.Where(column + " IS NOT NULL"), nil
+	}
+	if len(parts) != 3 {
+		return dal.Clause{}, errors.BadInput.New(fmt.Sprintf("value of filter %s is missing", filter))
+	}
+	if op == "in" {
+		values := make([]interface{}, 0)
+		for _, raw := range strings.Split(parts[2], ",") {
+			value, err := convertDomainValue(field, raw)
+			if err != nil {
+				return dal.Clause{}, err
+			}
+			values = append(values, value)
+		}
+		return dal.Where(column+" IN ?", values), nil
+	}
+	if op == "like" {
+		return dal.Where(column+" LIKE ?", parts[2]), nil
+	}
+	operators := map[string]string{"eq": "=", "ne": "<>", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+	operator, ok := operators[op]
+	if !ok {
+		return dal.Clause{}, errors.BadInput.New(fmt.Sprintf("unknown operator %s of filter %s", op, filter))
+	}
+	value, err := convertDomainValue(field, parts[2])
+	if err != nil {
+		return dal.Clause{}, err
+	}
+	return dal.Where(fmt.Sprintf("%s %s ?", column, operator), value), nil
+}
+
+// convertDomainValue converts the value from the query string to the type of the column
+func convertDomainValue(field *schema.Field, raw string) (interface{}, errors.Error) {
+	var value interface{}
+	var err error
+	switch field.DataType {
+	case schema.Bool:
+		value, err = strconv.ParseBool(raw)
+	case schema.Int:
+		value, err = strconv.ParseInt(raw, 10, 64)
+	case schema.Uint:
+		value, err = strconv.ParseUint(raw, 10, 64)
+	case schema.Float:
+		value, err = strconv.ParseFloat(raw, 64)
+	case schema.Time:
+		value, err = time.Parse(time.RFC3339, raw)
+		if err != nil {
+			value, err = time.Parse("2006-01-02", raw)
+		}
+	default:
+		value = raw
+	}
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, fmt.Sprintf("invalid value %s of column %s", raw, field.DBName))
+	}
+	return value, nil
+}
+
+// makeDomainKeysetClause matches rows after the cursor, which are greater in the first differing column of the order
+func makeDomainKeysetClause(tableName string, orders []*domainOrder, values []interface{}) dal.Clause {
+	alternatives := make([]string, 0, len(orders))
+	params := make([]interface{}, 0)
+	for k, order := range orders {
+		conditions := make([]string, 0, k+1)
+		alternativeParams := make([]interface{}, 0)
+		for i := 0; i < k; i++ {
+			column := fmt.Sprintf("%s.%s", tableName, orders[i].column)
+			if values[i] == nil {
+				conditions = append(conditions, column+" IS NULL")
+			} else {
+				conditions = append(conditions, column+" = ?")
+				alternativeParams = append(alternativeParams, values[i])
+			}
+		}
+		column := fmt.Sprintf("%s.%s", tableName, order.column)
+		switch {
+		case values[k] == nil && order.desc:
+			// nulls are the last ones in the descending order
+			continue
+		case values[k] == nil:
+			conditions = append(conditions, column+" IS NOT NULL")
+		case order.desc:
+			conditions = append(conditions, fmt.Sprintf("(%s < ? OR %s IS NULL)", column, column))
+			alternativeParams = append(alternativeParams, values[k])
+		default:
+			conditions = append(conditions, column+" > ?")
+			alternativeParams = append(alternativeParams, values[k])
+		}
+		alternatives = append(alternatives, "("+strings.Join(conditions, " AND ")+")")
+		params = append(params, alternativeParams...)
+	}
+	if len(alternatives) == 0 {
+		return dal.Where("1 = 0")
+	}
+	return dal.Where("("+strings.Join(alternatives, " OR ")+")", params...)
+}
+
+func makeDomainSortSignature(orders []*domainOrder) string {
+	signature := make([]string, len(orders))
+	for i, order := range orders {
+		signature[i] = order.column
+		if order.desc {
+			signature[i] = "-" + order.column
+		}
+	}
+	return strings.Join(signature, ",")
+}
+
+func encodeDomainCursor(sortSignature string, values []interface{}) (string, errors.Error) {
+	cursor := domainCursor{Sort: sortSignature, Values: make([]domainCursorValue, len(values))}
+	for i, value := range values {
+		if t, ok := value.(time.Time); ok {
+			cursor.Values[i].Time = &t
+		} else {
+			cursor.Values[i].Value = value
+		}
+	}
+	blob, err := json.Marshal(cursor)
+	if err != nil {
+		return "", errors.Default.Wrap(err, "error encoding cursor")
+	}
+	return base64.RawURLEncoding.EncodeToString(blob), nil
+}
+
+func decodeDomainCursor(encoded, sortSignature string) ([]interface{}, errors.Error) {
+	blob, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid cursor")
+	}
+	var cursor domainCursor
+	decoder := json.NewDecoder(bytes.NewReader(blob))
+	decoder.UseNumber()
+	if err = decoder.Decode(&cursor); err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid cursor")
+	}
+	if cursor.Sort != sortSignature {
+		return nil, errors.BadInput.New("the cursor was made for another sort order")
+	}
+	values := make([]interface{}, len(cursor.Values))
+	for i, value := range cursor.Values {
+		switch v := value.Value.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				values[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				values[i] = f
+			} else {
+				values[i] = v.String()
+			}
+		default:
+			values[i] = v
+		}
+		if value.Time != nil {
+			values[i] = *value.Time
+		}
+	}
+	return values, nil
+}
+
+// GetDomainOpenApiSpec describes the domain layer query api in OpenAPI 3, schemas are generated from the models
+func GetDomainOpenApiSpec() map[string]interface{} {
+	schemas := make(map[string]interface{})
+	paths := make(map[string]interface{})
+	for _, table := range GetDomainTables() {
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+		for _, column := range table.Columns {
+			property := map[string]interface{}{"type": column.Type}
+			if column.Format != "" {
+				property["format"] = column.Format
+			}
+			if !column.PrimaryKey {
+				property["nullable"] = true
+			} else {
+				required = append(required, column.Name)
+			}
+			properties[column.Name] = property
+		}
+		tableSchema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			tableSchema["required"] = required
+		}
+		schemas[table.Name] = tableSchema
+		description := fmt.Sprintf("Query rows of the domain table %s.", table.Name)
+		if !table.ProjectScoped {
+			description += " The table doesn't belong to projects, reading it requires read access to all projects."
+		}
+		paths["/domainlayer/tables/"+table.Name] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"tags":        []string{"framework/domainlayer"},
+				"summary":     "Query " + table.Name,
+				"description": description,
+				"operationId": "query_" + table.Name,
+				"parameters": []interface{}{
+					map[string]interface{}{"$ref": "#/components/parameters/fields"},
+					map[string]interface{}{"$ref": "#/components/parameters/filter"},
+					map[string]interface{}{"$ref": "#/components/parameters/sort"},
+					map[string]interface{}{"$ref": "#/components/parameters/project"},
+					map[string]interface{}{"$ref": "#/components/parameters/cursor"},
+					map[string]interface{}{"$ref": "#/components/parameters/pageSize"},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "a page of rows",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": map[string]interface{}{
+									"type": "object",
+									"properties": map[string]interface{}{
+										"table":      map[string]interface{}{"type": "string"},
+										"rows":       map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/" + table.Name}},
+										"nextCursor": map[string]interface{}{"type": "string", "description": "absent on the last page"},
+									},
+								},
+							},
+						},
+					},
+					"400": map[string]interface{}{"description": "invalid fields, filters, sort or cursor"},
+					"403": map[string]interface{}{"description": "the project or the table is not readable"},
+				},
+			},
+		}
+	}
+	queryParameter := func(name, description string, schema map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"name": name, "in": "query", "required": false, "description": description, "schema": schema}
+	}
+	stringSchema := map[string]interface{}{"type": "string"}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "DevLake Domain Layer API",
+			"description": "Read-only access to the domain layer tables",
+			"version":     "v1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"parameters": map[string]interface{}{
+				"fields":  queryParameter("fields", "comma separated columns to return, all columns by default", stringSchema),
+				"filter":  queryParameter("filter", "repeatable condition in the form of column:op:value, op is one of eq, ne, gt, gte, lt, lte, like, in (values separated by commas), null and notnull", map[string]interface{}{"type": "array", "items": stringSchema}),
+				"sort":    queryParameter("sort", "comma separated columns, prefixed with - for the descending order", stringSchema),
+				"project": queryParameter("project", "only rows belonging to the project", stringSchema),
+				"cursor":  queryParameter("cursor", "nextCursor of the previous page", stringSchema),
+				"pageSize": queryParameter("pageSize", fmt.Sprintf("rows per page, %d by default and %d at most", domainQueryDefaultPageSize, domainQueryMaxPageSize),
+					map[string]interface{}{"type": "integer", "minimum": 1, "maximum": domainQueryMaxPageSize}),
+			},
+		},
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
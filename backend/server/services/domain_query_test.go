@@ -0,0 +1,193 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainTableSchemas(t *testing.T) {
+	pullRequests, err := getDomainTableSchema("pull_requests")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id"}, pullRequests.primaryKeys)
+	assert.Contains(t, pullRequests.scopeCondition.sql, "pull_requests.base_repo_id IN")
+
+	issues, err := getDomainTableSchema("issues")
+	assert.Nil(t, err)
+	assert.Contains(t, issues.scopeCondition.sql, "board_issues")
+
+	accounts, err := getDomainTableSchema("accounts")
+	assert.Nil(t, err)
+	assert.Nil(t, accounts.scopeCondition)
+
+	_, err = getDomainTableSchema("_devlake_pipelines")
+	assert.Equal(t, errors.NotFound, err.GetType())
+}
+
+func TestDomainQueryParsing(t *testing.T) {
+	pullRequests, _ := getDomainTableSchema("pull_requests")
+
+	clause, err := pullRequests.parseFilter("status:in:MERGED,CLOSED")
+	assert.Nil(t, err)
+	assert.Equal(t, dal.Where("pull_requests.status IN ?", []interface{}{"MERGED", "CLOSED"}), clause)
+
+	clause, err = pullRequests.parseFilter("additions:gte:10")
+	assert.Nil(t, err)
+	assert.Equal(t, dal.Where("pull_requests.additions >= ?", int64(10)), clause)
+
+	clause, err = pullRequests.parseFilter("merged_date:notnull")
+	assert.Nil(t, err)
+	assert.Equal(t, dal.Where("pull_requests.merged_date IS NOT NULL"), clause)
+
+	clause, err = pullRequests.parseFilter("created_date:lt:2024-01-01")
+	assert.Nil(t, err)
+	assert.Equal(t, dal.Where("pull_requests.created_date < ?", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), clause)
+
+	_, err = pullRequests.parseFilter("additions:gte:many")
+	assert.NotNil(t, err)
+	_, err = pullRequests.parseFilter("password:eq:1")
+	assert.NotNil(t, err)
+	_, err = pullRequests.parseFilter("status:regexp:.*")
+	assert.NotNil(t, err)
+
+	orders, err := pullRequests.parseSort("-created_date,title,id")
+	assert.Nil(t, err)
+	assert.Equal(t, []*domainOrder{
+		{column: "created_date", desc: true, nullable: true},
+		{column: "title", nullable: true},
+		{column: "id"},
+	}, orders)
+	assert.Equal(t, "-created_date,title,id", makeDomainSortSignature(orders))
+
+	orders, err = pullRequests.parseSort("")
+	assert.Nil(t, err)
+	assert.Equal(t, []*domainOrder{{column: "id"}}, orders)
+
+	fields, err := pullRequests.parseFields("id, title,id")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id", "title"}, fields)
+	_, err = pullRequests.parseFields("id,1=1")
+	assert.NotNil(t, err)
+}
+
+func TestMakeDomainKeysetClause(t *testing.T) {
+	orders := []*domainOrder{{column: "created_date", desc: true, nullable: true}, {column: "id"}}
+	createdDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, dal.Where(
+		"(((t.created_date < ? OR t.created_date IS NULL)) OR (t.created_date = ? AND t.id > ?))",
+		createdDate, createdDate, "pr1",
+	), makeDomainKeysetClause("t", orders, []interface{}{createdDate, "pr1"}))
+
+	// nulls are the last ones in the descending order
+	assert.Equal(t, dal.Where(
+		"((t.created_date IS NULL AND t.id > ?))",
+		"pr1",
+	), makeDomainKeysetClause("t", orders, []interface{}{nil, "pr1"}))
+
+	orders = []*domainOrder{{column: "merged_date", nullable: true}, {column: "id"}}
+	assert.Equal(t, dal.Where(
+		"((t.merged_date IS NOT NULL) OR (t.merged_date IS NULL AND t.id > ?))",
+		"pr1",
+	), makeDomainKeysetClause("t", orders, []interface{}{nil, "pr1"}))
+}
+
+func TestDomainCursor(t *testing.T) {
+	createdDate := time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)
+	cursor, err := encodeDomainCursor("-created_date,id", []interface{}{createdDate, int64(9007199254740993), "pr1", nil})
+	assert.Nil(t, err)
+
+	values, err := decodeDomainCursor(cursor, "-created_date,id")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{createdDate, int64(9007199254740993), "pr1", nil}, values)
+
+	_, err = decodeDomainCursor(cursor, "id")
+	assert.NotNil(t, err)
+	_, err = decodeDomainCursor("not a cursor", "id")
+	assert.NotNil(t, err)
+}
+
+func TestQueryDomainTableAuthorization(t *testing.T) {
+	_, err := QueryDomainTable("pull_requests", &DomainQuery{Project: "secret", ReadableProjects: []string{"devlake"}})
+	assert.Equal(t, errors.Forbidden, err.GetType())
+
+	_, err = QueryDomainTable("accounts", &DomainQuery{ReadableProjects: []string{"devlake"}})
+	assert.Equal(t, errors.Forbidden, err.GetType())
+
+	_, err = QueryDomainTable("accounts", &DomainQuery{Project: "devlake"})
+	assert.Equal(t, errors.BadInput, err.GetType())
+
+	result, err := QueryDomainTable("pull_requests", &DomainQuery{ReadableProjects: []string{}})
+	assert.Nil(t, err)
+	assert.Empty(t, result.Rows)
+}
+
+func TestQueryDomainTableOfProjects(t *testing.T) {
+	setupTestDb(t, &crossdomain.ProjectMapping{}, &code.Repo{}, &code.PullRequest{}, &ticket.Incident{})
+	for _, mapping := range []*crossdomain.ProjectMapping{
+		{ProjectName: "devlake", Table: "repos", RowId: "repo1"},
+		{ProjectName: "secret", Table: "repos", RowId: "repo2"},
+		{ProjectName: "devlake", Table: "cicd_scopes", RowId: "scope1"},
+	} {
+		assert.Nil(t, db.Create(mapping))
+	}
+	assert.Nil(t, db.Create(&code.Repo{DomainEntity: domainlayer.DomainEntity{Id: "repo1"}, Name: "lake"}))
+	assert.Nil(t, db.Create(&code.Repo{DomainEntity: domainlayer.DomainEntity{Id: "repo2"}, Name: "secret"}))
+	assert.Nil(t, db.Create(&code.PullRequest{DomainEntity: domainlayer.DomainEntity{Id: "pr1"}, BaseRepoId: "repo1"}))
+	assert.Nil(t, db.Create(&code.PullRequest{DomainEntity: domainlayer.DomainEntity{Id: "pr2"}, BaseRepoId: "repo2"}))
+	assert.Nil(t, db.Create(&ticket.Incident{DomainEntity: domainlayer.DomainEntity{Id: "incident1"}, Table: "cicd_scopes", ScopeId: "scope1"}))
+	assert.Nil(t, db.Create(&ticket.Incident{DomainEntity: domainlayer.DomainEntity{Id: "incident2"}, Table: "boards", ScopeId: "scope1"}))
+
+	ids := func(tableName string, query *DomainQuery) []interface{} {
+		query.Fields = "id"
+		result, err := QueryDomainTable(tableName, query)
+		if !assert.Nil(t, err, tableName) {
+			return nil
+		}
+		values := make([]interface{}, 0, len(result.Rows))
+		for _, row := range result.Rows {
+			values = append(values, row["id"])
+		}
+		return values
+	}
+	assert.Equal(t, []interface{}{"repo1"}, ids("repos", &DomainQuery{Project: "devlake"}))
+	assert.Equal(t, []interface{}{"pr1"}, ids("pull_requests", &DomainQuery{ReadableProjects: []string{"devlake"}}))
+	assert.Equal(t, []interface{}{"pr1", "pr2"}, ids("pull_requests", &DomainQuery{}))
+	assert.Equal(t, []interface{}{"incident1"}, ids("incidents", &DomainQuery{Project: "devlake"}))
+	assert.Empty(t, ids("incidents", &DomainQuery{Project: "secret"}))
+}
+
+func TestGetDomainOpenApiSpec(t *testing.T) {
+	spec := GetDomainOpenApiSpec()
+	paths := spec["paths"].(map[string]interface{})
+	assert.Contains(t, paths, "/domainlayer/tables/pull_requests")
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	pullRequest := schemas["pull_requests"].(map[string]interface{})
+	properties := pullRequest["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time", "nullable": true}, properties["merged_date"])
+	assert.Equal(t, map[string]interface{}{"type": "integer", "format": "int64", "nullable": true}, properties["additions"])
+	assert.Equal(t, []string{"id"}, pullRequest["required"])
+}
@@ -0,0 +1,371 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/version"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+)
+
+// HA mode allows multiple devlake instances to share the same database:
+//
+//  1. every instance registers itself as a Worker and refreshes its heartbeat periodically
+//  2. pipelines are claimed row by row with a lease which is extended by the owner's heartbeat
+//  3. the instance holding the `scheduler` lease triggers blueprints and takes over pipelines whose
+//     lease expired, i.e. the owner died, by marking them as TASK_RESUME (or TASK_FAILED when
+//     RESUME_PIPELINES=false) so they would be picked up again by any live instance
+//  4. migrations are executed by the instance holding the `migration` lease
+//
+// NOTE: leases are compared against local clocks, all instances should keep their clocks synchronized
+const (
+	haLeaseScheduler = "scheduler"
+	haLeaseMigration = "migration"
+)
+
+var haLog = logruslog.Global.Nested("ha")
+
+// haWorkerId is the identity of the current instance, it stays empty when HA mode is disabled
+var haWorkerId string
+var haHeartbeatInterval time.Duration
+var haLeaseDuration time.Duration
+var haLeader atomic.Bool
+var haBlueprintsSignature string
+
+func haEnabled() bool {
+	return haWorkerId != ""
+}
+
+func haIsLeader() bool {
+	return haLeader.Load()
+}
+
+func haLeaseExpiry() *time.Time {
+	if !haEnabled() {
+		return nil
+	}
+	expiresAt := time.Now().Add(haLeaseDuration)
+	return &expiresAt
+}
+
+func getHADurations(heartbeatSeconds, leaseSeconds int) (time.Duration, time.Duration, errors.Error) {
+	if heartbeatSeconds == 0 {
+		heartbeatSeconds = 10
+	}
+	if leaseSeconds == 0 {
+		leaseSeconds = 60
+	}
+	if heartbeatSeconds < 0 || leaseSeconds < 0 {
+		return 0, 0, errors.BadInput.New("HA_HEARTBEAT_SECONDS and HA_LEASE_SECONDS should be positive integers")
+	}
+	if leaseSeconds < heartbeatSeconds*2 {
+		return 0, 0, errors.BadInput.New("HA_LEASE_SECONDS should be at least twice as long as HA_HEARTBEAT_SECONDS")
+	}
+	return time.Duration(heartbeatSeconds) * time.Second, time.Duration(leaseSeconds) * time.Second, nil
+}
+
+// initHA registers the current instance as a worker if HA_MODE is enabled
+func initHA() {
+	if !cfg.GetBool("HA_MODE") {
+		return
+	}
+	var err errors.Error
+	haHeartbeatInterval, haLeaseDuration, err = getHADurations(cfg.GetInt("HA_HEARTBEAT_SECONDS"), cfg.GetInt("HA_LEASE_SECONDS"))
+	if err != nil {
+		panic(err)
+	}
+	errors.Must(db.AutoMigrate(&models.Worker{}))
+	errors.Must(db.AutoMigrate(&models.LeaderLease{}))
+	hostName := errors.Must1(os.Hostname())
+	workerId := cfg.GetString("HA_WORKER_ID")
+	if workerId == "" {
+		workerId = fmt.Sprintf("%s-%d-%d", hostName, os.Getpid(), time.Now().Unix())
+	}
+	now := time.Now()
+	errors.Must(db.CreateOrUpdate(&models.Worker{
+		Id:          workerId,
+		HostName:    hostName,
+		Version:     version.Version,
+		StartedAt:   now,
+		HeartbeatAt: now,
+	}))
+	haWorkerId = workerId
+	haLog.Info("HA mode enabled, worker id: %s, heartbeat interval: %v, lease duration: %v", haWorkerId, haHeartbeatInterval, haLeaseDuration)
+}
+
+// acquireLease tries to obtain or renew the named lease for the current worker, returns true if it holds the lease
+func acquireLease(name string) (bool, errors.Error) {
+	now := time.Now()
+	lease := &models.LeaderLease{}
+	err := db.First(lease, dal.Where("name = ?", name))
+	if db.IsErrorNotFound(err) {
+		// another worker might win the race, the update below would sort it out
+		_ = db.Create(&models.LeaderLease{Name: name, Holder: haWorkerId, ExpiresAt: now.Add(haLeaseDuration)})
+	} else if err != nil {
+		return false, err
+	}
+	err = db.UpdateColumns(
+		&models.LeaderLease{},
+		[]dal.DalSet{
+			{ColumnName: "holder", Value: haWorkerId},
+			{ColumnName: "expires_at", Value: now.Add(haLeaseDuration)},
+		},
+		dal.Where("name = ? AND (holder = ? OR expires_at < ?)", name, haWorkerId, now),
+	)
+	if err != nil {
+		return false, err
+	}
+	err = db.First(lease, dal.Where("name = ?", name))
+	if err != nil {
+		return false, err
+	}
+	return lease.Holder == haWorkerId, nil
+}
+
+// releaseLease gives up the named lease so other workers could acquire it immediately
+func releaseLease(name string) errors.Error {
+	return db.UpdateColumns(
+		&models.LeaderLease{},
+		[]dal.DalSet{
+			{ColumnName: "holder", Value: ""},
+			{ColumnName: "expires_at", Value: time.Now()},
+		},
+		dal.Where("name = ? AND holder = ?", name, haWorkerId),
+	)
+}
+
+// withLease blocks until the named lease is obtained, then keeps it renewed while fn is being executed
+func withLease(name string, fn func() errors.Error) errors.Error {
+	for {
+		acquired, err := acquireLease(name)
+		if err != nil {
+			return err
+		}
+		if acquired {
+			break
+		}
+		haLog.Info("lease %s is held by another worker, waiting", name)
+		time.Sleep(haHeartbeatInterval)
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(haHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := acquireLease(name); err != nil {
+					haLog.Error(err, "failed to renew lease %s", name)
+				}
+			}
+		}
+	}()
+	defer func() {
+		close(done)
+		if err := releaseLease(name); err != nil {
+			haLog.Error(err, "failed to release lease %s", name)
+		}
+	}()
+	return fn()
+}
+
+// runHAHeartbeat keeps the current worker alive and performs the scheduler duties when elected
+func runHAHeartbeat() {
+	ticker := time.NewTicker(haHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		haHeartbeat()
+		<-ticker.C
+	}
+}
+
+func haHeartbeat() {
+	now := time.Now()
+	err := db.UpdateColumns(
+		&models.Worker{},
+		[]dal.DalSet{{ColumnName: "heartbeat_at", Value: now}},
+		dal.Where("id = ?", haWorkerId),
+	)
+	if err != nil {
+		haLog.Error(err, "failed to update worker heartbeat")
+	}
+	// extend the leases of pipelines claimed by the current worker
+	err = db.UpdateColumns(
+		&models.Pipeline{},
+		[]dal.DalSet{{ColumnName: "lease_expires_at", Value: now.Add(haLeaseDuration)}},
+		dal.Where("worker_id = ? AND status = ?", haWorkerId, models.TASK_RUNNING),
+	)
+	if err != nil {
+		haLog.Error(err, "failed to extend pipeline leases")
+	}
+	err = cancelOrphanedTasks()
+	if err != nil {
+		haLog.Error(err, "failed to cancel orphaned tasks")
+	}
+
+	leader, err := acquireLease(haLeaseScheduler)
+	if err != nil {
+		haLog.Error(err, "failed to acquire the scheduler lease")
+		leader = false
+	}
+	if leader != haLeader.Swap(leader) {
+		if leader {
+			haLog.Info("worker %s was elected as the scheduler", haWorkerId)
+		} else {
+			haLog.Info("worker %s is no longer the scheduler", haWorkerId)
+			cronManager.Stop()
+		}
+		haBlueprintsSignature = ""
+	}
+	if !leader {
+		return
+	}
+	err = reapExpiredPipelines()
+	if err != nil {
+		haLog.Error(err, "failed to take over expired pipelines")
+	}
+	err = reloadBlueprintsIfChanged()
+	if err != nil {
+		haLog.Error(err, "failed to reload blueprints")
+	}
+}
+
+// cancelOrphanedTasks stops local tasks whose pipeline was cancelled or taken over by another worker
+func cancelOrphanedTasks() errors.Error {
+	taskIds := runningTasks.TaskIds()
+	if len(taskIds) == 0 {
+		return nil
+	}
+	var orphanedIds []uint64
+	err := db.Pluck(
+		"_devlake_tasks.id",
+		&orphanedIds,
+		dal.From(&models.Task{}),
+		dal.Join("LEFT JOIN _devlake_pipelines ON _devlake_pipelines.id = _devlake_tasks.pipeline_id"),
+		dal.Where(
+			"_devlake_tasks.id IN ? AND (_devlake_pipelines.status <> ? OR _devlake_pipelines.worker_id <> ?)",
+			taskIds, models.TASK_RUNNING, haWorkerId,
+		),
+	)
+	if err != nil {
+		return err
+	}
+	for _, taskId := range orphanedIds {
+		haLog.Warn(nil, "task #%d is no longer owned by worker %s, cancelling", taskId, haWorkerId)
+		_ = CancelTask(taskId)
+	}
+	return nil
+}
+
+// reapExpiredPipelines takes over running pipelines whose owner stopped renewing the lease
+func reapExpiredPipelines() (err errors.Error) {
+	status := models.TASK_FAILED
+	if cfg.GetBool("RESUME_PIPELINES") {
+		status = models.TASK_RESUME
+	}
+	now := time.Now()
+	expired := dal.Where("status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)", models.TASK_RUNNING, now)
+	var pipelineIds []uint64
+	err = db.Pluck("id", &pipelineIds, dal.From(&models.Pipeline{}), expired)
+	if err != nil || len(pipelineIds) == 0 {
+		return err
+	}
+	tx := db.Begin()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	err = tx.UpdateColumns(
+		&models.Pipeline{},
+		[]dal.DalSet{
+			{ColumnName: "status", Value: status},
+			{ColumnName: "worker_id", Value: ""},
+			{ColumnName: "lease_expires_at", Value: nil},
+		},
+		dal.Where("id IN ?", pipelineIds),
+		expired,
+	)
+	if err != nil {
+		return err
+	}
+	err = tx.UpdateColumns(
+		&models.Task{},
+		[]dal.DalSet{
+			{ColumnName: "status", Value: status},
+			{ColumnName: "worker_id", Value: ""},
+		},
+		dal.Where("pipeline_id IN ? AND status = ?", pipelineIds, models.TASK_RUNNING),
+	)
+	if err != nil {
+		return err
+	}
+	haLog.Warn(nil, "pipelines %v lost their workers, marked as %s", pipelineIds, status)
+	// workers stopped heartbeating are dead, their pipelines were taken over already
+	return tx.Delete(&models.Worker{}, dal.Where("heartbeat_at < ?", now.Add(-haLeaseDuration)))
+}
+
+// reloadBlueprintsIfChanged reloads cronjobs when blueprints were modified through other workers
+func reloadBlueprintsIfChanged() errors.Error {
+	count, err := db.Count(dal.From(&models.Blueprint{}))
+	if err != nil {
+		return err
+	}
+	var updatedAts []time.Time
+	err = db.Pluck("updated_at", &updatedAts, dal.From(&models.Blueprint{}), dal.Orderby("updated_at DESC"), dal.Limit(1))
+	if err != nil {
+		return err
+	}
+	signature := fmt.Sprintf("%d", count)
+	if len(updatedAts) > 0 {
+		signature += updatedAts[0].UTC().Format(time.RFC3339Nano)
+	}
+	if signature == haBlueprintsSignature {
+		return nil
+	}
+	err = ReloadBlueprints()
+	if err != nil {
+		return err
+	}
+	haBlueprintsSignature = signature
+	return nil
+}
+
+// runningParallelLabelsOfAllWorkers returns `parallel/` labels of pipelines running on any worker
+func runningParallelLabelsOfAllWorkers() ([]string, errors.Error) {
+	labels := []string{}
+	err := db.Pluck(
+		"_devlake_pipeline_labels.name",
+		&labels,
+		dal.From(&models.DbPipelineLabel{}),
+		dal.Join("LEFT JOIN _devlake_pipelines ON _devlake_pipelines.id = _devlake_pipeline_labels.pipeline_id"),
+		dal.Where("_devlake_pipelines.status = ? AND _devlake_pipeline_labels.name LIKE 'parallel/%'", models.TASK_RUNNING),
+	)
+	return labels, err
+}
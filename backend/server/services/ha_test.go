@@ -0,0 +1,43 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHADurations(t *testing.T) {
+	heartbeat, lease, err := getHADurations(0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Second, heartbeat)
+	assert.Equal(t, 60*time.Second, lease)
+
+	heartbeat, lease, err = getHADurations(5, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Second, heartbeat)
+	assert.Equal(t, 10*time.Second, lease)
+
+	_, _, err = getHADurations(40, 0)
+	assert.NotNil(t, err)
+
+	_, _, err = getHADurations(-1, 60)
+	assert.NotNil(t, err)
+}
@@ -118,6 +118,7 @@ func Init() {
 
 	// lock the database to avoid multiple devlake instances from sharing the same one
 	lockDatabase()
+	initHA()
 
 	// now, load the plugins
 	errors.Must(runner.LoadPlugins(basicRes))
@@ -152,7 +153,18 @@ func ExecuteMigration() errors.Error {
 	serviceStatus = SERVICE_STATUS_MIGRATING
 	statusLock.Unlock() // unlock to allow other API requests to check the status
 	// apply all pending migration scripts
-	err := migrator.Execute()
+	var err errors.Error
+	if haEnabled() {
+		err = withLease(haLeaseMigration, func() errors.Error {
+			e := migrator.Refresh()
+			if e != nil {
+				return e
+			}
+			return migrator.Execute()
+		})
+	} else {
+		err = migrator.Execute()
+	}
 	if err != nil {
 		logger.Error(err, "failed to execute migration")
 		return err
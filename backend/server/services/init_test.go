@@ -0,0 +1,46 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/runner"
+	"github.com/apache/incubator-devlake/impls/dalgorm"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// setupTestDb replaces db with a temporary sqlite database having tables of the models
+func setupTestDb(t *testing.T, models ...interface{}) {
+	gormDb, err := runner.MakeDbConnection("sqlite://"+filepath.Join(t.TempDir(), "test.db"), &gorm.Config{})
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	// some models are encrypted
+	dalgorm.Init("services test secret")
+	originDb := db
+	db = dalgorm.NewDalgorm(gormDb)
+	t.Cleanup(func() { db = originDb })
+	for _, model := range models {
+		if !assert.Nil(t, db.AutoMigrate(model)) {
+			t.FailNow()
+		}
+	}
+}
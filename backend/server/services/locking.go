@@ -43,6 +43,15 @@ func lockDatabase() {
 		Version:  version.Version,
 	}
 	errors.Must(db.Create(lockingHistory))
+	// instances in HA mode share the database on purpose, they coordinate with each other by leases
+	if cfg.GetBool("HA_MODE") {
+		return
+	}
+	// a transaction holds the write lock of the whole sqlite database, a never released one would block all writes,
+	// and the database file is not meant to be shared by multiple instances anyway
+	if db.Dialect() == "sqlite" {
+		return
+	}
 	// 2. obtain the lock: using a never released transaction
 	// This prevents multiple devlake instances from sharing the same database by locking the migration history table
 	// However, it would not work if any older devlake instances were already using the database.
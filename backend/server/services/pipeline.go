@@ -76,6 +76,8 @@ type PipelineQuery struct {
 	Pending     int    `form:"pending"`
 	BlueprintId uint64 `uri:"blueprintId" form:"blueprint_id"`
 	Label       string `form:"label"`
+	// ProjectNames restricts the result to pipelines of the given projects, nil means no restriction
+	ProjectNames []string `form:"-"`
 }
 
 func pipelineServiceInit() {
@@ -89,12 +91,10 @@ func pipelineServiceInit() {
 		defaultNotificationService = NewDefaultPipelineNotificationService(notificationEndpoint, notificationSecret)
 	}
 
-	// standalone mode: reset pipeline status
-	if cfg.GetBool("RESUME_PIPELINES") {
-		markInterruptedPipelineAs(models.TASK_RESUME)
-	} else {
-		markInterruptedPipelineAs(models.TASK_FAILED)
+	if haEnabled() {
+		go runHAHeartbeat()
 	}
+	resetInterruptedPipelines()
 
 	// load cronjobs for blueprints
 	errors.Must(ReloadBlueprints())
@@ -113,6 +113,20 @@ func pipelineServiceInit() {
 	}
 }
 
+// resetInterruptedPipelines updates the status of pipelines that were running when the instance went down
+func resetInterruptedPipelines() {
+	if haEnabled() {
+		// HA mode: pipelines of dead workers would be taken over by the scheduler once their leases expired
+		return
+	}
+	if cfg.GetBool("RESUME_PIPELINES") {
+		// standalone mode: reset pipeline status
+		markInterruptedPipelineAs(models.TASK_RESUME)
+	} else {
+		markInterruptedPipelineAs(models.TASK_FAILED)
+	}
+}
+
 func markInterruptedPipelineAs(status string) {
 	errors.Must(db.UpdateColumns(
 		&models.Pipeline{},
@@ -250,30 +264,40 @@ func GetPipelineLogsArchivePath(pipeline *models.Pipeline) (string, errors.Error
 	return archive, err
 }
 
-func dequeuePipeline(runningParallelLabels []string) (pipeline *models.Pipeline, err errors.Error) {
-	txHelper := dbhelper.NewTxHelper(basicRes, &err)
-	defer txHelper.End()
-	tx := txHelper.Begin()
-	// mysql read lock, not sure if it works for postgresql
-	errors.Must(tx.LockTables(dal.LockTables{
-		{Table: "_devlake_pipelines", Exclusive: false},
-		{Table: "_devlake_pipeline_labels", Exclusive: false},
-	}))
+// dequeuePipeline picks the next runnable pipeline and claims it for the current worker, nil if there is none
+func dequeuePipeline(runningParallelLabels []string) (*models.Pipeline, errors.Error) {
+	for {
+		pipeline, err := findRunnablePipeline(runningParallelLabels)
+		if err != nil || pipeline == nil {
+			return nil, err
+		}
+		claimed, err := claimPipeline(pipeline)
+		if err != nil {
+			return nil, err
+		}
+		if claimed {
+			return pipeline, nil
+		}
+		// the pipeline was claimed by another worker in the meantime, try the next one
+	}
+}
+
+func findRunnablePipeline(runningParallelLabels []string) (*models.Pipeline, errors.Error) {
 	// prepare query to find an appropriate pipeline to execute
-	pipeline = &models.Pipeline{}
+	pipeline := &models.Pipeline{}
 	// 1. find out the current highest priority in the queue
 	top_priority := 0
 	var top_priorities []int
 	where_status := dal.Where("status IN ?", []string{models.TASK_CREATED, models.TASK_RERUN, models.TASK_RESUME})
-	err = tx.Pluck("priority", &top_priorities, dal.From(pipeline), where_status, dal.Orderby("priority DESC"), dal.Limit(1))
+	err := db.Pluck("priority", &top_priorities, dal.From(pipeline), where_status, dal.Orderby("priority DESC"), dal.Limit(1))
 	if err != nil {
-		panic(err)
+		return nil, errors.Default.Wrap(err, "dequeue failed")
 	}
 	if len(top_priorities) > 0 {
 		top_priority = top_priorities[0]
 	}
 	// 2. pick the earlier runnable pipeline with the highest priority
-	err = tx.First(pipeline,
+	err = db.First(pipeline,
 		where_status,
 		dal.Where("priority = ?", top_priority),
 		dal.Join(
@@ -289,34 +313,43 @@ func dequeuePipeline(runningParallelLabels []string) (pipeline *models.Pipeline,
 		dal.Orderby("id ASC"),
 		dal.Limit(1),
 	)
-	if err == nil {
-		// mark the pipeline running, now we want a write lock
-		if pipeline.BeganAt == nil {
-			now := time.Now()
-			pipeline.BeganAt = &now
-			globalPipelineLog.Info("resumed pipeline #%d", pipeline.ID)
-		}
-		errors.Must(tx.LockTables(dal.LockTables{{Table: "_devlake_pipelines", Exclusive: true}}))
-		err = tx.UpdateColumns(&models.Pipeline{}, []dal.DalSet{
-			{ColumnName: "status", Value: models.TASK_RUNNING},
-			{ColumnName: "message", Value: ""},
-			{ColumnName: "began_at", Value: pipeline.BeganAt},
-		}, dal.Where("id = ?", pipeline.ID))
-		if err != nil {
-			panic(err)
-		}
-
-		return
+	if db.IsErrorNotFound(err) {
+		return nil, nil
 	}
-	if tx.IsErrorNotFound(err) {
-		pipeline = nil
-		err = nil
-	} else {
+	if err != nil {
 		// log unexpected err
 		globalPipelineLog.Error(err, "dequeue failed")
+		return nil, err
 	}
+	return pipeline, nil
+}
 
-	return
+// claimPipeline marks the pipeline running only if it is still waiting in the queue, so that it would be claimed by
+// exactly one of the workers competing for it without locking the whole table
+func claimPipeline(pipeline *models.Pipeline) (bool, errors.Error) {
+	beganAt := pipeline.BeganAt
+	if beganAt == nil {
+		now := time.Now()
+		beganAt = &now
+	}
+	affected, err := db.UpdateColumnsCount(&models.Pipeline{}, []dal.DalSet{
+		{ColumnName: "status", Value: models.TASK_RUNNING},
+		{ColumnName: "message", Value: ""},
+		{ColumnName: "began_at", Value: beganAt},
+		{ColumnName: "worker_id", Value: haWorkerId},
+		{ColumnName: "lease_expires_at", Value: haLeaseExpiry()},
+	}, dal.Where("id = ? AND status IN ?", pipeline.ID, []string{models.TASK_CREATED, models.TASK_RERUN, models.TASK_RESUME}))
+	if err != nil {
+		return false, errors.Default.Wrap(err, "failed to claim pipeline")
+	}
+	if affected == 0 {
+		return false, nil
+	}
+	if pipeline.BeganAt == nil {
+		pipeline.BeganAt = beganAt
+		globalPipelineLog.Info("resumed pipeline #%d", pipeline.ID)
+	}
+	return true, nil
 }
 
 // RunPipelineInQueue query pipeline from db and run it in a queue
@@ -332,7 +365,17 @@ func RunPipelineInQueue(pipelineMaxParallel int64) {
 		globalPipelineLog.Info("get lock and wait next pipeline")
 		var dbPipeline *models.Pipeline
 		for {
-			dbPipeline, err = dequeuePipeline(runningParallelLabels)
+			parallelLabels := runningParallelLabels
+			if haEnabled() {
+				// pipelines running on other workers must be taken into account as well
+				parallelLabels, err = runningParallelLabelsOfAllWorkers()
+				if err != nil {
+					globalPipelineLog.Error(err, "failed to load running parallel labels")
+					time.Sleep(time.Second)
+					continue
+				}
+			}
+			dbPipeline, err = dequeuePipeline(parallelLabels)
 			if err == nil && dbPipeline != nil {
 				break
 			}
@@ -451,6 +494,7 @@ func CancelPipeline(pipelineId uint64) errors.Error {
 		if err != nil {
 			return errors.Default.Wrap(err, "faile to update pipeline tasks")
 		}
+		publishPipelineStatus(pipeline)
 		// the target pipeline is pending, no running, no need to perform the actual cancel operation
 		return nil
 	}
@@ -461,6 +505,14 @@ func CancelPipeline(pipelineId uint64) errors.Error {
 	if count == 0 {
 		return nil
 	}
+	if haEnabled() && pipeline.Status == models.TASK_RUNNING && pipeline.WorkerId != haWorkerId {
+		// the pipeline is running on another worker, it would stop the tasks on its next heartbeat
+		err = db.UpdateColumn(&models.Pipeline{}, "status", models.TASK_CANCELLED, dal.Where("id = ?", pipelineId))
+		if err != nil {
+			return errors.Default.Wrap(err, "faile to update pipeline")
+		}
+		return nil
+	}
 	for _, pendingTask := range pendingTasks {
 		_ = CancelTask(pendingTask.ID)
 	}
@@ -0,0 +1,55 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/runner"
+)
+
+// number of events buffered for a subscriber before new ones get dropped
+const pipelineEventsBufferSize = 1000
+
+// PipelineEventsSnapshot is the state of a pipeline at the moment its events got subscribed
+type PipelineEventsSnapshot struct {
+	Pipeline *models.Pipeline `json:"pipeline"`
+	Tasks    []*models.Task   `json:"tasks"`
+}
+
+// SubscribePipelineEvents starts listening to the events of the pipeline and returns its current state,
+// the subscription is made before the state gets loaded so no change would be missed in between
+func SubscribePipelineEvents(pipelineId uint64) (*PipelineEventsSnapshot, *runner.PipelineEventSubscription, errors.Error) {
+	subscription := runner.PipelineEvents.Subscribe(pipelineId, pipelineEventsBufferSize)
+	pipeline, err := GetPipeline(pipelineId, true)
+	if err != nil {
+		subscription.Close()
+		return nil, nil, err
+	}
+	tasks, err := GetTasksWithLastStatus(pipelineId, true, db)
+	if err != nil {
+		subscription.Close()
+		return nil, nil, err
+	}
+	return &PipelineEventsSnapshot{Pipeline: pipeline, Tasks: tasks}, subscription, nil
+}
+
+// IsPipelineFinished tells whether the status is a final one
+func IsPipelineFinished(status string) bool {
+	return containsString(models.FinishedTaskStatus, status)
+}
@@ -141,6 +141,12 @@ func GetDbPipelines(query *PipelineQuery) ([]*models.Pipeline, int64, errors.Err
 			dal.Where("pl.name = ?", query.Label),
 		)
 	}
+	if query.ProjectNames != nil {
+		clauses = append(clauses, dal.Where(
+			"_devlake_pipelines.blueprint_id IN (SELECT id FROM _devlake_blueprints WHERE project_name IN ?)",
+			query.ProjectNames,
+		))
+	}
 
 	// count total records
 	count, err := db.Count(clauses...)
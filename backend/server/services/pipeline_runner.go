@@ -72,6 +72,7 @@ func runPipeline(pipelineId uint64) errors.Error {
 		logger:   GetPipelineLogger(ppl),
 		pipeline: ppl,
 	}
+	publishPipelineStatus(ppl)
 	// run
 	err = pipelineRun.runPipelineStandalone()
 	isCancelled := errors.Is(err, context.Canceled)
@@ -82,8 +83,13 @@ func runPipeline(pipelineId uint64) errors.Error {
 	if e != nil {
 		return errors.Default.Wrap(err, fmt.Sprintf("Unable to get pipeline %d.", pipelineId))
 	}
+	if haEnabled() && dbPipeline.WorkerId != haWorkerId {
+		globalPipelineLog.Warn(nil, "pipeline #%d has been taken over by worker %s", pipelineId, dbPipeline.WorkerId)
+		return err
+	}
 	// finished, update database
 	finishedAt := time.Now()
+	dbPipeline.LeaseExpiresAt = nil
 	dbPipeline.FinishedAt = &finishedAt
 	if dbPipeline.BeganAt != nil {
 		dbPipeline.SpentSeconds = int(finishedAt.Unix() - dbPipeline.BeganAt.Unix())
@@ -102,10 +108,21 @@ func runPipeline(pipelineId uint64) errors.Error {
 		globalPipelineLog.Error(err, "update pipeline state failed")
 		return err
 	}
+	publishPipelineStatus(dbPipeline)
 	// notify external webhook
 	return NotifyExternal(pipelineId)
 }
 
+// publishPipelineStatus notifies the subscribers of the pipeline events about its current status
+func publishPipelineStatus(pipeline *models.Pipeline) {
+	runner.PipelineEvents.Publish(&runner.PipelineEvent{
+		Type:       runner.PIPELINE_EVENT_PIPELINE_STATUS,
+		PipelineId: pipeline.ID,
+		Status:     pipeline.Status,
+		Message:    pipeline.Message,
+	})
+}
+
 // ComputePipelineStatus determines pipleline status by its latest(rerun included) tasks statuses
 // 1. TASK_COMPLETED: all tasks were executed sucessfully
 // 2. TASK_FAILED: SkipOnFail=false with failed task(s)
@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimPipelineByCompetingWorkers(t *testing.T) {
+	setupTestDb(t, &models.Pipeline{}, &models.DbPipelineLabel{})
+	assert.Nil(t, db.Create(&models.Pipeline{Name: "queued", Status: models.TASK_CREATED}))
+
+	// both workers found the same pipeline before either of them claimed it
+	first, err := findRunnablePipeline(nil)
+	assert.Nil(t, err)
+	second, err := findRunnablePipeline(nil)
+	assert.Nil(t, err)
+	if !assert.NotNil(t, first) || !assert.NotNil(t, second) {
+		return
+	}
+	assert.Equal(t, first.ID, second.ID)
+
+	claimed, err := claimPipeline(first)
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	claimed, err = claimPipeline(second)
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+
+	pipeline := &models.Pipeline{}
+	assert.Nil(t, db.First(pipeline))
+	assert.Equal(t, models.TASK_RUNNING, pipeline.Status)
+	assert.NotNil(t, pipeline.BeganAt)
+
+	// nothing is left for the loser
+	dequeued, err := dequeuePipeline(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, dequeued)
+}
+
+func TestDequeuePipelineConcurrently(t *testing.T) {
+	setupTestDb(t, &models.Pipeline{}, &models.DbPipelineLabel{})
+	const pipelineCount = 5
+	for i := 0; i < pipelineCount; i++ {
+		assert.Nil(t, db.Create(&models.Pipeline{Name: "queued", Status: models.TASK_CREATED}))
+	}
+
+	var mu sync.Mutex
+	claims := make(map[uint64]int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < 2; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				pipeline, err := dequeuePipeline(nil)
+				assert.Nil(t, err)
+				if pipeline == nil {
+					return
+				}
+				mu.Lock()
+				claims[pipeline.ID]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, claims, pipelineCount)
+	for id, count := range claims {
+		assert.Equal(t, 1, count, "pipeline #%d claimed more than once", id)
+	}
+}
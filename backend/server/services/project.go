@@ -41,6 +41,8 @@ var projectService ProjectService
 type ProjectQuery struct {
 	Pagination
 	Keyword *string `json:"keyword" form:"keyword"`
+	// Names restricts the result to the given projects, nil means no restriction
+	Names []string `json:"-" form:"-"`
 }
 
 func (query *ProjectQuery) GetKeyword() string {
@@ -62,6 +64,9 @@ func GetProjects(query *ProjectQuery) ([]*models.ApiOutputProject, int64, errors
 	if query.Keyword != nil {
 		clauses = append(clauses, dal.Where("LOWER(name) LIKE ?", "%"+query.GetKeyword()+"%"))
 	}
+	if query.Names != nil {
+		clauses = append(clauses, dal.Where("name IN ?", query.Names))
+	}
 
 	count, err := db.Count(clauses...)
 	if err != nil {
@@ -121,7 +126,7 @@ func CreateProject(projectInput *models.ApiInputProject) (*models.ApiOutputProje
 	// create project first
 	project := &models.Project{}
 	project.BaseProject = projectInput.BaseProject
-	err = db.Create(project)
+	err = tx.Create(project)
 	if err != nil {
 		if db.IsDuplicationError(err) {
 			return nil, errors.BadInput.New(fmt.Sprintf("A project with name [%s] already exists", project.Name))
@@ -275,6 +280,16 @@ func PatchProject(name string, body map[string]interface{}) (*models.ApiOutputPr
 		if err != nil {
 			return nil, err
 		}
+
+		// ProjectGrant
+		err = tx.UpdateColumn(
+			&models.ProjectGrant{},
+			"project_name", project.Name,
+			dal.Where("project_name = ?", name),
+		)
+		if err != nil {
+			return nil, err
+		}
 		if projectService != nil {
 			if err := projectService.RenameProject(tx, name, project.Name); err != nil {
 				return nil, err
@@ -399,6 +414,10 @@ func DeleteProject(name string) errors.Error {
 	if err != nil {
 		return errors.Default.Wrap(err, "error deleting project Issue metric")
 	}
+	err = tx.Delete(&models.ProjectGrant{}, dal.Where("project_name = ?", name))
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting project grants")
+	}
 	return tx.Commit()
 }
 
@@ -0,0 +1,462 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/domaininfo"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/srvhelper"
+)
+
+const (
+	PURGE_LAYER_RAW    = "raw"
+	PURGE_LAYER_TOOL   = "tool"
+	PURGE_LAYER_DOMAIN = "domain"
+)
+
+// rows deleted by a single statement, keeps the transaction log and the lock time of a statement bounded
+const purgeBatchSize = 5000
+
+// PurgeQuery selects between previewing and performing a purge
+type PurgeQuery struct {
+	DryRun bool `form:"dryRun"`
+}
+
+// PurgeTableResult counts the rows of a table removed by a purge, Deleted, Batches and DurationMs report the progress
+// of the deletion and stay zero on a dry run
+type PurgeTableResult struct {
+	Table      string `json:"table"`
+	Layer      string `json:"layer"`
+	Rows       int64  `json:"rows"`
+	Deleted    int64  `json:"deleted"`
+	Batches    int    `json:"batches"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// PurgeScopeResult counts the rows of a scope removed by a purge, Skipped tells why the scope was left untouched
+type PurgeScopeResult struct {
+	PluginName   string `json:"pluginName"`
+	ConnectionId uint64 `json:"connectionId"`
+	ScopeId      string `json:"scopeId"`
+	ScopeName    string `json:"scopeName,omitempty"`
+	Rows         int64  `json:"rows"`
+	Skipped      string `json:"skipped,omitempty"`
+}
+
+// PurgeResult is the outcome of a purge, or the preview of it when DryRun is set
+type PurgeResult struct {
+	DryRun     bool                `json:"dryRun"`
+	Scopes     []*PurgeScopeResult `json:"scopes"`
+	Tables     []*PurgeTableResult `json:"tables"`
+	RawRows    int64               `json:"rawRows"`
+	ToolRows   int64               `json:"toolRows"`
+	DomainRows int64               `json:"domainRows"`
+	TotalRows  int64               `json:"totalRows"`
+}
+
+// purgeTarget is the set of rows of a table to be removed
+type purgeTarget struct {
+	table  string
+	layer  string
+	where  string
+	params []interface{}
+	scope  *PurgeScopeResult
+	rows   int64
+}
+
+// PurgeScopeData removes the raw, tool layer and domain layer data collected for the scope while keeping the scope
+// itself, the scope must not be used by any blueprint
+func PurgeScopeData(pluginName string, connectionId uint64, scopeId string, dryRun bool) (*PurgeResult, errors.Error) {
+	if pluginName == "" || connectionId == 0 || scopeId == "" {
+		return nil, errors.BadInput.New("plugin, connectionId and scopeId are required")
+	}
+	blueprintNames, err := getPurgeScopeBlueprints(pluginName, connectionId, scopeId, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(blueprintNames) > 0 {
+		return nil, errors.Conflict.New(fmt.Sprintf("scope %s is still used by blueprints %s", scopeId, strings.Join(blueprintNames, ", ")))
+	}
+	scopeResult := &PurgeScopeResult{PluginName: pluginName, ConnectionId: connectionId, ScopeId: scopeId}
+	targets, err := makeScopePurgeTargets(scopeResult, true)
+	if err != nil {
+		return nil, err
+	}
+	if scopeResult.Skipped != "" {
+		return nil, errors.NotFound.New(scopeResult.Skipped)
+	}
+	return runPurge(targets, []*PurgeScopeResult{scopeResult}, dryRun)
+}
+
+// PurgeProjectData removes the data of the project and of the scopes in its blueprint, scopes shared with other
+// blueprints are skipped. The project and its blueprint are kept
+func PurgeProjectData(projectName string, dryRun bool) (*PurgeResult, errors.Error) {
+	if projectName == "" {
+		return nil, errors.BadInput.New("project name is missing")
+	}
+	_, err := getProjectByName(db, projectName)
+	if err != nil {
+		return nil, err
+	}
+	blueprint, err := GetBlueprintByProjectName(projectName)
+	if err != nil {
+		return nil, err
+	}
+	var targets []*purgeTarget
+	var scopeResults []*PurgeScopeResult
+	if blueprint != nil {
+		unfinished, err := thereAreUnfinishedPipelinesUnderBlueprint(blueprint.ID)
+		if err != nil {
+			return nil, err
+		}
+		if unfinished {
+			return nil, errors.Conflict.New("There are unfinished pipelines in the current project. Its data cannot be purged at this time.")
+		}
+		var bpScopes []*models.BlueprintScope
+		err = db.All(&bpScopes, dal.Where("blueprint_id = ?", blueprint.ID), dal.Orderby("plugin_name, connection_id, scope_id"))
+		if err != nil {
+			return nil, errors.Default.Wrap(err, "error getting scopes of the project")
+		}
+		for _, bpScope := range bpScopes {
+			scopeResult := &PurgeScopeResult{PluginName: bpScope.PluginName, ConnectionId: bpScope.ConnectionId, ScopeId: bpScope.ScopeId}
+			scopeResults = append(scopeResults, scopeResult)
+			blueprintNames, err := getPurgeScopeBlueprints(bpScope.PluginName, bpScope.ConnectionId, bpScope.ScopeId, blueprint.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(blueprintNames) > 0 {
+				scopeResult.Skipped = fmt.Sprintf("shared with blueprints %s", strings.Join(blueprintNames, ", "))
+				continue
+			}
+			scopeTargets, err := makeScopePurgeTargets(scopeResult, false)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, scopeTargets...)
+		}
+	}
+	for _, tabler := range []dal.Tabler{
+		&crossdomain.ProjectMapping{},
+		&crossdomain.ProjectPrMetric{},
+		&crossdomain.ProjectIncidentDeploymentRelationship{},
+	} {
+		targets = append(targets, &purgeTarget{
+			table:  tabler.TableName(),
+			layer:  PURGE_LAYER_DOMAIN,
+			where:  "project_name = ?",
+			params: []interface{}{projectName},
+		})
+	}
+	return runPurge(targets, scopeResults, dryRun)
+}
+
+// getPurgeScopeBlueprints returns names of the blueprints using the scope except for the given one
+func getPurgeScopeBlueprints(pluginName string, connectionId uint64, scopeId string, exceptBlueprintId uint64) ([]string, errors.Error) {
+	var blueprintNames []string
+	err := db.Pluck(
+		"bp.name",
+		&blueprintNames,
+		dal.From("_devlake_blueprint_scopes bs"),
+		dal.Join("LEFT JOIN _devlake_blueprints bp ON bp.id = bs.blueprint_id"),
+		dal.Where(
+			"bs.plugin_name = ? AND bs.connection_id = ? AND bs.scope_id = ? AND bs.blueprint_id != ?",
+			pluginName, connectionId, scopeId, exceptBlueprintId,
+		),
+		dal.Orderby("bp.name"),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error getting blueprints of the scope")
+	}
+	return blueprintNames, nil
+}
+
+// makeScopePurgeTargets lists the rows belonging to the scope: raw rows by params, tool and domain rows by their
+// RawDataOrigin and the domain entity of the scope by the id generated by didgen
+func makeScopePurgeTargets(scopeResult *PurgeScopeResult, withMappings bool) ([]*purgeTarget, errors.Error) {
+	pluginName := scopeResult.PluginName
+	src, err := srvhelper.NewPluginSourceSrvHelper(basicRes, pluginName)
+	if err != nil {
+		scopeResult.Skipped = fmt.Sprintf("plugin %s is not supported", pluginName)
+		return nil, nil
+	}
+	if !src.HasScope() {
+		scopeResult.Skipped = fmt.Sprintf("plugin %s doesn't manage scopes", pluginName)
+		return nil, nil
+	}
+	scope, err := src.GetScope(scopeResult.ConnectionId, scopeResult.ScopeId)
+	if err != nil {
+		return nil, err
+	}
+	if scope == nil {
+		scopeResult.Skipped = fmt.Sprintf("scope %s of %s connection %d not found", scopeResult.ScopeId, pluginName, scopeResult.ConnectionId)
+		return nil, nil
+	}
+	scopeResult.ScopeName = scope.ScopeName()
+	rawDataParams := plugin.MarshalScopeParams(scope.ScopeParams())
+	scopeDomainId := getScopeDomainId(scope)
+
+	var targets []*purgeTarget
+	allTables, err := db.AllTables()
+	if err != nil {
+		return nil, err
+	}
+	// plugins sharing the scope table, i.e. github_graphql, collect data of the same scopes
+	otherPluginNames := make([]string, 0)
+	for name, meta := range plugin.AllPlugins() {
+		other, ok := meta.(plugin.PluginSource)
+		if !ok || other.Scope() == nil || reflect.ValueOf(other.Scope()).IsNil() {
+			continue
+		}
+		if other.Scope().TableName() != scope.TableName() {
+			otherPluginNames = append(otherPluginNames, name)
+		}
+	}
+	rawTables := getPluginRawTables(allTables, pluginName, otherPluginNames)
+	for _, table := range rawTables {
+		targets = append(targets, &purgeTarget{table: table, layer: PURGE_LAYER_RAW, where: "params = ?", params: []interface{}{rawDataParams}})
+	}
+	pluginMeta, err := plugin.GetPlugin(pluginName)
+	if err != nil {
+		return nil, err
+	}
+	if pluginModel, ok := pluginMeta.(plugin.PluginModel); ok {
+		for _, toolModel := range pluginModel.GetTablesInfo() {
+			if _, isScope := toolModel.(plugin.ToolLayerScope); isScope || !purgeHasField(toolModel, "RawDataParams") {
+				continue
+			}
+			targets = append(targets, &purgeTarget{table: toolModel.TableName(), layer: PURGE_LAYER_TOOL, where: "_raw_data_params = ?", params: []interface{}{rawDataParams}})
+		}
+	}
+	// rows are matched by the exact raw tables instead of the prefix of the plugin
+	if len(rawTables) > 0 {
+		targets = append(targets, &purgeTarget{
+			table:  models.CollectorLatestState{}.TableName(),
+			layer:  PURGE_LAYER_TOOL,
+			where:  "raw_data_table IN ? AND raw_data_params = ?",
+			params: []interface{}{rawTables, rawDataParams},
+		})
+	}
+	for _, domainModel := range domaininfo.GetDomainTablesInfo() {
+		var conditions []string
+		var params []interface{}
+		if len(rawTables) > 0 && purgeHasField(domainModel, "RawDataParams") {
+			conditions = append(conditions, "(_raw_data_table IN ? AND _raw_data_params = ?)")
+			params = append(params, rawTables, rawDataParams)
+		}
+		if scopeDomainId != "" && purgeHasField(domainModel, "Id") {
+			conditions = append(conditions, "id = ?")
+			params = append(params, scopeDomainId)
+		}
+		if len(conditions) == 0 {
+			continue
+		}
+		targets = append(targets, &purgeTarget{
+			table:  domainModel.TableName(),
+			layer:  PURGE_LAYER_DOMAIN,
+			where:  strings.Join(conditions, " OR "),
+			params: params,
+		})
+	}
+	if withMappings && scopeDomainId != "" {
+		targets = append(targets, &purgeTarget{
+			table:  crossdomain.ProjectMapping{}.TableName(),
+			layer:  PURGE_LAYER_DOMAIN,
+			where:  "row_id = ?",
+			params: []interface{}{scopeDomainId},
+		})
+	}
+	for _, target := range targets {
+		target.scope = scopeResult
+	}
+	return targets, nil
+}
+
+// getPluginRawTables returns raw tables of the plugin, tables prefixed with longer names of other plugins starting
+// with the name of the plugin are excluded, i.e. _raw_bitbucket_server_* for bitbucket
+func getPluginRawTables(allTables []string, pluginName string, otherPluginNames []string) []string {
+	rawTables := make([]string, 0)
+	for _, table := range allTables {
+		if !isRawTableOf(table, pluginName) {
+			continue
+		}
+		owned := true
+		for _, other := range otherPluginNames {
+			if len(other) > len(pluginName) && isRawTableOf(table, other) {
+				owned = false
+				break
+			}
+		}
+		if owned {
+			rawTables = append(rawTables, table)
+		}
+	}
+	return rawTables
+}
+
+func isRawTableOf(table, pluginName string) bool {
+	prefix := "_raw_" + pluginName
+	return table == prefix || strings.HasPrefix(table, prefix+"_")
+}
+
+// getScopeDomainId returns the id of the domain layer entity converted from the scope, i.e. repos.id of a github
+// repo, or an empty string if the scope is not a go model
+func getScopeDomainId(scope plugin.ToolLayerScope) (domainId string) {
+	defer func() {
+		if r := recover(); r != nil {
+			domainId = ""
+		}
+	}()
+	scopeType := reflect.TypeOf(models.UnwrapObject(scope))
+	for scopeType.Kind() == reflect.Ptr {
+		scopeType = scopeType.Elem()
+	}
+	if _, err := plugin.FindPluginNameBySubPkgPath(scopeType.PkgPath()); err != nil {
+		return ""
+	}
+	scopeValue := reflect.Indirect(reflect.ValueOf(models.UnwrapObject(scope)))
+	pkFields := db.GetPrimaryKeyFields(scopeType)
+	pkValues := make([]interface{}, 0, len(pkFields))
+	for _, pkField := range pkFields {
+		pkValues = append(pkValues, scopeValue.FieldByName(pkField.Name).Interface())
+	}
+	return didgen.NewDomainIdGenerator(reflect.New(scopeType).Interface()).Generate(pkValues...)
+}
+
+// runPurge counts the rows of all targets and deletes them in a single transaction unless dryRun is set
+func runPurge(targets []*purgeTarget, scopeResults []*PurgeScopeResult, dryRun bool) (result *PurgeResult, err errors.Error) {
+	result = &PurgeResult{DryRun: dryRun, Scopes: scopeResults, Tables: []*PurgeTableResult{}}
+	if result.Scopes == nil {
+		result.Scopes = []*PurgeScopeResult{}
+	}
+	tx := db
+	if !dryRun {
+		txn := db.Begin()
+		defer func() {
+			if r := recover(); r != nil {
+				err = errors.Default.New(fmt.Sprintf("purge failed: %v", r))
+			}
+			if err != nil {
+				_ = txn.Rollback()
+			} else {
+				err = txn.Commit()
+			}
+		}()
+		tx = txn
+	}
+	tables := make(map[string]*PurgeTableResult)
+	for _, target := range targets {
+		if !tx.HasTable(target.table) {
+			continue
+		}
+		target.rows, err = tx.Count(dal.From(target.table), dal.Where(target.where, target.params...))
+		if err != nil {
+			return nil, errors.Default.Wrap(err, fmt.Sprintf("error counting rows of %s", target.table))
+		}
+		if target.rows == 0 {
+			continue
+		}
+		tableResult, ok := tables[target.table]
+		if !ok {
+			tableResult = &PurgeTableResult{Table: target.table, Layer: target.layer}
+			tables[target.table] = tableResult
+			result.Tables = append(result.Tables, tableResult)
+		}
+		tableResult.Rows += target.rows
+		if !dryRun {
+			err = purgeTable(tx, target, tableResult)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.addRows(target.layer, target.rows)
+	}
+	sort.Slice(result.Tables, func(i, j int) bool { return result.Tables[i].Table < result.Tables[j].Table })
+	for _, target := range targets {
+		if target.scope != nil {
+			target.scope.Rows += target.rows
+		}
+	}
+	return result, nil
+}
+
+// purgeTable deletes the rows of the target batch by batch, the number of batches is derived from the count taken
+// in the same transaction, the progress is accumulated into tableResult
+func purgeTable(tx dal.Dal, target *purgeTarget, tableResult *PurgeTableResult) errors.Error {
+	batchSql := makePurgeBatchSql(tx.Dialect(), target.table, target.where)
+	startedAt := time.Now()
+	defer func() { tableResult.DurationMs += time.Since(startedAt).Milliseconds() }()
+	for deleted := int64(0); deleted < target.rows; {
+		err := tx.Exec(batchSql, target.params...)
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("error purging rows of %s", target.table))
+		}
+		batchRows := target.rows - deleted
+		if batchRows > purgeBatchSize {
+			batchRows = purgeBatchSize
+		}
+		deleted += batchRows
+		tableResult.Deleted += batchRows
+		tableResult.Batches++
+		logger.Info("purging %s: %d/%d rows deleted", target.table, deleted, target.rows)
+	}
+	return nil
+}
+
+// makePurgeBatchSql returns the statement deleting at most purgeBatchSize rows matching the where clause
+func makePurgeBatchSql(dialect, table, where string) string {
+	switch dialect {
+	case "mysql":
+		return fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT %d", table, where, purgeBatchSize)
+	case "postgres":
+		return fmt.Sprintf("DELETE FROM %s WHERE ctid IN (SELECT ctid FROM %s WHERE %s LIMIT %d)", table, table, where, purgeBatchSize)
+	default:
+		return fmt.Sprintf("DELETE FROM %s WHERE rowid IN (SELECT rowid FROM %s WHERE %s LIMIT %d)", table, table, where, purgeBatchSize)
+	}
+}
+
+func (result *PurgeResult) addRows(layer string, rows int64) {
+	switch layer {
+	case PURGE_LAYER_RAW:
+		result.RawRows += rows
+	case PURGE_LAYER_TOOL:
+		result.ToolRows += rows
+	case PURGE_LAYER_DOMAIN:
+		result.DomainRows += rows
+	}
+	result.TotalRows += rows
+}
+
+func purgeHasField(model interface{}, fieldName string) bool {
+	typ := reflect.TypeOf(models.UnwrapObject(model))
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	_, ok := typ.FieldByName(fieldName)
+	return ok
+}
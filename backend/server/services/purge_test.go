@@ -0,0 +1,113 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakePurgeBatchSql(t *testing.T) {
+	assert.Equal(t,
+		"DELETE FROM issues WHERE _raw_data_params = ? LIMIT 5000",
+		makePurgeBatchSql("mysql", "issues", "_raw_data_params = ?"),
+	)
+	assert.Equal(t,
+		"DELETE FROM issues WHERE ctid IN (SELECT ctid FROM issues WHERE _raw_data_params = ? LIMIT 5000)",
+		makePurgeBatchSql("postgres", "issues", "_raw_data_params = ?"),
+	)
+	assert.Equal(t,
+		"DELETE FROM issues WHERE rowid IN (SELECT rowid FROM issues WHERE _raw_data_params = ? LIMIT 5000)",
+		makePurgeBatchSql("sqlite", "issues", "_raw_data_params = ?"),
+	)
+}
+
+func TestPurgeResultAddRows(t *testing.T) {
+	result := &PurgeResult{}
+	result.addRows(PURGE_LAYER_RAW, 3)
+	result.addRows(PURGE_LAYER_TOOL, 2)
+	result.addRows(PURGE_LAYER_DOMAIN, 1)
+	result.addRows(PURGE_LAYER_DOMAIN, 4)
+	assert.Equal(t, &PurgeResult{RawRows: 3, ToolRows: 2, DomainRows: 5, TotalRows: 10}, result)
+}
+
+func TestRunPurgeReportsProgress(t *testing.T) {
+	setupTestDb(t, &crossdomain.ProjectMapping{})
+	originLogger := logger
+	logger = logruslog.Global
+	t.Cleanup(func() { logger = originLogger })
+	for _, mapping := range []*crossdomain.ProjectMapping{
+		{ProjectName: "p1", Table: "repos", RowId: "r1"},
+		{ProjectName: "p1", Table: "repos", RowId: "r2"},
+		{ProjectName: "p2", Table: "repos", RowId: "r3"},
+	} {
+		if !assert.Nil(t, db.Create(mapping)) {
+			t.FailNow()
+		}
+	}
+	makeTargets := func() []*purgeTarget {
+		return []*purgeTarget{
+			{table: "project_mapping", layer: PURGE_LAYER_DOMAIN, where: "project_name = ?", params: []interface{}{"p1"}},
+			{table: "_raw_missing", layer: PURGE_LAYER_RAW, where: "1 = 1"},
+		}
+	}
+
+	result, err := runPurge(makeTargets(), nil, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []*PurgeTableResult{{Table: "project_mapping", Layer: PURGE_LAYER_DOMAIN, Rows: 2}}, result.Tables)
+
+	result, err = runPurge(makeTargets(), nil, false)
+	assert.Nil(t, err)
+	if assert.Len(t, result.Tables, 1) {
+		assert.Equal(t, int64(2), result.Tables[0].Rows)
+		assert.Equal(t, int64(2), result.Tables[0].Deleted)
+		assert.Equal(t, 1, result.Tables[0].Batches)
+	}
+	assert.Equal(t, int64(2), result.DomainRows)
+	count, err := db.Count(dal.From(&crossdomain.ProjectMapping{}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestPurgeHasField(t *testing.T) {
+	assert.True(t, purgeHasField(&code.Repo{}, "RawDataParams"))
+	assert.True(t, purgeHasField(&code.Repo{}, "Id"))
+	assert.True(t, purgeHasField(&crossdomain.ProjectMapping{}, "RawDataParams"))
+	assert.False(t, purgeHasField(&crossdomain.ProjectMapping{}, "Id"))
+}
+
+func TestGetPluginRawTables(t *testing.T) {
+	allTables := []string{
+		"_raw_bitbucket_api_repositories",
+		"_raw_bitbucket_server_api_repositories",
+		"_raw_bitbucketx_api_repositories",
+		"_raw_github_api_issues",
+		"_raw_github_graphql_issues",
+		"bitbucket_repos",
+	}
+	assert.Equal(t, []string{"_raw_bitbucket_api_repositories"}, getPluginRawTables(allTables, "bitbucket", []string{"bitbucket_server", "github"}))
+	assert.Equal(t, []string{"_raw_bitbucket_server_api_repositories"}, getPluginRawTables(allTables, "bitbucket_server", []string{"bitbucket", "github"}))
+	// github_graphql manages the same scopes as github
+	assert.Equal(t, []string{"_raw_github_api_issues", "_raw_github_graphql_issues"}, getPluginRawTables(allTables, "github", []string{"bitbucket", "bitbucket_server"}))
+	assert.Empty(t, getPluginRawTables(allTables, "gitlab", []string{"bitbucket", "bitbucket_server", "github"}))
+}
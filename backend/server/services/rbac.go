@@ -0,0 +1,412 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// RoleAssignmentsQuery used to query role assignments
+type RoleAssignmentsQuery struct {
+	Pagination
+}
+
+// RbacEnabled returns true if role based access control should be enforced
+func RbacEnabled() bool {
+	return cfg.GetBool("RBAC_ENABLED")
+}
+
+func rbacSubjects(user *common.User) []string {
+	subjects := make([]string, 0, 2)
+	if user == nil {
+		return subjects
+	}
+	if user.Name != "" {
+		subjects = append(subjects, user.Name)
+	}
+	if user.Email != "" && user.Email != user.Name {
+		subjects = append(subjects, user.Email)
+	}
+	return subjects
+}
+
+// GetPrincipal loads roles and project grants of the user, everyone is an admin when RBAC is disabled
+func GetPrincipal(user *common.User) (*models.Principal, errors.Error) {
+	principal := &models.Principal{
+		User:         user,
+		ProjectRoles: make(map[string]string),
+	}
+	if !RbacEnabled() {
+		principal.Role = models.ROLE_ADMIN
+		return principal, nil
+	}
+	subjects := rbacSubjects(user)
+	if len(subjects) == 0 {
+		return principal, nil
+	}
+	// admins configured by env are used to bootstrap the role assignments
+	for _, admin := range strings.Split(cfg.GetString("RBAC_ADMINS"), ",") {
+		admin = strings.TrimSpace(admin)
+		for _, subject := range subjects {
+			if admin != "" && admin == subject {
+				principal.Role = models.ROLE_ADMIN
+				return principal, nil
+			}
+		}
+	}
+	var assignments []*models.RoleAssignment
+	err := db.All(&assignments, dal.Where("subject IN ?", subjects))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error finding role assignments")
+	}
+	for _, assignment := range assignments {
+		if assignment.Role == models.ROLE_ADMIN || principal.Role == "" {
+			principal.Role = assignment.Role
+		}
+	}
+	var grants []*models.ProjectGrant
+	err = db.All(&grants, dal.Where("subject IN ?", subjects))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error finding project grants")
+	}
+	for _, grant := range grants {
+		if principal.ProjectRoles[grant.ProjectName] != models.ROLE_MAINTAINER {
+			principal.ProjectRoles[grant.ProjectName] = grant.Role
+		}
+	}
+	return principal, nil
+}
+
+// GetRoleAssignments returns a paginated list of global role assignments
+func GetRoleAssignments(query *RoleAssignmentsQuery) ([]*models.RoleAssignment, int64, errors.Error) {
+	if err := VerifyStruct(query); err != nil {
+		return nil, 0, err
+	}
+	clauses := []dal.Clause{dal.From(&models.RoleAssignment{})}
+	count, err := db.Count(clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error getting DB count of role assignments")
+	}
+	clauses = append(clauses,
+		dal.Orderby("subject"),
+		dal.Offset(query.GetSkip()),
+		dal.Limit(query.GetPageSize()),
+	)
+	assignments := make([]*models.RoleAssignment, 0)
+	err = db.All(&assignments, clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error finding DB role assignments")
+	}
+	return assignments, count, nil
+}
+
+// PutRoleAssignment creates or updates the global role of a subject
+func PutRoleAssignment(user *common.User, input *models.RoleAssignment) (*models.RoleAssignment, errors.Error) {
+	if err := VerifyStruct(input); err != nil {
+		return nil, err
+	}
+	assignment := &models.RoleAssignment{}
+	err := db.First(assignment, dal.Where("subject = ?", input.Subject))
+	if err != nil && !db.IsErrorNotFound(err) {
+		return nil, errors.Default.Wrap(err, "error finding role assignment")
+	}
+	if assignment.ID == 0 {
+		assignment.Subject = input.Subject
+		if user != nil {
+			assignment.Creator = common.Creator{Creator: user.Name, CreatorEmail: user.Email}
+		}
+	}
+	assignment.Role = input.Role
+	err = db.CreateOrUpdate(assignment)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error saving role assignment")
+	}
+	return assignment, nil
+}
+
+// DeleteRoleAssignment revokes the global role of a subject
+func DeleteRoleAssignment(subject string) errors.Error {
+	if subject == "" {
+		return errors.BadInput.New("subject is missing")
+	}
+	return db.Delete(&models.RoleAssignment{}, dal.Where("subject = ?", subject))
+}
+
+// GetProjectGrants returns all grants of the project
+func GetProjectGrants(projectName string) ([]*models.ProjectGrant, errors.Error) {
+	_, err := getProjectByName(db, projectName)
+	if err != nil {
+		return nil, err
+	}
+	grants := make([]*models.ProjectGrant, 0)
+	err = db.All(&grants, dal.Where("project_name = ?", projectName), dal.Orderby("subject"))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error finding project grants")
+	}
+	return grants, nil
+}
+
+// PutProjectGrant creates or updates the role of a subject on the project
+func PutProjectGrant(user *common.User, projectName string, input *models.ProjectGrant) (*models.ProjectGrant, errors.Error) {
+	if err := VerifyStruct(input); err != nil {
+		return nil, err
+	}
+	_, err := getProjectByName(db, projectName)
+	if err != nil {
+		return nil, err
+	}
+	grant := &models.ProjectGrant{}
+	err = db.First(grant, dal.Where("project_name = ? AND subject = ?", projectName, input.Subject))
+	if db.IsErrorNotFound(err) {
+		grant.ProjectName = projectName
+		grant.Subject = input.Subject
+		if user != nil {
+			grant.Creator = common.Creator{Creator: user.Name, CreatorEmail: user.Email}
+		}
+	} else if err != nil {
+		return nil, errors.Default.Wrap(err, "error finding project grant")
+	}
+	grant.Role = input.Role
+	err = db.CreateOrUpdate(grant)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error saving project grant")
+	}
+	return grant, nil
+}
+
+// DeleteProjectGrant revokes the role of a subject on the project
+func DeleteProjectGrant(projectName, subject string) errors.Error {
+	if subject == "" {
+		return errors.BadInput.New("subject is missing")
+	}
+	return db.Delete(&models.ProjectGrant{}, dal.Where("project_name = ? AND subject = ?", projectName, subject))
+}
+
+// GetProjectNameOfBlueprint returns the project the blueprint belongs to, empty for standalone blueprints
+func GetProjectNameOfBlueprint(blueprintId uint64) (string, errors.Error) {
+	blueprint := &models.Blueprint{}
+	err := db.First(blueprint, dal.Select("project_name"), dal.Where("id = ?", blueprintId))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return "", errors.NotFound.New(fmt.Sprintf("blueprint %d not found", blueprintId))
+		}
+		return "", errors.Default.Wrap(err, "error finding blueprint")
+	}
+	return blueprint.ProjectName, nil
+}
+
+// GetProjectNameOfPipeline returns the project the pipeline was created for, empty for adhoc pipelines
+func GetProjectNameOfPipeline(pipelineId uint64) (string, errors.Error) {
+	pipeline := &models.Pipeline{}
+	err := db.First(pipeline, dal.Select("blueprint_id"), dal.Where("id = ?", pipelineId))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return "", errors.NotFound.New(fmt.Sprintf("pipeline %d not found", pipelineId))
+		}
+		return "", errors.Default.Wrap(err, "error finding pipeline")
+	}
+	if pipeline.BlueprintId == 0 {
+		return "", nil
+	}
+	projectName, err := GetProjectNameOfBlueprint(pipeline.BlueprintId)
+	if err != nil && err.GetType() == errors.NotFound {
+		return "", nil
+	}
+	return projectName, err
+}
+
+// GetProjectNameOfTask returns the project the task was created for
+func GetProjectNameOfTask(taskId uint64) (string, errors.Error) {
+	task := &models.Task{}
+	err := db.First(task, dal.Select("pipeline_id"), dal.Where("id = ?", taskId))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return "", errors.NotFound.New(fmt.Sprintf("task %d not found", taskId))
+		}
+		return "", errors.Default.Wrap(err, "error finding task")
+	}
+	return GetProjectNameOfPipeline(task.PipelineId)
+}
+
+// GetConnectionProjects returns names of projects using the connections of the plugin, grouped by connection id
+func GetConnectionProjects(pluginName string) (map[uint64][]string, errors.Error) {
+	var rows []struct {
+		ConnectionId uint64
+		ProjectName  string
+	}
+	err := db.All(
+		&rows,
+		dal.Select("DISTINCT bc.connection_id, bp.project_name"),
+		dal.From("_devlake_blueprint_connections bc"),
+		dal.Join("LEFT JOIN _devlake_blueprints bp ON bp.id = bc.blueprint_id"),
+		dal.Where("bc.plugin_name = ? AND bp.project_name <> ''", pluginName),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error finding projects of connections")
+	}
+	connectionProjects := make(map[uint64][]string)
+	for _, row := range rows {
+		connectionProjects[row.ConnectionId] = append(connectionProjects[row.ConnectionId], row.ProjectName)
+	}
+	return connectionProjects, nil
+}
+
+// CanAccessConnection checks the principal against projects using the connection. Connections not used by any
+// project yet are accessible to maintainers so they could be set up before being added to a blueprint.
+// Reading requires access to any of the projects while writing requires maintaining all of them
+func CanAccessConnection(principal *models.Principal, projectNames []string, write bool) bool {
+	if principal.IsAdmin() {
+		return true
+	}
+	if len(projectNames) == 0 {
+		return principal.IsMaintainer() || (!write && principal.CanReadAll())
+	}
+	for _, projectName := range projectNames {
+		if write && !principal.CanWrite(projectName) {
+			return false
+		}
+		if !write && principal.CanRead(projectName) {
+			return true
+		}
+	}
+	return write
+}
+
+// getScopeProjects returns names of projects using the scopes of the connection, grouped by scope id
+func getScopeProjects(pluginName string, connectionId uint64) (map[string][]string, errors.Error) {
+	var rows []struct {
+		ScopeId     string
+		ProjectName string
+	}
+	err := db.All(
+		&rows,
+		dal.Select("DISTINCT bs.scope_id, bp.project_name"),
+		dal.From("_devlake_blueprint_scopes bs"),
+		dal.Join("LEFT JOIN _devlake_blueprints bp ON bp.id = bs.blueprint_id"),
+		dal.Where("bs.plugin_name = ? AND bs.connection_id = ? AND bp.project_name <> ''", pluginName, connectionId),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error finding projects of scopes")
+	}
+	scopeProjects := make(map[string][]string)
+	for _, row := range rows {
+		scopeProjects[row.ScopeId] = append(scopeProjects[row.ScopeId], row.ProjectName)
+	}
+	return scopeProjects, nil
+}
+
+// blueprintRef is a connection (with empty scopeId) or a scope referenced by a blueprint
+type blueprintRef struct {
+	pluginName   string
+	connectionId uint64
+	scopeId      string
+}
+
+// blueprintRefs collects connections and scopes of the blueprint, including connections of the tasks in its plans
+func blueprintRefs(blueprint *models.Blueprint) map[blueprintRef]bool {
+	refs := make(map[blueprintRef]bool)
+	for _, connection := range blueprint.Connections {
+		refs[blueprintRef{connection.PluginName, connection.ConnectionId, ""}] = true
+		for _, scope := range connection.Scopes {
+			refs[blueprintRef{connection.PluginName, connection.ConnectionId, scope.ScopeId}] = true
+		}
+	}
+	for _, plan := range []models.PipelinePlan{blueprint.Plan, blueprint.BeforePlan, blueprint.AfterPlan} {
+		for _, stage := range plan {
+			for _, task := range stage {
+				if task == nil || task.Options["connectionId"] == nil {
+					continue
+				}
+				connectionId, err := cast.ToUint64E(task.Options["connectionId"])
+				if err == nil && connectionId > 0 {
+					refs[blueprintRef{task.Plugin, connectionId, ""}] = true
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// blueprintProjectsLookup finds projects using connections and scopes, stubbed by tests
+type blueprintProjectsLookup struct {
+	connectionProjects func(pluginName string) (map[uint64][]string, errors.Error)
+	scopeProjects      func(pluginName string, connectionId uint64) (map[string][]string, errors.Error)
+}
+
+// authorizeBlueprint makes sure the principal maintains the project the blueprint is saved to, and is allowed to
+// write every connection and scope not referenced by the blueprint before, so that maintainers of a project couldn't
+// move the blueprint to another project nor pull data sources of other projects into it
+func authorizeBlueprint(principal *models.Principal, blueprint *models.Blueprint, originRefs map[blueprintRef]bool) errors.Error {
+	return checkBlueprintAccess(principal, blueprint, originRefs, blueprintProjectsLookup{GetConnectionProjects, getScopeProjects})
+}
+
+func checkBlueprintAccess(
+	principal *models.Principal,
+	blueprint *models.Blueprint,
+	originRefs map[blueprintRef]bool,
+	lookup blueprintProjectsLookup,
+) errors.Error {
+	if principal == nil || principal.IsAdmin() {
+		return nil
+	}
+	if blueprint.ProjectName == "" || !principal.CanWrite(blueprint.ProjectName) {
+		return errors.Forbidden.New(fmt.Sprintf("permission denied: project %s", blueprint.ProjectName))
+	}
+	connectionProjects := make(map[string]map[uint64][]string)
+	scopeProjects := make(map[blueprintRef]map[string][]string)
+	for ref := range blueprintRefs(blueprint) {
+		if originRefs[ref] {
+			continue
+		}
+		if ref.scopeId == "" {
+			if connectionProjects[ref.pluginName] == nil {
+				projects, err := lookup.connectionProjects(ref.pluginName)
+				if err != nil {
+					return err
+				}
+				connectionProjects[ref.pluginName] = projects
+			}
+			if !CanAccessConnection(principal, connectionProjects[ref.pluginName][ref.connectionId], true) {
+				return errors.Forbidden.New(fmt.Sprintf("permission denied: %s connection %d", ref.pluginName, ref.connectionId))
+			}
+			continue
+		}
+		connectionRef := blueprintRef{ref.pluginName, ref.connectionId, ""}
+		if scopeProjects[connectionRef] == nil {
+			projects, err := lookup.scopeProjects(ref.pluginName, ref.connectionId)
+			if err != nil {
+				return err
+			}
+			scopeProjects[connectionRef] = projects
+		}
+		for _, projectName := range scopeProjects[connectionRef][ref.scopeId] {
+			if !principal.CanWrite(projectName) {
+				return errors.Forbidden.New(fmt.Sprintf("permission denied: %s scope %s of connection %d", ref.pluginName, ref.scopeId, ref.connectionId))
+			}
+		}
+	}
+	return nil
+}
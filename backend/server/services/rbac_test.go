@@ -0,0 +1,88 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBlueprintAccess(t *testing.T) {
+	lookup := blueprintProjectsLookup{
+		connectionProjects: func(pluginName string) (map[uint64][]string, errors.Error) {
+			return map[uint64][]string{1: {"team-a"}, 2: {"team-b"}, 3: {"team-a", "team-b"}}, nil
+		},
+		scopeProjects: func(pluginName string, connectionId uint64) (map[string][]string, errors.Error) {
+			return map[string][]string{"repo-a": {"team-a"}, "repo-b": {"team-b"}}, nil
+		},
+	}
+	principal := &models.Principal{ProjectRoles: map[string]string{"team-a": models.ROLE_MAINTAINER, "team-c": models.ROLE_VIEWER}}
+	connection := func(connectionId uint64, scopeIds ...string) *models.BlueprintConnection {
+		connection := &models.BlueprintConnection{PluginName: "github", ConnectionId: connectionId}
+		for _, scopeId := range scopeIds {
+			connection.Scopes = append(connection.Scopes, &models.BlueprintScope{ScopeId: scopeId})
+		}
+		return connection
+	}
+	origin := &models.Blueprint{ProjectName: "team-a", Connections: []*models.BlueprintConnection{connection(1, "repo-a")}}
+	originRefs := blueprintRefs(origin)
+
+	tests := []struct {
+		name      string
+		blueprint *models.Blueprint
+		allowed   bool
+	}{
+		{"unchanged", origin, true},
+		{"move to a project not maintained", &models.Blueprint{ProjectName: "team-b", Connections: origin.Connections}, false},
+		{"move to a project only viewed", &models.Blueprint{ProjectName: "team-c", Connections: origin.Connections}, false},
+		{"standalone", &models.Blueprint{Connections: origin.Connections}, false},
+		{"add connection of another project", &models.Blueprint{ProjectName: "team-a", Connections: []*models.BlueprintConnection{connection(1), connection(2)}}, false},
+		{"add connection shared with another project", &models.Blueprint{ProjectName: "team-a", Connections: []*models.BlueprintConnection{connection(3)}}, false},
+		{"add unused connection", &models.Blueprint{ProjectName: "team-a", Connections: []*models.BlueprintConnection{connection(1), connection(4)}}, true},
+		{"add scope of another project", &models.Blueprint{ProjectName: "team-a", Connections: []*models.BlueprintConnection{connection(1, "repo-a", "repo-b")}}, false},
+		{"add unused scope", &models.Blueprint{ProjectName: "team-a", Connections: []*models.BlueprintConnection{connection(1, "repo-a", "repo-c")}}, true},
+		{
+			"advanced plan using connection of another project",
+			&models.Blueprint{ProjectName: "team-a", Plan: models.PipelinePlan{{{Plugin: "github", Options: map[string]interface{}{"connectionId": float64(2)}}}}},
+			false,
+		},
+		{
+			"before plan using connection of another project",
+			&models.Blueprint{ProjectName: "team-a", BeforePlan: models.PipelinePlan{{{Plugin: "github", Options: map[string]interface{}{"connectionId": "2"}}}}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		err := checkBlueprintAccess(principal, tt.blueprint, originRefs, lookup)
+		if tt.allowed {
+			assert.Nil(t, err, tt.name)
+		} else if assert.NotNil(t, err, tt.name) {
+			assert.Equal(t, errors.Forbidden, err.GetType(), tt.name)
+		}
+	}
+
+	// new blueprints have no origin
+	assert.Nil(t, checkBlueprintAccess(principal, origin, nil, lookup))
+	assert.NotNil(t, checkBlueprintAccess(principal, &models.Blueprint{ProjectName: "team-a", Connections: []*models.BlueprintConnection{connection(2)}}, nil, lookup))
+	// nobody is checked when RBAC is disabled and admins are allowed to do anything
+	assert.Nil(t, checkBlueprintAccess(nil, &models.Blueprint{ProjectName: "team-b"}, originRefs, lookup))
+	assert.Nil(t, checkBlueprintAccess(&models.Principal{Role: models.ROLE_ADMIN}, &models.Blueprint{ProjectName: "team-b", Connections: []*models.BlueprintConnection{connection(2, "repo-b")}}, originRefs, lookup))
+}
@@ -20,6 +20,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/log"
 	"github.com/apache/incubator-devlake/core/models"
@@ -89,6 +90,17 @@ func (rt *RunningTask) Remove(taskId uint64) (context.CancelFunc, errors.Error)
 	return nil, errors.NotFound.New(fmt.Sprintf("task with id %d not found", taskId))
 }
 
+// TaskIds returns ids of tasks running in the current process
+func (rt *RunningTask) TaskIds() []uint64 {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	taskIds := make([]uint64, 0, len(rt.tasks))
+	for taskId := range rt.tasks {
+		taskIds = append(taskIds, taskId)
+	}
+	return taskIds
+}
+
 var runningTasks RunningTask
 
 func init() {
@@ -107,10 +119,20 @@ func runTaskStandalone(parentLog log.Logger, taskId uint64) errors.Error {
 	if err != nil {
 		return err
 	}
+	if haEnabled() {
+		err = db.UpdateColumn(&models.Task{}, "worker_id", haWorkerId, dal.Where("id = ?", taskId))
+		if err != nil {
+			return err
+		}
+	}
+	task, err := GetTask(taskId)
+	if err != nil {
+		return err
+	}
 	// now , create a progress update channel and kick off
 	progress := make(chan plugin.RunningProgress, 100)
 	doneSignal := make(chan struct{})
-	go updateTaskProgress(doneSignal, taskId, progress)
+	go updateTaskProgress(doneSignal, task, progress)
 	err = runner.RunTask(
 		ctx,
 		basicRes.ReplaceLogger(parentLog),
@@ -130,7 +152,8 @@ func getRunningTaskById(taskId uint64) *RunningTaskData {
 	return runningTasks.tasks[taskId]
 }
 
-func updateTaskProgress(done chan struct{}, taskId uint64, progress chan plugin.RunningProgress) {
+func updateTaskProgress(done chan struct{}, task *models.Task, progress chan plugin.RunningProgress) {
+	taskId := task.ID
 	data := getRunningTaskById(taskId)
 	if data == nil {
 		return
@@ -141,7 +164,14 @@ func updateTaskProgress(done chan struct{}, taskId uint64, progress chan plugin.
 		if hasMore {
 			runningTasks.mu.Lock()
 			runner.UpdateProgressDetail(basicRes, taskId, progressDetail, &p)
+			snapshot := *progressDetail
 			runningTasks.mu.Unlock()
+			runner.PipelineEvents.Publish(&runner.PipelineEvent{
+				Type:       runner.PIPELINE_EVENT_PROGRESS,
+				PipelineId: task.PipelineId,
+				TaskId:     taskId,
+				Progress:   &snapshot,
+			})
 		} else {
 			done <- struct{}{}
 			break
@@ -39,3 +39,11 @@ func UsePostgres(host string, port int) string {
 	config.GetConfig().Set("E2E_DB_URL", conn)
 	return conn
 }
+
+// UseSQLite points the tests at the sqlite database file, no database server is needed
+func UseSQLite(path string) string {
+	conn := fmt.Sprintf("sqlite://%s", path)
+	_ = os.Setenv("E2E_DB_URL", conn)
+	config.GetConfig().Set("E2E_DB_URL", conn)
+	return conn
+}